@@ -1,29 +1,38 @@
 package app
 
 import (
+	"context"
 	"database/sql"
 	"errors"
-	"log"
-	"os"
+	"net"
+	"net/http"
+	"os/signal"
+	"synk/gateway/app/config"
+	"synk/gateway/app/controller"
 	"synk/gateway/app/util"
+	"syscall"
+	"time"
 
 	"github.com/getsentry/sentry-go"
 )
 
+const ERROR_REPORTER_FLUSH_TIMEOUT = time.Second * 2
+
 type Service struct {
-	DB *sql.DB
+	Config *config.Config
+	DB     *sql.DB
+	Client *http.Client
 }
 
-func InitSentry() error {
-	dsn := os.Getenv("SENTRY_DSN")
-
-	if dsn == "" {
-		return errors.New("sentry DSN config is missing")
-	}
-
+func InitSentry(reporter config.Reporter, env string) error {
 	err := sentry.Init(sentry.ClientOptions{
-		Dsn:              dsn,
-		TracesSampleRate: 1.0,
+		Dsn:              reporter.SentryDsn,
+		Environment:      env,
+		SampleRate:       reporter.SampleRate,
+		EnableTracing:    reporter.TracesSampleRate > 0,
+		TracesSampleRate: reporter.TracesSampleRate,
+		BeforeSend:       controller.ScrubSentryEvent,
+		BeforeBreadcrumb: controller.ScrubSentryBreadcrumb,
 	})
 
 	if err != nil {
@@ -33,20 +42,129 @@ func InitSentry() error {
 	return nil
 }
 
-func Run() {
+// InitErrorReporter sets the reporter of config: `sentry`, `stdout` or `none`.
+func InitErrorReporter(config *config.Config) error {
+	switch config.Reporter.Kind {
+	case controller.ERROR_REPORTER_SENTRY:
+		sentryErr := InitSentry(config.Reporter, config.Env)
+
+		if sentryErr != nil {
+			return sentryErr
+		}
+
+		controller.SetErrorReporter(controller.SentryReporter{})
+	case controller.ERROR_REPORTER_STDOUT:
+		controller.SetErrorReporter(controller.StdoutReporter{})
+	default:
+		controller.SetErrorReporter(controller.NoopReporter{})
+	}
+
+	util.Log("reporting errors to " + config.Reporter.Kind)
+
+	return nil
+}
+
+// Run serves the app until a shutdown signal. It returns errors instead of exiting,
+// so the database and tracing cleanup still runs.
+func Run() error {
+	config, configErr := config.Load()
+
+	if configErr != nil {
+		return configErr
+	}
+
+	util.InitLogger(config.Log)
 	util.Log("starting app")
 
-	db, dbErr := InitDB(false)
+	serverConfig := config.Server
+
+	serverCerts, serverCertsErr := ServerCerts(serverConfig)
+
+	if serverCertsErr != nil {
+		return serverCertsErr
+	}
+
+	serviceCerts, serviceCertsErr := ServiceCerts(serverConfig)
+
+	if serviceCertsErr != nil {
+		return serviceCertsErr
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	for _, certs := range []*util.CertReloader{serverCerts, serviceCerts} {
+		if certs != nil {
+			go certs.Watch(ctx, serverConfig.CertReloadInterval)
+		}
+	}
+
+	shutdownTracing, tracingErr := InitTracing(config.TracesExporter)
+
+	if tracingErr != nil {
+		return tracingErr
+	}
+
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), TRACING_SHUTDOWN_TIMEOUT)
+		defer cancel()
+
+		shutdownTracing(ctx)
+	}()
+
+	db, dbErr := OpenDB(config.DB)
 
 	if dbErr != nil {
-		log.Fatal(dbErr)
+		return dbErr
 	}
 
-	sentryErr := InitSentry()
+	defer func() {
+		util.Log("closing database")
+
+		db.Close()
+	}()
+
+	migrateErr := AutoMigrate(db, config)
 
-	if sentryErr != nil {
-		log.Fatal(sentryErr)
+	if migrateErr != nil {
+		return migrateErr
 	}
 
-	Router(&Service{DB: db})
+	reporterErr := InitErrorReporter(config)
+
+	if reporterErr != nil {
+		return reporterErr
+	}
+
+	defer controller.CurrentErrorReporter().Flush(ERROR_REPORTER_FLUSH_TIMEOUT)
+
+	handler, routerErr := Router(&Service{Config: config, DB: db, Client: controller.NewServiceClient(serviceCerts)})
+
+	if routerErr != nil {
+		return errors.New("app failed on setting up routes: " + routerErr.Error())
+	}
+
+	listener, listenErr := net.Listen("tcp", ":"+serverConfig.Port)
+
+	if listenErr != nil {
+		return errors.New("app failed on listening on port " + serverConfig.Port + ": " + listenErr.Error())
+	}
+
+	util.Log("app running on port " + serverConfig.Port)
+
+	if serverCerts != nil {
+		util.Log("serving HTTPS with " + serverConfig.CertFilePath)
+	} else {
+		util.Log("serving HTTP")
+	}
+
+	serveErr := Serve(ctx, NewServer(serverConfig, handler, serverCerts), listener, serverConfig)
+
+	util.Log("server stopped")
+
+	if serveErr != nil {
+		return errors.New("app failed on running on port " + serverConfig.Port + ": " + serveErr.Error())
+	}
+
+	return nil
 }
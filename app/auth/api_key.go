@@ -0,0 +1,43 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+)
+
+const API_KEY_PREFIX = "synk_"
+const API_KEY_BYTES = 32
+const API_KEY_DISPLAY_LENGTH = 12
+
+type ApiKey struct {
+	Key    string
+	Prefix string
+	Hash   string
+}
+
+// GenerateApiKey returns a new random key. Only Prefix and Hash should be
+// stored; Key is shown to the user once.
+func GenerateApiKey() (ApiKey, error) {
+	var apiKey ApiKey
+
+	raw := make([]byte, API_KEY_BYTES)
+
+	if _, readErr := rand.Read(raw); readErr != nil {
+		return apiKey, fmt.Errorf("auth.api_key.generate: %s", readErr.Error())
+	}
+
+	apiKey.Key = API_KEY_PREFIX + base64.RawURLEncoding.EncodeToString(raw)
+	apiKey.Prefix = apiKey.Key[:API_KEY_DISPLAY_LENGTH]
+	apiKey.Hash = HashApiKey(apiKey.Key)
+
+	return apiKey, nil
+}
+
+func HashApiKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+
+	return hex.EncodeToString(sum[:])
+}
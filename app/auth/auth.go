@@ -0,0 +1,175 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"synk/gateway/app/config"
+	"time"
+)
+
+const DEFAULT_USER_CLAIM = config.DEFAULT_USER_CLAIM
+
+type Result struct {
+	UserId int
+	Scopes []string
+	Status int
+	Error  string
+}
+
+func (r Result) Ok() bool {
+	return r.Status == http.StatusOK
+}
+
+type remoteCheckResponse struct {
+	Resource struct {
+		Ok    bool   `json:"ok"`
+		Error string `json:"error"`
+	} `json:"resource"`
+	Data struct {
+		UserId int             `json:"user_id"`
+		Scopes json.RawMessage `json:"scopes"`
+	} `json:"user"`
+}
+
+type Authenticator struct {
+	verifier *Verifier
+	cache    *Cache
+	client   *http.Client
+	endpoint string
+}
+
+func NewAuthenticator(verifier *Verifier, cache *Cache, client *http.Client, endpoint string) *Authenticator {
+	authenticator := Authenticator{
+		verifier: verifier,
+		cache:    cache,
+		client:   client,
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+	}
+
+	return &authenticator
+}
+
+// NewAuthenticatorFromConfig wires local verification from the JWT secret
+// and the JWKS URL of config, keeping its endpoint as the remote fallback.
+func NewAuthenticatorFromConfig(config config.Auth, client *http.Client) *Authenticator {
+	var verifier *Verifier
+	var jwks *JWKS
+
+	if config.JwksUrl != "" {
+		jwks = NewJWKS(config.JwksUrl, client, config.JwksCacheTtl)
+	}
+
+	if config.JwtSecret != "" || jwks != nil {
+		verifier = NewVerifier(config.JwtSecret, jwks, config.UserClaim, config.Issuer)
+	}
+
+	cache := NewCache(config.CacheTtl, config.NegativeCacheTtl)
+
+	return NewAuthenticator(verifier, cache, client, config.Endpoint)
+}
+
+func (a *Authenticator) Configured() bool {
+	return a.endpoint != "" || a.verifier != nil
+}
+
+// Authenticate checks a bearer token locally when possible, or on the auth
+// server with the request ID and trace of ctx.
+func (a *Authenticator) Authenticate(ctx context.Context, authHeader string) Result {
+	token := strings.TrimSpace(authHeader)
+
+	if len(token) > 7 && strings.EqualFold(token[:7], "Bearer ") {
+		token = strings.TrimSpace(token[7:])
+	}
+
+	if cached, ok := a.cache.Get(token); ok {
+		return cached
+	}
+
+	if a.verifier != nil {
+		claims, verifyErr := a.verifier.Verify(token)
+
+		if verifyErr == nil {
+			result := Result{UserId: claims.UserId, Scopes: claims.Scopes, Status: http.StatusOK}
+
+			var maxTtl time.Duration
+
+			if !claims.ExpiresAt.IsZero() {
+				maxTtl = time.Until(claims.ExpiresAt)
+			}
+
+			a.cache.Set(token, result, maxTtl)
+
+			return result
+		}
+
+		if !errors.Is(verifyErr, ErrNotVerifiable) {
+			result := Result{Status: http.StatusUnauthorized, Error: verifyErr.Error()}
+
+			a.cache.Set(token, result, 0)
+
+			return result
+		}
+
+		// Without an auth server to fall back on, a token that can't be
+		// checked locally, such as an opaque one, is refused.
+		if a.endpoint == "" {
+			return Result{Status: http.StatusUnauthorized, Error: verifyErr.Error()}
+		}
+	}
+
+	if a.endpoint == "" {
+		return Result{Status: http.StatusInternalServerError, Error: "auth server is not configured"}
+	}
+
+	result := a.remoteCheck(ctx, authHeader)
+
+	if result.Ok() || result.Status == http.StatusUnauthorized || result.Status == http.StatusForbidden {
+		a.cache.Set(token, result, 0)
+	}
+
+	return result
+}
+
+func (a *Authenticator) remoteCheck(ctx context.Context, authHeader string) Result {
+	authReq, authReqErr := http.NewRequestWithContext(ctx, "GET", a.endpoint+"/users/check", nil)
+
+	if authReqErr != nil {
+		return Result{Status: http.StatusInternalServerError, Error: "error while setting auth server"}
+	}
+
+	authReq.Header.Set("Authorization", authHeader)
+	authReq.Header.Set("Accept", "application/json")
+
+	authResp, authRespErr := a.client.Do(authReq)
+
+	if authRespErr != nil {
+		return Result{Status: http.StatusInternalServerError, Error: "error while contacting auth server"}
+	}
+	defer authResp.Body.Close()
+
+	bodyBytes, readErr := io.ReadAll(authResp.Body)
+
+	if readErr != nil {
+		return Result{Status: http.StatusInternalServerError, Error: "error while parsing auth server response"}
+	}
+
+	var checkResponse remoteCheckResponse
+
+	if decodeErr := json.Unmarshal(bodyBytes, &checkResponse); decodeErr != nil {
+		return Result{Status: http.StatusInternalServerError, Error: "error while decoding auth server response"}
+	}
+
+	if authResp.StatusCode != http.StatusOK && !checkResponse.Resource.Ok {
+		return Result{Status: authResp.StatusCode, Error: checkResponse.Resource.Error}
+	}
+
+	return Result{
+		UserId: checkResponse.Data.UserId,
+		Scopes: remoteScopes(checkResponse.Data.Scopes),
+		Status: http.StatusOK,
+	}
+}
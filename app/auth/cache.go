@@ -0,0 +1,96 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// Entries are swept once this many tokens are cached, dropping the expired
+// ones.
+const AUTH_CACHE_SWEEP_SIZE = 10000
+
+type cacheEntry struct {
+	result    Result
+	expiresAt time.Time
+}
+
+type Cache struct {
+	mu          sync.Mutex
+	entries     map[string]cacheEntry
+	ttl         time.Duration
+	negativeTtl time.Duration
+	now         func() time.Time
+}
+
+func NewCache(ttl time.Duration, negativeTtl time.Duration) *Cache {
+	cache := Cache{
+		entries:     map[string]cacheEntry{},
+		ttl:         ttl,
+		negativeTtl: negativeTtl,
+		now:         time.Now,
+	}
+
+	return &cache
+}
+
+func (c *Cache) Get(token string) (Result, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := cacheKey(token)
+	entry, ok := c.entries[key]
+
+	if !ok {
+		return Result{}, false
+	}
+
+	if !c.now().Before(entry.expiresAt) {
+		delete(c.entries, key)
+
+		return Result{}, false
+	}
+
+	return entry.result, true
+}
+
+// Set stores a result for the token, capping positive entries at maxTtl when
+// it is positive so a cached token never outlives its own expiration.
+func (c *Cache) Set(token string, result Result, maxTtl time.Duration) {
+	ttl := c.ttl
+
+	if !result.Ok() {
+		ttl = c.negativeTtl
+	} else if maxTtl > 0 && maxTtl < ttl {
+		ttl = maxTtl
+	}
+
+	if ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+
+	if len(c.entries) >= AUTH_CACHE_SWEEP_SIZE {
+		for key, entry := range c.entries {
+			if !now.Before(entry.expiresAt) {
+				delete(c.entries, key)
+			}
+		}
+	}
+
+	c.entries[cacheKey(token)] = cacheEntry{
+		result:    result,
+		expiresAt: now.Add(ttl),
+	}
+}
+
+func cacheKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,183 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// JWKS_MIN_REFRESH keeps tokens signed with unknown key ids from hammering the
+// JWKS endpoint with a refresh per request.
+const JWKS_MIN_REFRESH = time.Second * 30
+
+type jwk struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+type jwkSet struct {
+	Keys []jwk `json:"keys"`
+}
+
+// JWKS keeps the keys of url, fetching them again after ttl or for an unknown
+// key id. Fetches run one at a time, outside of mu, and a failed one also
+// waits JWKS_MIN_REFRESH before the next, so an endpoint that is down isn't
+// called once per request.
+type JWKS struct {
+	url         string
+	client      *http.Client
+	ttl         time.Duration
+	mu          sync.Mutex
+	fetchMu     sync.Mutex
+	keys        map[string]*rsa.PublicKey
+	fetchedAt   time.Time
+	attemptedAt time.Time
+	fetchErr    error
+	now         func() time.Time
+}
+
+func NewJWKS(url string, client *http.Client, ttl time.Duration) *JWKS {
+	jwks := JWKS{
+		url:    url,
+		client: client,
+		ttl:    ttl,
+		keys:   map[string]*rsa.PublicKey{},
+		now:    time.Now,
+	}
+
+	return &jwks
+}
+
+func (j *JWKS) Key(kid string) (*rsa.PublicKey, error) {
+	key, known, due := j.lookup(kid)
+
+	if due {
+		j.fetchMu.Lock()
+		defer j.fetchMu.Unlock()
+
+		// Another request may have fetched the keys while this one waited.
+		key, known, due = j.lookup(kid)
+	}
+
+	if due {
+		keys, fetchErr := j.fetch()
+
+		j.mu.Lock()
+		j.attemptedAt = j.now()
+		j.fetchErr = fetchErr
+
+		if fetchErr == nil {
+			j.keys = keys
+			j.fetchedAt = j.attemptedAt
+		}
+		j.mu.Unlock()
+
+		key, known, _ = j.lookup(kid)
+	}
+
+	if known {
+		return key, nil
+	}
+
+	j.mu.Lock()
+	fetchErr := j.fetchErr
+	j.mu.Unlock()
+
+	if fetchErr != nil {
+		return nil, errors.Join(ErrNotVerifiable, fetchErr)
+	}
+
+	return nil, fmt.Errorf("unknown token key id %q", kid)
+}
+
+// lookup gives the key of kid and whether the keys should be fetched first:
+// they are stale or miss kid, and the last attempt is old enough.
+func (j *JWKS) lookup(kid string) (*rsa.PublicKey, bool, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	now := j.now()
+	key, known := j.keys[kid]
+
+	if known && now.Sub(j.fetchedAt) < j.ttl {
+		return key, true, false
+	}
+
+	due := j.attemptedAt.IsZero() || now.Sub(j.attemptedAt) >= min(j.ttl, JWKS_MIN_REFRESH)
+
+	return key, known, due
+}
+
+func (j *JWKS) fetch() (map[string]*rsa.PublicKey, error) {
+	req, reqErr := http.NewRequest("GET", j.url, nil)
+
+	if reqErr != nil {
+		return nil, fmt.Errorf("auth.jwks.fetch: %s", reqErr.Error())
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	resp, respErr := j.client.Do(req)
+
+	if respErr != nil {
+		return nil, fmt.Errorf("auth.jwks.fetch: %s", respErr.Error())
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("auth.jwks.fetch: unexpected status %d", resp.StatusCode)
+	}
+
+	var set jwkSet
+
+	if decodeErr := json.NewDecoder(resp.Body).Decode(&set); decodeErr != nil {
+		return nil, fmt.Errorf("auth.jwks.fetch: %s", decodeErr.Error())
+	}
+
+	keys := map[string]*rsa.PublicKey{}
+
+	for _, item := range set.Keys {
+		if item.Kty != "RSA" {
+			continue
+		}
+
+		key, keyErr := rsaKey(item)
+
+		if keyErr != nil {
+			continue
+		}
+
+		keys[item.Kid] = key
+	}
+
+	return keys, nil
+}
+
+func rsaKey(item jwk) (*rsa.PublicKey, error) {
+	n, nErr := base64.RawURLEncoding.DecodeString(item.N)
+
+	if nErr != nil {
+		return nil, nErr
+	}
+
+	e, eErr := base64.RawURLEncoding.DecodeString(item.E)
+
+	if eErr != nil {
+		return nil, eErr
+	}
+
+	key := rsa.PublicKey{
+		N: new(big.Int).SetBytes(n),
+		E: int(new(big.Int).SetBytes(e).Int64()),
+	}
+
+	return &key, nil
+}
@@ -0,0 +1,211 @@
+package auth
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"hash"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNotVerifiable means the token could not be checked locally (unknown
+// algorithm, missing key material, no user claim) and the remote auth check
+// must decide instead.
+var ErrNotVerifiable = errors.New("token can not be verified locally")
+
+const CLOCK_LEEWAY = 30 * time.Second
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+type Claims struct {
+	UserId    int
+	Scopes    []string
+	ExpiresAt time.Time
+}
+
+type Verifier struct {
+	secret    []byte
+	jwks      *JWKS
+	userClaim string
+	issuer    string
+	now       func() time.Time
+}
+
+func NewVerifier(secret string, jwks *JWKS, userClaim string, issuer string) *Verifier {
+	verifier := Verifier{
+		secret:    []byte(secret),
+		jwks:      jwks,
+		userClaim: userClaim,
+		issuer:    issuer,
+		now:       time.Now,
+	}
+
+	return &verifier
+}
+
+func (v *Verifier) Verify(token string) (Claims, error) {
+	var claims Claims
+
+	parts := strings.Split(token, ".")
+
+	if len(parts) != 3 {
+		return claims, ErrNotVerifiable
+	}
+
+	var header jwtHeader
+
+	if decodeErr := decodeSegment(parts[0], &header); decodeErr != nil {
+		return claims, ErrNotVerifiable
+	}
+
+	signature, signatureErr := base64.RawURLEncoding.DecodeString(parts[2])
+
+	if signatureErr != nil {
+		return claims, errors.New("malformed token signature")
+	}
+
+	signed := []byte(parts[0] + "." + parts[1])
+
+	if verifyErr := v.verifySignature(header, signed, signature); verifyErr != nil {
+		return claims, verifyErr
+	}
+
+	payload := map[string]any{}
+
+	if decodeErr := decodeSegment(parts[1], &payload); decodeErr != nil {
+		return claims, errors.New("malformed token payload")
+	}
+
+	now := v.now()
+
+	if exp, ok := numericClaim(payload["exp"]); ok {
+		claims.ExpiresAt = time.Unix(exp, 0)
+
+		if now.After(claims.ExpiresAt.Add(CLOCK_LEEWAY)) {
+			return claims, errors.New("token is expired")
+		}
+	}
+
+	if nbf, ok := numericClaim(payload["nbf"]); ok && now.Add(CLOCK_LEEWAY).Before(time.Unix(nbf, 0)) {
+		return claims, errors.New("token is not valid yet")
+	}
+
+	if v.issuer != "" && payload["iss"] != v.issuer {
+		return claims, errors.New("token issuer is not accepted")
+	}
+
+	userId, ok := numericClaim(payload[v.userClaim])
+
+	if !ok || userId <= 0 {
+		return claims, ErrNotVerifiable
+	}
+
+	claims.UserId = int(userId)
+	claims.Scopes = scopesClaim(payload)
+
+	return claims, nil
+}
+
+func (v *Verifier) verifySignature(header jwtHeader, signed []byte, signature []byte) error {
+	switch header.Alg {
+	case "HS256", "HS384", "HS512":
+		if len(v.secret) == 0 {
+			return ErrNotVerifiable
+		}
+
+		mac := hmac.New(hashFor(header.Alg), v.secret)
+		mac.Write(signed)
+
+		if !hmac.Equal(mac.Sum(nil), signature) {
+			return errors.New("invalid token signature")
+		}
+
+		return nil
+	case "RS256", "RS384", "RS512":
+		if v.jwks == nil {
+			return ErrNotVerifiable
+		}
+
+		key, keyErr := v.jwks.Key(header.Kid)
+
+		if keyErr != nil {
+			return keyErr
+		}
+
+		hasher := hashFor(header.Alg)()
+		hasher.Write(signed)
+
+		if rsa.VerifyPKCS1v15(key, cryptoHashFor(header.Alg), hasher.Sum(nil), signature) != nil {
+			return errors.New("invalid token signature")
+		}
+
+		return nil
+	default:
+		return ErrNotVerifiable
+	}
+}
+
+func hashFor(alg string) func() hash.Hash {
+	switch alg[2:] {
+	case "384":
+		return sha512.New384
+	case "512":
+		return sha512.New
+	default:
+		return sha256.New
+	}
+}
+
+func cryptoHashFor(alg string) crypto.Hash {
+	switch alg[2:] {
+	case "384":
+		return crypto.SHA384
+	case "512":
+		return crypto.SHA512
+	default:
+		return crypto.SHA256
+	}
+}
+
+func decodeSegment(segment string, target any) error {
+	raw, decodeErr := base64.RawURLEncoding.DecodeString(segment)
+
+	if decodeErr != nil {
+		return decodeErr
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+
+	return decoder.Decode(target)
+}
+
+func numericClaim(value any) (int64, bool) {
+	switch typed := value.(type) {
+	case json.Number:
+		if parsed, parseErr := typed.Int64(); parseErr == nil {
+			return parsed, true
+		}
+
+		parsed, parseErr := typed.Float64()
+
+		return int64(parsed), parseErr == nil
+	case string:
+		parsed, parseErr := strconv.ParseInt(typed, 10, 64)
+
+		return parsed, parseErr == nil
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,127 @@
+package auth
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+const SCOPE_ALL = "*"
+const SCOPE_POSTS_READ = "posts:read"
+const SCOPE_POSTS_WRITE = "posts:write"
+const SCOPE_POSTS_PUBLISH = "posts:publish"
+const SCOPE_POSTS_REVIEW = "posts:review"
+const SCOPE_TEMPLATES_READ = "templates:read"
+const SCOPE_TEMPLATES_WRITE = "templates:write"
+const SCOPE_PROFILES_READ = "profiles:read"
+const SCOPE_PROFILES_WRITE = "profiles:write"
+const SCOPE_CREDENTIALS_READ = "credentials:read"
+const SCOPE_CREDENTIALS_WRITE = "credentials:write"
+const SCOPE_API_KEYS_READ = "api_keys:read"
+const SCOPE_API_KEYS_WRITE = "api_keys:write"
+const SCOPE_WORKSPACES_READ = "workspaces:read"
+const SCOPE_WORKSPACES_WRITE = "workspaces:write"
+const SCOPE_AUDIT_READ = "audit:read"
+
+var KnownScopes = []string{
+	SCOPE_POSTS_READ,
+	SCOPE_POSTS_WRITE,
+	SCOPE_POSTS_PUBLISH,
+	SCOPE_POSTS_REVIEW,
+	SCOPE_TEMPLATES_READ,
+	SCOPE_TEMPLATES_WRITE,
+	SCOPE_PROFILES_READ,
+	SCOPE_PROFILES_WRITE,
+	SCOPE_CREDENTIALS_READ,
+	SCOPE_CREDENTIALS_WRITE,
+	SCOPE_API_KEYS_READ,
+	SCOPE_API_KEYS_WRITE,
+	SCOPE_WORKSPACES_READ,
+	SCOPE_WORKSPACES_WRITE,
+	SCOPE_AUDIT_READ,
+}
+
+// HasScope reports whether granted covers required, accepting `*` and
+// resource wildcards such as `posts:*`.
+func HasScope(granted []string, required string) bool {
+	resource, _, _ := strings.Cut(required, ":")
+
+	for _, scope := range granted {
+		if scope == SCOPE_ALL || scope == required || scope == resource+":*" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func ValidScope(scope string) bool {
+	if scope == SCOPE_ALL {
+		return true
+	}
+
+	resource, action, found := strings.Cut(scope, ":")
+
+	for _, known := range KnownScopes {
+		if known == scope {
+			return true
+		}
+
+		knownResource, _, _ := strings.Cut(known, ":")
+
+		if found && action == "*" && knownResource == resource {
+			return true
+		}
+	}
+
+	return false
+}
+
+func ParseScopes(value string) []string {
+	return strings.Fields(value)
+}
+
+func JoinScopes(scopes []string) string {
+	return strings.Join(scopes, " ")
+}
+
+// scopesClaim reads the OAuth style `scope` string or a `scopes` array. Tokens
+// without any of them are user sessions and keep full access.
+func scopesClaim(payload map[string]any) []string {
+	if value, ok := payload["scope"].(string); ok {
+		return ParseScopes(value)
+	}
+
+	if values, ok := payload["scopes"].([]any); ok {
+		scopes := []string{}
+
+		for _, value := range values {
+			if scope, ok := value.(string); ok {
+				scopes = append(scopes, scope)
+			}
+		}
+
+		return scopes
+	}
+
+	return []string{SCOPE_ALL}
+}
+
+func remoteScopes(raw json.RawMessage) []string {
+	if len(raw) == 0 || string(raw) == "null" {
+		return []string{SCOPE_ALL}
+	}
+
+	var scopes []string
+
+	if json.Unmarshal(raw, &scopes) == nil {
+		return scopes
+	}
+
+	var scope string
+
+	if json.Unmarshal(raw, &scope) == nil {
+		return ParseScopes(scope)
+	}
+
+	return []string{}
+}
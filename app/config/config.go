@@ -0,0 +1,411 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const ENV_PRODUCTION = "production"
+
+const DEFAULT_DB_NAME = "synk"
+const DEFAULT_DB_TEST_NAME = "synk_test"
+const DEFAULT_TLS_CERT_FILE_PATH = "/cert/cert.pem"
+const DEFAULT_TLS_KEY_FILE_PATH = "/cert/key.pem"
+const DEFAULT_ROOT_CERTIFICATE_FILE_PATH = "/cert/rootCA.pem"
+const DEFAULT_CORS_ALLOWED_METHODS = "POST, GET, OPTIONS, PUT, DELETE"
+const DEFAULT_CORS_ALLOWED_HEADERS = "Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, X-API-Key, X-Workspace-Id, X-Request-ID, traceparent, Idempotency-Key, If-Match"
+const DEFAULT_CORS_EXPOSED_HEADERS = "ETag, Retry-After, X-Request-ID, Idempotent-Replayed"
+const DEFAULT_USER_CLAIM = "user_id"
+
+const LOG_FORMAT_JSON = "json"
+const LOG_FORMAT_TEXT = "text"
+
+const TRACES_EXPORTER_OTLP = "otlp"
+const TRACES_EXPORTER_STDOUT = "stdout"
+const TRACES_EXPORTER_NONE = "none"
+
+const ERROR_REPORTER_SENTRY = "sentry"
+const ERROR_REPORTER_STDOUT = "stdout"
+const ERROR_REPORTER_NONE = "none"
+
+// Config holds every setting of the app, read once at startup by Load and
+// passed down to what needs it.
+type Config struct {
+	Env              string
+	Server           Server
+	DB               DB
+	DBAutoMigrate    bool
+	Auth             Auth
+	QueuerEndpoint   string
+	Cors             Cors
+	RateLimits       RateLimits
+	PublishQuota     PublishQuota
+	IdempotencyTtl   time.Duration
+	ReadinessTimeout time.Duration
+	Log              Log
+	TracesExporter   string
+	Reporter         Reporter
+}
+
+type Server struct {
+	Port               string
+	TLS                bool
+	CertFilePath       string
+	KeyFilePath        string
+	RootCertFilePath   string
+	ClientCertFilePath string
+	ClientKeyFilePath  string
+	CertReloadInterval time.Duration
+	ReadHeaderTimeout  time.Duration
+	ReadTimeout        time.Duration
+	WriteTimeout       time.Duration
+	IdleTimeout        time.Duration
+	ShutdownTimeout    time.Duration
+}
+
+type DB struct {
+	Host            string
+	Port            string
+	User            string
+	Pass            string
+	Name            string
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+}
+
+// Auth checks tokens locally with JwtSecret or JwksUrl when set, and on
+// Endpoint otherwise.
+type Auth struct {
+	Endpoint         string
+	JwtSecret        string
+	JwksUrl          string
+	JwksCacheTtl     time.Duration
+	UserClaim        string
+	Issuer           string
+	CacheTtl         time.Duration
+	NegativeCacheTtl time.Duration
+}
+
+type Cors struct {
+	AllowedOrigins []string
+	AllowedMethods string
+	AllowedHeaders string
+	ExposedHeaders string
+}
+
+// RateLimit allows Requests per Period for each user; 0 requests disables it.
+type RateLimit struct {
+	Requests int
+	Period   time.Duration
+}
+
+type RateLimits struct {
+	Read    RateLimit
+	Write   RateLimit
+	Publish RateLimit
+}
+
+// PublishQuota caps publishes per UTC day for each user and each integration
+// profile; 0 disables a cap.
+type PublishQuota struct {
+	User       int
+	IntProfile int
+}
+
+type Log struct {
+	Format string
+	Level  slog.Level
+}
+
+type Reporter struct {
+	Kind             string
+	SentryDsn        string
+	SampleRate       float64
+	TracesSampleRate float64
+}
+
+// Default gives the settings used when nothing is set, outside production.
+func Default() *Config {
+	config := Config{
+		Server: Server{
+			TLS:                true,
+			CertFilePath:       DEFAULT_TLS_CERT_FILE_PATH,
+			KeyFilePath:        DEFAULT_TLS_KEY_FILE_PATH,
+			RootCertFilePath:   DEFAULT_ROOT_CERTIFICATE_FILE_PATH,
+			CertReloadInterval: time.Minute,
+			ReadHeaderTimeout:  5 * time.Second,
+			ReadTimeout:        15 * time.Second,
+			WriteTimeout:       30 * time.Second,
+			IdleTimeout:        60 * time.Second,
+			ShutdownTimeout:    30 * time.Second,
+		},
+		DB: DB{
+			Name:            DEFAULT_DB_NAME,
+			MaxOpenConns:    10,
+			MaxIdleConns:    5,
+			ConnMaxLifetime: 30 * time.Minute,
+			ConnMaxIdleTime: 5 * time.Minute,
+		},
+		Auth: Auth{
+			JwksCacheTtl:     10 * time.Minute,
+			UserClaim:        DEFAULT_USER_CLAIM,
+			CacheTtl:         60 * time.Second,
+			NegativeCacheTtl: 10 * time.Second,
+		},
+		Cors: Cors{
+			AllowedMethods: DEFAULT_CORS_ALLOWED_METHODS,
+			AllowedHeaders: DEFAULT_CORS_ALLOWED_HEADERS,
+			ExposedHeaders: DEFAULT_CORS_EXPOSED_HEADERS,
+		},
+		RateLimits: RateLimits{
+			Read:    RateLimit{Requests: 300, Period: time.Minute},
+			Write:   RateLimit{Requests: 60, Period: time.Minute},
+			Publish: RateLimit{Requests: 10, Period: time.Minute},
+		},
+		PublishQuota: PublishQuota{
+			User:       100,
+			IntProfile: 50,
+		},
+		IdempotencyTtl:   24 * time.Hour,
+		ReadinessTimeout: 2 * time.Second,
+		Log: Log{
+			Format: LOG_FORMAT_JSON,
+			Level:  slog.LevelInfo,
+		},
+		TracesExporter: TRACES_EXPORTER_NONE,
+		Reporter: Reporter{
+			Kind:       ERROR_REPORTER_STDOUT,
+			SampleRate: 1,
+		},
+	}
+
+	return &config
+}
+
+func (c *Config) Production() bool {
+	return c.Env == ENV_PRODUCTION
+}
+
+// Load reads the settings from env and, for the ones env doesn't set, from
+// the dotenv file at CONFIG_FILE, if any. Every invalid or missing setting is
+// reported in the returned error, so they can all be fixed at once.
+func Load() (*Config, error) {
+	source, sourceErr := newSource()
+
+	if sourceErr != nil {
+		return nil, sourceErr
+	}
+
+	config := Default()
+	l := loader{source: source}
+
+	config.Env = l.string("ENV", "")
+
+	if config.Production() {
+		config.Server.TLS = false
+		config.Server.RootCertFilePath = ""
+	}
+
+	l.server(&config.Server)
+
+	config.DB = l.db(false)
+	config.DBAutoMigrate = l.bool("DB_AUTO_MIGRATE", false)
+
+	config.Auth.Endpoint = l.url("AUTH_ENDPOINT")
+	config.Auth.JwtSecret = l.string("AUTH_JWT_SECRET", "")
+	config.Auth.JwksUrl = l.url("AUTH_JWKS_URL")
+	config.Auth.JwksCacheTtl = l.duration("AUTH_JWKS_CACHE_TTL", config.Auth.JwksCacheTtl)
+	config.Auth.UserClaim = l.string("AUTH_JWT_USER_CLAIM", config.Auth.UserClaim)
+	config.Auth.Issuer = l.string("AUTH_JWT_ISSUER", "")
+	config.Auth.CacheTtl = l.duration("AUTH_CACHE_TTL", config.Auth.CacheTtl)
+	config.Auth.NegativeCacheTtl = l.duration("AUTH_NEGATIVE_CACHE_TTL", config.Auth.NegativeCacheTtl)
+
+	if config.Auth.Endpoint == "" && config.Auth.JwtSecret == "" && config.Auth.JwksUrl == "" {
+		l.fail("AUTH_ENDPOINT, AUTH_JWT_SECRET or AUTH_JWKS_URL must be set to check tokens")
+	}
+
+	config.QueuerEndpoint = l.url("QUEUER_ENDPOINT")
+	l.require("QUEUER_ENDPOINT", config.QueuerEndpoint)
+
+	for _, origin := range splitList(l.string("WEB_ENDPOINT", "")) {
+		if validUrl(origin) {
+			config.Cors.AllowedOrigins = append(config.Cors.AllowedOrigins, origin)
+		} else {
+			l.fail(fmt.Sprintf("WEB_ENDPOINT must list http(s) origins, got %q", origin))
+		}
+	}
+
+	config.Cors.AllowedMethods = l.string("CORS_ALLOWED_METHODS", config.Cors.AllowedMethods)
+	config.Cors.AllowedHeaders = l.string("CORS_ALLOWED_HEADERS", config.Cors.AllowedHeaders)
+	config.Cors.ExposedHeaders = l.string("CORS_EXPOSED_HEADERS", config.Cors.ExposedHeaders)
+
+	config.RateLimits.Read = l.rateLimit("RATE_LIMIT_READ", config.RateLimits.Read)
+	config.RateLimits.Write = l.rateLimit("RATE_LIMIT_WRITE", config.RateLimits.Write)
+	config.RateLimits.Publish = l.rateLimit("RATE_LIMIT_PUBLISH", config.RateLimits.Publish)
+
+	config.PublishQuota.User = l.count("PUBLISH_DAILY_QUOTA_USER", config.PublishQuota.User)
+	config.PublishQuota.IntProfile = l.count("PUBLISH_DAILY_QUOTA_PROFILE", config.PublishQuota.IntProfile)
+
+	config.IdempotencyTtl = l.positiveDuration("IDEMPOTENCY_TTL", config.IdempotencyTtl)
+	config.ReadinessTimeout = l.positiveDuration("READINESS_TIMEOUT", config.ReadinessTimeout)
+
+	config.Log.Format = l.oneOf("LOG_FORMAT", strings.ToLower(l.string("LOG_FORMAT", config.Log.Format)), LOG_FORMAT_JSON, LOG_FORMAT_TEXT)
+
+	if value := l.string("LOG_LEVEL", ""); value != "" {
+		if levelErr := config.Log.Level.UnmarshalText([]byte(value)); levelErr != nil {
+			l.fail(fmt.Sprintf("LOG_LEVEL must be debug, info, warn or error, got %q", value))
+		}
+	}
+
+	config.TracesExporter = l.oneOf("OTEL_TRACES_EXPORTER", l.string("OTEL_TRACES_EXPORTER", config.TracesExporter), TRACES_EXPORTER_OTLP, TRACES_EXPORTER_STDOUT, TRACES_EXPORTER_NONE)
+
+	config.Reporter.SentryDsn = l.string("SENTRY_DSN", "")
+
+	if config.Reporter.SentryDsn != "" {
+		config.Reporter.Kind = ERROR_REPORTER_SENTRY
+	}
+
+	config.Reporter.Kind = l.oneOf("ERROR_REPORTER", l.string("ERROR_REPORTER", config.Reporter.Kind), ERROR_REPORTER_SENTRY, ERROR_REPORTER_STDOUT, ERROR_REPORTER_NONE)
+	config.Reporter.SampleRate = l.rate("SENTRY_SAMPLE_RATE", config.Reporter.SampleRate)
+	config.Reporter.TracesSampleRate = l.rate("SENTRY_TRACES_SAMPLE_RATE", config.Reporter.TracesSampleRate)
+
+	if config.Reporter.Kind == ERROR_REPORTER_SENTRY {
+		l.require("SENTRY_DSN", config.Reporter.SentryDsn)
+	}
+
+	if reportErr := l.report(); reportErr != nil {
+		return nil, reportErr
+	}
+
+	return config, nil
+}
+
+// LoadDB reads only the database settings, for the migrate command and the
+// tests. With testing, DB_*_TEST override them, falling back to DB_*, and
+// DB_NAME_TEST must name another database than DB_NAME.
+func LoadDB(testing bool) (DB, error) {
+	source, sourceErr := newSource()
+
+	if sourceErr != nil {
+		return DB{}, sourceErr
+	}
+
+	l := loader{source: source}
+	config := l.db(testing)
+
+	return config, l.report()
+}
+
+func (l *loader) server(config *Server) {
+	config.Port = l.string("PORT", "")
+
+	if port, portErr := strconv.Atoi(config.Port); config.Port == "" {
+		l.require("PORT", config.Port)
+	} else if portErr != nil || port <= 0 || port > 65535 {
+		l.fail(fmt.Sprintf("PORT must be a port number, got %q", config.Port))
+	}
+
+	config.TLS = l.bool("TLS_ENABLED", config.TLS)
+	config.CertFilePath = l.string("TLS_CERT_FILE_PATH", config.CertFilePath)
+	config.KeyFilePath = l.string("TLS_KEY_FILE_PATH", config.KeyFilePath)
+	config.RootCertFilePath = l.string("ROOT_CERTIFICATE_FILE_PATH", config.RootCertFilePath)
+	config.ClientCertFilePath = l.string("TLS_CLIENT_CERT_FILE_PATH", "")
+	config.ClientKeyFilePath = l.string("TLS_CLIENT_KEY_FILE_PATH", "")
+
+	if (config.ClientCertFilePath == "") != (config.ClientKeyFilePath == "") {
+		l.fail("TLS_CLIENT_CERT_FILE_PATH and TLS_CLIENT_KEY_FILE_PATH must be set together")
+	}
+
+	config.CertReloadInterval = l.positiveDuration("CERT_RELOAD_INTERVAL", config.CertReloadInterval)
+	config.ReadHeaderTimeout = l.positiveDuration("SERVER_READ_HEADER_TIMEOUT", config.ReadHeaderTimeout)
+	config.ReadTimeout = l.positiveDuration("SERVER_READ_TIMEOUT", config.ReadTimeout)
+	config.WriteTimeout = l.positiveDuration("SERVER_WRITE_TIMEOUT", config.WriteTimeout)
+	config.IdleTimeout = l.positiveDuration("SERVER_IDLE_TIMEOUT", config.IdleTimeout)
+	config.ShutdownTimeout = l.positiveDuration("SHUTDOWN_TIMEOUT", config.ShutdownTimeout)
+}
+
+func (l *loader) db(testing bool) DB {
+	config := Default().DB
+
+	config.Host = l.string("DB_HOST", "")
+	config.Port = l.string("DB_PORT", "")
+	config.User = l.string("DB_USER", "")
+	config.Pass = l.string("DB_PASS", "")
+	config.Name = l.string("DB_NAME", config.Name)
+
+	if testing {
+		productionName := config.Name
+
+		config.Host = l.string("DB_HOST_TEST", config.Host)
+		config.Port = l.string("DB_PORT_TEST", config.Port)
+		config.User = l.string("DB_USER_TEST", config.User)
+		config.Pass = l.string("DB_PASS_TEST", config.Pass)
+		config.Name = l.string("DB_NAME_TEST", DEFAULT_DB_TEST_NAME)
+
+		if config.Name == productionName {
+			l.fail("DB_NAME_TEST must point to a different database than DB_NAME")
+		}
+	} else {
+		l.require("DB_HOST", config.Host)
+		l.require("DB_PORT", config.Port)
+		l.require("DB_USER", config.User)
+	}
+
+	config.MaxOpenConns = l.count("DB_MAX_OPEN_CONNS", config.MaxOpenConns)
+	config.MaxIdleConns = l.count("DB_MAX_IDLE_CONNS", config.MaxIdleConns)
+	config.ConnMaxLifetime = l.duration("DB_CONN_MAX_LIFETIME", config.ConnMaxLifetime)
+	config.ConnMaxIdleTime = l.duration("DB_CONN_MAX_IDLE_TIME", config.ConnMaxIdleTime)
+
+	return config
+}
+
+// ParseRateLimit reads `<requests>/<period>` such as `60/1m`. `0` or `off`
+// disables the limit.
+func ParseRateLimit(value string) (RateLimit, error) {
+	value = strings.TrimSpace(value)
+
+	if value == "0" || value == "off" {
+		return RateLimit{}, nil
+	}
+
+	requests, period, found := strings.Cut(value, "/")
+
+	if !found {
+		return RateLimit{}, fmt.Errorf("rate limit must look like 60/1m, got %q", value)
+	}
+
+	burst, burstErr := strconv.Atoi(requests)
+	duration, durationErr := time.ParseDuration(period)
+
+	if burstErr != nil || durationErr != nil || burst <= 0 || duration <= 0 {
+		return RateLimit{}, fmt.Errorf("rate limit must look like 60/1m, got %q", value)
+	}
+
+	return RateLimit{Requests: burst, Period: duration}, nil
+}
+
+func splitList(value string) []string {
+	items := []string{}
+
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+
+		if item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+func validUrl(value string) bool {
+	parsed, parseErr := url.Parse(value)
+
+	return parseErr == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
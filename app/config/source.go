@@ -0,0 +1,253 @@
+package config
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// source looks settings up in env, then in the values of CONFIG_FILE. Empty
+// env values count as unset, as docker-compose passes unset variables empty.
+type source struct {
+	file map[string]string
+}
+
+func newSource() (source, error) {
+	path := os.Getenv("CONFIG_FILE")
+
+	if path == "" {
+		return source{}, nil
+	}
+
+	file, fileErr := ReadFile(path)
+
+	if fileErr != nil {
+		return source{}, fileErr
+	}
+
+	return source{file: file}, nil
+}
+
+func (s source) lookup(key string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+
+	return s.file[key]
+}
+
+// ReadFile reads `KEY=value` lines, as in `_setup/example.env`. Blank lines,
+// lines starting with `#` and comments after ` #` are skipped, and values may
+// be quoted.
+func ReadFile(path string) (map[string]string, error) {
+	file, openErr := os.Open(path)
+
+	if openErr != nil {
+		return nil, fmt.Errorf("config.read_file: %s", openErr.Error())
+	}
+
+	defer file.Close()
+
+	values := map[string]string{}
+	scanner := bufio.NewScanner(file)
+	line := 0
+
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		key, value, found := strings.Cut(strings.TrimPrefix(text, "export "), "=")
+
+		if !found {
+			return nil, fmt.Errorf("config.read_file: %s:%d must look like KEY=value", path, line)
+		}
+
+		value = strings.TrimSpace(value)
+
+		if unquoted, unquoteErr := strconv.Unquote(value); unquoteErr == nil {
+			value = unquoted
+		} else if comment := strings.Index(value, " #"); comment >= 0 {
+			value = strings.TrimSpace(value[:comment])
+		} else if strings.HasPrefix(value, "#") {
+			value = ""
+		}
+
+		values[strings.TrimSpace(key)] = value
+	}
+
+	if scanErr := scanner.Err(); scanErr != nil {
+		return nil, fmt.Errorf("config.read_file: %s", scanErr.Error())
+	}
+
+	return values, nil
+}
+
+// loader reads typed settings, keeping the fallback and recording a problem
+// for each invalid value.
+type loader struct {
+	source   source
+	problems []string
+}
+
+func (l *loader) fail(problem string) {
+	l.problems = append(l.problems, problem)
+}
+
+func (l *loader) report() error {
+	if len(l.problems) == 0 {
+		return nil
+	}
+
+	return errors.New("invalid config:\n  - " + strings.Join(l.problems, "\n  - "))
+}
+
+func (l *loader) require(key string, value string) {
+	if value == "" {
+		l.fail(key + " is required")
+	}
+}
+
+func (l *loader) string(key string, fallback string) string {
+	if value := strings.TrimSpace(l.source.lookup(key)); value != "" {
+		return value
+	}
+
+	return fallback
+}
+
+func (l *loader) oneOf(key string, value string, allowed ...string) string {
+	for _, option := range allowed {
+		if value == option {
+			return value
+		}
+	}
+
+	l.fail(fmt.Sprintf("%s must be %s, got %q", key, strings.Join(allowed, ", "), value))
+
+	return value
+}
+
+func (l *loader) url(key string) string {
+	value := l.string(key, "")
+
+	if value != "" && !validUrl(value) {
+		l.fail(fmt.Sprintf("%s must be an http(s) URL, got %q", key, value))
+	}
+
+	return strings.TrimSuffix(value, "/")
+}
+
+func (l *loader) bool(key string, fallback bool) bool {
+	value := l.string(key, "")
+
+	if value == "" {
+		return fallback
+	}
+
+	parsed, parseErr := strconv.ParseBool(value)
+
+	if parseErr != nil {
+		l.fail(fmt.Sprintf("%s must be true or false, got %q", key, value))
+
+		return fallback
+	}
+
+	return parsed
+}
+
+// count reads an integer of at least 0.
+func (l *loader) count(key string, fallback int) int {
+	value := l.string(key, "")
+
+	if value == "" {
+		return fallback
+	}
+
+	parsed, parseErr := strconv.Atoi(value)
+
+	if parseErr != nil || parsed < 0 {
+		l.fail(fmt.Sprintf("%s must be a positive integer, got %q", key, value))
+
+		return fallback
+	}
+
+	return parsed
+}
+
+// rate reads a share between 0 and 1.
+func (l *loader) rate(key string, fallback float64) float64 {
+	value := l.string(key, "")
+
+	if value == "" {
+		return fallback
+	}
+
+	parsed, parseErr := strconv.ParseFloat(value, 64)
+
+	if parseErr != nil || parsed < 0 || parsed > 1 {
+		l.fail(fmt.Sprintf("%s must be a number between 0 and 1, got %q", key, value))
+
+		return fallback
+	}
+
+	return parsed
+}
+
+// duration reads a duration such as `30s` or `5m`, or a number of seconds.
+func (l *loader) duration(key string, fallback time.Duration) time.Duration {
+	value := l.string(key, "")
+
+	if value == "" {
+		return fallback
+	}
+
+	if parsed, parseErr := time.ParseDuration(value); parseErr == nil && parsed >= 0 {
+		return parsed
+	}
+
+	if seconds, parseErr := strconv.Atoi(value); parseErr == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	l.fail(fmt.Sprintf("%s must be a duration like 30s or 5m, got %q", key, value))
+
+	return fallback
+}
+
+func (l *loader) positiveDuration(key string, fallback time.Duration) time.Duration {
+	parsed := l.duration(key, fallback)
+
+	if parsed == 0 {
+		l.fail(key + " must be longer than 0")
+
+		return fallback
+	}
+
+	return parsed
+}
+
+func (l *loader) rateLimit(key string, fallback RateLimit) RateLimit {
+	value := l.string(key, "")
+
+	if value == "" {
+		return fallback
+	}
+
+	limit, parseErr := ParseRateLimit(value)
+
+	if parseErr != nil {
+		l.fail(key + ": " + parseErr.Error())
+
+		return fallback
+	}
+
+	return limit
+}
@@ -1,10 +1,9 @@
 package controller
 
 import (
-	"database/sql"
 	"encoding/json"
 	"net/http"
-	"os"
+	"synk/gateway/app/config"
 	"synk/gateway/app/model"
 	"synk/gateway/app/util"
 )
@@ -13,14 +12,17 @@ type AboutResponse struct {
 	ServerPort string `json:"server_port"`
 	AppPort    string `json:"app_port"`
 	DbWorking  bool   `json:"db_working"`
+	Version    string `json:"version"`
+	Commit     string `json:"commit"`
 }
 
 type About struct {
-	model *model.About
+	model model.AboutRepository
+	port  string
 }
 
-func NewAbout(db *sql.DB) *About {
-	about := About{model: model.NewAbout(db)}
+func NewAbout(repositories *model.Repositories, config *config.Config) *About {
+	about := About{model: repositories.About, port: config.Server.Port}
 
 	return &about
 }
@@ -28,20 +30,22 @@ func NewAbout(db *sql.DB) *About {
 func (a *About) HandleAbout(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
-	isDbWorking := a.model.Ping()
+	isDbWorking := a.model.Ping(r.Context())
 
 	response := Response{
 		Ok: true,
 		Info: AboutResponse{
-			AppPort:    os.Getenv("PORT"),
-			ServerPort: "8080",
+			AppPort:    a.port,
+			ServerPort: a.port,
 			DbWorking:  isDbWorking,
+			Version:    util.Version,
+			Commit:     util.BuildCommit(),
 		},
 	}
 
 	jsonResp, jsonErr := json.Marshal(response)
 	if jsonErr != nil {
-		util.LogRoute("/about", "error on response encoding")
+		util.LoggerFrom(r.Context()).Error("error on response encoding")
 
 		return
 	}
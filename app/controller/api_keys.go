@@ -0,0 +1,319 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"synk/gateway/app/auth"
+	"synk/gateway/app/model"
+)
+
+type ApiKeys struct {
+	model model.ApiKeysRepository
+	audit *Auditor
+}
+
+type HandleApiKeyListResponse struct {
+	Resource ResponseHeader      `json:"resource"`
+	Data     []model.ApiKeysList `json:"api_keys"`
+}
+
+type HandleApiKeyCreateResponse struct {
+	Resource ResponseHeader                 `json:"resource"`
+	Data     CreateApiKeyCreateDataResponse `json:"api_key"`
+}
+
+type CreateApiKeyCreateDataResponse struct {
+	ApiKeyId     int    `json:"api_key_id"`
+	ApiKey       string `json:"api_key"`
+	ApiKeyPrefix string `json:"api_key_prefix"`
+}
+
+type HandleApiKeyCreateRequest struct {
+	ApiKeyName   string   `json:"api_key_name"`
+	ApiKeyScopes []string `json:"api_key_scopes"`
+}
+
+type HandleApiKeyRevokeResponse struct {
+	Resource ResponseHeader           `json:"resource"`
+	Data     UpdateApiKeyDataResponse `json:"api_key"`
+}
+
+type UpdateApiKeyDataResponse struct {
+	RowsAffected int `json:"rows_affected"`
+}
+
+type HandleApiKeyRevokeRequest struct {
+	ApiKeyId int `json:"api_key_id"`
+}
+
+func NewApiKeys(repositories *model.Repositories) *ApiKeys {
+	apiKeys := ApiKeys{model: repositories.ApiKeys, audit: NewAuditor(repositories)}
+
+	return &apiKeys
+}
+
+func (a *ApiKeys) HandleList(w http.ResponseWriter, r *http.Request) {
+	SetJsonContentType(w)
+
+	response := HandleApiKeyListResponse{
+		Resource: ResponseHeader{
+			Ok: true,
+		},
+		Data: []model.ApiKeysList{},
+	}
+
+	ctxUserId := r.Context().Value(CONTEXT_USER_ID_KEY).(int)
+
+	if ctxUserId == 0 {
+		response.Resource.Ok = false
+		response.Resource.Error = "reference to user not found in context"
+
+		WriteErrorResponse(w, r, response, "/api_keys", response.Resource.Error, http.StatusInternalServerError)
+
+		return
+	}
+
+	apiKeysList, apiKeysErr := a.model.List(r.Context(), ctxUserId)
+
+	if apiKeysErr != nil {
+		response.Resource.Ok = false
+		response.Resource.Error = apiKeysErr.Error()
+
+		WriteErrorResponse(w, r, response, "/api_keys", "error on api key fetch", http.StatusInternalServerError)
+
+		return
+	}
+
+	if apiKeysList != nil {
+		response.Data = apiKeysList
+	}
+
+	WriteSuccessResponse(w, response)
+}
+
+func (a *ApiKeys) HandleCreate(w http.ResponseWriter, r *http.Request) {
+	SetJsonContentType(w)
+
+	response := HandleApiKeyCreateResponse{
+		Resource: ResponseHeader{
+			Ok: true,
+		},
+		Data: CreateApiKeyCreateDataResponse{},
+	}
+
+	ctxUserId := r.Context().Value(CONTEXT_USER_ID_KEY).(int)
+
+	if ctxUserId == 0 {
+		response.Resource.Ok = false
+		response.Resource.Error = "reference to user not found in context"
+
+		WriteErrorResponse(w, r, response, "/api_keys", response.Resource.Error, http.StatusInternalServerError)
+
+		return
+	}
+
+	bodyContent, bodyErr := io.ReadAll(r.Body)
+
+	if bodyErr != nil {
+		response.Resource.Ok = false
+		response.Resource.Error = "error on read creation body"
+
+		WriteErrorResponse(w, r, response, "/api_keys", response.Resource.Error, http.StatusBadRequest)
+
+		return
+	}
+
+	var apiKey HandleApiKeyCreateRequest
+
+	jsonErr := json.Unmarshal(bodyContent, &apiKey)
+
+	if jsonErr != nil {
+		response.Resource.Ok = false
+		response.Resource.Error = "some fields can be in invalid format"
+
+		WriteErrorResponse(w, r, response, "/api_keys", response.Resource.Error, http.StatusBadRequest)
+
+		return
+	}
+
+	apiKey.ApiKeyName = strings.TrimSpace(apiKey.ApiKeyName)
+
+	if apiKey.ApiKeyName == "" {
+		response.Resource.Ok = false
+		response.Resource.Error = "fields api_key_name is required"
+
+		WriteErrorResponse(w, r, response, "/api_keys", response.Resource.Error, http.StatusBadRequest)
+
+		return
+	}
+
+	if len(apiKey.ApiKeyScopes) == 0 {
+		response.Resource.Ok = false
+		response.Resource.Error = "fields api_key_scopes is required"
+
+		WriteErrorResponse(w, r, response, "/api_keys", response.Resource.Error, http.StatusBadRequest)
+
+		return
+	}
+
+	ctxScopes, _ := r.Context().Value(CONTEXT_SCOPES_KEY).([]string)
+
+	for _, scope := range apiKey.ApiKeyScopes {
+		if !auth.ValidScope(scope) {
+			response.Resource.Ok = false
+			response.Resource.Error = "scope " + scope + " is not valid"
+
+			WriteErrorResponse(w, r, response, "/api_keys", response.Resource.Error, http.StatusBadRequest)
+
+			return
+		}
+
+		if !auth.HasScope(ctxScopes, scope) {
+			response.Resource.Ok = false
+			response.Resource.Error = "missing scope " + scope
+
+			WriteErrorResponse(w, r, response, "/api_keys", response.Resource.Error, http.StatusForbidden)
+
+			return
+		}
+	}
+
+	generated, generateErr := auth.GenerateApiKey()
+
+	if generateErr != nil {
+		response.Resource.Ok = false
+		response.Resource.Error = generateErr.Error()
+
+		WriteErrorResponse(w, r, response, "/api_keys", "error on api key generation", http.StatusInternalServerError)
+
+		return
+	}
+
+	creationId, creationErr := a.model.Add(r.Context(), model.ApiKeyAddData{
+		ApiKeyName:   apiKey.ApiKeyName,
+		ApiKeyPrefix: generated.Prefix,
+		ApiKeyHash:   generated.Hash,
+		ApiKeyScopes: apiKey.ApiKeyScopes,
+	}, ctxUserId)
+
+	if creationErr != nil {
+		response.Resource.Ok = false
+		response.Resource.Error = creationErr.Error()
+
+		WriteErrorResponse(w, r, response, "/api_keys", "error on api key creation", http.StatusInternalServerError)
+
+		return
+	}
+
+	response.Data.ApiKeyId = creationId
+	response.Data.ApiKey = generated.Key
+	response.Data.ApiKeyPrefix = generated.Prefix
+
+	a.audit.Record(r, model.AuditActionCreate, model.AuditEntityApiKey, creationId, nil, model.ApiKeysList{
+		ApiKeyId:     creationId,
+		ApiKeyName:   apiKey.ApiKeyName,
+		ApiKeyPrefix: generated.Prefix,
+		ApiKeyScopes: apiKey.ApiKeyScopes,
+	})
+
+	WriteSuccessResponse(w, response)
+}
+
+func (a *ApiKeys) HandleRevoke(w http.ResponseWriter, r *http.Request) {
+	SetJsonContentType(w)
+
+	response := HandleApiKeyRevokeResponse{
+		Resource: ResponseHeader{
+			Ok: true,
+		},
+		Data: UpdateApiKeyDataResponse{},
+	}
+
+	ctxUserId := r.Context().Value(CONTEXT_USER_ID_KEY).(int)
+
+	if ctxUserId == 0 {
+		response.Resource.Ok = false
+		response.Resource.Error = "reference to user not found in context"
+
+		WriteErrorResponse(w, r, response, "/api_keys", response.Resource.Error, http.StatusInternalServerError)
+
+		return
+	}
+
+	bodyContent, bodyErr := io.ReadAll(r.Body)
+
+	if bodyErr != nil {
+		response.Resource.Ok = false
+		response.Resource.Error = "error on read revoke body"
+
+		WriteErrorResponse(w, r, response, "/api_keys", response.Resource.Error, http.StatusBadRequest)
+
+		return
+	}
+
+	var apiKey HandleApiKeyRevokeRequest
+
+	jsonErr := json.Unmarshal(bodyContent, &apiKey)
+
+	if jsonErr != nil {
+		response.Resource.Ok = false
+		response.Resource.Error = "some fields can be in invalid format"
+
+		WriteErrorResponse(w, r, response, "/api_keys", response.Resource.Error, http.StatusBadRequest)
+
+		return
+	}
+
+	if apiKey.ApiKeyId == 0 {
+		response.Resource.Ok = false
+		response.Resource.Error = "fields api_key_id is required"
+
+		WriteErrorResponse(w, r, response, "/api_keys", response.Resource.Error, http.StatusBadRequest)
+
+		return
+	}
+
+	apiKeyBefore := a.snapshot(r.Context(), apiKey.ApiKeyId, ctxUserId)
+
+	rowsAffected, revokeErr := a.model.Revoke(r.Context(), apiKey.ApiKeyId, ctxUserId)
+
+	if revokeErr != nil {
+		response.Resource.Ok = false
+		response.Resource.Error = revokeErr.Error()
+
+		WriteErrorResponse(w, r, response, "/api_keys", "error on api key revoke", http.StatusInternalServerError)
+
+		return
+	}
+
+	if rowsAffected == 0 {
+		response.Resource.Ok = false
+		response.Resource.Error = "api key with id " + strconv.Itoa(apiKey.ApiKeyId) + " not found"
+
+		WriteErrorResponse(w, r, response, "/api_keys", response.Resource.Error, http.StatusBadRequest)
+
+		return
+	}
+
+	response.Data.RowsAffected = rowsAffected
+
+	a.audit.Record(r, model.AuditActionDelete, model.AuditEntityApiKey, apiKey.ApiKeyId, apiKeyBefore, nil)
+
+	WriteSuccessResponse(w, response)
+}
+
+func (a *ApiKeys) snapshot(ctx context.Context, apiKeyId int, userId int) any {
+	apiKeys, _ := a.model.List(ctx, userId)
+
+	for _, apiKey := range apiKeys {
+		if apiKey.ApiKeyId == apiKeyId {
+			return apiKey
+		}
+	}
+
+	return nil
+}
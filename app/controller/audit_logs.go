@@ -0,0 +1,235 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"synk/gateway/app/model"
+	"synk/gateway/app/util"
+)
+
+const AUDIT_REDACTED = "[redacted]"
+
+// Snapshot keys holding secrets, matched exactly; keys containing any of
+// auditSensitiveParts are redacted as well.
+var auditSensitiveKeys = map[string]bool{
+	"int_credential_config": true,
+	"api_key":               true,
+	"api_key_hash":          true,
+	"user_pass":             true,
+}
+
+var auditSensitiveParts = []string{"password", "secret", "token"}
+
+type Auditor struct {
+	model model.AuditLogsRepository
+}
+
+type AuditLogs struct {
+	model model.AuditLogsRepository
+}
+
+type HandleAuditLogListResponse struct {
+	Resource ResponseHeader        `json:"resource"`
+	Data     []model.AuditLogsList `json:"audit_logs"`
+}
+
+func NewAuditor(repositories *model.Repositories) *Auditor {
+	auditor := Auditor{model: repositories.AuditLogs}
+
+	return &auditor
+}
+
+func NewAuditLogs(repositories *model.Repositories) *AuditLogs {
+	auditLogs := AuditLogs{model: repositories.AuditLogs}
+
+	return &auditLogs
+}
+
+// Record stores who did action on an entity, with redacted snapshots. It never
+// fails the request: the change is already done, so errors are only logged.
+func (a *Auditor) Record(r *http.Request, action model.AuditAction, entityType string, entityId int, before any, after any) {
+	ctxUserId, _ := r.Context().Value(CONTEXT_USER_ID_KEY).(int)
+	ctxWorkspaceId, _ := r.Context().Value(CONTEXT_WORKSPACE_ID_KEY).(int)
+	ctxRequestId, _ := r.Context().Value(CONTEXT_REQUEST_ID_KEY).(string)
+
+	// The change is committed even when the client went away, so is its audit.
+	_, addErr := a.model.Add(context.WithoutCancel(r.Context()), model.AuditLogAddData{
+		UserId:          ctxUserId,
+		WorkspaceId:     ctxWorkspaceId,
+		AuditAction:     action,
+		AuditEntityType: entityType,
+		AuditEntityId:   entityId,
+		AuditBefore:     RedactSnapshot(before),
+		AuditAfter:      RedactSnapshot(after),
+		RequestId:       ctxRequestId,
+	})
+
+	if addErr != nil {
+		util.LoggerFrom(r.Context()).Error("error on audit record: " + addErr.Error())
+	}
+}
+
+// RedactSnapshot encodes snapshot as JSON with secret fields replaced by
+// AUDIT_REDACTED. A nil snapshot gives an empty string.
+func RedactSnapshot(snapshot any) string {
+	if snapshot == nil {
+		return ""
+	}
+
+	encoded, encodeErr := json.Marshal(snapshot)
+
+	if encodeErr != nil {
+		return ""
+	}
+
+	var decoded any
+
+	if decodeErr := json.Unmarshal(encoded, &decoded); decodeErr != nil {
+		return ""
+	}
+
+	redacted, _ := json.Marshal(redactValue(decoded))
+
+	return string(redacted)
+}
+
+func redactValue(value any) any {
+	switch typed := value.(type) {
+	case map[string]any:
+		for key, item := range typed {
+			if isSensitiveKey(key) {
+				typed[key] = AUDIT_REDACTED
+			} else {
+				typed[key] = redactValue(item)
+			}
+		}
+	case []any:
+		for i, item := range typed {
+			typed[i] = redactValue(item)
+		}
+	}
+
+	return value
+}
+
+func isSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+
+	if auditSensitiveKeys[key] {
+		return true
+	}
+
+	for _, part := range auditSensitiveParts {
+		if strings.Contains(key, part) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (al *AuditLogs) HandleList(w http.ResponseWriter, r *http.Request) {
+	SetJsonContentType(w)
+
+	response := HandleAuditLogListResponse{
+		Resource: ResponseHeader{
+			Ok: true,
+		},
+		Data: []model.AuditLogsList{},
+	}
+
+	ctxUserId := r.Context().Value(CONTEXT_USER_ID_KEY).(int)
+
+	if ctxUserId == 0 {
+		response.Resource.Ok = false
+		response.Resource.Error = "reference to user not found in context"
+
+		WriteErrorResponse(w, r, response, "/audit", response.Resource.Error, http.StatusInternalServerError)
+
+		return
+	}
+
+	ctxWorkspaceId := r.Context().Value(CONTEXT_WORKSPACE_ID_KEY).(int)
+
+	if ctxWorkspaceId == 0 {
+		response.Resource.Ok = false
+		response.Resource.Error = "reference to workspace not found in context"
+
+		WriteErrorResponse(w, r, response, "/audit", response.Resource.Error, http.StatusInternalServerError)
+
+		return
+	}
+
+	query := r.URL.Query()
+
+	filter := model.AuditLogFilter{
+		AuditAction:     model.AuditAction(query.Get("audit_action")),
+		AuditEntityType: query.Get("audit_entity_type"),
+		Since:           query.Get("since"),
+		Until:           query.Get("until"),
+	}
+
+	for param, target := range map[string]*int{
+		"user_id":         &filter.UserId,
+		"audit_entity_id": &filter.AuditEntityId,
+		"limit":           &filter.Limit,
+	} {
+		value := query.Get(param)
+
+		if value == "" {
+			continue
+		}
+
+		parsed, parseErr := strconv.Atoi(value)
+
+		if parseErr != nil || parsed < 0 {
+			response.Resource.Ok = false
+			response.Resource.Error = "param " + param + " must be a positive number"
+
+			WriteErrorResponse(w, r, response, "/audit", response.Resource.Error, http.StatusBadRequest)
+
+			return
+		}
+
+		*target = parsed
+	}
+
+	for param, value := range map[string]*string{"since": &filter.Since, "until": &filter.Until} {
+		if *value == "" {
+			continue
+		}
+
+		parsed, parseErr := util.ParseTimeBound(*value, param == "until")
+
+		if parseErr != nil {
+			response.Resource.Ok = false
+			response.Resource.Error = "param " + param + " must be a date (YYYY-MM-DD) or RFC 3339 time"
+
+			WriteErrorResponse(w, r, response, "/audit", response.Resource.Error, http.StatusBadRequest)
+
+			return
+		}
+
+		*value = parsed
+	}
+
+	auditList, auditErr := al.model.List(r.Context(), filter, ctxWorkspaceId, ctxUserId)
+
+	if auditErr != nil {
+		response.Resource.Ok = false
+		response.Resource.Error = auditErr.Error()
+
+		WriteErrorResponse(w, r, response, "/audit", "error on audit fetch", http.StatusInternalServerError)
+
+		return
+	}
+
+	if auditList != nil {
+		response.Data = auditList
+	}
+
+	WriteSuccessResponse(w, response)
+}
@@ -0,0 +1,111 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const IF_MATCH_HEADER = "If-Match"
+const ETAG_HEADER = "ETag"
+
+type HandleVersionConflictResponse struct {
+	Resource ResponseHeader              `json:"resource"`
+	Data     VersionConflictDataResponse `json:"conflict"`
+}
+
+type VersionConflictDataResponse struct {
+	CurrentVersion int `json:"current_version"`
+}
+
+// ETag formats a resource version as a strong entity tag, such as `"3"`.
+func ETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// SetETag exposes the version of a single resource, so it can be sent back
+// on If-Match.
+func SetETag(w http.ResponseWriter, version int) {
+	w.Header().Set(ETAG_HEADER, ETag(version))
+}
+
+// ParseIfMatch reads the version from an If-Match header. It accepts `"3"`,
+// `W/"3"` and `3`, and `*` gives 0 to match any version.
+func ParseIfMatch(value string) (int, bool) {
+	value = strings.TrimSpace(value)
+
+	if value == "*" {
+		return 0, true
+	}
+
+	value = strings.TrimPrefix(value, "W/")
+	value = strings.Trim(value, `"`)
+
+	version, parseErr := strconv.Atoi(value)
+
+	if parseErr != nil || version <= 0 {
+		return 0, false
+	}
+
+	return version, true
+}
+
+// MatchVersion checks If-Match against the current version of a resource
+// and returns the version to update with. It answers 428 when the header is
+// missing or invalid and 412 when the version is stale.
+func MatchVersion(w http.ResponseWriter, r *http.Request, route string, entity string, id int, current int) (int, bool) {
+	response := ErrorResponse{
+		Resource: ResponseHeader{
+			Ok: false,
+		},
+	}
+
+	ifMatch := r.Header.Get(IF_MATCH_HEADER)
+
+	if ifMatch == "" {
+		response.Resource.Error = "header If-Match with the " + entity + " version is required"
+
+		WriteErrorResponse(w, r, response, route, response.Resource.Error, http.StatusPreconditionRequired)
+
+		return 0, false
+	}
+
+	version, ok := ParseIfMatch(ifMatch)
+
+	if !ok {
+		response.Resource.Error = "header If-Match must be a version such as \"1\""
+
+		WriteErrorResponse(w, r, response, route, response.Resource.Error, http.StatusPreconditionRequired)
+
+		return 0, false
+	}
+
+	if version == 0 {
+		return current, true
+	}
+
+	if version != current {
+		WriteVersionConflict(w, r, route, entity, id, current)
+
+		return 0, false
+	}
+
+	return version, true
+}
+
+// WriteVersionConflict answers 412 with the current version of the resource.
+func WriteVersionConflict(w http.ResponseWriter, r *http.Request, route string, entity string, id int, current int) {
+	response := HandleVersionConflictResponse{
+		Resource: ResponseHeader{
+			Ok:    false,
+			Error: entity + " with id " + strconv.Itoa(id) + " was modified, current version is " + strconv.Itoa(current),
+		},
+		Data: VersionConflictDataResponse{
+			CurrentVersion: current,
+		},
+	}
+
+	SetETag(w, current)
+
+	WriteErrorResponse(w, r, response, route, response.Resource.Error, http.StatusPreconditionFailed)
+}
@@ -1,18 +1,12 @@
 package controller
 
 import (
-	"context"
-	"crypto/tls"
-	"crypto/x509"
 	"encoding/json"
-	"io"
+	"log/slog"
 	"net/http"
-	"os"
-	"strings"
+	"strconv"
 	"synk/gateway/app/util"
 	"time"
-
-	"github.com/getsentry/sentry-go"
 )
 
 type Response struct {
@@ -31,38 +25,54 @@ type HandleAuthResponse struct {
 	Data     UserAuthDataResponse `json:"user"`
 }
 
+type ErrorResponse struct {
+	Resource ResponseHeader `json:"resource"`
+}
+
 type ResponseHeader struct {
-	Ok    bool   `json:"ok"`
-	Error string `json:"error"`
+	Ok        bool   `json:"ok"`
+	Error     string `json:"error"`
+	RequestId string `json:"request_id,omitempty"`
 }
 
 type ContextKey string
 
 const AUTH_TIMEOUT = time.Second * 5
-const SENTRY_LOG_TIMEOUT = time.Second * 5
 const CONTEXT_USER_ID_KEY ContextKey = "user_id"
+const CONTEXT_API_KEY_ID_KEY ContextKey = "api_key_id"
+const CONTEXT_SCOPES_KEY ContextKey = "scopes"
+const CONTEXT_WORKSPACE_ID_KEY ContextKey = "workspace_id"
+const CONTEXT_WORKSPACE_ROLE_KEY ContextKey = "workspace_role"
+const CONTEXT_REQUEST_ID_KEY ContextKey = "request_id"
+
+// WriteErrorResponse logs message and reports it to the error reporter when
+// status is a server error. Client errors only leave a breadcrumb.
+func WriteErrorResponse(w http.ResponseWriter, r *http.Request, response any, route string, message string, status int) {
+	level := slog.LevelWarn
+
+	if status >= http.StatusInternalServerError {
+		level = slog.LevelError
+	}
 
-func WriteErrorResponse(w http.ResponseWriter, response any, route string, message string, status int) {
-	defer sentry.Flush(SENTRY_LOG_TIMEOUT)
-
-	util.LogRoute(route, message)
-
-	sentry.CaptureMessage("error(@gateway" + route + "): " + message)
+	util.LoggerFrom(r.Context()).Log(r.Context(), level, message, "route", route, "status", status)
 
-	jsonResp, _ := json.Marshal(response)
+	if status >= http.StatusInternalServerError {
+		reporter.Error(r, route, message)
+	} else {
+		AddBreadcrumb(r, "response", strconv.Itoa(status)+" "+message)
+	}
 
-	w.WriteHeader(status)
-	w.Write(jsonResp)
+	writeJson(w, withRequestId(response, r), status)
 }
 
 func WriteSuccessResponse(w http.ResponseWriter, response any) {
-	defer sentry.Flush(SENTRY_LOG_TIMEOUT)
+	writeJson(w, response, http.StatusOK)
+}
 
+func writeJson(w http.ResponseWriter, response any, status int) {
 	jsonResp, _ := json.Marshal(response)
 
-	sentry.CaptureMessage("success(@gateway): " + string(jsonResp))
-
-	w.WriteHeader(http.StatusOK)
+	w.WriteHeader(status)
 	w.Write(jsonResp)
 }
 
@@ -70,149 +80,20 @@ func SetJsonContentType(w http.ResponseWriter) {
 	w.Header().Set("Content-Type", "application/json")
 }
 
-func NewServiceClient() *http.Client {
-	env := os.Getenv("ENV")
+// NewServiceClient calls the auth server and the queuer, verifying them
+// against the root CAs of certs and presenting its key pair for mTLS. Without
+// certs, it verifies them against the system roots.
+func NewServiceClient(certs *util.CertReloader) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
 
-	if env == "production" {
-		return &http.Client{
-			Timeout: AUTH_TIMEOUT,
-		}
-	}
-
-	caCert, caErr := os.ReadFile("/cert/rootCA.pem")
-	if caErr != nil {
-		util.LogRoute("/", "error reading root CA file: "+caErr.Error())
-	}
-
-	caCertPool := x509.NewCertPool()
-	caCertPool.AppendCertsFromPEM(caCert)
-
-	tlsConfig := &tls.Config{
-		RootCAs: caCertPool,
-	}
-
-	transport := &http.Transport{
-		TLSClientConfig: tlsConfig,
+	if certs != nil {
+		transport.TLSClientConfig = certs.ClientTLSConfig()
 	}
 
 	client := &http.Client{
-		Transport: transport,
+		Transport: PropagatingTransport{Base: transport},
 		Timeout:   AUTH_TIMEOUT,
 	}
 
 	return client
 }
-
-var authClient = NewServiceClient()
-
-func Cors(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		var allowedOriginsMap = map[string]struct{}{
-			strings.TrimSuffix(os.Getenv("WEB_ENDPOINT"), "/"): {},
-		}
-
-		w.Header().Set("Access-Control-Allow-Credentials", "true")
-
-		origin := r.Header.Get("Origin")
-		if _, ok := allowedOriginsMap[origin]; ok {
-			w.Header().Set("Access-Control-Allow-Origin", origin)
-			w.Header().Set("Vary", "Origin")
-		}
-
-		w.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE")
-		w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
-
-		if r.Method == "OPTIONS" {
-			w.WriteHeader(http.StatusNoContent)
-			return
-		}
-
-		response := HandleAuthResponse{
-			Resource: ResponseHeader{
-				Ok: true,
-			},
-		}
-
-		authEndpoint := os.Getenv("AUTH_ENDPOINT")
-
-		if authEndpoint == "" {
-			response.Resource.Ok = false
-			response.Resource.Error = "auth server is not configured"
-
-			WriteErrorResponse(w, response, "/", response.Resource.Error, http.StatusInternalServerError)
-
-			return
-		}
-
-		authHeader := r.Header.Get("Authorization")
-
-		if authHeader == "" {
-			response.Resource.Ok = false
-			response.Resource.Error = "Bearer Authorization header is required"
-
-			WriteErrorResponse(w, response, "/", response.Resource.Error, http.StatusBadRequest)
-
-			return
-		}
-
-		authReq, authReqErr := http.NewRequest("GET", strings.TrimSuffix(authEndpoint, "/")+"/users/check", nil)
-		if authReqErr != nil {
-			response.Resource.Ok = false
-			response.Resource.Error = "error while setting auth server"
-
-			WriteErrorResponse(w, response, "/", response.Resource.Error, http.StatusInternalServerError)
-
-			return
-		}
-
-		authReq.Header.Set("Authorization", authHeader)
-		authReq.Header.Set("Accept", "application/json")
-
-		authResp, authRespErr := authClient.Do(authReq)
-		if authRespErr != nil {
-			response.Resource.Ok = false
-			response.Resource.Error = "error while contacting auth server"
-
-			WriteErrorResponse(w, response, "/", response.Resource.Error, http.StatusInternalServerError)
-
-			return
-		}
-		defer authResp.Body.Close()
-
-		var authCheckResponse HandleAuthResponse
-
-		bodyBytes, readErr := io.ReadAll(authResp.Body)
-
-		if readErr != nil {
-			response.Resource.Ok = false
-			response.Resource.Error = "error while parsing auth server response"
-
-			WriteErrorResponse(w, response, "/", response.Resource.Error, http.StatusInternalServerError)
-
-			return
-		}
-
-		if err := json.Unmarshal(bodyBytes, &authCheckResponse); err != nil {
-			response.Resource.Ok = false
-			response.Resource.Error = "error while decoding auth server response"
-
-			WriteErrorResponse(w, response, "/", response.Resource.Error, http.StatusInternalServerError)
-
-			return
-		}
-
-		if authResp.StatusCode != http.StatusOK && !authCheckResponse.Resource.Ok {
-			response.Resource.Ok = false
-			response.Resource.Error = authCheckResponse.Resource.Error
-
-			WriteErrorResponse(w, response, "/", response.Resource.Error, authResp.StatusCode)
-
-			return
-		}
-
-		ctx := r.Context()
-		ctx = context.WithValue(ctx, CONTEXT_USER_ID_KEY, authCheckResponse.Data.UserId)
-
-		next.ServeHTTP(w, r.WithContext(ctx))
-	})
-}
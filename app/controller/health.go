@@ -0,0 +1,183 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"synk/gateway/app/config"
+	"synk/gateway/app/model"
+	"synk/gateway/app/util"
+	"time"
+)
+
+const HEALTH_STATUS_UP = "up"
+const HEALTH_STATUS_DOWN = "down"
+const HEALTH_STATUS_SKIPPED = "skipped"
+
+type HandleHealthResponse struct {
+	Resource ResponseHeader `json:"resource"`
+	Status   string         `json:"status"`
+}
+
+type HandleReadinessResponse struct {
+	Resource ResponseHeader                    `json:"resource"`
+	Checks   map[string]ReadinessCheckResponse `json:"checks"`
+}
+
+type ReadinessCheckResponse struct {
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// readinessCheck is skipped when check is nil, such as for a dependency
+// without endpoint.
+type readinessCheck struct {
+	name  string
+	check func(ctx context.Context) error
+}
+
+type Health struct {
+	checks  []readinessCheck
+	timeout time.Duration
+}
+
+// NewHealth checks the database, the auth server and the queuer, each within
+// the readiness timeout of config.
+func NewHealth(repositories *model.Repositories, client *http.Client, config *config.Config) *Health {
+	health := Health{
+		checks: []readinessCheck{
+			{name: "database", check: func(ctx context.Context) error {
+				if !repositories.About.Ping(ctx) {
+					return errors.New("database ping failed")
+				}
+
+				return nil
+			}},
+			{name: "auth", check: reachable(client, config.Auth.Endpoint)},
+			{name: "queuer", check: reachable(client, config.QueuerEndpoint)},
+		},
+		timeout: config.ReadinessTimeout,
+	}
+
+	return &health
+}
+
+// HandleLive answers while the process can serve requests, without checking
+// dependencies.
+func (h *Health) HandleLive(w http.ResponseWriter, r *http.Request) {
+	SetJsonContentType(w)
+
+	WriteSuccessResponse(w, HandleHealthResponse{
+		Resource: ResponseHeader{
+			Ok: true,
+		},
+		Status: HEALTH_STATUS_UP,
+	})
+}
+
+// HandleReady answers 503 when a dependency is down, with the status and
+// latency of each one.
+func (h *Health) HandleReady(w http.ResponseWriter, r *http.Request) {
+	SetJsonContentType(w)
+
+	response := HandleReadinessResponse{
+		Resource: ResponseHeader{
+			Ok: true,
+		},
+		Checks: map[string]ReadinessCheckResponse{},
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for _, dependency := range h.checks {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			result := h.run(r.Context(), dependency)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			response.Checks[dependency.name] = result
+		}()
+	}
+
+	wg.Wait()
+
+	down := []string{}
+
+	for _, dependency := range h.checks {
+		if response.Checks[dependency.name].Status == HEALTH_STATUS_DOWN {
+			down = append(down, dependency.name)
+		}
+	}
+
+	if len(down) == 0 {
+		WriteSuccessResponse(w, response)
+
+		return
+	}
+
+	response.Resource.Ok = false
+	response.Resource.Error = "not ready, down: " + strings.Join(down, ", ")
+
+	util.LoggerFrom(r.Context()).Warn(response.Resource.Error)
+
+	writeJson(w, withRequestId(response, r), http.StatusServiceUnavailable)
+}
+
+func (h *Health) run(ctx context.Context, dependency readinessCheck) ReadinessCheckResponse {
+	if dependency.check == nil {
+		return ReadinessCheckResponse{Status: HEALTH_STATUS_SKIPPED}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	start := time.Now()
+	checkErr := dependency.check(ctx)
+	latencyMs := float64(time.Since(start).Microseconds()) / 1000
+
+	if checkErr != nil {
+		return ReadinessCheckResponse{Status: HEALTH_STATUS_DOWN, LatencyMs: latencyMs, Error: checkErr.Error()}
+	}
+
+	return ReadinessCheckResponse{Status: HEALTH_STATUS_UP, LatencyMs: latencyMs}
+}
+
+// reachable checks that endpoint answers without a server error. Any other
+// status, such as 404 on its root, means it is up.
+func reachable(client *http.Client, endpoint string) func(ctx context.Context) error {
+	if endpoint == "" {
+		return nil
+	}
+
+	return func(ctx context.Context) error {
+		req, reqErr := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+
+		if reqErr != nil {
+			return reqErr
+		}
+
+		resp, respErr := client.Do(req)
+
+		if respErr != nil {
+			return respErr
+		}
+
+		defer resp.Body.Close()
+
+		if resp.StatusCode >= http.StatusInternalServerError {
+			return errors.New("answered " + strconv.Itoa(resp.StatusCode))
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,164 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strconv"
+	"synk/gateway/app/model"
+	"synk/gateway/app/util"
+	"time"
+)
+
+const IDEMPOTENCY_KEY_HEADER = "Idempotency-Key"
+const IDEMPOTENCY_REPLAYED_HEADER = "Idempotent-Replayed"
+const IDEMPOTENCY_KEY_MAX_LENGTH = 255
+
+type bodyRecorder struct {
+	statusRecorder
+	body bytes.Buffer
+}
+
+func (b *bodyRecorder) Write(content []byte) (int, error) {
+	b.body.Write(content)
+
+	return b.ResponseWriter.Write(content)
+}
+
+// Idempotency stores the first response of each Idempotency-Key of a user for
+// ttl and replays it for retries. Reusing a key with another body, route or
+// workspace gives 422, and retrying while the first request still runs gives
+// 409. Failed requests (5xx, 429 or a panic) release the key. It must run
+// after Auth.
+func Idempotency(keys model.IdempotencyKeysRepository, ttl time.Duration) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			idempotencyKey := r.Header.Get(IDEMPOTENCY_KEY_HEADER)
+			ctxUserId, _ := r.Context().Value(CONTEXT_USER_ID_KEY).(int)
+
+			if idempotencyKey == "" || ctxUserId == 0 {
+				next.ServeHTTP(w, r)
+
+				return
+			}
+
+			SetJsonContentType(w)
+
+			response := ErrorResponse{
+				Resource: ResponseHeader{
+					Ok: false,
+				},
+			}
+
+			if len(idempotencyKey) > IDEMPOTENCY_KEY_MAX_LENGTH {
+				response.Resource.Error = "Idempotency-Key must have at most " + strconv.Itoa(IDEMPOTENCY_KEY_MAX_LENGTH) + " characters"
+
+				WriteErrorResponse(w, r, response, r.URL.Path, response.Resource.Error, http.StatusBadRequest)
+
+				return
+			}
+
+			bodyContent, bodyErr := io.ReadAll(r.Body)
+
+			if bodyErr != nil {
+				response.Resource.Error = "error on read request body"
+
+				WriteErrorResponse(w, r, response, r.URL.Path, response.Resource.Error, http.StatusBadRequest)
+
+				return
+			}
+
+			r.Body = io.NopCloser(bytes.NewReader(bodyContent))
+
+			key := model.IdempotencyKeyData{
+				UserId:         ctxUserId,
+				IdempotencyKey: idempotencyKey,
+				RequestHash:    idempotencyHash(r, bodyContent),
+			}
+
+			stored, reserved, reserveErr := keys.Reserve(r.Context(), key, ttl)
+
+			if reserveErr != nil {
+				response.Resource.Error = "error on idempotency key check"
+
+				WriteErrorResponse(w, r, response, r.URL.Path, reserveErr.Error(), http.StatusInternalServerError)
+
+				return
+			}
+
+			if !reserved {
+				replayIdempotent(w, r, response, key, stored)
+
+				return
+			}
+
+			recorder := &bodyRecorder{statusRecorder: statusRecorder{ResponseWriter: w, status: http.StatusOK}}
+
+			// The key is settled even when the client already went away, or its
+			// retries would get 409 until the key expires.
+			settleCtx := context.WithoutCancel(r.Context())
+
+			defer func() {
+				if recovered := recover(); recovered != nil {
+					keys.Release(settleCtx, ctxUserId, idempotencyKey)
+
+					panic(recovered)
+				}
+			}()
+
+			next.ServeHTTP(recorder, r)
+
+			if recorder.status >= http.StatusInternalServerError || recorder.status == http.StatusTooManyRequests {
+				if releaseErr := keys.Release(settleCtx, ctxUserId, idempotencyKey); releaseErr != nil {
+					util.LoggerFrom(r.Context()).Error(releaseErr.Error())
+				}
+
+				return
+			}
+
+			key.ResponseStatus = recorder.status
+			key.ResponseBody = recorder.body.String()
+
+			if completeErr := keys.Complete(settleCtx, key); completeErr != nil {
+				util.LoggerFrom(r.Context()).Error(completeErr.Error())
+			}
+		})
+	}
+}
+
+func replayIdempotent(w http.ResponseWriter, r *http.Request, response ErrorResponse, key model.IdempotencyKeyData, stored model.IdempotencyKeyData) {
+	if stored.RequestHash != key.RequestHash {
+		response.Resource.Error = "Idempotency-Key was already used with another request"
+
+		WriteErrorResponse(w, r, response, r.URL.Path, response.Resource.Error, http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	if stored.ResponseStatus == 0 {
+		response.Resource.Error = "request with this Idempotency-Key is still in progress"
+
+		WriteErrorResponse(w, r, response, r.URL.Path, response.Resource.Error, http.StatusConflict)
+
+		return
+	}
+
+	w.Header().Set(IDEMPOTENCY_REPLAYED_HEADER, "true")
+	w.WriteHeader(stored.ResponseStatus)
+	w.Write([]byte(stored.ResponseBody))
+}
+
+// idempotencyHash binds a key to the route, workspace and body of the first
+// request.
+func idempotencyHash(r *http.Request, bodyContent []byte) string {
+	ctxWorkspaceId, _ := r.Context().Value(CONTEXT_WORKSPACE_ID_KEY).(int)
+
+	hash := sha256.New()
+	hash.Write([]byte(r.Method + " " + r.URL.Path + " " + strconv.Itoa(ctxWorkspaceId) + "\n"))
+	hash.Write(bodyContent)
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -1,7 +1,7 @@
 package controller
 
 import (
-	"database/sql"
+	"context"
 	"encoding/json"
 	"io"
 	"net/http"
@@ -12,8 +12,9 @@ import (
 )
 
 type IntCredentials struct {
-	model      *model.IntCredentials
-	ColorModel *model.Colors
+	model      model.IntCredentialsRepository
+	ColorModel model.ColorsRepository
+	audit      *Auditor
 }
 
 type HandleIntCredentialsBasicListResponse struct {
@@ -61,10 +62,11 @@ type HandleIntCredentialDeleteRequest struct {
 	IntCredentialId int `json:"int_credential_id"`
 }
 
-func NewIntCredentials(db *sql.DB) *IntCredentials {
+func NewIntCredentials(repositories *model.Repositories) *IntCredentials {
 	intCredentials := IntCredentials{
-		model:      model.NewIntCredentials(db),
-		ColorModel: model.NewColors(db),
+		model:      repositories.IntCredentials,
+		ColorModel: repositories.Colors,
+		audit:      NewAuditor(repositories),
 	}
 
 	return &intCredentials
@@ -86,12 +88,23 @@ func (ic *IntCredentials) HandleBasicList(w http.ResponseWriter, r *http.Request
 		response.Resource.Ok = false
 		response.Resource.Error = "reference to user not found in context"
 
-		WriteErrorResponse(w, response, "/int_profiles/basic", response.Resource.Error, http.StatusInternalServerError)
+		WriteErrorResponse(w, r, response, "/int_profiles/basic", response.Resource.Error, http.StatusInternalServerError)
 
 		return
 	}
 
-	intCredentialsList, intProfilesErr := ic.model.BasicList(ctxUserId)
+	ctxWorkspaceId := r.Context().Value(CONTEXT_WORKSPACE_ID_KEY).(int)
+
+	if ctxWorkspaceId == 0 {
+		response.Resource.Ok = false
+		response.Resource.Error = "reference to workspace not found in context"
+
+		WriteErrorResponse(w, r, response, "/int_profiles/basic", response.Resource.Error, http.StatusInternalServerError)
+
+		return
+	}
+
+	intCredentialsList, intProfilesErr := ic.model.BasicList(r.Context(), ctxWorkspaceId)
 
 	if intProfilesErr != nil {
 		response.Resource.Ok = false
@@ -102,7 +115,7 @@ func (ic *IntCredentials) HandleBasicList(w http.ResponseWriter, r *http.Request
 
 	jsonResp, jsonErr := json.Marshal(response)
 	if jsonErr != nil {
-		util.LogRoute("/int_profiles/basic", "error on response encoding")
+		util.LoggerFrom(r.Context()).Error("error on response encoding")
 
 		return
 	}
@@ -111,7 +124,7 @@ func (ic *IntCredentials) HandleBasicList(w http.ResponseWriter, r *http.Request
 
 	_, writeErr := w.Write(jsonResp)
 	if writeErr != nil {
-		util.LogRoute("/int_profiles/basic", "error on response log")
+		util.LoggerFrom(r.Context()).Error("error on response log")
 	}
 }
 
@@ -134,24 +147,39 @@ func (ic *IntCredentials) HandleList(w http.ResponseWriter, r *http.Request) {
 		response.Resource.Ok = false
 		response.Resource.Error = "reference to user not found in context"
 
-		WriteErrorResponse(w, response, "/int_credentials", response.Resource.Error, http.StatusInternalServerError)
+		WriteErrorResponse(w, r, response, "/int_credentials", response.Resource.Error, http.StatusInternalServerError)
+
+		return
+	}
+
+	ctxWorkspaceId := r.Context().Value(CONTEXT_WORKSPACE_ID_KEY).(int)
+
+	if ctxWorkspaceId == 0 {
+		response.Resource.Ok = false
+		response.Resource.Error = "reference to workspace not found in context"
+
+		WriteErrorResponse(w, r, response, "/int_credentials", response.Resource.Error, http.StatusInternalServerError)
 
 		return
 	}
 
-	intCredentialList, intCrendentialErr := ic.model.List(intCredentialId, includeConfig == "1", ctxUserId)
+	intCredentialList, intCrendentialErr := ic.model.List(r.Context(), intCredentialId, includeConfig == "1", ctxWorkspaceId)
 
 	if intCrendentialErr != nil {
 		response.Resource.Ok = false
 		response.Resource.Error = intCrendentialErr.Error()
 
-		WriteErrorResponse(w, response, "/int_credentials", "error on integration credentials fetch", http.StatusInternalServerError)
+		WriteErrorResponse(w, r, response, "/int_credentials", "error on integration credentials fetch", http.StatusInternalServerError)
 
 		return
 	}
 
 	response.Data = intCredentialList
 
+	if intCredentialId != "" && len(intCredentialList) == 1 {
+		SetETag(w, intCredentialList[0].IntCredentialVersion)
+	}
+
 	WriteSuccessResponse(w, response)
 }
 
@@ -171,7 +199,18 @@ func (ic *IntCredentials) HandleCreate(w http.ResponseWriter, r *http.Request) {
 		response.Resource.Ok = false
 		response.Resource.Error = "reference to user not found in context"
 
-		WriteErrorResponse(w, response, "/int_credentials", response.Resource.Error, http.StatusInternalServerError)
+		WriteErrorResponse(w, r, response, "/int_credentials", response.Resource.Error, http.StatusInternalServerError)
+
+		return
+	}
+
+	ctxWorkspaceId := r.Context().Value(CONTEXT_WORKSPACE_ID_KEY).(int)
+
+	if ctxWorkspaceId == 0 {
+		response.Resource.Ok = false
+		response.Resource.Error = "reference to workspace not found in context"
+
+		WriteErrorResponse(w, r, response, "/int_credentials", response.Resource.Error, http.StatusInternalServerError)
 
 		return
 	}
@@ -182,7 +221,7 @@ func (ic *IntCredentials) HandleCreate(w http.ResponseWriter, r *http.Request) {
 		response.Resource.Ok = false
 		response.Resource.Error = "error on read creation body"
 
-		WriteErrorResponse(w, response, "/int_credentials", response.Resource.Error, http.StatusBadRequest)
+		WriteErrorResponse(w, r, response, "/int_credentials", response.Resource.Error, http.StatusBadRequest)
 
 		return
 	}
@@ -195,7 +234,7 @@ func (ic *IntCredentials) HandleCreate(w http.ResponseWriter, r *http.Request) {
 		response.Resource.Ok = false
 		response.Resource.Error = "some fields can be in invalid format"
 
-		WriteErrorResponse(w, response, "/int_credentials", response.Resource.Error, http.StatusBadRequest)
+		WriteErrorResponse(w, r, response, "/int_credentials", response.Resource.Error, http.StatusBadRequest)
 
 		return
 	}
@@ -210,28 +249,31 @@ func (ic *IntCredentials) HandleCreate(w http.ResponseWriter, r *http.Request) {
 		response.Resource.Ok = false
 		response.Resource.Error = "fields int_credential_name, int_credential_type, int_credential_config are required"
 
-		WriteErrorResponse(w, response, "/int_credentials", response.Resource.Error, http.StatusBadRequest)
+		WriteErrorResponse(w, r, response, "/int_credentials", response.Resource.Error, http.StatusBadRequest)
 
 		return
 	}
 
-	creationId, creationErr := ic.model.Add(model.IntCredentialAddData{
+	creationId, creationErr := ic.model.Add(r.Context(), model.IntCredentialAddData{
 		IntCredentialName:   intCredential.IntCredentialName,
 		IntCredentialType:   intCredential.IntCredentialType,
 		IntCredentialConfig: intCredential.IntCredentialConfig,
+		WorkspaceId:         ctxWorkspaceId,
 	}, ctxUserId)
 
 	if creationErr != nil {
 		response.Resource.Ok = false
 		response.Resource.Error = creationErr.Error()
 
-		WriteErrorResponse(w, response, "/int_credentials", response.Resource.Error, http.StatusBadRequest)
+		WriteErrorResponse(w, r, response, "/int_credentials", response.Resource.Error, http.StatusBadRequest)
 
 		return
 	}
 
 	response.Data.IntCredentialId = creationId
 
+	ic.audit.Record(r, model.AuditActionCreate, model.AuditEntityIntCredential, creationId, nil, ic.snapshot(r.Context(), creationId, ctxWorkspaceId))
+
 	WriteSuccessResponse(w, response)
 }
 
@@ -251,7 +293,18 @@ func (ic *IntCredentials) HandleUpdate(w http.ResponseWriter, r *http.Request) {
 		response.Resource.Ok = false
 		response.Resource.Error = "reference to user not found in context"
 
-		WriteErrorResponse(w, response, "/int_credentials", response.Resource.Error, http.StatusInternalServerError)
+		WriteErrorResponse(w, r, response, "/int_credentials", response.Resource.Error, http.StatusInternalServerError)
+
+		return
+	}
+
+	ctxWorkspaceId := r.Context().Value(CONTEXT_WORKSPACE_ID_KEY).(int)
+
+	if ctxWorkspaceId == 0 {
+		response.Resource.Ok = false
+		response.Resource.Error = "reference to workspace not found in context"
+
+		WriteErrorResponse(w, r, response, "/int_credentials", response.Resource.Error, http.StatusInternalServerError)
 
 		return
 	}
@@ -262,7 +315,7 @@ func (ic *IntCredentials) HandleUpdate(w http.ResponseWriter, r *http.Request) {
 		response.Resource.Ok = false
 		response.Resource.Error = "error on read update body"
 
-		WriteErrorResponse(w, response, "/int_credentials", response.Resource.Error, http.StatusBadRequest)
+		WriteErrorResponse(w, r, response, "/int_credentials", response.Resource.Error, http.StatusBadRequest)
 
 		return
 	}
@@ -275,7 +328,7 @@ func (ic *IntCredentials) HandleUpdate(w http.ResponseWriter, r *http.Request) {
 		response.Resource.Ok = false
 		response.Resource.Error = "some fields can be in invalid format"
 
-		WriteErrorResponse(w, response, "/int_credentials", response.Resource.Error, http.StatusBadRequest)
+		WriteErrorResponse(w, r, response, "/int_credentials", response.Resource.Error, http.StatusBadRequest)
 
 		return
 	}
@@ -291,40 +344,63 @@ func (ic *IntCredentials) HandleUpdate(w http.ResponseWriter, r *http.Request) {
 		response.Resource.Ok = false
 		response.Resource.Error = "fields int_credential_id, int_credential_name, int_credential_type, int_credential_config are required"
 
-		WriteErrorResponse(w, response, "/int_credentials", response.Resource.Error, http.StatusBadRequest)
+		WriteErrorResponse(w, r, response, "/int_credentials", response.Resource.Error, http.StatusBadRequest)
 
 		return
 	}
 
-	intCredentialById, _ := ic.model.List(strconv.Itoa(intCredential.IntCredentialId), false, ctxUserId)
+	intCredentialById, _ := ic.model.List(r.Context(), strconv.Itoa(intCredential.IntCredentialId), false, ctxWorkspaceId)
 
 	if len(intCredentialById) == 0 {
 		response.Resource.Ok = false
 		response.Resource.Error = "integration credential with id " + strconv.Itoa(intCredential.IntCredentialId) + " not found"
 
-		WriteErrorResponse(w, response, "/int_credentials", response.Resource.Error, http.StatusBadRequest)
+		WriteErrorResponse(w, r, response, "/int_credentials", response.Resource.Error, http.StatusBadRequest)
 
 		return
 	}
 
-	rowsAffected, updateErr := ic.model.Update(model.IntCredentialUpdateData{
-		IntCredentialId:     intCredential.IntCredentialId,
-		IntCredentialName:   intCredential.IntCredentialName,
-		IntCredentialType:   intCredential.IntCredentialType,
-		IntCredentialConfig: intCredential.IntCredentialConfig,
-	}, ctxUserId)
+	intCredentialVersion, versionOk := MatchVersion(w, r, "/int_credentials", "integration credential", intCredential.IntCredentialId, intCredentialById[0].IntCredentialVersion)
+
+	if !versionOk {
+		return
+	}
+
+	intCredentialBefore := ic.snapshot(r.Context(), intCredential.IntCredentialId, ctxWorkspaceId)
+
+	rowsAffected, updateErr := ic.model.Update(r.Context(), model.IntCredentialUpdateData{
+		IntCredentialId:      intCredential.IntCredentialId,
+		IntCredentialName:    intCredential.IntCredentialName,
+		IntCredentialType:    intCredential.IntCredentialType,
+		IntCredentialConfig:  intCredential.IntCredentialConfig,
+		IntCredentialVersion: intCredentialVersion,
+	}, ctxWorkspaceId)
 
 	if updateErr != nil {
 		response.Resource.Ok = false
 		response.Resource.Error = updateErr.Error()
 
-		WriteErrorResponse(w, response, "/int_credentials", response.Resource.Error, http.StatusBadRequest)
+		WriteErrorResponse(w, r, response, "/int_credentials", response.Resource.Error, http.StatusBadRequest)
+
+		return
+	}
+
+	intCredentialAfter, _ := ic.model.List(r.Context(), strconv.Itoa(intCredential.IntCredentialId), false, ctxWorkspaceId)
+
+	if rowsAffected == 0 && len(intCredentialAfter) == 1 {
+		WriteVersionConflict(w, r, "/int_credentials", "integration credential", intCredential.IntCredentialId, intCredentialAfter[0].IntCredentialVersion)
 
 		return
 	}
 
 	response.Data.RowsAffected = rowsAffected
 
+	if len(intCredentialAfter) == 1 {
+		SetETag(w, intCredentialAfter[0].IntCredentialVersion)
+	}
+
+	ic.audit.Record(r, model.AuditActionUpdate, model.AuditEntityIntCredential, intCredential.IntCredentialId, intCredentialBefore, ic.snapshot(r.Context(), intCredential.IntCredentialId, ctxWorkspaceId))
+
 	WriteSuccessResponse(w, response)
 }
 
@@ -344,7 +420,18 @@ func (ic *IntCredentials) HandleDelete(w http.ResponseWriter, r *http.Request) {
 		response.Resource.Ok = false
 		response.Resource.Error = "reference to user not found in context"
 
-		WriteErrorResponse(w, response, "/int_credentials", response.Resource.Error, http.StatusInternalServerError)
+		WriteErrorResponse(w, r, response, "/int_credentials", response.Resource.Error, http.StatusInternalServerError)
+
+		return
+	}
+
+	ctxWorkspaceId := r.Context().Value(CONTEXT_WORKSPACE_ID_KEY).(int)
+
+	if ctxWorkspaceId == 0 {
+		response.Resource.Ok = false
+		response.Resource.Error = "reference to workspace not found in context"
+
+		WriteErrorResponse(w, r, response, "/int_credentials", response.Resource.Error, http.StatusInternalServerError)
 
 		return
 	}
@@ -355,7 +442,7 @@ func (ic *IntCredentials) HandleDelete(w http.ResponseWriter, r *http.Request) {
 		response.Resource.Ok = false
 		response.Resource.Error = "error on read delete body"
 
-		WriteErrorResponse(w, response, "/int_credentials", response.Resource.Error, http.StatusBadRequest)
+		WriteErrorResponse(w, r, response, "/int_credentials", response.Resource.Error, http.StatusBadRequest)
 
 		return
 	}
@@ -368,7 +455,7 @@ func (ic *IntCredentials) HandleDelete(w http.ResponseWriter, r *http.Request) {
 		response.Resource.Ok = false
 		response.Resource.Error = "some fields can be in invalid format"
 
-		WriteErrorResponse(w, response, "/int_credentials", response.Resource.Error, http.StatusBadRequest)
+		WriteErrorResponse(w, r, response, "/int_credentials", response.Resource.Error, http.StatusBadRequest)
 
 		return
 	}
@@ -379,34 +466,68 @@ func (ic *IntCredentials) HandleDelete(w http.ResponseWriter, r *http.Request) {
 		response.Resource.Ok = false
 		response.Resource.Error = "fields int_credential_id is required"
 
-		WriteErrorResponse(w, response, "/int_credentials", response.Resource.Error, http.StatusBadRequest)
+		WriteErrorResponse(w, r, response, "/int_credentials", response.Resource.Error, http.StatusBadRequest)
 
 		return
 	}
 
-	intCredentialById, _ := ic.model.List(strconv.Itoa(intCredential.IntCredentialId), false, ctxUserId)
+	intCredentialById, _ := ic.model.List(r.Context(), strconv.Itoa(intCredential.IntCredentialId), false, ctxWorkspaceId)
 
 	if len(intCredentialById) == 0 {
 		response.Resource.Ok = false
 		response.Resource.Error = "integration credential with id " + strconv.Itoa(intCredential.IntCredentialId) + " not found"
 
-		WriteErrorResponse(w, response, "/int_credentials", response.Resource.Error, http.StatusBadRequest)
+		WriteErrorResponse(w, r, response, "/int_credentials", response.Resource.Error, http.StatusBadRequest)
+
+		return
+	}
+
+	intCredentialVersion, versionOk := MatchVersion(w, r, "/int_credentials", "integration credential", intCredential.IntCredentialId, intCredentialById[0].IntCredentialVersion)
 
+	if !versionOk {
 		return
 	}
 
-	rowsAffected, updateErr := ic.model.Delete(intCredential.IntCredentialId, ctxUserId)
+	intCredentialBefore := ic.snapshot(r.Context(), intCredential.IntCredentialId, ctxWorkspaceId)
+
+	rowsAffected, updateErr := ic.model.Delete(r.Context(), intCredential.IntCredentialId, intCredentialVersion, ctxWorkspaceId)
 
 	if updateErr != nil {
 		response.Resource.Ok = false
 		response.Resource.Error = updateErr.Error()
 
-		WriteErrorResponse(w, response, "/int_credentials", response.Resource.Error, http.StatusBadRequest)
+		WriteErrorResponse(w, r, response, "/int_credentials", response.Resource.Error, http.StatusBadRequest)
 
 		return
 	}
 
+	if rowsAffected == 0 {
+		intCredentialAfter, _ := ic.model.List(r.Context(), strconv.Itoa(intCredential.IntCredentialId), false, ctxWorkspaceId)
+
+		if len(intCredentialAfter) == 1 {
+			WriteVersionConflict(w, r, "/int_credentials", "integration credential", intCredential.IntCredentialId, intCredentialAfter[0].IntCredentialVersion)
+
+			return
+		}
+	}
+
 	response.Data.RowsAffected = rowsAffected
 
+	if rowsAffected > 0 {
+		ic.audit.Record(r, model.AuditActionDelete, model.AuditEntityIntCredential, intCredential.IntCredentialId, intCredentialBefore, nil)
+	}
+
 	WriteSuccessResponse(w, response)
 }
+
+// snapshot loads the credential with its config for the audit log, where the
+// config is redacted.
+func (ic *IntCredentials) snapshot(ctx context.Context, intCredentialId int, workspaceId int) any {
+	list, _ := ic.model.List(ctx, strconv.Itoa(intCredentialId), true, workspaceId)
+
+	if len(list) == 0 {
+		return nil
+	}
+
+	return list[0]
+}
@@ -1,7 +1,7 @@
 package controller
 
 import (
-	"database/sql"
+	"context"
 	"encoding/json"
 	"io"
 	"net/http"
@@ -12,9 +12,10 @@ import (
 )
 
 type IntProfiles struct {
-	model              *model.IntProfiles
-	ColorModel         *model.Colors
-	IntCredentialModel *model.IntCredentials
+	model              model.IntProfilesRepository
+	ColorModel         model.ColorsRepository
+	IntCredentialModel model.IntCredentialsRepository
+	audit              *Auditor
 }
 
 type HandleIntProfilesBasicListResponse struct {
@@ -37,9 +38,10 @@ type CreateIntProfileCreateDataResponse struct {
 }
 
 type HandleIntProfileCreateRequest struct {
-	IntProfileName  string `json:"int_profile_name"`
-	ColorId         int    `json:"color_id"`
-	CredentialsList []int  `json:"credentials"`
+	IntProfileName             string `json:"int_profile_name"`
+	IntProfileRequiresApproval *bool  `json:"int_profile_requires_approval"`
+	ColorId                    int    `json:"color_id"`
+	CredentialsList            []int  `json:"credentials"`
 }
 
 type HandleIntProfileUpdateResponse struct {
@@ -52,21 +54,27 @@ type UpdateIntProfileDataResponse struct {
 }
 
 type HandleIntProfileUpdateRequest struct {
-	IntProfileId    int    `json:"int_profile_id"`
-	IntProfileName  string `json:"int_profile_name"`
-	ColorId         int    `json:"color_id"`
-	CredentialsList []int  `json:"credentials"`
+	IntProfileId               int    `json:"int_profile_id"`
+	IntProfileName             string `json:"int_profile_name"`
+	IntProfileRequiresApproval *bool  `json:"int_profile_requires_approval"`
+	ColorId                    int    `json:"color_id"`
+	CredentialsList            []int  `json:"credentials"`
 }
 
 type HandleIntProfileDeleteRequest struct {
 	IntProfileId int `json:"int_profile_id"`
 }
 
-func NewIntProfiles(db *sql.DB) *IntProfiles {
+type HandleIntProfileCloneRequest struct {
+	IntProfileId int `json:"int_profile_id"`
+}
+
+func NewIntProfiles(repositories *model.Repositories) *IntProfiles {
 	intProfiles := IntProfiles{
-		model:              model.NewIntProfiles(db),
-		ColorModel:         model.NewColors(db),
-		IntCredentialModel: model.NewIntCredentials(db),
+		model:              repositories.IntProfiles,
+		ColorModel:         repositories.Colors,
+		IntCredentialModel: repositories.IntCredentials,
+		audit:              NewAuditor(repositories),
 	}
 
 	return &intProfiles
@@ -88,12 +96,23 @@ func (ip *IntProfiles) HandleBasicList(w http.ResponseWriter, r *http.Request) {
 		response.Resource.Ok = false
 		response.Resource.Error = "reference to user not found in context"
 
-		WriteErrorResponse(w, response, "/int_profiles/basic", response.Resource.Error, http.StatusInternalServerError)
+		WriteErrorResponse(w, r, response, "/int_profiles/basic", response.Resource.Error, http.StatusInternalServerError)
 
 		return
 	}
 
-	intProfilesList, intProfilesErr := ip.model.BasicList(ctxUserId)
+	ctxWorkspaceId := r.Context().Value(CONTEXT_WORKSPACE_ID_KEY).(int)
+
+	if ctxWorkspaceId == 0 {
+		response.Resource.Ok = false
+		response.Resource.Error = "reference to workspace not found in context"
+
+		WriteErrorResponse(w, r, response, "/int_profiles/basic", response.Resource.Error, http.StatusInternalServerError)
+
+		return
+	}
+
+	intProfilesList, intProfilesErr := ip.model.BasicList(r.Context(), ctxWorkspaceId)
 
 	if intProfilesErr != nil {
 		response.Resource.Ok = false
@@ -104,7 +123,7 @@ func (ip *IntProfiles) HandleBasicList(w http.ResponseWriter, r *http.Request) {
 
 	jsonResp, jsonErr := json.Marshal(response)
 	if jsonErr != nil {
-		util.LogRoute("/int_profiles/basic", "error on response encoding")
+		util.LoggerFrom(r.Context()).Error("error on response encoding")
 
 		return
 	}
@@ -113,7 +132,7 @@ func (ip *IntProfiles) HandleBasicList(w http.ResponseWriter, r *http.Request) {
 
 	_, writeErr := w.Write(jsonResp)
 	if writeErr != nil {
-		util.LogRoute("/int_profiles/basic", "error on response log")
+		util.LoggerFrom(r.Context()).Error("error on response log")
 	}
 }
 
@@ -133,19 +152,30 @@ func (ip *IntProfiles) HandleList(w http.ResponseWriter, r *http.Request) {
 		response.Resource.Ok = false
 		response.Resource.Error = "reference to user not found in context"
 
-		WriteErrorResponse(w, response, "/int_profiles", response.Resource.Error, http.StatusInternalServerError)
+		WriteErrorResponse(w, r, response, "/int_profiles", response.Resource.Error, http.StatusInternalServerError)
+
+		return
+	}
+
+	ctxWorkspaceId := r.Context().Value(CONTEXT_WORKSPACE_ID_KEY).(int)
+
+	if ctxWorkspaceId == 0 {
+		response.Resource.Ok = false
+		response.Resource.Error = "reference to workspace not found in context"
+
+		WriteErrorResponse(w, r, response, "/int_profiles", response.Resource.Error, http.StatusInternalServerError)
 
 		return
 	}
 
 	intProfileId := r.URL.Query().Get("int_profile_id")
 
-	intProfileList, templateErr := ip.model.List(intProfileId, ctxUserId)
+	intProfileList, templateErr := ip.model.List(r.Context(), intProfileId, ctxWorkspaceId)
 
 	serializeProfileList := []model.IntProfileList{}
 
 	for _, intProfileItem := range intProfileList {
-		itemCredentialsList, _ := ip.IntCredentialModel.BasicListByProfile(intProfileItem.IntProfileId, ctxUserId)
+		itemCredentialsList, _ := ip.IntCredentialModel.BasicListByProfile(r.Context(), intProfileItem.IntProfileId, ctxWorkspaceId)
 
 		intProfileItem.Credentials = itemCredentialsList
 
@@ -158,11 +188,15 @@ func (ip *IntProfiles) HandleList(w http.ResponseWriter, r *http.Request) {
 		response.Resource.Ok = false
 		response.Resource.Error = templateErr.Error()
 
-		WriteErrorResponse(w, response, "/int_profiles", "error on integration profiles fetch", http.StatusInternalServerError)
+		WriteErrorResponse(w, r, response, "/int_profiles", "error on integration profiles fetch", http.StatusInternalServerError)
 
 		return
 	}
 
+	if intProfileId != "" && len(serializeProfileList) == 1 {
+		SetETag(w, serializeProfileList[0].IntProfileVersion)
+	}
+
 	WriteSuccessResponse(w, response)
 }
 
@@ -182,7 +216,18 @@ func (ip *IntProfiles) HandleCreate(w http.ResponseWriter, r *http.Request) {
 		response.Resource.Ok = false
 		response.Resource.Error = "reference to user not found in context"
 
-		WriteErrorResponse(w, response, "/int_profiles", response.Resource.Error, http.StatusInternalServerError)
+		WriteErrorResponse(w, r, response, "/int_profiles", response.Resource.Error, http.StatusInternalServerError)
+
+		return
+	}
+
+	ctxWorkspaceId := r.Context().Value(CONTEXT_WORKSPACE_ID_KEY).(int)
+
+	if ctxWorkspaceId == 0 {
+		response.Resource.Ok = false
+		response.Resource.Error = "reference to workspace not found in context"
+
+		WriteErrorResponse(w, r, response, "/int_profiles", response.Resource.Error, http.StatusInternalServerError)
 
 		return
 	}
@@ -193,7 +238,7 @@ func (ip *IntProfiles) HandleCreate(w http.ResponseWriter, r *http.Request) {
 		response.Resource.Ok = false
 		response.Resource.Error = "error on read creation body"
 
-		WriteErrorResponse(w, response, "/int_profiles", response.Resource.Error, http.StatusBadRequest)
+		WriteErrorResponse(w, r, response, "/int_profiles", response.Resource.Error, http.StatusBadRequest)
 
 		return
 	}
@@ -206,7 +251,7 @@ func (ip *IntProfiles) HandleCreate(w http.ResponseWriter, r *http.Request) {
 		response.Resource.Ok = false
 		response.Resource.Error = "some fields can be in invalid format"
 
-		WriteErrorResponse(w, response, "/int_profiles", response.Resource.Error, http.StatusBadRequest)
+		WriteErrorResponse(w, r, response, "/int_profiles", response.Resource.Error, http.StatusBadRequest)
 
 		return
 	}
@@ -221,18 +266,18 @@ func (ip *IntProfiles) HandleCreate(w http.ResponseWriter, r *http.Request) {
 		response.Resource.Ok = false
 		response.Resource.Error = "fields int_profile_name, color_id and credentials are required"
 
-		WriteErrorResponse(w, response, "/int_profiles", response.Resource.Error, http.StatusBadRequest)
+		WriteErrorResponse(w, r, response, "/int_profiles", response.Resource.Error, http.StatusBadRequest)
 
 		return
 	}
 
-	colorsById, _ := ip.ColorModel.List(intProfile.ColorId)
+	colorsById, _ := ip.ColorModel.List(r.Context(), intProfile.ColorId)
 
 	if len(colorsById) == 0 {
 		response.Resource.Ok = false
 		response.Resource.Error = "color with id " + strconv.Itoa(intProfile.ColorId) + " not found"
 
-		WriteErrorResponse(w, response, "/int_profiles", response.Resource.Error, http.StatusBadRequest)
+		WriteErrorResponse(w, r, response, "/int_profiles", response.Resource.Error, http.StatusBadRequest)
 
 		return
 	}
@@ -240,7 +285,7 @@ func (ip *IntProfiles) HandleCreate(w http.ResponseWriter, r *http.Request) {
 	allCredentialsExists := true
 
 	for _, credentialId := range intProfile.CredentialsList {
-		credentialSearchResult, credentialSearchError := ip.IntCredentialModel.List(strconv.Itoa(credentialId), false, ctxUserId)
+		credentialSearchResult, credentialSearchError := ip.IntCredentialModel.List(r.Context(), strconv.Itoa(credentialId), false, ctxWorkspaceId)
 
 		if credentialSearchError != nil || len(credentialSearchResult) == 0 {
 			allCredentialsExists = false
@@ -253,27 +298,37 @@ func (ip *IntProfiles) HandleCreate(w http.ResponseWriter, r *http.Request) {
 		response.Resource.Ok = false
 		response.Resource.Error = "not all credential IDs from list are valid or exists"
 
-		WriteErrorResponse(w, response, "/int_profiles", response.Resource.Error, http.StatusBadRequest)
+		WriteErrorResponse(w, r, response, "/int_profiles", response.Resource.Error, http.StatusBadRequest)
 
 		return
 	}
 
-	creationId, creationErr := ip.model.Add(model.IntProfileAddData{
-		IntProfileName: intProfile.IntProfileName,
-		ColorId:        intProfile.ColorId,
+	requiresApproval, approvalOk := ip.requiresApproval(w, r, intProfile.IntProfileRequiresApproval, false)
+
+	if !approvalOk {
+		return
+	}
+
+	creationId, creationErr := ip.model.Add(r.Context(), model.IntProfileAddData{
+		IntProfileName:             intProfile.IntProfileName,
+		IntProfileRequiresApproval: requiresApproval,
+		ColorId:                    intProfile.ColorId,
+		WorkspaceId:                ctxWorkspaceId,
 	}, intProfile.CredentialsList, ctxUserId)
 
 	if creationErr != nil {
 		response.Resource.Ok = false
 		response.Resource.Error = creationErr.Error()
 
-		WriteErrorResponse(w, response, "/int_profiles", response.Resource.Error, http.StatusBadRequest)
+		WriteErrorResponse(w, r, response, "/int_profiles", response.Resource.Error, http.StatusBadRequest)
 
 		return
 	}
 
 	response.Data.IntProfileId = creationId
 
+	ip.audit.Record(r, model.AuditActionCreate, model.AuditEntityIntProfile, creationId, nil, ip.snapshot(r.Context(), creationId, ctxWorkspaceId))
+
 	WriteSuccessResponse(w, response)
 }
 
@@ -293,7 +348,18 @@ func (ip *IntProfiles) HandleUpdate(w http.ResponseWriter, r *http.Request) {
 		response.Resource.Ok = false
 		response.Resource.Error = "reference to user not found in context"
 
-		WriteErrorResponse(w, response, "/int_profiles", response.Resource.Error, http.StatusInternalServerError)
+		WriteErrorResponse(w, r, response, "/int_profiles", response.Resource.Error, http.StatusInternalServerError)
+
+		return
+	}
+
+	ctxWorkspaceId := r.Context().Value(CONTEXT_WORKSPACE_ID_KEY).(int)
+
+	if ctxWorkspaceId == 0 {
+		response.Resource.Ok = false
+		response.Resource.Error = "reference to workspace not found in context"
+
+		WriteErrorResponse(w, r, response, "/int_profiles", response.Resource.Error, http.StatusInternalServerError)
 
 		return
 	}
@@ -304,7 +370,7 @@ func (ip *IntProfiles) HandleUpdate(w http.ResponseWriter, r *http.Request) {
 		response.Resource.Ok = false
 		response.Resource.Error = "error on read update body"
 
-		WriteErrorResponse(w, response, "/int_profiles", response.Resource.Error, http.StatusBadRequest)
+		WriteErrorResponse(w, r, response, "/int_profiles", response.Resource.Error, http.StatusBadRequest)
 
 		return
 	}
@@ -317,7 +383,7 @@ func (ip *IntProfiles) HandleUpdate(w http.ResponseWriter, r *http.Request) {
 		response.Resource.Ok = false
 		response.Resource.Error = "some fields can be in invalid format"
 
-		WriteErrorResponse(w, response, "/int_profiles", response.Resource.Error, http.StatusBadRequest)
+		WriteErrorResponse(w, r, response, "/int_profiles", response.Resource.Error, http.StatusBadRequest)
 
 		return
 	}
@@ -333,37 +399,43 @@ func (ip *IntProfiles) HandleUpdate(w http.ResponseWriter, r *http.Request) {
 		response.Resource.Ok = false
 		response.Resource.Error = "fields int_profile_id, int_profile_name, color_id and credentials are required"
 
-		WriteErrorResponse(w, response, "/int_profiles", response.Resource.Error, http.StatusBadRequest)
+		WriteErrorResponse(w, r, response, "/int_profiles", response.Resource.Error, http.StatusBadRequest)
 
 		return
 	}
 
-	colorsById, _ := ip.ColorModel.List(intProfile.ColorId)
+	colorsById, _ := ip.ColorModel.List(r.Context(), intProfile.ColorId)
 
 	if len(colorsById) == 0 {
 		response.Resource.Ok = false
 		response.Resource.Error = "color with id " + strconv.Itoa(intProfile.ColorId) + " not found"
 
-		WriteErrorResponse(w, response, "/int_profiles", response.Resource.Error, http.StatusBadRequest)
+		WriteErrorResponse(w, r, response, "/int_profiles", response.Resource.Error, http.StatusBadRequest)
 
 		return
 	}
 
-	intProfileById, _ := ip.model.ById(intProfile.IntProfileId, ctxUserId)
+	intProfileById, _ := ip.model.ById(r.Context(), intProfile.IntProfileId, ctxWorkspaceId)
 
 	if intProfileById.IntProfileId == 0 {
 		response.Resource.Ok = false
 		response.Resource.Error = "integration profile with id " + strconv.Itoa(intProfile.IntProfileId) + " not found"
 
-		WriteErrorResponse(w, response, "/int_profiles", response.Resource.Error, http.StatusBadRequest)
+		WriteErrorResponse(w, r, response, "/int_profiles", response.Resource.Error, http.StatusBadRequest)
 
 		return
 	}
 
+	intProfileVersion, versionOk := MatchVersion(w, r, "/int_profiles", "integration profile", intProfileById.IntProfileId, intProfileById.IntProfileVersion)
+
+	if !versionOk {
+		return
+	}
+
 	allCredentialsExists := true
 
 	for _, credentialId := range intProfile.CredentialsList {
-		credentialSearchResult, credentialSearchError := ip.IntCredentialModel.List(strconv.Itoa(credentialId), false, ctxUserId)
+		credentialSearchResult, credentialSearchError := ip.IntCredentialModel.List(r.Context(), strconv.Itoa(credentialId), false, ctxWorkspaceId)
 
 		if credentialSearchError != nil || len(credentialSearchResult) == 0 {
 			allCredentialsExists = false
@@ -376,28 +448,49 @@ func (ip *IntProfiles) HandleUpdate(w http.ResponseWriter, r *http.Request) {
 		response.Resource.Ok = false
 		response.Resource.Error = "not all credential IDs from list are valid or exists"
 
-		WriteErrorResponse(w, response, "/int_profiles", response.Resource.Error, http.StatusBadRequest)
+		WriteErrorResponse(w, r, response, "/int_profiles", response.Resource.Error, http.StatusBadRequest)
 
 		return
 	}
 
-	rowsAffected, updateErr := ip.model.Update(model.IntProfileUpdateData{
-		IntProfileId:   intProfile.IntProfileId,
-		IntProfileName: intProfile.IntProfileName,
-		ColorId:        intProfile.ColorId,
-	}, intProfile.CredentialsList, ctxUserId)
+	requiresApproval, approvalOk := ip.requiresApproval(w, r, intProfile.IntProfileRequiresApproval, intProfileById.IntProfileRequiresApproval)
+
+	if !approvalOk {
+		return
+	}
+
+	intProfileBefore := ip.snapshot(r.Context(), intProfileById.IntProfileId, ctxWorkspaceId)
+
+	rowsAffected, updateErr := ip.model.Update(r.Context(), model.IntProfileUpdateData{
+		IntProfileId:               intProfile.IntProfileId,
+		IntProfileName:             intProfile.IntProfileName,
+		IntProfileRequiresApproval: requiresApproval,
+		ColorId:                    intProfile.ColorId,
+		IntProfileVersion:          intProfileVersion,
+	}, intProfile.CredentialsList, ctxWorkspaceId)
 
 	if updateErr != nil {
 		response.Resource.Ok = false
 		response.Resource.Error = updateErr.Error()
 
-		WriteErrorResponse(w, response, "/int_profiles", response.Resource.Error, http.StatusBadRequest)
+		WriteErrorResponse(w, r, response, "/int_profiles", response.Resource.Error, http.StatusBadRequest)
+
+		return
+	}
+
+	intProfileAfter, _ := ip.model.ById(r.Context(), intProfileById.IntProfileId, ctxWorkspaceId)
+
+	if rowsAffected == 0 {
+		WriteVersionConflict(w, r, "/int_profiles", "integration profile", intProfileById.IntProfileId, intProfileAfter.IntProfileVersion)
 
 		return
 	}
 
 	response.Data.RowsAffected = rowsAffected
 
+	SetETag(w, intProfileAfter.IntProfileVersion)
+	ip.audit.Record(r, model.AuditActionUpdate, model.AuditEntityIntProfile, intProfileById.IntProfileId, intProfileBefore, ip.snapshot(r.Context(), intProfileById.IntProfileId, ctxWorkspaceId))
+
 	WriteSuccessResponse(w, response)
 }
 
@@ -417,7 +510,18 @@ func (ip *IntProfiles) HandleDelete(w http.ResponseWriter, r *http.Request) {
 		response.Resource.Ok = false
 		response.Resource.Error = "reference to user not found in context"
 
-		WriteErrorResponse(w, response, "/int_profiles", response.Resource.Error, http.StatusInternalServerError)
+		WriteErrorResponse(w, r, response, "/int_profiles", response.Resource.Error, http.StatusInternalServerError)
+
+		return
+	}
+
+	ctxWorkspaceId := r.Context().Value(CONTEXT_WORKSPACE_ID_KEY).(int)
+
+	if ctxWorkspaceId == 0 {
+		response.Resource.Ok = false
+		response.Resource.Error = "reference to workspace not found in context"
+
+		WriteErrorResponse(w, r, response, "/int_profiles", response.Resource.Error, http.StatusInternalServerError)
 
 		return
 	}
@@ -428,7 +532,7 @@ func (ip *IntProfiles) HandleDelete(w http.ResponseWriter, r *http.Request) {
 		response.Resource.Ok = false
 		response.Resource.Error = "error on read delete body"
 
-		WriteErrorResponse(w, response, "/int_profiles", response.Resource.Error, http.StatusBadRequest)
+		WriteErrorResponse(w, r, response, "/int_profiles", response.Resource.Error, http.StatusBadRequest)
 
 		return
 	}
@@ -441,7 +545,7 @@ func (ip *IntProfiles) HandleDelete(w http.ResponseWriter, r *http.Request) {
 		response.Resource.Ok = false
 		response.Resource.Error = "some fields can be in invalid format"
 
-		WriteErrorResponse(w, response, "/int_profiles", response.Resource.Error, http.StatusBadRequest)
+		WriteErrorResponse(w, r, response, "/int_profiles", response.Resource.Error, http.StatusBadRequest)
 
 		return
 	}
@@ -452,34 +556,188 @@ func (ip *IntProfiles) HandleDelete(w http.ResponseWriter, r *http.Request) {
 		response.Resource.Ok = false
 		response.Resource.Error = "fields int_profile_id is required"
 
-		WriteErrorResponse(w, response, "/int_profiles", response.Resource.Error, http.StatusBadRequest)
+		WriteErrorResponse(w, r, response, "/int_profiles", response.Resource.Error, http.StatusBadRequest)
 
 		return
 	}
 
-	intProfileById, _ := ip.model.ById(intProfile.IntProfileId, ctxUserId)
+	intProfileById, _ := ip.model.ById(r.Context(), intProfile.IntProfileId, ctxWorkspaceId)
 
 	if intProfileById.IntProfileId == 0 {
 		response.Resource.Ok = false
 		response.Resource.Error = "integration profile with id " + strconv.Itoa(intProfile.IntProfileId) + " not found"
 
-		WriteErrorResponse(w, response, "/int_profiles", response.Resource.Error, http.StatusBadRequest)
+		WriteErrorResponse(w, r, response, "/int_profiles", response.Resource.Error, http.StatusBadRequest)
 
 		return
 	}
 
-	rowsAffected, updateErr := ip.model.Delete(intProfile.IntProfileId, ctxUserId)
+	intProfileVersion, versionOk := MatchVersion(w, r, "/int_profiles", "integration profile", intProfileById.IntProfileId, intProfileById.IntProfileVersion)
+
+	if !versionOk {
+		return
+	}
+
+	intProfileBefore := ip.snapshot(r.Context(), intProfileById.IntProfileId, ctxWorkspaceId)
+
+	rowsAffected, updateErr := ip.model.Delete(r.Context(), intProfile.IntProfileId, intProfileVersion, ctxWorkspaceId)
 
 	if updateErr != nil {
 		response.Resource.Ok = false
 		response.Resource.Error = updateErr.Error()
 
-		WriteErrorResponse(w, response, "/int_profiles", response.Resource.Error, http.StatusBadRequest)
+		WriteErrorResponse(w, r, response, "/int_profiles", response.Resource.Error, http.StatusBadRequest)
 
 		return
 	}
 
+	if rowsAffected == 0 {
+		intProfileAfter, _ := ip.model.ById(r.Context(), intProfileById.IntProfileId, ctxWorkspaceId)
+
+		if intProfileAfter.IntProfileId != 0 {
+			WriteVersionConflict(w, r, "/int_profiles", "integration profile", intProfileById.IntProfileId, intProfileAfter.IntProfileVersion)
+
+			return
+		}
+	}
+
 	response.Data.RowsAffected = rowsAffected
 
+	if rowsAffected > 0 {
+		ip.audit.Record(r, model.AuditActionDelete, model.AuditEntityIntProfile, intProfileById.IntProfileId, intProfileBefore, nil)
+	}
+
 	WriteSuccessResponse(w, response)
 }
+
+func (ip *IntProfiles) HandleClone(w http.ResponseWriter, r *http.Request) {
+	SetJsonContentType(w)
+
+	response := HandleIntProfileCreateResponse{
+		Resource: ResponseHeader{
+			Ok: true,
+		},
+		Data: CreateIntProfileCreateDataResponse{},
+	}
+
+	ctxUserId := r.Context().Value(CONTEXT_USER_ID_KEY).(int)
+
+	if ctxUserId == 0 {
+		response.Resource.Ok = false
+		response.Resource.Error = "reference to user not found in context"
+
+		WriteErrorResponse(w, r, response, "/int_profiles", response.Resource.Error, http.StatusInternalServerError)
+
+		return
+	}
+
+	ctxWorkspaceId := r.Context().Value(CONTEXT_WORKSPACE_ID_KEY).(int)
+
+	if ctxWorkspaceId == 0 {
+		response.Resource.Ok = false
+		response.Resource.Error = "reference to workspace not found in context"
+
+		WriteErrorResponse(w, r, response, "/int_profiles", response.Resource.Error, http.StatusInternalServerError)
+
+		return
+	}
+
+	bodyContent, bodyErr := io.ReadAll(r.Body)
+
+	if bodyErr != nil {
+		response.Resource.Ok = false
+		response.Resource.Error = "error on read clone body"
+
+		WriteErrorResponse(w, r, response, "/int_profiles", response.Resource.Error, http.StatusBadRequest)
+
+		return
+	}
+
+	var cloneRequest HandleIntProfileCloneRequest
+
+	jsonErr := json.Unmarshal(bodyContent, &cloneRequest)
+
+	if jsonErr != nil {
+		response.Resource.Ok = false
+		response.Resource.Error = "some fields can be in invalid format"
+
+		WriteErrorResponse(w, r, response, "/int_profiles", response.Resource.Error, http.StatusBadRequest)
+
+		return
+	}
+
+	hasAllData := cloneRequest.IntProfileId != 0
+
+	if !hasAllData {
+		response.Resource.Ok = false
+		response.Resource.Error = "fields int_profile_id is required"
+
+		WriteErrorResponse(w, r, response, "/int_profiles", response.Resource.Error, http.StatusBadRequest)
+
+		return
+	}
+
+	itemById, _ := ip.model.ById(r.Context(), cloneRequest.IntProfileId, ctxWorkspaceId)
+
+	if itemById.IntProfileId == 0 {
+		response.Resource.Ok = false
+		response.Resource.Error = "integration profile with id " + strconv.Itoa(cloneRequest.IntProfileId) + " not found"
+
+		WriteErrorResponse(w, r, response, "/int_profiles", response.Resource.Error, http.StatusBadRequest)
+
+		return
+	}
+
+	clonedId, cloneErr := ip.model.Clone(r.Context(), cloneRequest.IntProfileId, ctxWorkspaceId)
+
+	if cloneErr != nil {
+		response.Resource.Ok = false
+		response.Resource.Error = cloneErr.Error()
+
+		WriteErrorResponse(w, r, response, "/int_profiles", response.Resource.Error, http.StatusBadRequest)
+
+		return
+	}
+
+	response.Data.IntProfileId = clonedId
+
+	ip.audit.Record(r, model.AuditActionCreate, model.AuditEntityIntProfile, clonedId, nil, ip.snapshot(r.Context(), clonedId, ctxWorkspaceId))
+
+	WriteSuccessResponse(w, response)
+}
+
+// snapshot loads the integration profile with its credentials for the audit log.
+func (ip *IntProfiles) snapshot(ctx context.Context, intProfileId int, workspaceId int) any {
+	list, _ := ip.model.List(ctx, strconv.Itoa(intProfileId), workspaceId)
+
+	if len(list) == 0 {
+		return nil
+	}
+
+	return list[0]
+}
+
+// requiresApproval keeps the current flag when the request leaves it out. Only
+// owners can change it, so editors can not skip the review of their own posts.
+func (ip *IntProfiles) requiresApproval(w http.ResponseWriter, r *http.Request, requested *bool, current bool) (bool, bool) {
+	if requested == nil || *requested == current {
+		return current, true
+	}
+
+	role, _ := r.Context().Value(CONTEXT_WORKSPACE_ROLE_KEY).(model.WorkspaceRole)
+
+	if role != model.WorkspaceRoleOwner {
+		response := ErrorResponse{
+			Resource: ResponseHeader{
+				Ok:    false,
+				Error: "workspace role " + string(model.WorkspaceRoleOwner) + " is required to change int_profile_requires_approval",
+			},
+		}
+
+		WriteErrorResponse(w, r, response, "/int_profiles", response.Resource.Error, http.StatusForbidden)
+
+		return current, false
+	}
+
+	return *requested, true
+}
@@ -0,0 +1,182 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"synk/gateway/app/model"
+	"synk/gateway/app/util"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const METRICS_NAMESPACE = "synk"
+const METRICS_UNMATCHED_ROUTE = "unmatched"
+const METRICS_COLLECT_TIMEOUT = time.Second * 5
+
+const QUEUER_OUTCOME_ACCEPTED = "accepted"
+const QUEUER_OUTCOME_REJECTED = "rejected"
+const QUEUER_OUTCOME_ERROR = "error"
+
+var httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: METRICS_NAMESPACE,
+	Name:      "http_requests_total",
+	Help:      "Requests handled, by route and status.",
+}, []string{"route", "status"})
+
+var httpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: METRICS_NAMESPACE,
+	Name:      "http_request_duration_seconds",
+	Help:      "Time to handle a request, by route and status.",
+	Buckets:   prometheus.DefBuckets,
+}, []string{"route", "status"})
+
+var authCheckDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: METRICS_NAMESPACE,
+	Name:      "auth_check_duration_seconds",
+	Help:      "Time to check a bearer token, locally or on the auth server, by result.",
+	Buckets:   prometheus.DefBuckets,
+}, []string{"result"})
+
+var authCheckFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: METRICS_NAMESPACE,
+	Name:      "auth_check_failures_total",
+	Help:      "Bearer tokens refused or not checked, by status.",
+}, []string{"status"})
+
+var queuerPublishes = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: METRICS_NAMESPACE,
+	Name:      "queuer_publish_total",
+	Help:      "Posts sent to the queuer, by outcome: accepted, rejected or error.",
+}, []string{"outcome"})
+
+// NewMetricsRegistry gives a registry with the runtime and request metrics and
+// the given collectors, such as the DB pool stats. Each router builds its own,
+// so building one again does not fail on duplicate registration.
+func NewMetricsRegistry(metricsCollectors ...prometheus.Collector) (*prometheus.Registry, error) {
+	registry := prometheus.NewRegistry()
+
+	metricsCollectors = append([]prometheus.Collector{
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests,
+		httpRequestDuration,
+		authCheckDuration,
+		authCheckFailures,
+		queuerPublishes,
+	}, metricsCollectors...)
+
+	for _, collector := range metricsCollectors {
+		if registerErr := registry.Register(collector); registerErr != nil {
+			return nil, registerErr
+		}
+	}
+
+	return registry, nil
+}
+
+func HandleMetrics(registry *prometheus.Registry) http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// Metrics counts and times requests by the route pattern they matched, so
+// IDs in paths don't add series, and adds the pattern as "route" to the access
+// log. It must wrap the router directly, which sets the pattern on the request.
+func Metrics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		defer func() {
+			status := recorder.status
+			recovered := recover()
+
+			if recovered != nil {
+				status = http.StatusInternalServerError
+			}
+
+			route := r.Pattern
+
+			if route == "" {
+				route = METRICS_UNMATCHED_ROUTE
+			}
+
+			// Logging only sees the request before the mux set its pattern.
+			util.AddLogAttrs(r.Context(), "route", route)
+
+			httpRequests.WithLabelValues(route, strconv.Itoa(status)).Inc()
+			httpRequestDuration.WithLabelValues(route, strconv.Itoa(status)).Observe(time.Since(start).Seconds())
+
+			if recovered != nil {
+				panic(recovered)
+			}
+		}()
+
+		next.ServeHTTP(recorder, r)
+	})
+}
+
+func observeAuthCheck(status int, duration time.Duration) {
+	result := "ok"
+
+	if status != http.StatusOK {
+		result = "failed"
+
+		authCheckFailures.WithLabelValues(strconv.Itoa(status)).Inc()
+	}
+
+	authCheckDuration.WithLabelValues(result).Observe(duration.Seconds())
+}
+
+func observeQueuerPublish(outcome string) {
+	queuerPublishes.WithLabelValues(outcome).Inc()
+}
+
+// PostStatusCollector exposes the number of posts by publication status,
+// counted on each scrape.
+type PostStatusCollector struct {
+	publication model.PublicationRepository
+	desc        *prometheus.Desc
+}
+
+func NewPostStatusCollector(publication model.PublicationRepository) *PostStatusCollector {
+	collector := PostStatusCollector{
+		publication: publication,
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(METRICS_NAMESPACE, "", "posts"),
+			"Posts by publication status.",
+			[]string{"status"},
+			nil,
+		),
+	}
+
+	return &collector
+}
+
+func (c *PostStatusCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *PostStatusCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), METRICS_COLLECT_TIMEOUT)
+	defer cancel()
+
+	statusCount, countErr := c.publication.CountByStatus(ctx)
+
+	if countErr != nil {
+		ch <- prometheus.NewInvalidMetric(c.desc, countErr)
+
+		return
+	}
+
+	for _, status := range []model.PublicationStatus{
+		model.PublicationStatusPending,
+		model.PublicationStatusFailed,
+		model.PublicationStatusPublished,
+	} {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(statusCount[status]), string(status))
+	}
+}
@@ -0,0 +1,422 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"synk/gateway/app/auth"
+	"synk/gateway/app/config"
+	"synk/gateway/app/model"
+	"synk/gateway/app/util"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+const API_KEY_HEADER = "X-API-Key"
+const WORKSPACE_HEADER = "X-Workspace-Id"
+const WORKSPACE_PATH_PREFIX = "/w/"
+const REQUEST_ID_HEADER = "X-Request-ID"
+const REQUEST_ID_MAX_LENGTH = 64
+
+type Middleware func(http.Handler) http.Handler
+
+// Chain wraps handler so the first middleware is the outermost one.
+func Chain(handler http.Handler, middlewares ...Middleware) http.Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+
+	return handler
+}
+
+func Cors(config config.Cors) Middleware {
+	allowedOriginsMap := map[string]struct{}{}
+
+	for _, origin := range config.AllowedOrigins {
+		allowedOriginsMap[strings.TrimSuffix(origin, "/")] = struct{}{}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Access-Control-Allow-Credentials", "true")
+
+			origin := r.Header.Get("Origin")
+			if _, ok := allowedOriginsMap[origin]; ok {
+				w.Header().Set("Access-Control-Allow-Origin", origin)
+			}
+			w.Header().Add("Vary", "Origin")
+
+			w.Header().Set("Access-Control-Allow-Methods", config.AllowedMethods)
+			w.Header().Set("Access-Control-Allow-Headers", config.AllowedHeaders)
+			w.Header().Set("Access-Control-Expose-Headers", config.ExposedHeaders)
+
+			if r.Method == "OPTIONS" {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func Auth(authenticator *auth.Authenticator, apiKeys model.ApiKeysRepository) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			SetJsonContentType(w)
+
+			response := HandleAuthResponse{
+				Resource: ResponseHeader{
+					Ok: true,
+				},
+			}
+
+			ctx := r.Context()
+
+			if apiKey := r.Header.Get(API_KEY_HEADER); apiKey != "" {
+				owner, ownerErr := apiKeys.ByHash(r.Context(), auth.HashApiKey(apiKey))
+
+				if ownerErr != nil {
+					response.Resource.Ok = false
+					response.Resource.Error = "error while checking api key"
+
+					WriteErrorResponse(w, r, response, r.URL.Path, ownerErr.Error(), http.StatusInternalServerError)
+
+					return
+				}
+
+				if owner.UserId == 0 {
+					response.Resource.Ok = false
+					response.Resource.Error = "invalid api key"
+
+					WriteErrorResponse(w, r, response, r.URL.Path, response.Resource.Error, http.StatusUnauthorized)
+
+					return
+				}
+
+				if touchErr := apiKeys.Touch(r.Context(), owner.ApiKeyId); touchErr != nil {
+					util.LoggerFrom(r.Context()).Error(touchErr.Error())
+				}
+
+				ctx = context.WithValue(ctx, CONTEXT_USER_ID_KEY, owner.UserId)
+				ctx = context.WithValue(ctx, CONTEXT_API_KEY_ID_KEY, owner.ApiKeyId)
+				ctx = context.WithValue(ctx, CONTEXT_SCOPES_KEY, owner.ApiKeyScopes)
+
+				util.AddLogAttrs(ctx, "user_id", owner.UserId, "api_key_id", owner.ApiKeyId)
+
+				AddBreadcrumb(r, "auth", "authenticated user "+strconv.Itoa(owner.UserId)+" by api key "+strconv.Itoa(owner.ApiKeyId))
+
+				next.ServeHTTP(w, r.WithContext(ctx))
+
+				return
+			}
+
+			if !authenticator.Configured() {
+				response.Resource.Ok = false
+				response.Resource.Error = "auth server is not configured"
+
+				WriteErrorResponse(w, r, response, r.URL.Path, response.Resource.Error, http.StatusInternalServerError)
+
+				return
+			}
+
+			authHeader := r.Header.Get("Authorization")
+
+			if authHeader == "" {
+				response.Resource.Ok = false
+				response.Resource.Error = "Bearer Authorization or " + API_KEY_HEADER + " header is required"
+
+				WriteErrorResponse(w, r, response, r.URL.Path, response.Resource.Error, http.StatusBadRequest)
+
+				return
+			}
+
+			authStart := time.Now()
+			authResult := authenticator.Authenticate(r.Context(), authHeader)
+
+			observeAuthCheck(authResult.Status, time.Since(authStart))
+
+			if !authResult.Ok() {
+				response.Resource.Ok = false
+				response.Resource.Error = authResult.Error
+
+				WriteErrorResponse(w, r, response, r.URL.Path, response.Resource.Error, authResult.Status)
+
+				return
+			}
+
+			ctx = context.WithValue(ctx, CONTEXT_USER_ID_KEY, authResult.UserId)
+			ctx = context.WithValue(ctx, CONTEXT_SCOPES_KEY, authResult.Scopes)
+
+			util.AddLogAttrs(ctx, "user_id", authResult.UserId)
+
+			AddBreadcrumb(r, "auth", "authenticated user "+strconv.Itoa(authResult.UserId)+" by bearer token")
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// RequireScope must run after Auth, which stores the granted scopes in the
+// request context.
+func RequireScope(scope string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			granted, _ := r.Context().Value(CONTEXT_SCOPES_KEY).([]string)
+
+			if !auth.HasScope(granted, scope) {
+				SetJsonContentType(w)
+
+				response := ErrorResponse{
+					Resource: ResponseHeader{
+						Ok:    false,
+						Error: "missing scope " + scope,
+					},
+				}
+
+				WriteErrorResponse(w, r, response, r.URL.Path, response.Resource.Error, http.StatusForbidden)
+
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// WorkspacePath turns `/w/{workspace_id}/post` into `/post` with the
+// workspace set in the X-Workspace-Id header, so routes only match once.
+func WorkspacePath(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, WORKSPACE_PATH_PREFIX) {
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		workspaceId, rest, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, WORKSPACE_PATH_PREFIX), "/")
+
+		rewritten := r.Clone(r.Context())
+		rewritten.URL.Path = "/" + rest
+		rewritten.URL.RawPath = ""
+		rewritten.Header.Set(WORKSPACE_HEADER, workspaceId)
+
+		next.ServeHTTP(w, rewritten)
+	})
+}
+
+// Workspace must run after Auth. Without X-Workspace-Id the user personal
+// workspace is used.
+func Workspace(workspaces model.WorkspacesRepository, required model.WorkspaceRole) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			SetJsonContentType(w)
+
+			response := ErrorResponse{
+				Resource: ResponseHeader{
+					Ok: true,
+				},
+			}
+
+			ctxUserId, _ := r.Context().Value(CONTEXT_USER_ID_KEY).(int)
+
+			if ctxUserId == 0 {
+				response.Resource.Ok = false
+				response.Resource.Error = "reference to user not found in context"
+
+				WriteErrorResponse(w, r, response, r.URL.Path, response.Resource.Error, http.StatusInternalServerError)
+
+				return
+			}
+
+			var workspaceId int
+
+			if header := r.Header.Get(WORKSPACE_HEADER); header != "" {
+				headerId, headerErr := strconv.Atoi(header)
+
+				if headerErr != nil || headerId <= 0 {
+					response.Resource.Ok = false
+					response.Resource.Error = "invalid workspace id " + header
+
+					WriteErrorResponse(w, r, response, r.URL.Path, response.Resource.Error, http.StatusBadRequest)
+
+					return
+				}
+
+				workspaceId = headerId
+			} else {
+				personalId, personalErr := workspaces.Personal(r.Context(), ctxUserId)
+
+				if personalErr != nil {
+					response.Resource.Ok = false
+					response.Resource.Error = "error while loading personal workspace"
+
+					WriteErrorResponse(w, r, response, r.URL.Path, personalErr.Error(), http.StatusInternalServerError)
+
+					return
+				}
+
+				workspaceId = personalId
+			}
+
+			role, roleErr := workspaces.Role(r.Context(), workspaceId, ctxUserId)
+
+			if roleErr != nil {
+				response.Resource.Ok = false
+				response.Resource.Error = "error while checking workspace membership"
+
+				WriteErrorResponse(w, r, response, r.URL.Path, roleErr.Error(), http.StatusInternalServerError)
+
+				return
+			}
+
+			if role == "" {
+				response.Resource.Ok = false
+				response.Resource.Error = "not a member of workspace " + strconv.Itoa(workspaceId)
+
+				WriteErrorResponse(w, r, response, r.URL.Path, response.Resource.Error, http.StatusForbidden)
+
+				return
+			}
+
+			if !role.Allows(required) {
+				response.Resource.Ok = false
+				response.Resource.Error = "workspace role " + string(required) + " is required"
+
+				WriteErrorResponse(w, r, response, r.URL.Path, response.Resource.Error, http.StatusForbidden)
+
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), CONTEXT_WORKSPACE_ID_KEY, workspaceId)
+			ctx = context.WithValue(ctx, CONTEXT_WORKSPACE_ROLE_KEY, role)
+
+			util.AddLogAttrs(ctx, "workspace_id", workspaceId)
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// RequestId keeps a well-formed incoming X-Request-ID or generates one, and
+// echoes it in the response so clients can quote it.
+func RequestId(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestId := r.Header.Get(REQUEST_ID_HEADER)
+
+		if !validRequestId(requestId) {
+			requestId = newRequestId()
+		}
+
+		w.Header().Set(REQUEST_ID_HEADER, requestId)
+
+		ctx := context.WithValue(r.Context(), CONTEXT_REQUEST_ID_KEY, requestId)
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func validRequestId(requestId string) bool {
+	if requestId == "" || len(requestId) > REQUEST_ID_MAX_LENGTH {
+		return false
+	}
+
+	for _, char := range requestId {
+		isAllowed := (char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z') ||
+			(char >= '0' && char <= '9') || char == '-' || char == '_' || char == '.'
+
+		if !isAllowed {
+			return false
+		}
+	}
+
+	return true
+}
+
+func newRequestId() string {
+	return randomHex(16)
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	s.status = status
+	s.ResponseWriter.WriteHeader(status)
+}
+
+func (s *statusRecorder) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
+
+// Logging starts the request-scoped logger with the request ID, trace, method
+// and path, and writes one access log per request with its status and
+// latency. It must run after RequestId and Tracing.
+func Logging(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		requestId, _ := r.Context().Value(CONTEXT_REQUEST_ID_KEY).(string)
+		logger := slog.Default().With("request_id", requestId, "method", r.Method, "path", r.URL.Path)
+
+		if spanContext := trace.SpanContextFromContext(r.Context()); spanContext.IsValid() {
+			logger = logger.With("trace_id", spanContext.TraceID().String(), "span_id", spanContext.SpanID().String())
+		}
+
+		ctx := util.WithLogger(r.Context(), logger)
+
+		next.ServeHTTP(recorder, r.WithContext(ctx))
+
+		level := slog.LevelInfo
+
+		if recorder.status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		} else if recorder.status >= http.StatusBadRequest {
+			level = slog.LevelWarn
+		}
+
+		util.LoggerFrom(ctx).Log(ctx, level, "request",
+			"status", recorder.status,
+			"latency_ms", float64(time.Since(start).Microseconds())/1000,
+		)
+	})
+}
+
+func Recovery(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			recovered := recover()
+
+			if recovered == nil {
+				return
+			}
+
+			if recovered == http.ErrAbortHandler {
+				panic(recovered)
+			}
+
+			util.LoggerFrom(r.Context()).Error("panic recovered", "error", fmt.Sprint(recovered))
+
+			reporter.Panic(r, recovered)
+
+			response := ErrorResponse{
+				Resource: ResponseHeader{
+					Ok:    false,
+					Error: "internal server error",
+				},
+			}
+
+			SetJsonContentType(w)
+			writeJson(w, withRequestId(response, r), http.StatusInternalServerError)
+		}()
+
+		next.ServeHTTP(w, r)
+	})
+}
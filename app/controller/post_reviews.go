@@ -0,0 +1,239 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"synk/gateway/app/model"
+)
+
+type PostReviews struct {
+	model     model.PostReviewsRepository
+	postModel model.PostsRepository
+	audit     *Auditor
+}
+
+type HandlePostReviewListResponse struct {
+	Resource ResponseHeader          `json:"resource"`
+	Data     []model.PostReviewsList `json:"reviews"`
+}
+
+type HandlePostReviewRequest struct {
+	PostId            int                    `json:"post_id"`
+	PostReviewStatus  model.PostReviewStatus `json:"post_review_status"`
+	PostReviewComment string                 `json:"post_review_comment"`
+}
+
+type HandlePostReviewResponse struct {
+	Resource ResponseHeader         `json:"resource"`
+	Data     UpdatePostDataResponse `json:"post"`
+}
+
+func NewPostReviews(repositories *model.Repositories) *PostReviews {
+	postReviews := PostReviews{
+		model:     repositories.PostReviews,
+		postModel: repositories.Posts,
+		audit:     NewAuditor(repositories),
+	}
+
+	return &postReviews
+}
+
+func (pr *PostReviews) HandleList(w http.ResponseWriter, r *http.Request) {
+	SetJsonContentType(w)
+
+	response := HandlePostReviewListResponse{
+		Resource: ResponseHeader{
+			Ok: true,
+		},
+		Data: []model.PostReviewsList{},
+	}
+
+	ctxWorkspaceId := r.Context().Value(CONTEXT_WORKSPACE_ID_KEY).(int)
+
+	if ctxWorkspaceId == 0 {
+		response.Resource.Ok = false
+		response.Resource.Error = "reference to workspace not found in context"
+
+		WriteErrorResponse(w, r, response, "/post/reviews", response.Resource.Error, http.StatusInternalServerError)
+
+		return
+	}
+
+	postId, postIdErr := strconv.Atoi(r.URL.Query().Get("post_id"))
+
+	if postIdErr != nil || postId == 0 {
+		response.Resource.Ok = false
+		response.Resource.Error = "param post_id is required"
+
+		WriteErrorResponse(w, r, response, "/post/reviews", response.Resource.Error, http.StatusBadRequest)
+
+		return
+	}
+
+	reviewList, reviewErr := pr.model.List(r.Context(), postId, ctxWorkspaceId)
+
+	if reviewErr != nil {
+		response.Resource.Ok = false
+		response.Resource.Error = reviewErr.Error()
+
+		WriteErrorResponse(w, r, response, "/post/reviews", "error on post review fetch", http.StatusInternalServerError)
+
+		return
+	}
+
+	if reviewList != nil {
+		response.Data = reviewList
+	}
+
+	WriteSuccessResponse(w, response)
+}
+
+func (pr *PostReviews) HandleSubmit(w http.ResponseWriter, r *http.Request) {
+	pr.handleTransition(w, r, "/post/submit", false, pr.model.Submit)
+}
+
+func (pr *PostReviews) HandleDecide(w http.ResponseWriter, r *http.Request) {
+	pr.handleTransition(w, r, "/post/review", true, pr.model.Decide)
+}
+
+// handleTransition reads the review body shared by submit and decide. A
+// decision must carry the approved/rejected status; a submission ignores it.
+func (pr *PostReviews) handleTransition(
+	w http.ResponseWriter,
+	r *http.Request,
+	route string,
+	decision bool,
+	transition func(ctx context.Context, review model.PostReviewData, userId int, workspaceId int) (int, error),
+) {
+	SetJsonContentType(w)
+
+	response := HandlePostReviewResponse{
+		Resource: ResponseHeader{
+			Ok: true,
+		},
+		Data: UpdatePostDataResponse{},
+	}
+
+	ctxUserId := r.Context().Value(CONTEXT_USER_ID_KEY).(int)
+
+	if ctxUserId == 0 {
+		response.Resource.Ok = false
+		response.Resource.Error = "reference to user not found in context"
+
+		WriteErrorResponse(w, r, response, route, response.Resource.Error, http.StatusInternalServerError)
+
+		return
+	}
+
+	ctxWorkspaceId := r.Context().Value(CONTEXT_WORKSPACE_ID_KEY).(int)
+
+	if ctxWorkspaceId == 0 {
+		response.Resource.Ok = false
+		response.Resource.Error = "reference to workspace not found in context"
+
+		WriteErrorResponse(w, r, response, route, response.Resource.Error, http.StatusInternalServerError)
+
+		return
+	}
+
+	bodyContent, bodyErr := io.ReadAll(r.Body)
+
+	if bodyErr != nil {
+		response.Resource.Ok = false
+		response.Resource.Error = "error on read review body"
+
+		WriteErrorResponse(w, r, response, route, response.Resource.Error, http.StatusBadRequest)
+
+		return
+	}
+
+	var review HandlePostReviewRequest
+
+	jsonErr := json.Unmarshal(bodyContent, &review)
+
+	if jsonErr != nil {
+		response.Resource.Ok = false
+		response.Resource.Error = "some fields can be in invalid format"
+
+		WriteErrorResponse(w, r, response, route, response.Resource.Error, http.StatusBadRequest)
+
+		return
+	}
+
+	if review.PostId == 0 {
+		response.Resource.Ok = false
+		response.Resource.Error = "fields post_id is required"
+
+		WriteErrorResponse(w, r, response, route, response.Resource.Error, http.StatusBadRequest)
+
+		return
+	}
+
+	if decision && !review.PostReviewStatus.IsDecision() {
+		response.Resource.Ok = false
+		response.Resource.Error = "post_review_status must be approved or rejected"
+
+		WriteErrorResponse(w, r, response, route, response.Resource.Error, http.StatusBadRequest)
+
+		return
+	}
+
+	postById, _ := pr.postModel.ById(r.Context(), review.PostId, ctxWorkspaceId)
+
+	if postById.PostId == 0 {
+		response.Resource.Ok = false
+		response.Resource.Error = "post with id " + strconv.Itoa(review.PostId) + " not found"
+
+		WriteErrorResponse(w, r, response, route, response.Resource.Error, http.StatusBadRequest)
+
+		return
+	}
+
+	rowsAffected, transitionErr := transition(r.Context(), model.PostReviewData{
+		PostId:            review.PostId,
+		PostReviewStatus:  review.PostReviewStatus,
+		PostReviewComment: review.PostReviewComment,
+	}, ctxUserId, ctxWorkspaceId)
+
+	if transitionErr != nil {
+		response.Resource.Ok = false
+		response.Resource.Error = transitionErr.Error()
+
+		WriteErrorResponse(w, r, response, route, "error on post review", http.StatusInternalServerError)
+
+		return
+	}
+
+	if rowsAffected == 0 {
+		response.Resource.Ok = false
+		response.Resource.Error = "post with id " + strconv.Itoa(review.PostId) + " is " + string(postById.PostReviewStatus)
+
+		WriteErrorResponse(w, r, response, route, response.Resource.Error, http.StatusConflict)
+
+		return
+	}
+
+	response.Data.RowsAffected = rowsAffected
+
+	action := model.AuditActionReview
+	reviewAfter := model.PostReviewData{
+		PostId:            review.PostId,
+		PostReviewStatus:  review.PostReviewStatus,
+		PostReviewComment: review.PostReviewComment,
+	}
+
+	if !decision {
+		action = model.AuditActionSubmit
+		reviewAfter.PostReviewStatus = model.PostReviewStatusPending
+	}
+
+	pr.audit.Record(r, action, model.AuditEntityPost, review.PostId, model.PostReviewData{
+		PostId:           review.PostId,
+		PostReviewStatus: postById.PostReviewStatus,
+	}, reviewAfter)
+
+	WriteSuccessResponse(w, response)
+}
@@ -2,20 +2,27 @@ package controller
 
 import (
 	"bytes"
-	"database/sql"
+	"context"
 	"encoding/json"
 	"io"
 	"net/http"
-	"os"
 	"strconv"
 	"strings"
+	"synk/gateway/app/config"
 	"synk/gateway/app/model"
+	"synk/gateway/app/util"
+	"time"
 )
 
 type Posts struct {
-	model           *model.Posts
-	templateModel   *model.Templates
-	intProfileModel *model.IntProfiles
+	model           model.PostsRepository
+	templateModel   model.TemplatesRepository
+	intProfileModel model.IntProfilesRepository
+	usageModel      model.PublishUsageRepository
+	quota           PublishQuota
+	audit           *Auditor
+	queuerClient    *http.Client
+	queuerEndpoint  string
 }
 
 type HandleListResponse struct {
@@ -42,6 +49,10 @@ type HandlePostDeleteRequest struct {
 	PostId int `json:"post_id"`
 }
 
+type HandlePostCloneRequest struct {
+	PostId int `json:"post_id"`
+}
+
 type HandlePostCreateResponse struct {
 	Resource ResponseHeader               `json:"resource"`
 	Data     CreatePostCreateDataResponse `json:"post"`
@@ -64,11 +75,16 @@ type UpdatePostDataResponse struct {
 	RowsAffected int `json:"rows_affected"`
 }
 
-func NewPosts(db *sql.DB) *Posts {
+func NewPosts(repositories *model.Repositories, queuerClient *http.Client, config *config.Config) *Posts {
 	posts := Posts{
-		model:           model.NewPosts(db),
-		templateModel:   model.NewTemplates(db),
-		intProfileModel: model.NewIntProfiles(db),
+		model:           repositories.Posts,
+		templateModel:   repositories.Templates,
+		intProfileModel: repositories.IntProfiles,
+		usageModel:      repositories.PublishUsage,
+		quota:           PublishQuota(config.PublishQuota),
+		audit:           NewAuditor(repositories),
+		queuerClient:    queuerClient,
+		queuerEndpoint:  config.QueuerEndpoint,
 	}
 
 	return &posts
@@ -93,24 +109,39 @@ func (p *Posts) HandleList(w http.ResponseWriter, r *http.Request) {
 		response.Resource.Ok = false
 		response.Resource.Error = "reference to user not found in context"
 
-		WriteErrorResponse(w, response, "/posts", response.Resource.Error, http.StatusInternalServerError)
+		WriteErrorResponse(w, r, response, "/posts", response.Resource.Error, http.StatusInternalServerError)
 
 		return
 	}
 
-	postList, postErr := p.model.List(postId, includeContent == "1", ctxUserId)
+	ctxWorkspaceId := r.Context().Value(CONTEXT_WORKSPACE_ID_KEY).(int)
+
+	if ctxWorkspaceId == 0 {
+		response.Resource.Ok = false
+		response.Resource.Error = "reference to workspace not found in context"
+
+		WriteErrorResponse(w, r, response, "/posts", response.Resource.Error, http.StatusInternalServerError)
+
+		return
+	}
+
+	postList, postErr := p.model.List(r.Context(), postId, includeContent == "1", ctxWorkspaceId)
 
 	if postErr != nil {
 		response.Resource.Ok = false
 		response.Resource.Error = postErr.Error()
 
-		WriteErrorResponse(w, response, "/posts", "error on post fetch", http.StatusInternalServerError)
+		WriteErrorResponse(w, r, response, "/posts", "error on post fetch", http.StatusInternalServerError)
 
 		return
 	}
 
 	response.Data = postList
 
+	if postId != "" && len(postList) == 1 {
+		SetETag(w, postList[0].PostVersion)
+	}
+
 	WriteSuccessResponse(w, response)
 }
 
@@ -130,7 +161,18 @@ func (p *Posts) HandleCreate(w http.ResponseWriter, r *http.Request) {
 		response.Resource.Ok = false
 		response.Resource.Error = "reference to user not found in context"
 
-		WriteErrorResponse(w, response, "/posts", response.Resource.Error, http.StatusInternalServerError)
+		WriteErrorResponse(w, r, response, "/posts", response.Resource.Error, http.StatusInternalServerError)
+
+		return
+	}
+
+	ctxWorkspaceId := r.Context().Value(CONTEXT_WORKSPACE_ID_KEY).(int)
+
+	if ctxWorkspaceId == 0 {
+		response.Resource.Ok = false
+		response.Resource.Error = "reference to workspace not found in context"
+
+		WriteErrorResponse(w, r, response, "/posts", response.Resource.Error, http.StatusInternalServerError)
 
 		return
 	}
@@ -141,7 +183,7 @@ func (p *Posts) HandleCreate(w http.ResponseWriter, r *http.Request) {
 		response.Resource.Ok = false
 		response.Resource.Error = "error on read creation body"
 
-		WriteErrorResponse(w, response, "/posts", response.Resource.Error, http.StatusBadRequest)
+		WriteErrorResponse(w, r, response, "/posts", response.Resource.Error, http.StatusBadRequest)
 
 		return
 	}
@@ -154,7 +196,7 @@ func (p *Posts) HandleCreate(w http.ResponseWriter, r *http.Request) {
 		response.Resource.Ok = false
 		response.Resource.Error = "some fields can be in invalid format"
 
-		WriteErrorResponse(w, response, "/posts", response.Resource.Error, http.StatusBadRequest)
+		WriteErrorResponse(w, r, response, "/posts", response.Resource.Error, http.StatusBadRequest)
 
 		return
 	}
@@ -171,51 +213,54 @@ func (p *Posts) HandleCreate(w http.ResponseWriter, r *http.Request) {
 		response.Resource.Ok = false
 		response.Resource.Error = "fields post_name, post_content, template_id, int_profile_id are required"
 
-		WriteErrorResponse(w, response, "/posts", response.Resource.Error, http.StatusBadRequest)
+		WriteErrorResponse(w, r, response, "/posts", response.Resource.Error, http.StatusBadRequest)
 
 		return
 	}
 
-	templateById, _ := p.templateModel.ById(post.TemplateId, ctxUserId)
+	templateById, _ := p.templateModel.ById(r.Context(), post.TemplateId, ctxWorkspaceId)
 
 	if templateById.TemplateId == 0 {
 		response.Resource.Ok = false
 		response.Resource.Error = "template with id " + strconv.Itoa(post.TemplateId) + " not found"
 
-		WriteErrorResponse(w, response, "/posts", response.Resource.Error, http.StatusBadRequest)
+		WriteErrorResponse(w, r, response, "/posts", response.Resource.Error, http.StatusBadRequest)
 
 		return
 	}
 
-	intProfileById, _ := p.intProfileModel.ById(post.IntProfileId, ctxUserId)
+	intProfileById, _ := p.intProfileModel.ById(r.Context(), post.IntProfileId, ctxWorkspaceId)
 
 	if intProfileById.IntProfileId == 0 {
 		response.Resource.Ok = false
 		response.Resource.Error = "integration profile with id " + strconv.Itoa(post.IntProfileId) + " not found"
 
-		WriteErrorResponse(w, response, "/posts", response.Resource.Error, http.StatusBadRequest)
+		WriteErrorResponse(w, r, response, "/posts", response.Resource.Error, http.StatusBadRequest)
 
 		return
 	}
 
-	creationId, creationErr := p.model.Add(model.PostAddData{
+	creationId, creationErr := p.model.Add(r.Context(), model.PostAddData{
 		PostName:     post.PostName,
 		PostContent:  post.PostContent,
 		TemplateId:   post.TemplateId,
 		IntProfileId: post.IntProfileId,
+		WorkspaceId:  ctxWorkspaceId,
 	}, ctxUserId)
 
 	if creationErr != nil {
 		response.Resource.Ok = false
 		response.Resource.Error = creationErr.Error()
 
-		WriteErrorResponse(w, response, "/posts", response.Resource.Error, http.StatusBadRequest)
+		WriteErrorResponse(w, r, response, "/posts", response.Resource.Error, http.StatusBadRequest)
 
 		return
 	}
 
 	response.Data.PostId = creationId
 
+	p.audit.Record(r, model.AuditActionCreate, model.AuditEntityPost, creationId, nil, p.snapshot(r.Context(), creationId, ctxWorkspaceId))
+
 	WriteSuccessResponse(w, response)
 }
 
@@ -235,7 +280,18 @@ func (p *Posts) HandleUpdate(w http.ResponseWriter, r *http.Request) {
 		response.Resource.Ok = false
 		response.Resource.Error = "reference to user not found in context"
 
-		WriteErrorResponse(w, response, "/posts", response.Resource.Error, http.StatusInternalServerError)
+		WriteErrorResponse(w, r, response, "/posts", response.Resource.Error, http.StatusInternalServerError)
+
+		return
+	}
+
+	ctxWorkspaceId := r.Context().Value(CONTEXT_WORKSPACE_ID_KEY).(int)
+
+	if ctxWorkspaceId == 0 {
+		response.Resource.Ok = false
+		response.Resource.Error = "reference to workspace not found in context"
+
+		WriteErrorResponse(w, r, response, "/posts", response.Resource.Error, http.StatusInternalServerError)
 
 		return
 	}
@@ -246,7 +302,7 @@ func (p *Posts) HandleUpdate(w http.ResponseWriter, r *http.Request) {
 		response.Resource.Ok = false
 		response.Resource.Error = "error on read update body"
 
-		WriteErrorResponse(w, response, "/posts", response.Resource.Error, http.StatusBadRequest)
+		WriteErrorResponse(w, r, response, "/posts", response.Resource.Error, http.StatusBadRequest)
 
 		return
 	}
@@ -259,7 +315,7 @@ func (p *Posts) HandleUpdate(w http.ResponseWriter, r *http.Request) {
 		response.Resource.Ok = false
 		response.Resource.Error = "some fields can be in invalid format"
 
-		WriteErrorResponse(w, response, "/posts", response.Resource.Error, http.StatusBadRequest)
+		WriteErrorResponse(w, r, response, "/posts", response.Resource.Error, http.StatusBadRequest)
 
 		return
 	}
@@ -277,63 +333,83 @@ func (p *Posts) HandleUpdate(w http.ResponseWriter, r *http.Request) {
 		response.Resource.Ok = false
 		response.Resource.Error = "fields post_id, post_name, post_content, template_id, int_profile_id are required"
 
-		WriteErrorResponse(w, response, "/posts", response.Resource.Error, http.StatusBadRequest)
+		WriteErrorResponse(w, r, response, "/posts", response.Resource.Error, http.StatusBadRequest)
 
 		return
 	}
 
-	postById, _ := p.model.ById(post.PostId, ctxUserId)
+	postById, _ := p.model.ById(r.Context(), post.PostId, ctxWorkspaceId)
 
 	if postById.PostId == 0 {
 		response.Resource.Ok = false
 		response.Resource.Error = "post with id " + strconv.Itoa(post.PostId) + " not found"
 
-		WriteErrorResponse(w, response, "/posts", response.Resource.Error, http.StatusBadRequest)
+		WriteErrorResponse(w, r, response, "/posts", response.Resource.Error, http.StatusBadRequest)
 
 		return
 	}
 
-	templateById, _ := p.templateModel.ById(post.TemplateId, ctxUserId)
+	postVersion, versionOk := MatchVersion(w, r, "/posts", "post", postById.PostId, postById.PostVersion)
+
+	if !versionOk {
+		return
+	}
+
+	templateById, _ := p.templateModel.ById(r.Context(), post.TemplateId, ctxWorkspaceId)
 
 	if templateById.TemplateId == 0 {
 		response.Resource.Ok = false
 		response.Resource.Error = "template with id " + strconv.Itoa(post.TemplateId) + " not found"
 
-		WriteErrorResponse(w, response, "/posts", response.Resource.Error, http.StatusBadRequest)
+		WriteErrorResponse(w, r, response, "/posts", response.Resource.Error, http.StatusBadRequest)
 
 		return
 	}
 
-	intProfileById, _ := p.intProfileModel.ById(post.IntProfileId, ctxUserId)
+	intProfileById, _ := p.intProfileModel.ById(r.Context(), post.IntProfileId, ctxWorkspaceId)
 
 	if intProfileById.IntProfileId == 0 {
 		response.Resource.Ok = false
 		response.Resource.Error = "integration profile with id " + strconv.Itoa(post.IntProfileId) + " not found"
 
-		WriteErrorResponse(w, response, "/posts", response.Resource.Error, http.StatusBadRequest)
+		WriteErrorResponse(w, r, response, "/posts", response.Resource.Error, http.StatusBadRequest)
 
 		return
 	}
 
-	rowsAffected, updateErr := p.model.Update(model.PostUpdateData{
+	postBefore := p.snapshot(r.Context(), postById.PostId, ctxWorkspaceId)
+
+	rowsAffected, updateErr := p.model.Update(r.Context(), model.PostUpdateData{
 		PostId:       post.PostId,
 		PostName:     post.PostName,
 		PostContent:  post.PostContent,
 		TemplateId:   post.TemplateId,
 		IntProfileId: post.IntProfileId,
-	}, ctxUserId)
+		PostVersion:  postVersion,
+	}, ctxWorkspaceId)
 
 	if updateErr != nil {
 		response.Resource.Ok = false
 		response.Resource.Error = updateErr.Error()
 
-		WriteErrorResponse(w, response, "/posts", response.Resource.Error, http.StatusBadRequest)
+		WriteErrorResponse(w, r, response, "/posts", response.Resource.Error, http.StatusBadRequest)
+
+		return
+	}
+
+	postAfter, _ := p.model.ById(r.Context(), postById.PostId, ctxWorkspaceId)
+
+	if rowsAffected == 0 {
+		WriteVersionConflict(w, r, "/posts", "post", postById.PostId, postAfter.PostVersion)
 
 		return
 	}
 
 	response.Data.RowsAffected = rowsAffected
 
+	SetETag(w, postAfter.PostVersion)
+	p.audit.Record(r, model.AuditActionUpdate, model.AuditEntityPost, postById.PostId, postBefore, p.snapshot(r.Context(), postById.PostId, ctxWorkspaceId))
+
 	WriteSuccessResponse(w, response)
 }
 
@@ -353,7 +429,18 @@ func (p *Posts) HandleDelete(w http.ResponseWriter, r *http.Request) {
 		response.Resource.Ok = false
 		response.Resource.Error = "reference to user not found in context"
 
-		WriteErrorResponse(w, response, "/posts", response.Resource.Error, http.StatusInternalServerError)
+		WriteErrorResponse(w, r, response, "/posts", response.Resource.Error, http.StatusInternalServerError)
+
+		return
+	}
+
+	ctxWorkspaceId := r.Context().Value(CONTEXT_WORKSPACE_ID_KEY).(int)
+
+	if ctxWorkspaceId == 0 {
+		response.Resource.Ok = false
+		response.Resource.Error = "reference to workspace not found in context"
+
+		WriteErrorResponse(w, r, response, "/posts", response.Resource.Error, http.StatusInternalServerError)
 
 		return
 	}
@@ -364,7 +451,7 @@ func (p *Posts) HandleDelete(w http.ResponseWriter, r *http.Request) {
 		response.Resource.Ok = false
 		response.Resource.Error = "error on read delete body"
 
-		WriteErrorResponse(w, response, "/posts", response.Resource.Error, http.StatusBadRequest)
+		WriteErrorResponse(w, r, response, "/posts", response.Resource.Error, http.StatusBadRequest)
 
 		return
 	}
@@ -377,7 +464,7 @@ func (p *Posts) HandleDelete(w http.ResponseWriter, r *http.Request) {
 		response.Resource.Ok = false
 		response.Resource.Error = "some fields can be in invalid format"
 
-		WriteErrorResponse(w, response, "/posts", response.Resource.Error, http.StatusBadRequest)
+		WriteErrorResponse(w, r, response, "/posts", response.Resource.Error, http.StatusBadRequest)
 
 		return
 	}
@@ -388,35 +475,153 @@ func (p *Posts) HandleDelete(w http.ResponseWriter, r *http.Request) {
 		response.Resource.Ok = false
 		response.Resource.Error = "fields post_id is required"
 
-		WriteErrorResponse(w, response, "/posts", response.Resource.Error, http.StatusBadRequest)
+		WriteErrorResponse(w, r, response, "/posts", response.Resource.Error, http.StatusBadRequest)
 
 		return
 	}
 
-	postById, _ := p.model.ById(post.PostId, ctxUserId)
+	postById, _ := p.model.ById(r.Context(), post.PostId, ctxWorkspaceId)
 
 	if postById.PostId == 0 {
 		response.Resource.Ok = false
 		response.Resource.Error = "post with id " + strconv.Itoa(post.PostId) + " not found"
 
-		WriteErrorResponse(w, response, "/posts", response.Resource.Error, http.StatusBadRequest)
+		WriteErrorResponse(w, r, response, "/posts", response.Resource.Error, http.StatusBadRequest)
+
+		return
+	}
+
+	postVersion, versionOk := MatchVersion(w, r, "/posts", "post", postById.PostId, postById.PostVersion)
 
+	if !versionOk {
 		return
 	}
 
-	rowsAffected, updateErr := p.model.Delete(post.PostId, ctxUserId)
+	postBefore := p.snapshot(r.Context(), postById.PostId, ctxWorkspaceId)
+
+	rowsAffected, updateErr := p.model.Delete(r.Context(), post.PostId, postVersion, ctxWorkspaceId)
 
 	if updateErr != nil {
 		response.Resource.Ok = false
 		response.Resource.Error = updateErr.Error()
 
-		WriteErrorResponse(w, response, "/posts", response.Resource.Error, http.StatusBadRequest)
+		WriteErrorResponse(w, r, response, "/posts", response.Resource.Error, http.StatusBadRequest)
 
 		return
 	}
 
+	if rowsAffected == 0 {
+		postAfter, _ := p.model.ById(r.Context(), postById.PostId, ctxWorkspaceId)
+
+		if postAfter.PostId != 0 {
+			WriteVersionConflict(w, r, "/posts", "post", postById.PostId, postAfter.PostVersion)
+
+			return
+		}
+	}
+
 	response.Data.RowsAffected = rowsAffected
 
+	if rowsAffected > 0 {
+		p.audit.Record(r, model.AuditActionDelete, model.AuditEntityPost, postById.PostId, postBefore, nil)
+	}
+
+	WriteSuccessResponse(w, response)
+}
+
+func (p *Posts) HandleClone(w http.ResponseWriter, r *http.Request) {
+	SetJsonContentType(w)
+
+	response := HandlePostCreateResponse{
+		Resource: ResponseHeader{
+			Ok: true,
+		},
+		Data: CreatePostCreateDataResponse{},
+	}
+
+	ctxUserId := r.Context().Value(CONTEXT_USER_ID_KEY).(int)
+
+	if ctxUserId == 0 {
+		response.Resource.Ok = false
+		response.Resource.Error = "reference to user not found in context"
+
+		WriteErrorResponse(w, r, response, "/posts", response.Resource.Error, http.StatusInternalServerError)
+
+		return
+	}
+
+	ctxWorkspaceId := r.Context().Value(CONTEXT_WORKSPACE_ID_KEY).(int)
+
+	if ctxWorkspaceId == 0 {
+		response.Resource.Ok = false
+		response.Resource.Error = "reference to workspace not found in context"
+
+		WriteErrorResponse(w, r, response, "/posts", response.Resource.Error, http.StatusInternalServerError)
+
+		return
+	}
+
+	bodyContent, bodyErr := io.ReadAll(r.Body)
+
+	if bodyErr != nil {
+		response.Resource.Ok = false
+		response.Resource.Error = "error on read clone body"
+
+		WriteErrorResponse(w, r, response, "/posts", response.Resource.Error, http.StatusBadRequest)
+
+		return
+	}
+
+	var cloneRequest HandlePostCloneRequest
+
+	jsonErr := json.Unmarshal(bodyContent, &cloneRequest)
+
+	if jsonErr != nil {
+		response.Resource.Ok = false
+		response.Resource.Error = "some fields can be in invalid format"
+
+		WriteErrorResponse(w, r, response, "/posts", response.Resource.Error, http.StatusBadRequest)
+
+		return
+	}
+
+	hasAllData := cloneRequest.PostId != 0
+
+	if !hasAllData {
+		response.Resource.Ok = false
+		response.Resource.Error = "fields post_id is required"
+
+		WriteErrorResponse(w, r, response, "/posts", response.Resource.Error, http.StatusBadRequest)
+
+		return
+	}
+
+	itemById, _ := p.model.ById(r.Context(), cloneRequest.PostId, ctxWorkspaceId)
+
+	if itemById.PostId == 0 {
+		response.Resource.Ok = false
+		response.Resource.Error = "post with id " + strconv.Itoa(cloneRequest.PostId) + " not found"
+
+		WriteErrorResponse(w, r, response, "/posts", response.Resource.Error, http.StatusBadRequest)
+
+		return
+	}
+
+	clonedId, cloneErr := p.model.Clone(r.Context(), cloneRequest.PostId, ctxWorkspaceId)
+
+	if cloneErr != nil {
+		response.Resource.Ok = false
+		response.Resource.Error = cloneErr.Error()
+
+		WriteErrorResponse(w, r, response, "/posts", response.Resource.Error, http.StatusBadRequest)
+
+		return
+	}
+
+	response.Data.PostId = clonedId
+
+	p.audit.Record(r, model.AuditActionCreate, model.AuditEntityPost, clonedId, nil, p.snapshot(r.Context(), clonedId, ctxWorkspaceId))
+
 	WriteSuccessResponse(w, response)
 }
 
@@ -435,7 +640,18 @@ func (p *Posts) HandlePublish(w http.ResponseWriter, r *http.Request) {
 		response.Resource.Ok = false
 		response.Resource.Error = "reference to user not found in context"
 
-		WriteErrorResponse(w, response, "/posts", response.Resource.Error, http.StatusInternalServerError)
+		WriteErrorResponse(w, r, response, "/posts", response.Resource.Error, http.StatusInternalServerError)
+
+		return
+	}
+
+	ctxWorkspaceId := r.Context().Value(CONTEXT_WORKSPACE_ID_KEY).(int)
+
+	if ctxWorkspaceId == 0 {
+		response.Resource.Ok = false
+		response.Resource.Error = "reference to workspace not found in context"
+
+		WriteErrorResponse(w, r, response, "/posts", response.Resource.Error, http.StatusInternalServerError)
 
 		return
 	}
@@ -446,7 +662,7 @@ func (p *Posts) HandlePublish(w http.ResponseWriter, r *http.Request) {
 		response.Resource.Ok = false
 		response.Resource.Error = "error on read delete body"
 
-		WriteErrorResponse(w, response, "/posts", response.Resource.Error, http.StatusBadRequest)
+		WriteErrorResponse(w, r, response, "/posts", response.Resource.Error, http.StatusBadRequest)
 
 		return
 	}
@@ -459,7 +675,7 @@ func (p *Posts) HandlePublish(w http.ResponseWriter, r *http.Request) {
 		response.Resource.Ok = false
 		response.Resource.Error = "some fields can be in invalid format"
 
-		WriteErrorResponse(w, response, "/posts", response.Resource.Error, http.StatusBadRequest)
+		WriteErrorResponse(w, r, response, "/posts", response.Resource.Error, http.StatusBadRequest)
 
 		return
 	}
@@ -470,34 +686,107 @@ func (p *Posts) HandlePublish(w http.ResponseWriter, r *http.Request) {
 		response.Resource.Ok = false
 		response.Resource.Error = "fields `post_id` is required"
 
-		WriteErrorResponse(w, response, "/posts", response.Resource.Error, http.StatusBadRequest)
+		WriteErrorResponse(w, r, response, "/posts", response.Resource.Error, http.StatusBadRequest)
 
 		return
 	}
 
-	postById, _ := p.model.ById(post.PostId, ctxUserId)
+	postById, _ := p.model.ById(r.Context(), post.PostId, ctxWorkspaceId)
 
 	if postById.PostId == 0 {
 		response.Resource.Ok = false
 		response.Resource.Error = "post with id " + strconv.Itoa(post.PostId) + " not found"
 
-		WriteErrorResponse(w, response, "/posts", response.Resource.Error, http.StatusBadRequest)
+		WriteErrorResponse(w, r, response, "/posts", response.Resource.Error, http.StatusBadRequest)
+
+		return
+	}
+
+	intProfileById, intProfileErr := p.intProfileModel.ById(r.Context(), postById.IntProfileId, ctxWorkspaceId)
+
+	if intProfileErr != nil {
+		response.Resource.Ok = false
+		response.Resource.Error = intProfileErr.Error()
+
+		WriteErrorResponse(w, r, response, "/posts", "error on integration profile fetch", http.StatusInternalServerError)
+
+		return
+	}
+
+	// Without its profile, whether the post needs approval is unknown, so it
+	// is not published.
+	if intProfileById.IntProfileId == 0 {
+		response.Resource.Ok = false
+		response.Resource.Error = "integration profile with id " + strconv.Itoa(postById.IntProfileId) + " not found"
+
+		WriteErrorResponse(w, r, response, "/posts", response.Resource.Error, http.StatusBadRequest)
 
 		return
 	}
 
-	queuerClient := NewServiceClient()
-	queuerUrl := strings.TrimSuffix(os.Getenv("QUEUER_ENDPOINT"), "/")
+	needsApproval := intProfileById.IntProfileRequiresApproval &&
+		postById.PostReviewStatus != model.PostReviewStatusApproved
+
+	if needsApproval {
+		response.Resource.Ok = false
+		response.Resource.Error = "post with id " + strconv.Itoa(post.PostId) + " requires approval before publishing"
+
+		WriteErrorResponse(w, r, response, "/posts", response.Resource.Error, http.StatusForbidden)
+
+		return
+	}
+
+	now := time.Now().UTC()
+	dayStart, _ := util.ParseTimeBound(now.Format(time.DateOnly), false)
+
+	queuerUrl := p.queuerEndpoint
 
 	if queuerUrl == "" {
 		response.Resource.Ok = false
 		response.Resource.Error = "queue url not set at config"
 
-		WriteErrorResponse(w, response, "/posts", response.Resource.Error, http.StatusInternalServerError)
+		WriteErrorResponse(w, r, response, "/posts", response.Resource.Error, http.StatusInternalServerError)
 
 		return
 	}
 
+	// The usage is reserved before calling the queuer, so concurrent publishes
+	// can not go past the quota, and released when the publish does not happen.
+	usageId, usage, usageErr := p.usageModel.Reserve(r.Context(), model.PublishUsageAddData{
+		UserId:       ctxUserId,
+		IntProfileId: postById.IntProfileId,
+		PostId:       postById.PostId,
+	}, dayStart, p.quota.User, p.quota.IntProfile)
+
+	if usageErr != nil {
+		response.Resource.Ok = false
+		response.Resource.Error = usageErr.Error()
+
+		WriteErrorResponse(w, r, response, "/posts", "error on publish quota check", http.StatusInternalServerError)
+
+		return
+	}
+
+	if usageId == 0 {
+		nextDay := now.Truncate(24 * time.Hour).Add(24 * time.Hour)
+
+		WriteTooManyRequests(w, r, "/posts", p.quota.Exceeded(usage), nextDay.Sub(now))
+
+		return
+	}
+
+	published := false
+
+	defer func() {
+		if published {
+			return
+		}
+
+		if releaseErr := p.usageModel.Release(context.WithoutCancel(r.Context()), usageId); releaseErr != nil {
+			util.LoggerFrom(r.Context()).Error("error on publish usage release: " + releaseErr.Error())
+		}
+	}()
+
 	jsonPayload, jsonPayloadErr := json.Marshal(map[string]any{
 		"posts": []int{post.PostId},
 	})
@@ -506,18 +795,18 @@ func (p *Posts) HandlePublish(w http.ResponseWriter, r *http.Request) {
 		response.Resource.Ok = false
 		response.Resource.Error = "error while encoding communication body to queue: " + jsonPayloadErr.Error()
 
-		WriteErrorResponse(w, response, "/posts", response.Resource.Error, http.StatusInternalServerError)
+		WriteErrorResponse(w, r, response, "/posts", response.Resource.Error, http.StatusInternalServerError)
 
 		return
 	}
 
-	publishReq, publishReqErr := http.NewRequest("POST", queuerUrl+"/send", bytes.NewBuffer(jsonPayload))
+	publishReq, publishReqErr := http.NewRequestWithContext(r.Context(), "POST", queuerUrl+"/send", bytes.NewBuffer(jsonPayload))
 
 	if publishReqErr != nil {
 		response.Resource.Ok = false
 		response.Resource.Error = "error while setting communication to queue: " + publishReqErr.Error()
 
-		WriteErrorResponse(w, response, "/posts", response.Resource.Error, http.StatusInternalServerError)
+		WriteErrorResponse(w, r, response, "/posts", response.Resource.Error, http.StatusInternalServerError)
 
 		return
 	}
@@ -525,42 +814,92 @@ func (p *Posts) HandlePublish(w http.ResponseWriter, r *http.Request) {
 	publishReq.Header.Set("Accept", "application/json")
 	publishReq.Header.Set("Content-Type", "application/json")
 
-	publishResp, publishRespErr := queuerClient.Do(publishReq)
+	AddBreadcrumb(r, "http", "POST queuer /send for post "+strconv.Itoa(post.PostId))
+
+	publishResp, publishRespErr := p.queuerClient.Do(publishReq)
 
 	if publishRespErr != nil {
+		observeQueuerPublish(QUEUER_OUTCOME_ERROR)
+
 		response.Resource.Ok = false
 		response.Resource.Error = "error while communicating to queue: " + publishRespErr.Error()
 
-		WriteErrorResponse(w, response, "/posts", response.Resource.Error, http.StatusInternalServerError)
+		WriteErrorResponse(w, r, response, "/posts", response.Resource.Error, http.StatusInternalServerError)
 
 		return
 	}
 
 	defer publishResp.Body.Close()
 
+	AddBreadcrumb(r, "http", "queuer answered "+strconv.Itoa(publishResp.StatusCode))
+
 	var publishRespContent HandlePostPublishResponse
 
 	bodyBytes, readErr := io.ReadAll(publishResp.Body)
 
 	if readErr != nil {
+		observeQueuerPublish(QUEUER_OUTCOME_ERROR)
+
 		response.Resource.Ok = false
 		response.Resource.Error = "error while parsing queue server response: " + readErr.Error()
 
-		WriteErrorResponse(w, response, "/posts", response.Resource.Error, http.StatusInternalServerError)
+		WriteErrorResponse(w, r, response, "/posts", response.Resource.Error, http.StatusInternalServerError)
+
+		return
+	}
+
+	// A rejected publish is not counted in the quota nor audited. Client
+	// errors of the queuer are passed on, server ones answer 502.
+	if publishResp.StatusCode >= http.StatusBadRequest {
+		observeQueuerPublish(QUEUER_OUTCOME_REJECTED)
+
+		json.Unmarshal(bodyBytes, &publishRespContent)
+
+		status := publishResp.StatusCode
+
+		if status >= http.StatusInternalServerError {
+			status = http.StatusBadGateway
+		}
+
+		response.Resource.Ok = false
+		response.Resource.Error = "queue server refused the publish with status " + strconv.Itoa(publishResp.StatusCode)
+
+		if publishRespContent.Resource.Error != "" {
+			response.Resource.Error += ": " + publishRespContent.Resource.Error
+		}
+
+		WriteErrorResponse(w, r, response, "/posts", response.Resource.Error, status)
 
 		return
 	}
 
 	if err := json.Unmarshal(bodyBytes, &publishRespContent); err != nil {
+		observeQueuerPublish(QUEUER_OUTCOME_ERROR)
+
 		response.Resource.Ok = false
 		response.Resource.Error = "error while decoding queue server response: " + err.Error()
 
-		WriteErrorResponse(w, response, "/posts", response.Resource.Error, http.StatusInternalServerError)
+		WriteErrorResponse(w, r, response, "/posts", response.Resource.Error, http.StatusInternalServerError)
 
 		return
 	}
 
-	response.Resource = response.Resource
+	observeQueuerPublish(QUEUER_OUTCOME_ACCEPTED)
+
+	published = true
+
+	p.audit.Record(r, model.AuditActionPublish, model.AuditEntityPost, postById.PostId, nil, p.snapshot(r.Context(), postById.PostId, ctxWorkspaceId))
 
 	WriteSuccessResponse(w, response)
 }
+
+// snapshot loads the post with its content for the audit log.
+func (p *Posts) snapshot(ctx context.Context, postId int, workspaceId int) any {
+	list, _ := p.model.List(ctx, strconv.Itoa(postId), true, workspaceId)
+
+	if len(list) == 0 {
+		return nil
+	}
+
+	return list[0]
+}
@@ -0,0 +1,172 @@
+package controller
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"synk/gateway/app/config"
+	"synk/gateway/app/model"
+	"time"
+)
+
+const RATE_LIMIT_CLASS_READ = "read"
+const RATE_LIMIT_CLASS_WRITE = "write"
+const RATE_LIMIT_CLASS_PUBLISH = "publish"
+
+// Buckets are swept once this many users are tracked, dropping the ones
+// already refilled.
+const RATE_LIMIT_SWEEP_SIZE = 10000
+
+type rateBucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+// RateLimiter is a token bucket per user: burst requests at once, refilled at
+// burst per period.
+type RateLimiter struct {
+	mu      sync.Mutex
+	buckets map[int]*rateBucket
+	burst   float64
+	period  time.Duration
+	now     func() time.Time
+}
+
+type RateLimits map[string]*RateLimiter
+
+type PublishQuota config.PublishQuota
+
+func NewRateLimiter(burst int, period time.Duration) *RateLimiter {
+	limiter := RateLimiter{
+		buckets: map[int]*rateBucket{},
+		burst:   float64(burst),
+		period:  period,
+		now:     time.Now,
+	}
+
+	return &limiter
+}
+
+// NewRateLimits gives a limiter for each class of limits, leaving out the
+// disabled ones.
+func NewRateLimits(limits config.RateLimits) RateLimits {
+	rateLimits := RateLimits{}
+
+	for class, limit := range map[string]config.RateLimit{
+		RATE_LIMIT_CLASS_READ:    limits.Read,
+		RATE_LIMIT_CLASS_WRITE:   limits.Write,
+		RATE_LIMIT_CLASS_PUBLISH: limits.Publish,
+	} {
+		if limit.Requests > 0 {
+			rateLimits[class] = NewRateLimiter(limit.Requests, limit.Period)
+		}
+	}
+
+	return rateLimits
+}
+
+// Exceeded returns the error for the first cap reached by count, or an empty
+// string.
+func (pq PublishQuota) Exceeded(count model.PublishUsageCount) string {
+	if pq.User > 0 && count.UserCount >= pq.User {
+		return "daily publish quota of " + strconv.Itoa(pq.User) + " reached for user"
+	}
+
+	if pq.IntProfile > 0 && count.IntProfileCount >= pq.IntProfile {
+		return "daily publish quota of " + strconv.Itoa(pq.IntProfile) + " reached for integration profile"
+	}
+
+	return ""
+}
+
+// Allow takes a token from the bucket of the user. When it is empty, it
+// returns how long until the next token.
+func (rl *RateLimiter) Allow(userId int) (bool, time.Duration) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := rl.now()
+	rate := rl.burst / float64(rl.period)
+
+	if len(rl.buckets) >= RATE_LIMIT_SWEEP_SIZE {
+		for key, bucket := range rl.buckets {
+			if bucket.tokens+float64(now.Sub(bucket.updatedAt))*rate >= rl.burst {
+				delete(rl.buckets, key)
+			}
+		}
+	}
+
+	bucket, ok := rl.buckets[userId]
+
+	if !ok {
+		bucket = &rateBucket{tokens: rl.burst, updatedAt: now}
+		rl.buckets[userId] = bucket
+	}
+
+	bucket.tokens = math.Min(rl.burst, bucket.tokens+float64(now.Sub(bucket.updatedAt))*rate)
+	bucket.updatedAt = now
+
+	if bucket.tokens < 1 {
+		return false, time.Duration((1 - bucket.tokens) / rate)
+	}
+
+	bucket.tokens--
+
+	return true, 0
+}
+
+// RateLimitClass puts publishing in its own class, then splits reads from
+// writes by method.
+func RateLimitClass(r *http.Request) string {
+	if r.URL.Path == "/post/publish" {
+		return RATE_LIMIT_CLASS_PUBLISH
+	}
+
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		return RATE_LIMIT_CLASS_READ
+	}
+
+	return RATE_LIMIT_CLASS_WRITE
+}
+
+// RateLimit must run after Auth, as buckets are kept per user.
+func RateLimit(limits RateLimits) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			limiter := limits[RateLimitClass(r)]
+			ctxUserId, _ := r.Context().Value(CONTEXT_USER_ID_KEY).(int)
+
+			if limiter == nil || ctxUserId == 0 {
+				next.ServeHTTP(w, r)
+
+				return
+			}
+
+			if allowed, retryAfter := limiter.Allow(ctxUserId); !allowed {
+				WriteTooManyRequests(w, r, r.URL.Path, "rate limit exceeded", retryAfter)
+
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// WriteTooManyRequests answers 429 with Retry-After rounded up to seconds, at
+// least one.
+func WriteTooManyRequests(w http.ResponseWriter, r *http.Request, route string, message string, retryAfter time.Duration) {
+	SetJsonContentType(w)
+
+	response := ErrorResponse{
+		Resource: ResponseHeader{
+			Ok:    false,
+			Error: message,
+		},
+	}
+
+	w.Header().Set("Retry-After", strconv.Itoa(max(1, int(math.Ceil(retryAfter.Seconds())))))
+
+	WriteErrorResponse(w, r, response, route, message, http.StatusTooManyRequests)
+}
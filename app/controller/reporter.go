@@ -0,0 +1,93 @@
+package controller
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"synk/gateway/app/config"
+	"time"
+)
+
+const ERROR_REPORTER_SENTRY = config.ERROR_REPORTER_SENTRY
+const ERROR_REPORTER_STDOUT = config.ERROR_REPORTER_STDOUT
+const ERROR_REPORTER_NONE = config.ERROR_REPORTER_NONE
+
+// ErrorReporter receives server errors and panics, plus breadcrumbs of the
+// steps that led to them within a request.
+type ErrorReporter interface {
+	Breadcrumb(r *http.Request, category string, message string)
+	Error(r *http.Request, route string, message string)
+	Panic(r *http.Request, recovered any)
+	Flush(timeout time.Duration) bool
+}
+
+var reporter ErrorReporter = NoopReporter{}
+
+// SetErrorReporter replaces the reporter used by WriteErrorResponse and
+// Recovery, which is NoopReporter until set.
+func SetErrorReporter(errorReporter ErrorReporter) {
+	reporter = errorReporter
+}
+
+// CurrentErrorReporter gives the reporter set by SetErrorReporter.
+func CurrentErrorReporter() ErrorReporter {
+	return reporter
+}
+
+// AddBreadcrumb records a step of the request on the current reporter.
+func AddBreadcrumb(r *http.Request, category string, message string) {
+	reporter.Breadcrumb(r, category, message)
+}
+
+type NoopReporter struct{}
+
+func (NoopReporter) Breadcrumb(r *http.Request, category string, message string) {}
+
+func (NoopReporter) Error(r *http.Request, route string, message string) {}
+
+func (NoopReporter) Panic(r *http.Request, recovered any) {}
+
+func (NoopReporter) Flush(timeout time.Duration) bool {
+	return true
+}
+
+// StdoutReporter logs reports with their request context, for local and
+// offline runs. Breadcrumbs are left to the request log.
+type StdoutReporter struct{}
+
+func (StdoutReporter) Breadcrumb(r *http.Request, category string, message string) {}
+
+func (StdoutReporter) Error(r *http.Request, route string, message string) {
+	slog.Error("reported error", append(reportAttrs(r), "route", route, "error", ScrubText(message))...)
+}
+
+func (StdoutReporter) Panic(r *http.Request, recovered any) {
+	slog.Error("reported panic", append(reportAttrs(r), "route", r.URL.Path, "error", ScrubText(fmt.Sprint(recovered)), "stack", string(debug.Stack()))...)
+}
+
+func (StdoutReporter) Flush(timeout time.Duration) bool {
+	return true
+}
+
+func reportAttrs(r *http.Request) []any {
+	attrs := []any{}
+
+	if r == nil {
+		return attrs
+	}
+
+	if requestId, ok := r.Context().Value(CONTEXT_REQUEST_ID_KEY).(string); ok {
+		attrs = append(attrs, "request_id", requestId)
+	}
+
+	if userId, ok := r.Context().Value(CONTEXT_USER_ID_KEY).(int); ok && userId != 0 {
+		attrs = append(attrs, "user_id", userId)
+	}
+
+	if workspaceId, ok := r.Context().Value(CONTEXT_WORKSPACE_ID_KEY).(int); ok && workspaceId != 0 {
+		attrs = append(attrs, "workspace_id", workspaceId)
+	}
+
+	return attrs
+}
@@ -0,0 +1,219 @@
+package controller
+
+import (
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"synk/gateway/app/auth"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+)
+
+var sentrySensitiveHeaders = map[string]bool{
+	"authorization": true,
+	"cookie":        true,
+	"set-cookie":    true,
+	"x-api-key":     true,
+	"x-csrf-token":  true,
+}
+
+var sentryBearerPattern = regexp.MustCompile(`(?i)bearer\s+[a-z0-9\-._~+/]+=*`)
+var sentryApiKeyPattern = regexp.MustCompile(regexp.QuoteMeta(auth.API_KEY_PREFIX) + `[A-Za-z0-9_\-]+`)
+var sentryJsonFieldPattern = regexp.MustCompile(`"([^"]+)"\s*:\s*("(?:[^"\\]|\\.)*"|[^,}\]\s]+)`)
+var sentryQueryFieldPattern = regexp.MustCompile(`([A-Za-z0-9_\-]+)=([^&\s]+)`)
+
+// SentryHub gives each request its own hub, so breadcrumbs and tags of
+// concurrent requests don't mix. It must run before Recovery.
+func SentryHub(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hub := sentry.CurrentHub().Clone()
+		hub.Scope().ClearBreadcrumbs()
+		hub.Scope().SetRequest(r)
+
+		next.ServeHTTP(w, r.WithContext(sentry.SetHubOnContext(r.Context(), hub)))
+	})
+}
+
+// SentryReporter sends reports through the hub of each request, set by
+// SentryHub, or the global one.
+type SentryReporter struct{}
+
+// Breadcrumb keeps a step of the request, sent along with a later error of the
+// same request. Requests without their own hub keep no breadcrumbs.
+func (SentryReporter) Breadcrumb(r *http.Request, category string, message string) {
+	if r == nil {
+		return
+	}
+
+	hub := sentry.GetHubFromContext(r.Context())
+
+	if hub == nil {
+		return
+	}
+
+	level := sentry.LevelInfo
+
+	if category == "response" {
+		level = sentry.LevelWarning
+	}
+
+	hub.AddBreadcrumb(&sentry.Breadcrumb{
+		Category: category,
+		Message:  message,
+		Level:    level,
+	}, nil)
+}
+
+// Error sends message tagged with the route, request and user. Delivery is
+// asynchronous.
+func (SentryReporter) Error(r *http.Request, route string, message string) {
+	hub := hubFromRequest(r)
+
+	hub.WithScope(func(scope *sentry.Scope) {
+		tagSentryScope(scope, r, route)
+
+		hub.CaptureMessage("error(@gateway" + route + "): " + message)
+	})
+}
+
+// Panic sends a recovered panic with its stack trace.
+func (SentryReporter) Panic(r *http.Request, recovered any) {
+	hub := hubFromRequest(r)
+
+	hub.WithScope(func(scope *sentry.Scope) {
+		tagSentryScope(scope, r, r.URL.Path)
+
+		hub.RecoverWithContext(r.Context(), recovered)
+	})
+}
+
+func (SentryReporter) Flush(timeout time.Duration) bool {
+	return sentry.Flush(timeout)
+}
+
+// ScrubSentryEvent is the BeforeSend hook: it drops cookies, bodies and user
+// details other than the ID, and redacts secret headers, query params and
+// values found in messages.
+func ScrubSentryEvent(event *sentry.Event, hint *sentry.EventHint) *sentry.Event {
+	event.Message = ScrubText(event.Message)
+
+	for i := range event.Exception {
+		event.Exception[i].Value = ScrubText(event.Exception[i].Value)
+	}
+
+	for _, breadcrumb := range event.Breadcrumbs {
+		ScrubSentryBreadcrumb(breadcrumb, nil)
+	}
+
+	if event.Request != nil {
+		event.Request.Cookies = ""
+		event.Request.Data = ""
+		event.Request.QueryString = scrubSentryQuery(event.Request.QueryString)
+
+		for name := range event.Request.Headers {
+			if sentrySensitiveHeaders[strings.ToLower(name)] || isSensitiveKey(name) {
+				event.Request.Headers[name] = AUDIT_REDACTED
+			}
+		}
+	}
+
+	event.User = sentry.User{ID: event.User.ID}
+
+	for key := range event.Extra {
+		if isSensitiveKey(key) {
+			event.Extra[key] = AUDIT_REDACTED
+		}
+	}
+
+	return event
+}
+
+// ScrubSentryBreadcrumb is the BeforeBreadcrumb hook, redacting the same
+// values as ScrubSentryEvent.
+func ScrubSentryBreadcrumb(breadcrumb *sentry.Breadcrumb, hint *sentry.BreadcrumbHint) *sentry.Breadcrumb {
+	breadcrumb.Message = ScrubText(breadcrumb.Message)
+
+	for key := range breadcrumb.Data {
+		if isSensitiveKey(key) {
+			breadcrumb.Data[key] = AUDIT_REDACTED
+		}
+	}
+
+	return breadcrumb
+}
+
+// ScrubText redacts bearer tokens, API keys and secret JSON fields or query
+// params inside free text.
+func ScrubText(text string) string {
+	text = sentryBearerPattern.ReplaceAllString(text, "Bearer "+AUDIT_REDACTED)
+	text = sentryApiKeyPattern.ReplaceAllString(text, AUDIT_REDACTED)
+
+	text = sentryJsonFieldPattern.ReplaceAllStringFunc(text, func(field string) string {
+		parts := sentryJsonFieldPattern.FindStringSubmatch(field)
+
+		if !isSensitiveKey(parts[1]) {
+			return field
+		}
+
+		return `"` + parts[1] + `": "` + AUDIT_REDACTED + `"`
+	})
+
+	return sentryQueryFieldPattern.ReplaceAllStringFunc(text, func(field string) string {
+		parts := sentryQueryFieldPattern.FindStringSubmatch(field)
+
+		if !isSensitiveKey(parts[1]) {
+			return field
+		}
+
+		return parts[1] + "=" + AUDIT_REDACTED
+	})
+}
+
+func scrubSentryQuery(rawQuery string) string {
+	values, parseErr := url.ParseQuery(rawQuery)
+
+	if parseErr != nil {
+		return ""
+	}
+
+	for key := range values {
+		if isSensitiveKey(key) {
+			values[key] = []string{AUDIT_REDACTED}
+		}
+	}
+
+	return values.Encode()
+}
+
+func tagSentryScope(scope *sentry.Scope, r *http.Request, route string) {
+	scope.SetTag("route", route)
+
+	if r == nil {
+		return
+	}
+
+	if requestId, ok := r.Context().Value(CONTEXT_REQUEST_ID_KEY).(string); ok {
+		scope.SetTag("request_id", requestId)
+	}
+
+	if userId, ok := r.Context().Value(CONTEXT_USER_ID_KEY).(int); ok && userId != 0 {
+		scope.SetUser(sentry.User{ID: strconv.Itoa(userId)})
+	}
+
+	if workspaceId, ok := r.Context().Value(CONTEXT_WORKSPACE_ID_KEY).(int); ok && workspaceId != 0 {
+		scope.SetTag("workspace_id", strconv.Itoa(workspaceId))
+	}
+}
+
+func hubFromRequest(r *http.Request) *sentry.Hub {
+	if r != nil {
+		if hub := sentry.GetHubFromContext(r.Context()); hub != nil {
+			return hub
+		}
+	}
+
+	return sentry.CurrentHub()
+}
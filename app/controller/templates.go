@@ -1,7 +1,7 @@
 package controller
 
 import (
-	"database/sql"
+	"context"
 	"encoding/json"
 	"io"
 	"net/http"
@@ -12,7 +12,8 @@ import (
 )
 
 type Templates struct {
-	model *model.Templates
+	model model.TemplatesRepository
+	audit *Auditor
 }
 
 type HandleTemplateListResponse struct {
@@ -60,8 +61,12 @@ type HandleTemplateDeleteRequest struct {
 	TemplateId int `json:"template_id"`
 }
 
-func NewTemplates(db *sql.DB) *Templates {
-	templates := Templates{model: model.NewTemplates(db)}
+type HandleTemplateCloneRequest struct {
+	TemplateId int `json:"template_id"`
+}
+
+func NewTemplates(repositories *model.Repositories) *Templates {
+	templates := Templates{model: repositories.Templates, audit: NewAuditor(repositories)}
 
 	return &templates
 }
@@ -84,18 +89,29 @@ func (t *Templates) HandleBasicList(w http.ResponseWriter, r *http.Request) {
 		response.Resource.Ok = false
 		response.Resource.Error = "reference to user not found in context"
 
-		WriteErrorResponse(w, response, "/templates", response.Resource.Error, http.StatusInternalServerError)
+		WriteErrorResponse(w, r, response, "/templates", response.Resource.Error, http.StatusInternalServerError)
+
+		return
+	}
+
+	ctxWorkspaceId := r.Context().Value(CONTEXT_WORKSPACE_ID_KEY).(int)
+
+	if ctxWorkspaceId == 0 {
+		response.Resource.Ok = false
+		response.Resource.Error = "reference to workspace not found in context"
+
+		WriteErrorResponse(w, r, response, "/templates", response.Resource.Error, http.StatusInternalServerError)
 
 		return
 	}
 
-	templatesListContent, templatesErr := t.model.BasicList(ctxUserId)
+	templatesListContent, templatesErr := t.model.BasicList(r.Context(), ctxWorkspaceId)
 
 	if templatesErr != nil {
 		response.Resource.Ok = false
 		response.Resource.Error = templatesErr.Error()
 
-		WriteErrorResponse(w, response, "/templates", "error on template fetch", http.StatusInternalServerError)
+		WriteErrorResponse(w, r, response, "/templates", "error on template fetch", http.StatusInternalServerError)
 
 		return
 	}
@@ -105,7 +121,7 @@ func (t *Templates) HandleBasicList(w http.ResponseWriter, r *http.Request) {
 
 	jsonResp, jsonErr := json.Marshal(response)
 	if jsonErr != nil {
-		util.LogRoute("/templates/basic", "error on response encoding")
+		util.LoggerFrom(r.Context()).Error("error on response encoding")
 
 		return
 	}
@@ -114,7 +130,7 @@ func (t *Templates) HandleBasicList(w http.ResponseWriter, r *http.Request) {
 
 	_, writeErr := w.Write(jsonResp)
 	if writeErr != nil {
-		util.LogRoute("/templates/basic", "error on response log")
+		util.LoggerFrom(r.Context()).Error("error on response log")
 	}
 }
 
@@ -134,7 +150,18 @@ func (t *Templates) HandleList(w http.ResponseWriter, r *http.Request) {
 		response.Resource.Ok = false
 		response.Resource.Error = "reference to user not found in context"
 
-		WriteErrorResponse(w, response, "/templates", response.Resource.Error, http.StatusInternalServerError)
+		WriteErrorResponse(w, r, response, "/templates", response.Resource.Error, http.StatusInternalServerError)
+
+		return
+	}
+
+	ctxWorkspaceId := r.Context().Value(CONTEXT_WORKSPACE_ID_KEY).(int)
+
+	if ctxWorkspaceId == 0 {
+		response.Resource.Ok = false
+		response.Resource.Error = "reference to workspace not found in context"
+
+		WriteErrorResponse(w, r, response, "/templates", response.Resource.Error, http.StatusInternalServerError)
 
 		return
 	}
@@ -142,19 +169,23 @@ func (t *Templates) HandleList(w http.ResponseWriter, r *http.Request) {
 	templateId := r.URL.Query().Get("template_id")
 	includeContent := r.URL.Query().Get("include_content")
 
-	templateList, templateErr := t.model.List(templateId, includeContent == "1", ctxUserId)
+	templateList, templateErr := t.model.List(r.Context(), templateId, includeContent == "1", ctxWorkspaceId)
 
 	if templateErr != nil {
 		response.Resource.Ok = false
 		response.Resource.Error = templateErr.Error()
 
-		WriteErrorResponse(w, response, "/templates", "error on template fetch", http.StatusInternalServerError)
+		WriteErrorResponse(w, r, response, "/templates", "error on template fetch", http.StatusInternalServerError)
 
 		return
 	}
 
 	response.Data = templateList
 
+	if templateId != "" && len(templateList) == 1 {
+		SetETag(w, templateList[0].TemplateVersion)
+	}
+
 	WriteSuccessResponse(w, response)
 }
 
@@ -174,7 +205,18 @@ func (t *Templates) HandleCreate(w http.ResponseWriter, r *http.Request) {
 		response.Resource.Ok = false
 		response.Resource.Error = "reference to user not found in context"
 
-		WriteErrorResponse(w, response, "/templates", response.Resource.Error, http.StatusInternalServerError)
+		WriteErrorResponse(w, r, response, "/templates", response.Resource.Error, http.StatusInternalServerError)
+
+		return
+	}
+
+	ctxWorkspaceId := r.Context().Value(CONTEXT_WORKSPACE_ID_KEY).(int)
+
+	if ctxWorkspaceId == 0 {
+		response.Resource.Ok = false
+		response.Resource.Error = "reference to workspace not found in context"
+
+		WriteErrorResponse(w, r, response, "/templates", response.Resource.Error, http.StatusInternalServerError)
 
 		return
 	}
@@ -185,7 +227,7 @@ func (t *Templates) HandleCreate(w http.ResponseWriter, r *http.Request) {
 		response.Resource.Ok = false
 		response.Resource.Error = "error on read creation body"
 
-		WriteErrorResponse(w, response, "/templates", response.Resource.Error, http.StatusBadRequest)
+		WriteErrorResponse(w, r, response, "/templates", response.Resource.Error, http.StatusBadRequest)
 
 		return
 	}
@@ -198,7 +240,7 @@ func (t *Templates) HandleCreate(w http.ResponseWriter, r *http.Request) {
 		response.Resource.Ok = false
 		response.Resource.Error = "some fields can be in invalid format"
 
-		WriteErrorResponse(w, response, "/templates", response.Resource.Error, http.StatusBadRequest)
+		WriteErrorResponse(w, r, response, "/templates", response.Resource.Error, http.StatusBadRequest)
 
 		return
 	}
@@ -215,29 +257,32 @@ func (t *Templates) HandleCreate(w http.ResponseWriter, r *http.Request) {
 		response.Resource.Ok = false
 		response.Resource.Error = "fields template_name, template_content, template_url_import are required"
 
-		WriteErrorResponse(w, response, "/templates", response.Resource.Error, http.StatusBadRequest)
+		WriteErrorResponse(w, r, response, "/templates", response.Resource.Error, http.StatusBadRequest)
 
 		return
 	}
 
-	creationId, creationErr := t.model.Add(model.TemplateAddData{
+	creationId, creationErr := t.model.Add(r.Context(), model.TemplateAddData{
 		TemplateName:      template.TemplateName,
 		TemplateContent:   template.TemplateContent,
 		TemplateUrlImport: template.TemplateUrlImport,
 		UserId:            ctxUserId,
+		WorkspaceId:       ctxWorkspaceId,
 	})
 
 	if creationErr != nil {
 		response.Resource.Ok = false
 		response.Resource.Error = creationErr.Error()
 
-		WriteErrorResponse(w, response, "/templates", response.Resource.Error, http.StatusBadRequest)
+		WriteErrorResponse(w, r, response, "/templates", response.Resource.Error, http.StatusBadRequest)
 
 		return
 	}
 
 	response.Data.TemplateId = creationId
 
+	t.audit.Record(r, model.AuditActionCreate, model.AuditEntityTemplate, creationId, nil, t.snapshot(r.Context(), creationId, ctxWorkspaceId))
+
 	WriteSuccessResponse(w, response)
 }
 
@@ -257,7 +302,18 @@ func (t *Templates) HandleUpdate(w http.ResponseWriter, r *http.Request) {
 		response.Resource.Ok = false
 		response.Resource.Error = "reference to user not found in context"
 
-		WriteErrorResponse(w, response, "/templates", response.Resource.Error, http.StatusInternalServerError)
+		WriteErrorResponse(w, r, response, "/templates", response.Resource.Error, http.StatusInternalServerError)
+
+		return
+	}
+
+	ctxWorkspaceId := r.Context().Value(CONTEXT_WORKSPACE_ID_KEY).(int)
+
+	if ctxWorkspaceId == 0 {
+		response.Resource.Ok = false
+		response.Resource.Error = "reference to workspace not found in context"
+
+		WriteErrorResponse(w, r, response, "/templates", response.Resource.Error, http.StatusInternalServerError)
 
 		return
 	}
@@ -268,7 +324,7 @@ func (t *Templates) HandleUpdate(w http.ResponseWriter, r *http.Request) {
 		response.Resource.Ok = false
 		response.Resource.Error = "error on read update body"
 
-		WriteErrorResponse(w, response, "/templates", response.Resource.Error, http.StatusBadRequest)
+		WriteErrorResponse(w, r, response, "/templates", response.Resource.Error, http.StatusBadRequest)
 
 		return
 	}
@@ -281,7 +337,7 @@ func (t *Templates) HandleUpdate(w http.ResponseWriter, r *http.Request) {
 		response.Resource.Ok = false
 		response.Resource.Error = "some fields can be in invalid format"
 
-		WriteErrorResponse(w, response, "/templates", response.Resource.Error, http.StatusBadRequest)
+		WriteErrorResponse(w, r, response, "/templates", response.Resource.Error, http.StatusBadRequest)
 
 		return
 	}
@@ -299,40 +355,60 @@ func (t *Templates) HandleUpdate(w http.ResponseWriter, r *http.Request) {
 		response.Resource.Ok = false
 		response.Resource.Error = "fields template_id, template_name, template_content, template_url_import are required"
 
-		WriteErrorResponse(w, response, "/templates", response.Resource.Error, http.StatusBadRequest)
+		WriteErrorResponse(w, r, response, "/templates", response.Resource.Error, http.StatusBadRequest)
 
 		return
 	}
 
-	templateById, _ := t.model.ById(template.TemplateId, ctxUserId)
+	templateById, _ := t.model.ById(r.Context(), template.TemplateId, ctxWorkspaceId)
 
 	if templateById.TemplateId == 0 {
 		response.Resource.Ok = false
 		response.Resource.Error = "template with id " + strconv.Itoa(template.TemplateId) + " not found"
 
-		WriteErrorResponse(w, response, "/templates", response.Resource.Error, http.StatusBadRequest)
+		WriteErrorResponse(w, r, response, "/templates", response.Resource.Error, http.StatusBadRequest)
 
 		return
 	}
 
-	rowsAffected, updateErr := t.model.Update(model.TemplateUpdateData{
+	templateVersion, versionOk := MatchVersion(w, r, "/templates", "template", templateById.TemplateId, templateById.TemplateVersion)
+
+	if !versionOk {
+		return
+	}
+
+	templateBefore := t.snapshot(r.Context(), templateById.TemplateId, ctxWorkspaceId)
+
+	rowsAffected, updateErr := t.model.Update(r.Context(), model.TemplateUpdateData{
 		TemplateId:        templateById.TemplateId,
 		TemplateName:      template.TemplateName,
 		TemplateContent:   template.TemplateContent,
 		TemplateUrlImport: template.TemplateUrlImport,
-	}, ctxUserId)
+		TemplateVersion:   templateVersion,
+	}, ctxWorkspaceId)
 
 	if updateErr != nil {
 		response.Resource.Ok = false
 		response.Resource.Error = updateErr.Error()
 
-		WriteErrorResponse(w, response, "/templates", response.Resource.Error, http.StatusBadRequest)
+		WriteErrorResponse(w, r, response, "/templates", response.Resource.Error, http.StatusBadRequest)
+
+		return
+	}
+
+	templateAfter, _ := t.model.ById(r.Context(), templateById.TemplateId, ctxWorkspaceId)
+
+	if rowsAffected == 0 {
+		WriteVersionConflict(w, r, "/templates", "template", templateById.TemplateId, templateAfter.TemplateVersion)
 
 		return
 	}
 
 	response.Data.RowsAffected = rowsAffected
 
+	SetETag(w, templateAfter.TemplateVersion)
+	t.audit.Record(r, model.AuditActionUpdate, model.AuditEntityTemplate, templateById.TemplateId, templateBefore, t.snapshot(r.Context(), templateById.TemplateId, ctxWorkspaceId))
+
 	WriteSuccessResponse(w, response)
 }
 
@@ -352,7 +428,18 @@ func (t *Templates) HandleDelete(w http.ResponseWriter, r *http.Request) {
 		response.Resource.Ok = false
 		response.Resource.Error = "reference to user not found in context"
 
-		WriteErrorResponse(w, response, "/templates", response.Resource.Error, http.StatusInternalServerError)
+		WriteErrorResponse(w, r, response, "/templates", response.Resource.Error, http.StatusInternalServerError)
+
+		return
+	}
+
+	ctxWorkspaceId := r.Context().Value(CONTEXT_WORKSPACE_ID_KEY).(int)
+
+	if ctxWorkspaceId == 0 {
+		response.Resource.Ok = false
+		response.Resource.Error = "reference to workspace not found in context"
+
+		WriteErrorResponse(w, r, response, "/templates", response.Resource.Error, http.StatusInternalServerError)
 
 		return
 	}
@@ -363,7 +450,7 @@ func (t *Templates) HandleDelete(w http.ResponseWriter, r *http.Request) {
 		response.Resource.Ok = false
 		response.Resource.Error = "error on read delete body"
 
-		WriteErrorResponse(w, response, "/templates", response.Resource.Error, http.StatusBadRequest)
+		WriteErrorResponse(w, r, response, "/templates", response.Resource.Error, http.StatusBadRequest)
 
 		return
 	}
@@ -376,7 +463,7 @@ func (t *Templates) HandleDelete(w http.ResponseWriter, r *http.Request) {
 		response.Resource.Ok = false
 		response.Resource.Error = "some fields can be in invalid format"
 
-		WriteErrorResponse(w, response, "/templates", response.Resource.Error, http.StatusBadRequest)
+		WriteErrorResponse(w, r, response, "/templates", response.Resource.Error, http.StatusBadRequest)
 
 		return
 	}
@@ -387,34 +474,163 @@ func (t *Templates) HandleDelete(w http.ResponseWriter, r *http.Request) {
 		response.Resource.Ok = false
 		response.Resource.Error = "fields template_id is required"
 
-		WriteErrorResponse(w, response, "/templates", response.Resource.Error, http.StatusBadRequest)
+		WriteErrorResponse(w, r, response, "/templates", response.Resource.Error, http.StatusBadRequest)
 
 		return
 	}
 
-	templateById, _ := t.model.ById(template.TemplateId, ctxUserId)
+	templateById, _ := t.model.ById(r.Context(), template.TemplateId, ctxWorkspaceId)
 
 	if templateById.TemplateId == 0 {
 		response.Resource.Ok = false
 		response.Resource.Error = "template with id " + strconv.Itoa(template.TemplateId) + " not found"
 
-		WriteErrorResponse(w, response, "/templates", response.Resource.Error, http.StatusBadRequest)
+		WriteErrorResponse(w, r, response, "/templates", response.Resource.Error, http.StatusBadRequest)
 
 		return
 	}
 
-	rowsAffected, updateErr := t.model.Delete(template.TemplateId, ctxUserId)
+	templateVersion, versionOk := MatchVersion(w, r, "/templates", "template", templateById.TemplateId, templateById.TemplateVersion)
+
+	if !versionOk {
+		return
+	}
+
+	templateBefore := t.snapshot(r.Context(), templateById.TemplateId, ctxWorkspaceId)
+
+	rowsAffected, updateErr := t.model.Delete(r.Context(), template.TemplateId, templateVersion, ctxWorkspaceId)
 
 	if updateErr != nil {
 		response.Resource.Ok = false
 		response.Resource.Error = updateErr.Error()
 
-		WriteErrorResponse(w, response, "/templates", response.Resource.Error, http.StatusBadRequest)
+		WriteErrorResponse(w, r, response, "/templates", response.Resource.Error, http.StatusBadRequest)
 
 		return
 	}
 
+	if rowsAffected == 0 {
+		templateAfter, _ := t.model.ById(r.Context(), templateById.TemplateId, ctxWorkspaceId)
+
+		if templateAfter.TemplateId != 0 {
+			WriteVersionConflict(w, r, "/templates", "template", templateById.TemplateId, templateAfter.TemplateVersion)
+
+			return
+		}
+	}
+
 	response.Data.RowsAffected = rowsAffected
 
+	if rowsAffected > 0 {
+		t.audit.Record(r, model.AuditActionDelete, model.AuditEntityTemplate, templateById.TemplateId, templateBefore, nil)
+	}
+
 	WriteSuccessResponse(w, response)
 }
+
+func (t *Templates) HandleClone(w http.ResponseWriter, r *http.Request) {
+	SetJsonContentType(w)
+
+	response := HandleTemplateCreateResponse{
+		Resource: ResponseHeader{
+			Ok: true,
+		},
+		Data: CreateTemplateCreateDataResponse{},
+	}
+
+	ctxUserId := r.Context().Value(CONTEXT_USER_ID_KEY).(int)
+
+	if ctxUserId == 0 {
+		response.Resource.Ok = false
+		response.Resource.Error = "reference to user not found in context"
+
+		WriteErrorResponse(w, r, response, "/templates", response.Resource.Error, http.StatusInternalServerError)
+
+		return
+	}
+
+	ctxWorkspaceId := r.Context().Value(CONTEXT_WORKSPACE_ID_KEY).(int)
+
+	if ctxWorkspaceId == 0 {
+		response.Resource.Ok = false
+		response.Resource.Error = "reference to workspace not found in context"
+
+		WriteErrorResponse(w, r, response, "/templates", response.Resource.Error, http.StatusInternalServerError)
+
+		return
+	}
+
+	bodyContent, bodyErr := io.ReadAll(r.Body)
+
+	if bodyErr != nil {
+		response.Resource.Ok = false
+		response.Resource.Error = "error on read clone body"
+
+		WriteErrorResponse(w, r, response, "/templates", response.Resource.Error, http.StatusBadRequest)
+
+		return
+	}
+
+	var cloneRequest HandleTemplateCloneRequest
+
+	jsonErr := json.Unmarshal(bodyContent, &cloneRequest)
+
+	if jsonErr != nil {
+		response.Resource.Ok = false
+		response.Resource.Error = "some fields can be in invalid format"
+
+		WriteErrorResponse(w, r, response, "/templates", response.Resource.Error, http.StatusBadRequest)
+
+		return
+	}
+
+	hasAllData := cloneRequest.TemplateId != 0
+
+	if !hasAllData {
+		response.Resource.Ok = false
+		response.Resource.Error = "fields template_id is required"
+
+		WriteErrorResponse(w, r, response, "/templates", response.Resource.Error, http.StatusBadRequest)
+
+		return
+	}
+
+	itemById, _ := t.model.ById(r.Context(), cloneRequest.TemplateId, ctxWorkspaceId)
+
+	if itemById.TemplateId == 0 {
+		response.Resource.Ok = false
+		response.Resource.Error = "template with id " + strconv.Itoa(cloneRequest.TemplateId) + " not found"
+
+		WriteErrorResponse(w, r, response, "/templates", response.Resource.Error, http.StatusBadRequest)
+
+		return
+	}
+
+	clonedId, cloneErr := t.model.Clone(r.Context(), cloneRequest.TemplateId, ctxWorkspaceId)
+
+	if cloneErr != nil {
+		response.Resource.Ok = false
+		response.Resource.Error = cloneErr.Error()
+
+		WriteErrorResponse(w, r, response, "/templates", response.Resource.Error, http.StatusBadRequest)
+
+		return
+	}
+
+	response.Data.TemplateId = clonedId
+
+	t.audit.Record(r, model.AuditActionCreate, model.AuditEntityTemplate, clonedId, nil, t.snapshot(r.Context(), clonedId, ctxWorkspaceId))
+
+	WriteSuccessResponse(w, response)
+}
+
+// snapshot loads the template with its content for the audit log.
+func (t *Templates) snapshot(ctx context.Context, templateId int, workspaceId int) any {
+	list, _ := t.model.List(ctx, strconv.Itoa(templateId), true, workspaceId)
+
+	if len(list) == 0 {
+		return nil
+	}
+
+	return list[0]
+}
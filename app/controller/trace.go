@@ -0,0 +1,147 @@
+package controller
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.43.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const TRACEPARENT_HEADER = "traceparent"
+const TRACER_NAME = "synk/gateway"
+
+// Tracing starts a server span per request, joining the trace of an incoming
+// traceparent. It must run after RequestId and before Logging.
+func Tracing(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+
+		ctx, span := otel.Tracer(TRACER_NAME).Start(ctx, r.Method+" "+spanRoute(r.URL.Path),
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(r.Method),
+				semconv.URLPath(r.URL.Path),
+			),
+		)
+		defer span.End()
+
+		if requestId, ok := r.Context().Value(CONTEXT_REQUEST_ID_KEY).(string); ok {
+			span.SetAttributes(semconv.HTTPRequestHeader(REQUEST_ID_HEADER, requestId))
+		}
+
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		next.ServeHTTP(recorder, r.WithContext(ctx))
+
+		span.SetAttributes(semconv.HTTPResponseStatusCode(recorder.status))
+
+		if recorder.status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(recorder.status))
+		}
+	})
+}
+
+// PropagatingTransport traces calls to the auth server and the queuer as
+// client spans, and forwards the request ID and traceparent taken from the
+// context of each outgoing request.
+type PropagatingTransport struct {
+	Base http.RoundTripper
+}
+
+func (p PropagatingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, span := otel.Tracer(TRACER_NAME).Start(req.Context(), req.Method+" "+req.URL.Path,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.HTTPRequestMethodKey.String(req.Method),
+			semconv.ServerAddress(req.URL.Hostname()),
+			semconv.URLPath(req.URL.Path),
+		),
+	)
+	defer span.End()
+
+	req = req.Clone(ctx)
+
+	if requestId, ok := ctx.Value(CONTEXT_REQUEST_ID_KEY).(string); ok && req.Header.Get(REQUEST_ID_HEADER) == "" {
+		req.Header.Set(REQUEST_ID_HEADER, requestId)
+	}
+
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	base := p.Base
+
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	resp, respErr := base.RoundTrip(req)
+
+	if respErr != nil {
+		span.RecordError(respErr)
+		span.SetStatus(codes.Error, respErr.Error())
+
+		return resp, respErr
+	}
+
+	span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, http.StatusText(resp.StatusCode))
+	}
+
+	return resp, nil
+}
+
+// spanRoute names spans after the route, without the workspace of
+// `/w/{workspace_id}` paths.
+func spanRoute(path string) string {
+	if !strings.HasPrefix(path, WORKSPACE_PATH_PREFIX) {
+		return path
+	}
+
+	_, rest, _ := strings.Cut(strings.TrimPrefix(path, WORKSPACE_PATH_PREFIX), "/")
+
+	return "/" + rest
+}
+
+// withRequestId gives a copy of response with the request ID set on its
+// Resource header, so clients can quote it when reporting an error.
+func withRequestId(response any, r *http.Request) any {
+	requestId, ok := r.Context().Value(CONTEXT_REQUEST_ID_KEY).(string)
+
+	if !ok {
+		return response
+	}
+
+	value := reflect.ValueOf(response)
+
+	if value.Kind() != reflect.Struct {
+		return response
+	}
+
+	copied := reflect.New(value.Type()).Elem()
+	copied.Set(value)
+
+	header := copied.FieldByName("Resource")
+
+	if !header.IsValid() || header.Type() != reflect.TypeOf(ResponseHeader{}) {
+		return response
+	}
+
+	header.FieldByName("RequestId").SetString(requestId)
+
+	return copied.Interface()
+}
+
+func randomHex(size int) string {
+	id := make([]byte, size)
+	rand.Read(id)
+
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,398 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"synk/gateway/app/model"
+)
+
+type Workspaces struct {
+	model model.WorkspacesRepository
+	audit *Auditor
+}
+
+type HandleWorkspaceListResponse struct {
+	Resource ResponseHeader         `json:"resource"`
+	Data     []model.WorkspacesList `json:"workspaces"`
+}
+
+type HandleWorkspaceCreateResponse struct {
+	Resource ResponseHeader                    `json:"resource"`
+	Data     CreateWorkspaceCreateDataResponse `json:"workspace"`
+}
+
+type CreateWorkspaceCreateDataResponse struct {
+	WorkspaceId int `json:"workspace_id"`
+}
+
+type HandleWorkspaceCreateRequest struct {
+	WorkspaceName string `json:"workspace_name"`
+}
+
+type HandleWorkspaceMemberListResponse struct {
+	Resource ResponseHeader               `json:"resource"`
+	Data     []model.WorkspaceMembersList `json:"members"`
+}
+
+type HandleWorkspaceMemberUpdateResponse struct {
+	Resource ResponseHeader                    `json:"resource"`
+	Data     UpdateWorkspaceMemberDataResponse `json:"member"`
+}
+
+type UpdateWorkspaceMemberDataResponse struct {
+	RowsAffected int `json:"rows_affected"`
+}
+
+type HandleWorkspaceMemberRequest struct {
+	UserId        int                 `json:"user_id"`
+	WorkspaceRole model.WorkspaceRole `json:"workspace_role"`
+}
+
+func NewWorkspaces(repositories *model.Repositories) *Workspaces {
+	workspaces := Workspaces{model: repositories.Workspaces, audit: NewAuditor(repositories)}
+
+	return &workspaces
+}
+
+func (ws *Workspaces) HandleList(w http.ResponseWriter, r *http.Request) {
+	SetJsonContentType(w)
+
+	response := HandleWorkspaceListResponse{
+		Resource: ResponseHeader{
+			Ok: true,
+		},
+		Data: []model.WorkspacesList{},
+	}
+
+	ctxUserId := r.Context().Value(CONTEXT_USER_ID_KEY).(int)
+
+	if ctxUserId == 0 {
+		response.Resource.Ok = false
+		response.Resource.Error = "reference to user not found in context"
+
+		WriteErrorResponse(w, r, response, "/workspaces", response.Resource.Error, http.StatusInternalServerError)
+
+		return
+	}
+
+	if _, personalErr := ws.model.Personal(r.Context(), ctxUserId); personalErr != nil {
+		response.Resource.Ok = false
+		response.Resource.Error = personalErr.Error()
+
+		WriteErrorResponse(w, r, response, "/workspaces", "error on personal workspace fetch", http.StatusInternalServerError)
+
+		return
+	}
+
+	workspacesList, workspacesErr := ws.model.List(r.Context(), ctxUserId)
+
+	if workspacesErr != nil {
+		response.Resource.Ok = false
+		response.Resource.Error = workspacesErr.Error()
+
+		WriteErrorResponse(w, r, response, "/workspaces", "error on workspace fetch", http.StatusInternalServerError)
+
+		return
+	}
+
+	if workspacesList != nil {
+		response.Data = workspacesList
+	}
+
+	WriteSuccessResponse(w, response)
+}
+
+func (ws *Workspaces) HandleCreate(w http.ResponseWriter, r *http.Request) {
+	SetJsonContentType(w)
+
+	response := HandleWorkspaceCreateResponse{
+		Resource: ResponseHeader{
+			Ok: true,
+		},
+		Data: CreateWorkspaceCreateDataResponse{},
+	}
+
+	ctxUserId := r.Context().Value(CONTEXT_USER_ID_KEY).(int)
+
+	if ctxUserId == 0 {
+		response.Resource.Ok = false
+		response.Resource.Error = "reference to user not found in context"
+
+		WriteErrorResponse(w, r, response, "/workspaces", response.Resource.Error, http.StatusInternalServerError)
+
+		return
+	}
+
+	bodyContent, bodyErr := io.ReadAll(r.Body)
+
+	if bodyErr != nil {
+		response.Resource.Ok = false
+		response.Resource.Error = "error on read creation body"
+
+		WriteErrorResponse(w, r, response, "/workspaces", response.Resource.Error, http.StatusBadRequest)
+
+		return
+	}
+
+	var workspace HandleWorkspaceCreateRequest
+
+	jsonErr := json.Unmarshal(bodyContent, &workspace)
+
+	if jsonErr != nil {
+		response.Resource.Ok = false
+		response.Resource.Error = "some fields can be in invalid format"
+
+		WriteErrorResponse(w, r, response, "/workspaces", response.Resource.Error, http.StatusBadRequest)
+
+		return
+	}
+
+	workspace.WorkspaceName = strings.TrimSpace(workspace.WorkspaceName)
+
+	if workspace.WorkspaceName == "" {
+		response.Resource.Ok = false
+		response.Resource.Error = "fields workspace_name is required"
+
+		WriteErrorResponse(w, r, response, "/workspaces", response.Resource.Error, http.StatusBadRequest)
+
+		return
+	}
+
+	creationId, creationErr := ws.model.Add(r.Context(), model.WorkspaceAddData{
+		WorkspaceName: workspace.WorkspaceName,
+	}, ctxUserId)
+
+	if creationErr != nil {
+		response.Resource.Ok = false
+		response.Resource.Error = creationErr.Error()
+
+		WriteErrorResponse(w, r, response, "/workspaces", "error on workspace creation", http.StatusInternalServerError)
+
+		return
+	}
+
+	response.Data.WorkspaceId = creationId
+
+	ws.audit.Record(r, model.AuditActionCreate, model.AuditEntityWorkspace, creationId, nil, model.WorkspaceAddData{
+		WorkspaceName: workspace.WorkspaceName,
+	})
+
+	WriteSuccessResponse(w, response)
+}
+
+func (ws *Workspaces) HandleMemberList(w http.ResponseWriter, r *http.Request) {
+	SetJsonContentType(w)
+
+	response := HandleWorkspaceMemberListResponse{
+		Resource: ResponseHeader{
+			Ok: true,
+		},
+		Data: []model.WorkspaceMembersList{},
+	}
+
+	ctxWorkspaceId := r.Context().Value(CONTEXT_WORKSPACE_ID_KEY).(int)
+
+	if ctxWorkspaceId == 0 {
+		response.Resource.Ok = false
+		response.Resource.Error = "reference to workspace not found in context"
+
+		WriteErrorResponse(w, r, response, "/workspaces/members", response.Resource.Error, http.StatusInternalServerError)
+
+		return
+	}
+
+	membersList, membersErr := ws.model.Members(r.Context(), ctxWorkspaceId)
+
+	if membersErr != nil {
+		response.Resource.Ok = false
+		response.Resource.Error = membersErr.Error()
+
+		WriteErrorResponse(w, r, response, "/workspaces/members", "error on workspace member fetch", http.StatusInternalServerError)
+
+		return
+	}
+
+	if membersList != nil {
+		response.Data = membersList
+	}
+
+	WriteSuccessResponse(w, response)
+}
+
+func (ws *Workspaces) HandleMemberAdd(w http.ResponseWriter, r *http.Request) {
+	ws.handleMemberChange(w, r, func(workspaceId int, member HandleWorkspaceMemberRequest) (int, error) {
+		return ws.model.AddMember(r.Context(), workspaceId, member.UserId, member.WorkspaceRole)
+	})
+}
+
+func (ws *Workspaces) HandleMemberUpdate(w http.ResponseWriter, r *http.Request) {
+	ws.handleMemberChange(w, r, func(workspaceId int, member HandleWorkspaceMemberRequest) (int, error) {
+		return ws.model.UpdateMember(r.Context(), workspaceId, member.UserId, member.WorkspaceRole)
+	})
+}
+
+func (ws *Workspaces) HandleMemberRemove(w http.ResponseWriter, r *http.Request) {
+	ws.handleMemberChange(w, r, func(workspaceId int, member HandleWorkspaceMemberRequest) (int, error) {
+		return ws.model.RemoveMember(r.Context(), workspaceId, member.UserId)
+	})
+}
+
+// handleMemberChange reads and validates the member body shared by the
+// add/update/remove routes before running change on the current workspace.
+func (ws *Workspaces) handleMemberChange(
+	w http.ResponseWriter,
+	r *http.Request,
+	change func(workspaceId int, member HandleWorkspaceMemberRequest) (int, error),
+) {
+	SetJsonContentType(w)
+
+	response := HandleWorkspaceMemberUpdateResponse{
+		Resource: ResponseHeader{
+			Ok: true,
+		},
+		Data: UpdateWorkspaceMemberDataResponse{},
+	}
+
+	ctxWorkspaceId := r.Context().Value(CONTEXT_WORKSPACE_ID_KEY).(int)
+
+	if ctxWorkspaceId == 0 {
+		response.Resource.Ok = false
+		response.Resource.Error = "reference to workspace not found in context"
+
+		WriteErrorResponse(w, r, response, "/workspaces/members", response.Resource.Error, http.StatusInternalServerError)
+
+		return
+	}
+
+	bodyContent, bodyErr := io.ReadAll(r.Body)
+
+	if bodyErr != nil {
+		response.Resource.Ok = false
+		response.Resource.Error = "error on read member body"
+
+		WriteErrorResponse(w, r, response, "/workspaces/members", response.Resource.Error, http.StatusBadRequest)
+
+		return
+	}
+
+	var member HandleWorkspaceMemberRequest
+
+	jsonErr := json.Unmarshal(bodyContent, &member)
+
+	if jsonErr != nil {
+		response.Resource.Ok = false
+		response.Resource.Error = "some fields can be in invalid format"
+
+		WriteErrorResponse(w, r, response, "/workspaces/members", response.Resource.Error, http.StatusBadRequest)
+
+		return
+	}
+
+	if member.UserId == 0 {
+		response.Resource.Ok = false
+		response.Resource.Error = "fields user_id is required"
+
+		WriteErrorResponse(w, r, response, "/workspaces/members", response.Resource.Error, http.StatusBadRequest)
+
+		return
+	}
+
+	if r.Method != http.MethodDelete && !member.WorkspaceRole.IsValid() {
+		response.Resource.Ok = false
+		response.Resource.Error = "workspace_role must be owner, editor or viewer"
+
+		WriteErrorResponse(w, r, response, "/workspaces/members", response.Resource.Error, http.StatusBadRequest)
+
+		return
+	}
+
+	demotesOwner := r.Method == http.MethodDelete ||
+		(r.Method == http.MethodPut && member.WorkspaceRole != model.WorkspaceRoleOwner)
+
+	if demotesOwner && ws.isLastOwner(r.Context(), ctxWorkspaceId, member.UserId) {
+		response.Resource.Ok = false
+		response.Resource.Error = "workspace must keep at least one owner"
+
+		WriteErrorResponse(w, r, response, "/workspaces/members", response.Resource.Error, http.StatusConflict)
+
+		return
+	}
+
+	var memberBefore any
+
+	if roleBefore, _ := ws.model.Role(r.Context(), ctxWorkspaceId, member.UserId); roleBefore != "" {
+		memberBefore = HandleWorkspaceMemberRequest{UserId: member.UserId, WorkspaceRole: roleBefore}
+	}
+
+	rowsAffected, changeErr := change(ctxWorkspaceId, member)
+
+	if errors.Is(changeErr, model.ErrMemberExists) {
+		response.Resource.Ok = false
+		response.Resource.Error = "user with id " + strconv.Itoa(member.UserId) + " is already a member"
+
+		WriteErrorResponse(w, r, response, "/workspaces/members", response.Resource.Error, http.StatusConflict)
+
+		return
+	}
+
+	if errors.Is(changeErr, model.ErrUserNotFound) {
+		response.Resource.Ok = false
+		response.Resource.Error = "user with id " + strconv.Itoa(member.UserId) + " not found"
+
+		WriteErrorResponse(w, r, response, "/workspaces/members", response.Resource.Error, http.StatusBadRequest)
+
+		return
+	}
+
+	if changeErr != nil {
+		response.Resource.Ok = false
+		response.Resource.Error = changeErr.Error()
+
+		WriteErrorResponse(w, r, response, "/workspaces/members", "error on workspace member change", http.StatusInternalServerError)
+
+		return
+	}
+
+	if rowsAffected == 0 {
+		response.Resource.Ok = false
+		response.Resource.Error = "member with user id " + strconv.Itoa(member.UserId) + " not found"
+
+		WriteErrorResponse(w, r, response, "/workspaces/members", response.Resource.Error, http.StatusBadRequest)
+
+		return
+	}
+
+	response.Data.RowsAffected = rowsAffected
+
+	switch r.Method {
+	case http.MethodPost:
+		ws.audit.Record(r, model.AuditActionCreate, model.AuditEntityWorkspaceMember, member.UserId, nil, member)
+	case http.MethodPut:
+		ws.audit.Record(r, model.AuditActionUpdate, model.AuditEntityWorkspaceMember, member.UserId, memberBefore, member)
+	case http.MethodDelete:
+		ws.audit.Record(r, model.AuditActionDelete, model.AuditEntityWorkspaceMember, member.UserId, memberBefore, nil)
+	}
+
+	WriteSuccessResponse(w, response)
+}
+
+func (ws *Workspaces) isLastOwner(ctx context.Context, workspaceId int, userId int) bool {
+	members, _ := ws.model.Members(ctx, workspaceId)
+
+	owners := 0
+	isOwner := false
+
+	for _, member := range members {
+		if member.WorkspaceRole == model.WorkspaceRoleOwner {
+			owners++
+			isOwner = isOwner || member.UserId == userId
+		}
+	}
+
+	return isOwner && owners == 1
+}
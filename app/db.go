@@ -1,41 +1,160 @@
 package app
 
 import (
+	"crypto/rand"
 	"database/sql"
-	"os"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"synk/gateway/app/config"
+	"synk/gateway/app/migration"
 	"synk/gateway/app/util"
+	"time"
 
+	"github.com/XSAM/otelsql"
 	"github.com/go-sql-driver/mysql"
+	semconv "go.opentelemetry.io/otel/semconv/v1.43.0"
 )
 
-func InitDB(testing bool) (*sql.DB, error) {
-	cfg := mysql.NewConfig()
+const DB_TEST_SCHEMA_PREFIX = "synk_test_"
 
-	user := os.Getenv("DB_USER")
-	pass := os.Getenv("DB_PASS")
-	address := os.Getenv("DB_HOST") + ":" + os.Getenv("DB_PORT")
+func mysqlConfig(dbConfig config.DB) *mysql.Config {
+	cfg := mysql.NewConfig()
 
-	cfg.User = user
-	cfg.Passwd = pass
+	cfg.User = dbConfig.User
+	cfg.Passwd = dbConfig.Pass
 	cfg.Net = "tcp"
-	cfg.Addr = address
-	cfg.DBName = "synk"
+	cfg.Addr = dbConfig.Host + ":" + dbConfig.Port
+	cfg.DBName = dbConfig.Name
+
+	// Dates are compared with UTC bounds, such as the daily publish quota, so
+	// the session stores and reads TIMESTAMP values in UTC as well.
+	cfg.Loc = time.UTC
+	cfg.Params = map[string]string{"time_zone": "'+00:00'"}
+
+	return cfg
+}
+
+// InitDB opens the database of LoadDB, for the migrate command and the tests.
+func InitDB(testing bool) (*sql.DB, error) {
+	dbConfig, configErr := config.LoadDB(testing)
+
+	if configErr != nil {
+		util.LogError("invalid database config: " + configErr.Error())
+
+		return nil, configErr
+	}
+
+	return OpenDB(dbConfig)
+}
 
-	util.Log("connecting do database")
+func OpenDB(dbConfig config.DB) (*sql.DB, error) {
+	util.Log("connecting do database " + dbConfig.Name)
 
-	db, err := sql.Open("mysql", cfg.FormatDSN())
+	db, err := otelsql.Open("mysql", mysqlConfig(dbConfig).FormatDSN(),
+		otelsql.WithAttributes(semconv.DBSystemNameMySQL),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{
+			DisableErrSkip:       true,
+			OmitConnResetSession: true,
+			OmitConnectorConnect: true,
+			OmitRows:             true,
+		}),
+	)
 	if err != nil {
-		util.Log("error when connecting on db: " + err.Error())
+		util.LogError("error when connecting on db: " + err.Error())
 
 		return nil, err
 	}
 
+	db.SetMaxOpenConns(dbConfig.MaxOpenConns)
+	db.SetMaxIdleConns(dbConfig.MaxIdleConns)
+	db.SetConnMaxLifetime(dbConfig.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(dbConfig.ConnMaxIdleTime)
+
 	pingErr := db.Ping()
 	if pingErr != nil {
-		util.Log("error when ping on db: " + pingErr.Error())
+		util.LogError("error when ping on db: " + pingErr.Error())
+
+		db.Close()
 
 		return nil, pingErr
 	}
 
 	return db, nil
 }
+
+func CreateTestSchema() (string, error) {
+	dbConfig, configErr := config.LoadDB(true)
+
+	if configErr != nil {
+		return "", configErr
+	}
+
+	suffix := make([]byte, 4)
+
+	if _, randErr := rand.Read(suffix); randErr != nil {
+		return "", fmt.Errorf("app.create_test_schema: %s", randErr.Error())
+	}
+
+	schemaName := DB_TEST_SCHEMA_PREFIX + strconv.FormatInt(time.Now().Unix(), 10) + "_" + hex.EncodeToString(suffix)
+
+	serverConfig := dbConfig
+	serverConfig.Name = ""
+
+	server, serverErr := OpenDB(serverConfig)
+
+	if serverErr != nil {
+		return "", serverErr
+	}
+
+	defer server.Close()
+
+	if _, createErr := server.Exec("CREATE DATABASE `" + schemaName + "`"); createErr != nil {
+		return "", fmt.Errorf("app.create_test_schema: %s", createErr.Error())
+	}
+
+	dbConfig.Name = schemaName
+
+	db, dbErr := OpenDB(dbConfig)
+
+	if dbErr != nil {
+		return schemaName, dbErr
+	}
+
+	defer db.Close()
+
+	if _, upErr := migration.NewMigrator(db).Up(); upErr != nil {
+		return schemaName, upErr
+	}
+
+	return schemaName, nil
+}
+
+func DropTestSchema(schemaName string) error {
+	if !strings.HasPrefix(schemaName, DB_TEST_SCHEMA_PREFIX) || schemaName == DB_TEST_SCHEMA_PREFIX {
+		return fmt.Errorf("app.drop_test_schema: refusing to drop %s", schemaName)
+	}
+
+	dbConfig, configErr := config.LoadDB(true)
+
+	if configErr != nil {
+		return configErr
+	}
+
+	dbConfig.Name = ""
+
+	server, serverErr := OpenDB(dbConfig)
+
+	if serverErr != nil {
+		return serverErr
+	}
+
+	defer server.Close()
+
+	if _, dropErr := server.Exec("DROP DATABASE IF EXISTS `" + schemaName + "`"); dropErr != nil {
+		return fmt.Errorf("app.drop_test_schema: %s", dropErr.Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,83 @@
+package app
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"synk/gateway/app/config"
+	"synk/gateway/app/migration"
+	"synk/gateway/app/util"
+)
+
+const MIGRATE_USAGE = "usage: gateway migrate <up|down|status>"
+
+func Migrate(args []string) error {
+	if len(args) != 1 {
+		return errors.New(MIGRATE_USAGE)
+	}
+
+	db, dbErr := InitDB(false)
+
+	if dbErr != nil {
+		return dbErr
+	}
+
+	defer db.Close()
+
+	migrator := migration.NewMigrator(db)
+
+	switch args[0] {
+	case "up":
+		applied, upErr := migrator.Up()
+
+		if upErr != nil {
+			return upErr
+		}
+
+		util.Log(fmt.Sprintf("%d migration(s) applied", len(applied)))
+	case "down":
+		reverted, downErr := migrator.Down()
+
+		if downErr != nil {
+			return downErr
+		}
+
+		if reverted == "" {
+			util.Log("no migration to revert")
+		} else {
+			util.Log("migration " + reverted + " reverted")
+		}
+	case "status":
+		statusList, statusErr := migrator.Status()
+
+		if statusErr != nil {
+			return statusErr
+		}
+
+		for _, status := range statusList {
+			state := "pending"
+
+			if status.Applied {
+				state = "applied at " + status.AppliedAt
+			}
+
+			fmt.Println(status.Version + "_" + status.Name + " > " + state)
+		}
+	default:
+		return errors.New(MIGRATE_USAGE)
+	}
+
+	return nil
+}
+
+func AutoMigrate(db *sql.DB, config *config.Config) error {
+	if config.Production() || !config.DBAutoMigrate {
+		return nil
+	}
+
+	util.Log("running database migrations")
+
+	_, upErr := migration.NewMigrator(db).Up()
+
+	return upErr
+}
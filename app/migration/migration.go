@@ -0,0 +1,341 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"synk/gateway/app/util"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+//go:embed sql/*.sql
+var files embed.FS
+
+const MIGRATIONS_TABLE = "schema_migration"
+
+// BASELINE_VERSION only adopts the tables that existed before the migrations, so
+// reverting it would drop their data. Down stops above it.
+const BASELINE_VERSION = "0001"
+
+// MySQL errors of statements whose change is already in place, skipped when a
+// migration that failed halfway is run again.
+const MYSQL_ERR_TABLE_EXISTS = 1050
+const MYSQL_ERR_DUPLICATE_COLUMN = 1060
+const MYSQL_ERR_DUPLICATE_KEY_NAME = 1061
+const MYSQL_ERR_CANT_DROP_MISSING = 1091
+const MYSQL_ERR_DUPLICATE_FOREIGN_KEY = 1826
+
+type Migration struct {
+	Version string
+	Name    string
+	Up      string
+	Down    string
+}
+
+type MigrationStatus struct {
+	Version   string
+	Name      string
+	Applied   bool
+	AppliedAt string
+}
+
+type Migrator struct {
+	db *sql.DB
+}
+
+func NewMigrator(db *sql.DB) *Migrator {
+	migrator := Migrator{db: db}
+
+	return &migrator
+}
+
+func List() ([]Migration, error) {
+	entries, readErr := files.ReadDir("sql")
+
+	if readErr != nil {
+		return nil, fmt.Errorf("migration.list: %s", readErr.Error())
+	}
+
+	migrationsByVersion := map[string]*Migration{}
+
+	for _, entry := range entries {
+		fileName := entry.Name()
+
+		var direction string
+
+		switch {
+		case strings.HasSuffix(fileName, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(fileName, ".down.sql"):
+			direction = "down"
+		default:
+			return nil, fmt.Errorf("migration.list: file %s must end with .up.sql or .down.sql", fileName)
+		}
+
+		baseName := strings.TrimSuffix(fileName, "."+direction+".sql")
+		version, name, found := strings.Cut(baseName, "_")
+
+		if !found || version == "" || name == "" {
+			return nil, fmt.Errorf("migration.list: file %s must be named <version>_<name>.%s.sql", fileName, direction)
+		}
+
+		content, contentErr := files.ReadFile(path.Join("sql", fileName))
+
+		if contentErr != nil {
+			return nil, fmt.Errorf("migration.list: %s", contentErr.Error())
+		}
+
+		migration, ok := migrationsByVersion[version]
+
+		if !ok {
+			migration = &Migration{Version: version, Name: name}
+			migrationsByVersion[version] = migration
+		}
+
+		if migration.Name != name {
+			return nil, fmt.Errorf("migration.list: version %s is used by %s and %s", version, migration.Name, name)
+		}
+
+		if direction == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := []Migration{}
+
+	for _, migration := range migrationsByVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration.list: version %s must have both up and down files", migration.Version)
+		}
+
+		migrations = append(migrations, *migration)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+func (m *Migrator) Up() ([]string, error) {
+	migrations, applied, loadErr := m.load()
+
+	if loadErr != nil {
+		return nil, loadErr
+	}
+
+	appliedNow := []string{}
+
+	for _, migration := range migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+
+		util.Log("applying migration " + migration.Version + "_" + migration.Name)
+
+		runErr := m.run(migration.Up, func(ctx context.Context, db *sql.DB) error {
+			_, insertErr := db.ExecContext(
+				ctx,
+				`INSERT INTO `+MIGRATIONS_TABLE+` (version, name) VALUES (?, ?)`,
+				migration.Version, migration.Name,
+			)
+
+			return insertErr
+		})
+
+		if runErr != nil {
+			return appliedNow, fmt.Errorf("migration.up(%s): %s", migration.Version, runErr.Error())
+		}
+
+		appliedNow = append(appliedNow, migration.Version)
+	}
+
+	return appliedNow, nil
+}
+
+func (m *Migrator) Down() (string, error) {
+	migrations, applied, loadErr := m.load()
+
+	if loadErr != nil {
+		return "", loadErr
+	}
+
+	for i := len(migrations) - 1; i >= 0; i-- {
+		migration := migrations[i]
+
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+
+		if migration.Version <= BASELINE_VERSION {
+			return "", fmt.Errorf("migration.down(%s): the baseline migration can not be reverted", migration.Version)
+		}
+
+		util.Log("reverting migration " + migration.Version + "_" + migration.Name)
+
+		runErr := m.run(migration.Down, func(ctx context.Context, db *sql.DB) error {
+			_, deleteErr := db.ExecContext(
+				ctx,
+				`DELETE FROM `+MIGRATIONS_TABLE+` WHERE version = ?`,
+				migration.Version,
+			)
+
+			return deleteErr
+		})
+
+		if runErr != nil {
+			return "", fmt.Errorf("migration.down(%s): %s", migration.Version, runErr.Error())
+		}
+
+		return migration.Version, nil
+	}
+
+	return "", nil
+}
+
+func (m *Migrator) Status() ([]MigrationStatus, error) {
+	migrations, applied, loadErr := m.load()
+
+	if loadErr != nil {
+		return nil, loadErr
+	}
+
+	statusList := []MigrationStatus{}
+
+	for _, migration := range migrations {
+		appliedAt, ok := applied[migration.Version]
+
+		statusList = append(statusList, MigrationStatus{
+			Version:   migration.Version,
+			Name:      migration.Name,
+			Applied:   ok,
+			AppliedAt: appliedAt,
+		})
+	}
+
+	return statusList, nil
+}
+
+func (m *Migrator) load() ([]Migration, map[string]string, error) {
+	migrations, listErr := List()
+
+	if listErr != nil {
+		return nil, nil, listErr
+	}
+
+	_, createErr := m.db.ExecContext(
+		context.Background(),
+		`CREATE TABLE IF NOT EXISTS `+MIGRATIONS_TABLE+` (
+            version VARCHAR(32) NOT NULL,
+            name VARCHAR(255) NOT NULL,
+            applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+            PRIMARY KEY (version)
+        )`,
+	)
+
+	if createErr != nil {
+		return nil, nil, fmt.Errorf("migration.load: %s", createErr.Error())
+	}
+
+	rows, rowsErr := m.db.Query(`SELECT version, applied_at FROM ` + MIGRATIONS_TABLE)
+
+	if rowsErr != nil {
+		return nil, nil, fmt.Errorf("migration.load: %s", rowsErr.Error())
+	}
+
+	defer rows.Close()
+
+	applied := map[string]string{}
+
+	for rows.Next() {
+		var version string
+		var appliedAt string
+
+		exception := rows.Scan(&version, &appliedAt)
+
+		if exception != nil {
+			return nil, nil, fmt.Errorf("migration.load: %s", exception.Error())
+		}
+
+		applied[version] = util.ToTimeBR(appliedAt)
+	}
+
+	rowsErr = rows.Err()
+
+	if rowsErr != nil {
+		return nil, nil, fmt.Errorf("migration.load: %s", rowsErr.Error())
+	}
+
+	return migrations, applied, nil
+}
+
+// run executes the statements one by one and then records the migration. MySQL
+// commits each DDL statement on its own, so a migration is not atomic: when one
+// fails halfway, the applied statements stay and the version is not recorded.
+// Running it again skips the statements that are already applied, so scripts
+// keep one DDL change per statement and guard their data changes.
+func (m *Migrator) run(script string, record func(ctx context.Context, db *sql.DB) error) error {
+	ctx := context.Background()
+
+	for _, statement := range Statements(script) {
+		_, execErr := m.db.ExecContext(ctx, statement)
+
+		if execErr != nil && !AlreadyApplied(execErr) {
+			return execErr
+		}
+	}
+
+	return record(ctx, m.db)
+}
+
+// AlreadyApplied tells if a statement failed only because its change is already in place.
+func AlreadyApplied(err error) bool {
+	var mysqlErr *mysql.MySQLError
+
+	if !errors.As(err, &mysqlErr) {
+		return false
+	}
+
+	switch mysqlErr.Number {
+	case MYSQL_ERR_TABLE_EXISTS, MYSQL_ERR_DUPLICATE_COLUMN, MYSQL_ERR_DUPLICATE_KEY_NAME, MYSQL_ERR_CANT_DROP_MISSING, MYSQL_ERR_DUPLICATE_FOREIGN_KEY:
+		return true
+	}
+
+	return false
+}
+
+func Statements(script string) []string {
+	statements := []string{}
+	current := []string{}
+
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+
+		current = append(current, line)
+
+		if strings.HasSuffix(trimmed, ";") {
+			statement := strings.TrimSuffix(strings.TrimSpace(strings.Join(current, "\n")), ";")
+			statements = append(statements, statement)
+			current = []string{}
+		}
+	}
+
+	if len(current) > 0 {
+		statements = append(statements, strings.TrimSpace(strings.Join(current, "\n")))
+	}
+
+	return statements
+}
@@ -0,0 +1,2 @@
+-- 0001 is the baseline of a schema that already existed, with the users owned by
+-- the auth service, so it is never reverted. The migrator refuses to go below it.
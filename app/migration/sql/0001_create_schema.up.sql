@@ -0,0 +1,98 @@
+CREATE TABLE IF NOT EXISTS user (
+    user_id INT NOT NULL AUTO_INCREMENT,
+    user_name VARCHAR(255) NOT NULL,
+    user_email VARCHAR(255) NOT NULL,
+    user_pass VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NULL DEFAULT NULL,
+    deleted_at TIMESTAMP NULL DEFAULT NULL,
+    PRIMARY KEY (user_id),
+    UNIQUE KEY uk_user_email (user_email)
+);
+
+CREATE TABLE IF NOT EXISTS color (
+    color_id INT NOT NULL AUTO_INCREMENT,
+    color_name VARCHAR(100) NOT NULL,
+    color_hex CHAR(6) NOT NULL,
+    PRIMARY KEY (color_id)
+);
+
+CREATE TABLE IF NOT EXISTS template (
+    template_id INT NOT NULL AUTO_INCREMENT,
+    template_name VARCHAR(255) NOT NULL,
+    template_content TEXT NOT NULL,
+    template_url_import VARCHAR(2048) NULL DEFAULT NULL,
+    user_id INT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NULL DEFAULT NULL,
+    deleted_at TIMESTAMP NULL DEFAULT NULL,
+    PRIMARY KEY (template_id),
+    KEY idx_template_user (user_id),
+    CONSTRAINT fk_template_user FOREIGN KEY (user_id) REFERENCES user (user_id)
+);
+
+CREATE TABLE IF NOT EXISTS integration_credential (
+    int_credential_id INT NOT NULL AUTO_INCREMENT,
+    int_credential_name VARCHAR(255) NOT NULL,
+    int_credential_type VARCHAR(50) NOT NULL,
+    int_credential_config TEXT NOT NULL,
+    user_id INT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NULL DEFAULT NULL,
+    deleted_at TIMESTAMP NULL DEFAULT NULL,
+    PRIMARY KEY (int_credential_id),
+    KEY idx_integration_credential_user (user_id),
+    CONSTRAINT fk_integration_credential_user FOREIGN KEY (user_id) REFERENCES user (user_id)
+);
+
+CREATE TABLE IF NOT EXISTS integration_profile (
+    int_profile_id INT NOT NULL AUTO_INCREMENT,
+    int_profile_name VARCHAR(255) NOT NULL,
+    color_id INT NOT NULL,
+    user_id INT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NULL DEFAULT NULL,
+    deleted_at TIMESTAMP NULL DEFAULT NULL,
+    PRIMARY KEY (int_profile_id),
+    KEY idx_integration_profile_user (user_id),
+    CONSTRAINT fk_integration_profile_color FOREIGN KEY (color_id) REFERENCES color (color_id),
+    CONSTRAINT fk_integration_profile_user FOREIGN KEY (user_id) REFERENCES user (user_id)
+);
+
+CREATE TABLE IF NOT EXISTS integration_group (
+    int_profile_id INT NOT NULL,
+    int_credential_id INT NOT NULL,
+    PRIMARY KEY (int_profile_id, int_credential_id),
+    CONSTRAINT fk_integration_group_profile FOREIGN KEY (int_profile_id) REFERENCES integration_profile (int_profile_id),
+    CONSTRAINT fk_integration_group_credential FOREIGN KEY (int_credential_id) REFERENCES integration_credential (int_credential_id)
+);
+
+CREATE TABLE IF NOT EXISTS post (
+    post_id INT NOT NULL AUTO_INCREMENT,
+    post_name VARCHAR(255) NOT NULL,
+    post_content TEXT NOT NULL,
+    template_id INT NOT NULL,
+    int_profile_id INT NOT NULL,
+    user_id INT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NULL DEFAULT NULL,
+    deleted_at TIMESTAMP NULL DEFAULT NULL,
+    PRIMARY KEY (post_id),
+    KEY idx_post_user (user_id),
+    CONSTRAINT fk_post_template FOREIGN KEY (template_id) REFERENCES template (template_id),
+    CONSTRAINT fk_post_integration_profile FOREIGN KEY (int_profile_id) REFERENCES integration_profile (int_profile_id),
+    CONSTRAINT fk_post_user FOREIGN KEY (user_id) REFERENCES user (user_id)
+);
+
+CREATE TABLE IF NOT EXISTS publication (
+    publication_id INT NOT NULL AUTO_INCREMENT,
+    post_id INT NOT NULL,
+    int_credential_id INT NOT NULL,
+    publication_status ENUM('pending', 'failed', 'published') NOT NULL DEFAULT 'pending',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NULL DEFAULT NULL,
+    PRIMARY KEY (publication_id),
+    KEY idx_publication_post (post_id),
+    CONSTRAINT fk_publication_post FOREIGN KEY (post_id) REFERENCES post (post_id),
+    CONSTRAINT fk_publication_credential FOREIGN KEY (int_credential_id) REFERENCES integration_credential (int_credential_id)
+);
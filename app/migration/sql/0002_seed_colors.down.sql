@@ -0,0 +1,3 @@
+DELETE FROM color
+WHERE color_hex IN ('007BFF', '28A745', 'DC3545', 'FFC107', '17A2B8', '343A40')
+    AND color_id NOT IN (SELECT color_id FROM (SELECT DISTINCT color_id FROM integration_profile) used);
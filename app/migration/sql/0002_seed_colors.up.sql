@@ -0,0 +1,13 @@
+INSERT INTO color (color_name, color_hex)
+SELECT seed.color_name, seed.color_hex
+FROM (
+    SELECT 'Primary Blue' color_name, '007BFF' color_hex
+    UNION ALL SELECT 'Success Green', '28A745'
+    UNION ALL SELECT 'Danger Red', 'DC3545'
+    UNION ALL SELECT 'Warning Yellow', 'FFC107'
+    UNION ALL SELECT 'Info Cyan', '17A2B8'
+    UNION ALL SELECT 'Dark Gray', '343A40'
+) seed
+WHERE NOT EXISTS (
+    SELECT 1 FROM color WHERE color.color_hex = seed.color_hex
+);
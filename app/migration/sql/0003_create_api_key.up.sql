@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS api_key (
+    api_key_id INT NOT NULL AUTO_INCREMENT,
+    api_key_name VARCHAR(255) NOT NULL,
+    api_key_prefix VARCHAR(16) NOT NULL,
+    api_key_hash CHAR(64) NOT NULL,
+    user_id INT NOT NULL,
+    last_used_at TIMESTAMP NULL DEFAULT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    revoked_at TIMESTAMP NULL DEFAULT NULL,
+    PRIMARY KEY (api_key_id),
+    UNIQUE KEY uk_api_key_hash (api_key_hash),
+    KEY idx_api_key_user (user_id),
+    CONSTRAINT fk_api_key_user FOREIGN KEY (user_id) REFERENCES user (user_id)
+);
@@ -0,0 +1 @@
+ALTER TABLE api_key ADD COLUMN api_key_scopes VARCHAR(1024) NOT NULL DEFAULT '*' AFTER api_key_hash;
@@ -0,0 +1,10 @@
+ALTER TABLE post DROP FOREIGN KEY fk_post_workspace;
+ALTER TABLE post DROP COLUMN workspace_id;
+ALTER TABLE integration_profile DROP FOREIGN KEY fk_integration_profile_workspace;
+ALTER TABLE integration_profile DROP COLUMN workspace_id;
+ALTER TABLE integration_credential DROP FOREIGN KEY fk_integration_credential_workspace;
+ALTER TABLE integration_credential DROP COLUMN workspace_id;
+ALTER TABLE template DROP FOREIGN KEY fk_template_workspace;
+ALTER TABLE template DROP COLUMN workspace_id;
+DROP TABLE IF EXISTS workspace_member;
+DROP TABLE IF EXISTS workspace;
@@ -0,0 +1,75 @@
+CREATE TABLE IF NOT EXISTS workspace (
+    workspace_id INT NOT NULL AUTO_INCREMENT,
+    workspace_name VARCHAR(255) NOT NULL,
+    personal_user_id INT NULL DEFAULT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NULL DEFAULT NULL,
+    deleted_at TIMESTAMP NULL DEFAULT NULL,
+    PRIMARY KEY (workspace_id),
+    UNIQUE KEY uk_workspace_personal_user (personal_user_id),
+    CONSTRAINT fk_workspace_personal_user FOREIGN KEY (personal_user_id) REFERENCES user (user_id)
+);
+
+CREATE TABLE IF NOT EXISTS workspace_member (
+    workspace_id INT NOT NULL,
+    user_id INT NOT NULL,
+    workspace_role ENUM('owner', 'editor', 'viewer') NOT NULL DEFAULT 'viewer',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NULL DEFAULT NULL,
+    PRIMARY KEY (workspace_id, user_id),
+    KEY idx_workspace_member_user (user_id),
+    CONSTRAINT fk_workspace_member_workspace FOREIGN KEY (workspace_id) REFERENCES workspace (workspace_id),
+    CONSTRAINT fk_workspace_member_user FOREIGN KEY (user_id) REFERENCES user (user_id)
+);
+
+INSERT INTO workspace (workspace_name, personal_user_id)
+SELECT user_name, user_id FROM user
+WHERE NOT EXISTS (
+    SELECT 1 FROM workspace WHERE workspace.personal_user_id = user.user_id
+);
+
+INSERT INTO workspace_member (workspace_id, user_id, workspace_role)
+SELECT workspace_id, personal_user_id, 'owner' FROM workspace
+WHERE personal_user_id IS NOT NULL
+    AND NOT EXISTS (
+        SELECT 1 FROM workspace_member
+        WHERE workspace_member.workspace_id = workspace.workspace_id
+            AND workspace_member.user_id = workspace.personal_user_id
+    );
+
+ALTER TABLE template ADD COLUMN workspace_id INT NULL DEFAULT NULL AFTER user_id;
+ALTER TABLE integration_credential ADD COLUMN workspace_id INT NULL DEFAULT NULL AFTER user_id;
+ALTER TABLE integration_profile ADD COLUMN workspace_id INT NULL DEFAULT NULL AFTER user_id;
+ALTER TABLE post ADD COLUMN workspace_id INT NULL DEFAULT NULL AFTER user_id;
+
+UPDATE template
+JOIN workspace ON workspace.personal_user_id = template.user_id
+SET template.workspace_id = workspace.workspace_id;
+
+UPDATE integration_credential
+JOIN workspace ON workspace.personal_user_id = integration_credential.user_id
+SET integration_credential.workspace_id = workspace.workspace_id;
+
+UPDATE integration_profile
+JOIN workspace ON workspace.personal_user_id = integration_profile.user_id
+SET integration_profile.workspace_id = workspace.workspace_id;
+
+UPDATE post
+JOIN workspace ON workspace.personal_user_id = post.user_id
+SET post.workspace_id = workspace.workspace_id;
+
+ALTER TABLE template MODIFY workspace_id INT NOT NULL;
+ALTER TABLE template ADD KEY idx_template_workspace (workspace_id);
+ALTER TABLE template ADD CONSTRAINT fk_template_workspace FOREIGN KEY (workspace_id) REFERENCES workspace (workspace_id);
+
+ALTER TABLE integration_credential MODIFY workspace_id INT NOT NULL;
+ALTER TABLE integration_credential ADD KEY idx_integration_credential_workspace (workspace_id);
+ALTER TABLE integration_credential ADD CONSTRAINT fk_integration_credential_workspace FOREIGN KEY (workspace_id) REFERENCES workspace (workspace_id);
+
+ALTER TABLE integration_profile MODIFY workspace_id INT NOT NULL;
+ALTER TABLE integration_profile ADD KEY idx_integration_profile_workspace (workspace_id);
+ALTER TABLE integration_profile ADD CONSTRAINT fk_integration_profile_workspace FOREIGN KEY (workspace_id) REFERENCES workspace (workspace_id);
+
+ALTER TABLE post MODIFY workspace_id INT NOT NULL;
+ALTER TABLE post ADD KEY idx_post_workspace (workspace_id);
+ALTER TABLE post ADD CONSTRAINT fk_post_workspace FOREIGN KEY (workspace_id) REFERENCES workspace (workspace_id);
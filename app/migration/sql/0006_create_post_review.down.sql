@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS post_review;
+
+ALTER TABLE post DROP COLUMN post_review_status;
+
+ALTER TABLE integration_profile DROP COLUMN int_profile_requires_approval;
@@ -0,0 +1,18 @@
+ALTER TABLE integration_profile
+    ADD COLUMN int_profile_requires_approval TINYINT(1) NOT NULL DEFAULT 0 AFTER color_id;
+
+ALTER TABLE post
+    ADD COLUMN post_review_status ENUM('draft', 'pending', 'approved', 'rejected') NOT NULL DEFAULT 'draft' AFTER int_profile_id;
+
+CREATE TABLE IF NOT EXISTS post_review (
+    post_review_id INT NOT NULL AUTO_INCREMENT,
+    post_id INT NOT NULL,
+    user_id INT NOT NULL,
+    post_review_status ENUM('pending', 'approved', 'rejected') NOT NULL,
+    post_review_comment TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (post_review_id),
+    KEY idx_post_review_post (post_id),
+    CONSTRAINT fk_post_review_post FOREIGN KEY (post_id) REFERENCES post (post_id),
+    CONSTRAINT fk_post_review_user FOREIGN KEY (user_id) REFERENCES user (user_id)
+);
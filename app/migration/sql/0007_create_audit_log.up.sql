@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS audit_log (
+    audit_log_id INT NOT NULL AUTO_INCREMENT,
+    user_id INT NOT NULL,
+    workspace_id INT NULL DEFAULT NULL,
+    audit_action VARCHAR(32) NOT NULL,
+    audit_entity_type VARCHAR(64) NOT NULL,
+    audit_entity_id INT NOT NULL,
+    audit_before TEXT NULL DEFAULT NULL,
+    audit_after TEXT NULL DEFAULT NULL,
+    request_id VARCHAR(64) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (audit_log_id),
+    KEY idx_audit_log_workspace (workspace_id, created_at),
+    KEY idx_audit_log_user (user_id, created_at),
+    KEY idx_audit_log_entity (audit_entity_type, audit_entity_id)
+);
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS publish_usage (
+    publish_usage_id INT NOT NULL AUTO_INCREMENT,
+    user_id INT NOT NULL,
+    int_profile_id INT NOT NULL,
+    post_id INT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (publish_usage_id),
+    KEY idx_publish_usage_user (user_id, created_at),
+    KEY idx_publish_usage_profile (int_profile_id, created_at)
+);
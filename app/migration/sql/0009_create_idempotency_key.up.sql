@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS idempotency_key (
+    idempotency_key_id INT NOT NULL AUTO_INCREMENT,
+    user_id INT NOT NULL,
+    idempotency_key VARCHAR(255) NOT NULL,
+    request_hash CHAR(64) NOT NULL,
+    response_status INT NOT NULL DEFAULT 0,
+    response_body MEDIUMTEXT NULL DEFAULT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (idempotency_key_id),
+    UNIQUE KEY uq_idempotency_key_user (user_id, idempotency_key),
+    KEY idx_idempotency_key_expires (expires_at)
+);
@@ -0,0 +1,7 @@
+ALTER TABLE integration_credential DROP COLUMN int_credential_version;
+
+ALTER TABLE integration_profile DROP COLUMN int_profile_version;
+
+ALTER TABLE template DROP COLUMN template_version;
+
+ALTER TABLE post DROP COLUMN post_version;
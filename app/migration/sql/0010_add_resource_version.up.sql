@@ -0,0 +1,11 @@
+ALTER TABLE post
+    ADD COLUMN post_version INT NOT NULL DEFAULT 1 AFTER post_review_status;
+
+ALTER TABLE template
+    ADD COLUMN template_version INT NOT NULL DEFAULT 1 AFTER template_url_import;
+
+ALTER TABLE integration_profile
+    ADD COLUMN int_profile_version INT NOT NULL DEFAULT 1 AFTER int_profile_requires_approval;
+
+ALTER TABLE integration_credential
+    ADD COLUMN int_credential_version INT NOT NULL DEFAULT 1 AFTER int_credential_config;
@@ -1,6 +1,7 @@
 package model
 
 import (
+	"context"
 	"database/sql"
 )
 
@@ -14,8 +15,8 @@ func NewAbout(db *sql.DB) *About {
 	return &about
 }
 
-func (a *About) Ping() bool {
-	pingErr := a.db.Ping()
+func (a *About) Ping(ctx context.Context) bool {
+	pingErr := a.db.PingContext(ctx)
 
 	return pingErr == nil
 }
@@ -0,0 +1,189 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"synk/gateway/app/util"
+)
+
+type ApiKeys struct {
+	db *sql.DB
+}
+
+type ApiKeysList struct {
+	ApiKeyId     int      `json:"api_key_id"`
+	ApiKeyName   string   `json:"api_key_name"`
+	ApiKeyPrefix string   `json:"api_key_prefix"`
+	ApiKeyScopes []string `json:"api_key_scopes"`
+	LastUsedAt   string   `json:"last_used_at"`
+	CreatedAt    string   `json:"created_at"`
+}
+
+type ApiKeyAddData struct {
+	ApiKeyName   string   `json:"api_key_name"`
+	ApiKeyPrefix string   `json:"api_key_prefix"`
+	ApiKeyHash   string   `json:"api_key_hash"`
+	ApiKeyScopes []string `json:"api_key_scopes"`
+}
+
+type ApiKeyOwnerData struct {
+	ApiKeyId     int      `json:"api_key_id"`
+	UserId       int      `json:"user_id"`
+	ApiKeyScopes []string `json:"api_key_scopes"`
+}
+
+func NewApiKeys(db *sql.DB) *ApiKeys {
+	apiKeys := ApiKeys{db: db}
+
+	return &apiKeys
+}
+
+func (a *ApiKeys) List(ctx context.Context, userId int) ([]ApiKeysList, error) {
+	var apiKeys []ApiKeysList
+
+	rows, rowsErr := a.db.QueryContext(
+		ctx,
+		`SELECT api_key_id, api_key_name, api_key_prefix, api_key_scopes, last_used_at, created_at
+        FROM api_key
+        WHERE revoked_at IS NULL AND user_id = ?
+        ORDER BY api_key_id`, userId,
+	)
+
+	if rowsErr != nil {
+		return nil, fmt.Errorf("models.api_keys.list: %s", rowsErr.Error())
+	}
+
+	defer rows.Close()
+
+	rowsErr = rows.Err()
+
+	if rowsErr != nil {
+		return nil, fmt.Errorf("models.api_keys.list: %s", rowsErr.Error())
+	}
+
+	for rows.Next() {
+		var apiKey ApiKeysList
+		var lastUsedAt sql.NullString
+		var scopes string
+
+		exception := rows.Scan(
+			&apiKey.ApiKeyId,
+			&apiKey.ApiKeyName,
+			&apiKey.ApiKeyPrefix,
+			&scopes,
+			&lastUsedAt,
+			&apiKey.CreatedAt,
+		)
+
+		if exception != nil {
+			return nil, fmt.Errorf("models.api_keys.list: %s", exception.Error())
+		}
+
+		apiKey.ApiKeyScopes = strings.Fields(scopes)
+		apiKey.LastUsedAt = util.ToTimeBR(lastUsedAt.String)
+		apiKey.CreatedAt = util.ToTimeBR(apiKey.CreatedAt)
+
+		apiKeys = append(apiKeys, apiKey)
+	}
+
+	return apiKeys, nil
+}
+
+func (a *ApiKeys) Add(ctx context.Context, apiKey ApiKeyAddData, userId int) (int, error) {
+	var apiKeyId int
+
+	insertRes, insertErr := a.db.ExecContext(
+		ctx,
+		`INSERT INTO api_key (api_key_name, api_key_prefix, api_key_hash, api_key_scopes, user_id)
+        VALUES (?, ?, ?, ?, ?)`,
+		apiKey.ApiKeyName, apiKey.ApiKeyPrefix, apiKey.ApiKeyHash, strings.Join(apiKey.ApiKeyScopes, " "), userId,
+	)
+
+	if insertErr != nil {
+		return apiKeyId, fmt.Errorf("models.api_keys.add: %s", insertErr.Error())
+	}
+
+	id, exception := insertRes.LastInsertId()
+
+	if exception != nil {
+		return apiKeyId, fmt.Errorf("models.api_keys.add: %s", exception.Error())
+	}
+
+	apiKeyId = int(id)
+
+	return apiKeyId, nil
+}
+
+func (a *ApiKeys) Revoke(ctx context.Context, apiKeyId int, userId int) (int, error) {
+	var rowsAffected int64
+
+	updateRes, updateErr := a.db.ExecContext(
+		ctx,
+		`UPDATE api_key
+        SET revoked_at = CURRENT_TIMESTAMP
+        WHERE revoked_at IS NULL AND
+            user_id = ? AND
+            api_key_id = ?`, userId, apiKeyId,
+	)
+
+	if updateErr != nil {
+		return int(rowsAffected), fmt.Errorf("models.api_keys.revoke: %s", updateErr.Error())
+	}
+
+	rowsAffectedVal, exception := updateRes.RowsAffected()
+
+	if exception != nil {
+		return int(rowsAffected), fmt.Errorf("models.api_keys.revoke: %s", exception.Error())
+	}
+
+	rowsAffected = rowsAffectedVal
+
+	return int(rowsAffected), nil
+}
+
+func (a *ApiKeys) ByHash(ctx context.Context, apiKeyHash string) (ApiKeyOwnerData, error) {
+	var apiKey ApiKeyOwnerData
+
+	row := a.db.QueryRowContext(
+		ctx,
+		`SELECT api_key_id, user_id, api_key_scopes
+        FROM api_key
+        WHERE revoked_at IS NULL AND api_key_hash = ?`, apiKeyHash,
+	)
+
+	var scopes string
+
+	exception := row.Scan(&apiKey.ApiKeyId, &apiKey.UserId, &scopes)
+
+	if exception == sql.ErrNoRows {
+		return apiKey, nil
+	}
+
+	if exception != nil {
+		return apiKey, fmt.Errorf("models.api_keys.by_hash: %s", exception.Error())
+	}
+
+	apiKey.ApiKeyScopes = strings.Fields(scopes)
+
+	return apiKey, nil
+}
+
+// Touch records the key usage, at most once a minute to avoid a write per
+// request.
+func (a *ApiKeys) Touch(ctx context.Context, apiKeyId int) error {
+	_, updateErr := a.db.ExecContext(
+		ctx,
+		`UPDATE api_key
+        SET last_used_at = CURRENT_TIMESTAMP
+        WHERE api_key_id = ? AND
+            (last_used_at IS NULL OR last_used_at < CURRENT_TIMESTAMP - INTERVAL 1 MINUTE)`, apiKeyId,
+	)
+
+	if updateErr != nil {
+		return fmt.Errorf("models.api_keys.touch: %s", updateErr.Error())
+	}
+
+	return nil
+}
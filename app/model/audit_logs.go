@@ -0,0 +1,229 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"synk/gateway/app/util"
+)
+
+const AUDIT_DEFAULT_LIMIT = 50
+const AUDIT_MAX_LIMIT = 500
+
+type AuditAction string
+
+const (
+	AuditActionCreate  AuditAction = "create"
+	AuditActionUpdate  AuditAction = "update"
+	AuditActionDelete  AuditAction = "delete"
+	AuditActionPublish AuditAction = "publish"
+	AuditActionSubmit  AuditAction = "submit"
+	AuditActionReview  AuditAction = "review"
+)
+
+const (
+	AuditEntityPost            = "post"
+	AuditEntityTemplate        = "template"
+	AuditEntityIntProfile      = "int_profile"
+	AuditEntityIntCredential   = "int_credential"
+	AuditEntityApiKey          = "api_key"
+	AuditEntityWorkspace       = "workspace"
+	AuditEntityWorkspaceMember = "workspace_member"
+)
+
+type AuditLogs struct {
+	db *sql.DB
+}
+
+type AuditLogsList struct {
+	AuditLogId      int             `json:"audit_log_id"`
+	UserId          int             `json:"user_id"`
+	WorkspaceId     int             `json:"workspace_id"`
+	AuditAction     AuditAction     `json:"audit_action"`
+	AuditEntityType string          `json:"audit_entity_type"`
+	AuditEntityId   int             `json:"audit_entity_id"`
+	AuditBefore     json.RawMessage `json:"audit_before"`
+	AuditAfter      json.RawMessage `json:"audit_after"`
+	RequestId       string          `json:"request_id"`
+	CreatedAt       string          `json:"created_at"`
+}
+
+// AuditLogAddData carries snapshots already encoded and redacted; an empty
+// snapshot is stored as NULL.
+type AuditLogAddData struct {
+	UserId          int         `json:"user_id"`
+	WorkspaceId     int         `json:"workspace_id"`
+	AuditAction     AuditAction `json:"audit_action"`
+	AuditEntityType string      `json:"audit_entity_type"`
+	AuditEntityId   int         `json:"audit_entity_id"`
+	AuditBefore     string      `json:"audit_before"`
+	AuditAfter      string      `json:"audit_after"`
+	RequestId       string      `json:"request_id"`
+}
+
+// AuditLogFilter narrows List; zero values are ignored. Since and Until are
+// `YYYY-MM-DD HH:MM:SS` bounds on created_at.
+type AuditLogFilter struct {
+	UserId          int
+	AuditAction     AuditAction
+	AuditEntityType string
+	AuditEntityId   int
+	Since           string
+	Until           string
+	Limit           int
+}
+
+func NewAuditLogs(db *sql.DB) *AuditLogs {
+	auditLogs := AuditLogs{db: db}
+
+	return &auditLogs
+}
+
+// List returns entries of the workspace plus entries without a workspace
+// (such as API keys) made by userId, newest first.
+func (al *AuditLogs) List(ctx context.Context, filter AuditLogFilter, workspaceId int, userId int) ([]AuditLogsList, error) {
+	var auditLogs []AuditLogsList
+
+	whereList := []string{"(workspace_id = ? OR (workspace_id IS NULL AND user_id = ?))"}
+	whereValues := []any{workspaceId, userId}
+
+	if filter.UserId != 0 {
+		whereList = append(whereList, "user_id = ?")
+		whereValues = append(whereValues, filter.UserId)
+	}
+	if filter.AuditAction != "" {
+		whereList = append(whereList, "audit_action = ?")
+		whereValues = append(whereValues, filter.AuditAction)
+	}
+	if filter.AuditEntityType != "" {
+		whereList = append(whereList, "audit_entity_type = ?")
+		whereValues = append(whereValues, filter.AuditEntityType)
+	}
+	if filter.AuditEntityId != 0 {
+		whereList = append(whereList, "audit_entity_id = ?")
+		whereValues = append(whereValues, filter.AuditEntityId)
+	}
+	if filter.Since != "" {
+		whereList = append(whereList, "created_at >= ?")
+		whereValues = append(whereValues, filter.Since)
+	}
+	if filter.Until != "" {
+		whereList = append(whereList, "created_at <= ?")
+		whereValues = append(whereValues, filter.Until)
+	}
+
+	whereValues = append(whereValues, AuditLimit(filter.Limit))
+
+	rows, rowsErr := al.db.QueryContext(
+		ctx,
+		`SELECT audit_log_id, user_id, COALESCE(workspace_id, 0), audit_action, audit_entity_type,
+            audit_entity_id, audit_before, audit_after, request_id, created_at
+        FROM audit_log
+        WHERE `+strings.Join(whereList, " AND ")+`
+        ORDER BY audit_log_id DESC
+        LIMIT ?`, whereValues...,
+	)
+
+	if rowsErr != nil {
+		return nil, fmt.Errorf("models.audit_logs.list: %s", rowsErr.Error())
+	}
+
+	defer rows.Close()
+
+	rowsErr = rows.Err()
+
+	if rowsErr != nil {
+		return nil, fmt.Errorf("models.audit_logs.list: %s", rowsErr.Error())
+	}
+
+	for rows.Next() {
+		var auditLog AuditLogsList
+		var before sql.NullString
+		var after sql.NullString
+
+		exception := rows.Scan(
+			&auditLog.AuditLogId,
+			&auditLog.UserId,
+			&auditLog.WorkspaceId,
+			&auditLog.AuditAction,
+			&auditLog.AuditEntityType,
+			&auditLog.AuditEntityId,
+			&before,
+			&after,
+			&auditLog.RequestId,
+			&auditLog.CreatedAt,
+		)
+
+		if exception != nil {
+			return nil, fmt.Errorf("models.audit_logs.list: %s", exception.Error())
+		}
+
+		auditLog.AuditBefore = AuditSnapshot(before.String)
+		auditLog.AuditAfter = AuditSnapshot(after.String)
+		auditLog.CreatedAt = util.ToTimeBR(auditLog.CreatedAt)
+
+		auditLogs = append(auditLogs, auditLog)
+	}
+
+	return auditLogs, nil
+}
+
+func (al *AuditLogs) Add(ctx context.Context, auditLog AuditLogAddData) (int, error) {
+	var auditLogId int
+
+	insertRes, insertErr := al.db.ExecContext(
+		ctx,
+		`INSERT INTO audit_log (
+            user_id,
+            workspace_id,
+            audit_action,
+            audit_entity_type,
+            audit_entity_id,
+            audit_before,
+            audit_after,
+            request_id
+        )
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		auditLog.UserId,
+		sql.NullInt64{Int64: int64(auditLog.WorkspaceId), Valid: auditLog.WorkspaceId != 0},
+		auditLog.AuditAction,
+		auditLog.AuditEntityType,
+		auditLog.AuditEntityId,
+		sql.NullString{String: auditLog.AuditBefore, Valid: auditLog.AuditBefore != ""},
+		sql.NullString{String: auditLog.AuditAfter, Valid: auditLog.AuditAfter != ""},
+		auditLog.RequestId,
+	)
+
+	if insertErr != nil {
+		return auditLogId, fmt.Errorf("models.audit_logs.add: %s", insertErr.Error())
+	}
+
+	id, exception := insertRes.LastInsertId()
+
+	if exception != nil {
+		return auditLogId, fmt.Errorf("models.audit_logs.add: %s", exception.Error())
+	}
+
+	auditLogId = int(id)
+
+	return auditLogId, nil
+}
+
+func AuditLimit(limit int) int {
+	if limit <= 0 {
+		return AUDIT_DEFAULT_LIMIT
+	}
+
+	return min(limit, AUDIT_MAX_LIMIT)
+}
+
+// AuditSnapshot turns a stored snapshot back into JSON, using null when empty.
+func AuditSnapshot(snapshot string) json.RawMessage {
+	if snapshot == "" {
+		return json.RawMessage("null")
+	}
+
+	return json.RawMessage(snapshot)
+}
@@ -1,6 +1,7 @@
 package model
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"strings"
@@ -22,7 +23,7 @@ func NewColors(db *sql.DB) *Colors {
 	return &colors
 }
 
-func (c *Colors) List(id int) ([]ColorsList, error) {
+func (c *Colors) List(ctx context.Context, id int) ([]ColorsList, error) {
 	var colors []ColorsList
 
 	whereList := []string{}
@@ -39,7 +40,8 @@ func (c *Colors) List(id int) ([]ColorsList, error) {
 		where = " WHERE " + strings.Join(whereList, " AND ")
 	}
 
-	rows, rowsErr := c.db.Query(
+	rows, rowsErr := c.db.QueryContext(
+		ctx,
 		`SELECT color_id, color_name, color_hex
         FROM color `+where, whereValues...,
 	)
@@ -0,0 +1,139 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"synk/gateway/app/util"
+	"time"
+)
+
+// Reserve purges up to this many expired keys of any user, so the table
+// doesn't grow with keys that are never reused.
+const IDEMPOTENCY_PURGE_LIMIT = 100
+
+type IdempotencyKeys struct {
+	db *sql.DB
+}
+
+// IdempotencyKeyData is a stored request; ResponseStatus stays 0 while the
+// first request is still running.
+type IdempotencyKeyData struct {
+	UserId         int    `json:"user_id"`
+	IdempotencyKey string `json:"idempotency_key"`
+	RequestHash    string `json:"request_hash"`
+	ResponseStatus int    `json:"response_status"`
+	ResponseBody   string `json:"response_body"`
+}
+
+func NewIdempotencyKeys(db *sql.DB) *IdempotencyKeys {
+	idempotencyKeys := IdempotencyKeys{db: db}
+
+	return &idempotencyKeys
+}
+
+// Reserve claims the key of the user for ttl. When the key is already taken
+// and not expired, it returns the stored record and false.
+func (ik *IdempotencyKeys) Reserve(ctx context.Context, key IdempotencyKeyData, ttl time.Duration) (IdempotencyKeyData, bool, error) {
+	var stored IdempotencyKeyData
+	var reserved bool
+
+	_, purgeErr := ik.db.ExecContext(
+		ctx,
+		`DELETE FROM idempotency_key WHERE expires_at <= CURRENT_TIMESTAMP LIMIT ?`,
+		IDEMPOTENCY_PURGE_LIMIT,
+	)
+
+	if purgeErr != nil {
+		util.LoggerFrom(ctx).Warn("models.idempotency_keys.purge: " + purgeErr.Error())
+	}
+
+	txErr := WithTx(ctx, ik.db, func(tx *sql.Tx) error {
+		_, deleteErr := tx.ExecContext(
+			ctx,
+			`DELETE FROM idempotency_key
+            WHERE user_id = ? AND idempotency_key = ? AND expires_at <= CURRENT_TIMESTAMP`,
+			key.UserId, key.IdempotencyKey,
+		)
+
+		if deleteErr != nil {
+			return deleteErr
+		}
+
+		insertRes, insertErr := tx.ExecContext(
+			ctx,
+			`INSERT IGNORE INTO idempotency_key (user_id, idempotency_key, request_hash, expires_at)
+            VALUES (?, ?, ?, DATE_ADD(CURRENT_TIMESTAMP, INTERVAL ? SECOND))`,
+			key.UserId, key.IdempotencyKey, key.RequestHash, int(ttl.Seconds()),
+		)
+
+		if insertErr != nil {
+			return insertErr
+		}
+
+		rowsAffected, exception := insertRes.RowsAffected()
+
+		if exception != nil {
+			return exception
+		}
+
+		if rowsAffected == 1 {
+			reserved = true
+			stored = key
+
+			return nil
+		}
+
+		var body sql.NullString
+
+		selectErr := tx.QueryRowContext(
+			ctx,
+			`SELECT user_id, idempotency_key, request_hash, response_status, response_body
+            FROM idempotency_key
+            WHERE user_id = ? AND idempotency_key = ?`,
+			key.UserId, key.IdempotencyKey,
+		).Scan(&stored.UserId, &stored.IdempotencyKey, &stored.RequestHash, &stored.ResponseStatus, &body)
+
+		stored.ResponseBody = body.String
+
+		return selectErr
+	})
+
+	if txErr != nil {
+		return stored, false, fmt.Errorf("models.idempotency_keys.reserve: %s", txErr.Error())
+	}
+
+	return stored, reserved, nil
+}
+
+func (ik *IdempotencyKeys) Complete(ctx context.Context, key IdempotencyKeyData) error {
+	_, updateErr := ik.db.ExecContext(
+		ctx,
+		`UPDATE idempotency_key
+        SET response_status = ?,
+            response_body = ?
+        WHERE user_id = ? AND idempotency_key = ?`,
+		key.ResponseStatus, key.ResponseBody, key.UserId, key.IdempotencyKey,
+	)
+
+	if updateErr != nil {
+		return fmt.Errorf("models.idempotency_keys.complete: %s", updateErr.Error())
+	}
+
+	return nil
+}
+
+// Release drops a reservation whose request failed, so it can be retried.
+func (ik *IdempotencyKeys) Release(ctx context.Context, userId int, idempotencyKey string) error {
+	_, deleteErr := ik.db.ExecContext(
+		ctx,
+		`DELETE FROM idempotency_key WHERE user_id = ? AND idempotency_key = ?`,
+		userId, idempotencyKey,
+	)
+
+	if deleteErr != nil {
+		return fmt.Errorf("models.idempotency_keys.release: %s", deleteErr.Error())
+	}
+
+	return nil
+}
@@ -35,24 +35,27 @@ type IntCredentialsBasicList struct {
 }
 
 type IntCredentialList struct {
-	IntCredentialId     int    `json:"int_credential_id"`
-	IntCredentialName   string `json:"int_credential_name"`
-	IntCredentialType   string `json:"int_credential_type"`
-	IntCredentialConfig string `json:"int_credential_config"`
-	CreatedAt           string `json:"created_at"`
+	IntCredentialId      int    `json:"int_credential_id"`
+	IntCredentialName    string `json:"int_credential_name"`
+	IntCredentialType    string `json:"int_credential_type"`
+	IntCredentialConfig  string `json:"int_credential_config"`
+	IntCredentialVersion int    `json:"int_credential_version"`
+	CreatedAt            string `json:"created_at"`
 }
 
 type IntCredentialAddData struct {
 	IntCredentialName   string         `json:"int_credential_name"`
 	IntCredentialType   SocialPlatform `json:"int_credential_type"`
 	IntCredentialConfig string         `json:"int_credential_config"`
+	WorkspaceId         int            `json:"workspace_id"`
 }
 
 type IntCredentialUpdateData struct {
-	IntCredentialId     int            `json:"int_credential_id"`
-	IntCredentialName   string         `json:"int_credential_name"`
-	IntCredentialType   SocialPlatform `json:"int_credential_type"`
-	IntCredentialConfig string         `json:"int_credential_config"`
+	IntCredentialId      int            `json:"int_credential_id"`
+	IntCredentialName    string         `json:"int_credential_name"`
+	IntCredentialType    SocialPlatform `json:"int_credential_type"`
+	IntCredentialConfig  string         `json:"int_credential_config"`
+	IntCredentialVersion int            `json:"int_credential_version"`
 }
 
 func NewIntCredentials(db *sql.DB) *IntCredentials {
@@ -61,15 +64,16 @@ func NewIntCredentials(db *sql.DB) *IntCredentials {
 	return &intCredentials
 }
 
-func (ic *IntCredentials) BasicList(userId int) ([]IntCredentialsBasicList, error) {
+func (ic *IntCredentials) BasicList(ctx context.Context, workspaceId int) ([]IntCredentialsBasicList, error) {
 	var intCredentials []IntCredentialsBasicList
 
-	rows, rowsErr := ic.db.Query(
+	rows, rowsErr := ic.db.QueryContext(
+		ctx,
 		`SELECT credential.int_credential_id, credential.int_credential_name,
             credential.int_credential_type
         FROM integration_credential credential
-        WHERE credential.deleted_at IS NULL AND credential.user_id = ?
-        ORDER BY credential.int_credential_name`, userId,
+        WHERE credential.deleted_at IS NULL AND credential.workspace_id = ?
+        ORDER BY credential.int_credential_name`, workspaceId,
 	)
 
 	if rowsErr != nil {
@@ -104,18 +108,19 @@ func (ic *IntCredentials) BasicList(userId int) ([]IntCredentialsBasicList, erro
 	return intCredentials, nil
 }
 
-func (ic *IntCredentials) BasicListByProfile(profileId int, userId int) ([]IntCredentialsBasicList, error) {
+func (ic *IntCredentials) BasicListByProfile(ctx context.Context, profileId int, workspaceId int) ([]IntCredentialsBasicList, error) {
 	var intCredentials []IntCredentialsBasicList
 
-	rows, rowsErr := ic.db.Query(
+	rows, rowsErr := ic.db.QueryContext(
+		ctx,
 		`SELECT credential.int_credential_id, credential.int_credential_name, credential.int_credential_type
         FROM integration_group int_group
         LEFT JOIN integration_credential credential ON credential.int_credential_id = int_group.int_credential_id
             AND credential.deleted_at IS NULL
         WHERE credential.deleted_at IS NULL AND
-            credential.user_id = ? AND
+            credential.workspace_id = ? AND
             int_group.int_profile_id = ?
-        ORDER BY credential.int_credential_type, credential.int_credential_name`, userId, profileId,
+        ORDER BY credential.int_credential_type, credential.int_credential_name`, workspaceId, profileId,
 	)
 
 	if rowsErr != nil {
@@ -149,15 +154,15 @@ func (ic *IntCredentials) BasicListByProfile(profileId int, userId int) ([]IntCr
 	return intCredentials, nil
 }
 
-func (ic *IntCredentials) List(id string, includeConfig bool, userId int) ([]IntCredentialList, error) {
+func (ic *IntCredentials) List(ctx context.Context, id string, includeConfig bool, workspaceId int) ([]IntCredentialList, error) {
 	var intCredentials []IntCredentialList
 
 	whereList := []string{}
 	whereValues := []any{}
 	columnsList := []string{}
 
-	whereList = append(whereList, "user_id = ?")
-	whereValues = append(whereValues, userId)
+	whereList = append(whereList, "workspace_id = ?")
+	whereValues = append(whereValues, workspaceId)
 
 	if id != "" {
 		whereList = append(whereList, "int_credential_id = ?")
@@ -179,9 +184,10 @@ func (ic *IntCredentials) List(id string, includeConfig bool, userId int) ([]Int
 		columns = ", " + strings.Join(columnsList, ", ")
 	}
 
-	rows, rowsErr := ic.db.Query(
+	rows, rowsErr := ic.db.QueryContext(
+		ctx,
 		`SELECT int_credential_id, int_credential_name,
-            int_credential_type, created_at `+columns+`
+            int_credential_type, int_credential_version, created_at `+columns+`
         FROM integration_credential
         WHERE deleted_at IS NULL `+where, whereValues...,
 	)
@@ -206,6 +212,7 @@ func (ic *IntCredentials) List(id string, includeConfig bool, userId int) ([]Int
 			&intCredential.IntCredentialId,
 			&intCredential.IntCredentialName,
 			&intCredential.IntCredentialType,
+			&intCredential.IntCredentialVersion,
 			&intCredential.CreatedAt,
 			&intCredentialConfig,
 		)
@@ -223,22 +230,24 @@ func (ic *IntCredentials) List(id string, includeConfig bool, userId int) ([]Int
 	return intCredentials, nil
 }
 
-func (ic *IntCredentials) Add(intCredential IntCredentialAddData, userId int) (int, error) {
+func (ic *IntCredentials) Add(ctx context.Context, intCredential IntCredentialAddData, userId int) (int, error) {
 	var intCredentialId int
 
 	insertRes, insertErr := ic.db.ExecContext(
-		context.Background(),
-		`INSERT INTO synk.integration_credential (
+		ctx,
+		`INSERT INTO integration_credential (
             int_credential_name,
             int_credential_type,
             int_credential_config,
-            user_id
+            user_id,
+            workspace_id
         )
-        VALUES (?, ?, ?, ?)`,
+        VALUES (?, ?, ?, ?, ?)`,
 		intCredential.IntCredentialName,
 		intCredential.IntCredentialType,
 		intCredential.IntCredentialConfig,
 		userId,
+		intCredential.WorkspaceId,
 	)
 
 	if insertErr != nil {
@@ -256,24 +265,37 @@ func (ic *IntCredentials) Add(intCredential IntCredentialAddData, userId int) (i
 	return intCredentialId, nil
 }
 
-func (ic *IntCredentials) Update(intCredential IntCredentialUpdateData, userId int) (int, error) {
+// Update bumps the credential version. An IntCredentialVersion other than 0
+// must match the stored one, or no row is affected.
+func (ic *IntCredentials) Update(ctx context.Context, intCredential IntCredentialUpdateData, workspaceId int) (int, error) {
 	var rowsAffected int64
 
+	whereValues := []any{
+		intCredential.IntCredentialId,
+		intCredential.IntCredentialName,
+		intCredential.IntCredentialType,
+		intCredential.IntCredentialConfig,
+		workspaceId,
+		intCredential.IntCredentialId,
+	}
+	versionWhere := ""
+
+	if intCredential.IntCredentialVersion != 0 {
+		versionWhere = " AND int_credential_version = ?"
+		whereValues = append(whereValues, intCredential.IntCredentialVersion)
+	}
+
 	updateRes, updateErr := ic.db.ExecContext(
-		context.Background(),
+		ctx,
 		`UPDATE integration_credential
         SET int_credential_id = ?,
             int_credential_name = ?,
             int_credential_type = ?,
             int_credential_config = ?,
+            int_credential_version = int_credential_version + 1,
             updated_at = CURRENT_TIMESTAMP
-        WHERE deleted_at IS NULL AND user_id = ? AND int_credential_id = ?`,
-		intCredential.IntCredentialId,
-		intCredential.IntCredentialName,
-		intCredential.IntCredentialType,
-		intCredential.IntCredentialConfig,
-		userId,
-		intCredential.IntCredentialId,
+        WHERE deleted_at IS NULL AND workspace_id = ? AND int_credential_id = ?`+versionWhere,
+		whereValues...,
 	)
 
 	if updateErr != nil {
@@ -291,15 +313,25 @@ func (ic *IntCredentials) Update(intCredential IntCredentialUpdateData, userId i
 	return int(rowsAffected), nil
 }
 
-func (ic *IntCredentials) Delete(intCredentialId int, userId int) (int, error) {
+// Delete soft deletes the integration credential. A version other than 0 must match the
+// stored one, or no row is affected.
+func (ic *IntCredentials) Delete(ctx context.Context, intCredentialId int, intCredentialVersion int, workspaceId int) (int, error) {
 	var rowsAffected int64
 
+	whereValues := []any{workspaceId, intCredentialId}
+	versionWhere := ""
+
+	if intCredentialVersion != 0 {
+		versionWhere = " AND int_credential_version = ?"
+		whereValues = append(whereValues, intCredentialVersion)
+	}
+
 	insertRes, insertErr := ic.db.ExecContext(
-		context.Background(),
+		ctx,
 		`UPDATE integration_credential
         SET deleted_at = CURRENT_TIMESTAMP
-        WHERE deleted_at IS NULL AND user_id = ? AND int_credential_id = ?`,
-		userId, intCredentialId,
+        WHERE deleted_at IS NULL AND workspace_id = ? AND int_credential_id = ?`+versionWhere,
+		whereValues...,
 	)
 
 	if insertErr != nil {
@@ -20,28 +20,36 @@ type IntProfilesBasicList struct {
 }
 
 type IntProfilesByIdData struct {
-	IntProfileId int `json:"int_profile_id"`
+	IntProfileId               int  `json:"int_profile_id"`
+	IntProfileRequiresApproval bool `json:"int_profile_requires_approval"`
+	IntProfileVersion          int  `json:"int_profile_version"`
 }
 
 type IntProfileList struct {
-	IntProfileId   int                       `json:"int_profile_id"`
-	IntProfileName string                    `json:"int_profile_name"`
-	ColorId        int                       `json:"color_id"`
-	ColorName      string                    `json:"color_name"`
-	ColorHex       string                    `json:"color_hex"`
-	CreatedAt      string                    `json:"created_at"`
-	Credentials    []IntCredentialsBasicList `json:"credentials"`
+	IntProfileId               int                       `json:"int_profile_id"`
+	IntProfileName             string                    `json:"int_profile_name"`
+	IntProfileRequiresApproval bool                      `json:"int_profile_requires_approval"`
+	ColorId                    int                       `json:"color_id"`
+	ColorName                  string                    `json:"color_name"`
+	ColorHex                   string                    `json:"color_hex"`
+	IntProfileVersion          int                       `json:"int_profile_version"`
+	CreatedAt                  string                    `json:"created_at"`
+	Credentials                []IntCredentialsBasicList `json:"credentials"`
 }
 
 type IntProfileAddData struct {
-	IntProfileName string `json:"int_profile_name"`
-	ColorId        int    `json:"color_id"`
+	IntProfileName             string `json:"int_profile_name"`
+	IntProfileRequiresApproval bool   `json:"int_profile_requires_approval"`
+	ColorId                    int    `json:"color_id"`
+	WorkspaceId                int    `json:"workspace_id"`
 }
 
 type IntProfileUpdateData struct {
-	IntProfileId   int    `json:"int_profile_id"`
-	IntProfileName string `json:"int_profile_name"`
-	ColorId        int    `json:"color_id"`
+	IntProfileId               int    `json:"int_profile_id"`
+	IntProfileName             string `json:"int_profile_name"`
+	IntProfileRequiresApproval bool   `json:"int_profile_requires_approval"`
+	ColorId                    int    `json:"color_id"`
+	IntProfileVersion          int    `json:"int_profile_version"`
 }
 
 func NewIntProfiles(db *sql.DB) *IntProfiles {
@@ -50,16 +58,17 @@ func NewIntProfiles(db *sql.DB) *IntProfiles {
 	return &intProfiles
 }
 
-func (ip *IntProfiles) BasicList(userId int) ([]IntProfilesBasicList, error) {
+func (ip *IntProfiles) BasicList(ctx context.Context, workspaceId int) ([]IntProfilesBasicList, error) {
 	var intProfiles []IntProfilesBasicList
 
-	rows, rowsErr := ip.db.Query(
+	rows, rowsErr := ip.db.QueryContext(
+		ctx,
 		`SELECT profile.int_profile_id, profile.int_profile_name,
             color.color_hex, color.color_name
         FROM integration_profile profile
         LEFT JOIN color ON color.color_id = profile.color_id
-        WHERE profile.deleted_at IS NULL AND profile.user_id = ?
-        ORDER BY profile.int_profile_name`, userId,
+        WHERE profile.deleted_at IS NULL AND profile.workspace_id = ?
+        ORDER BY profile.int_profile_name`, workspaceId,
 	)
 
 	if rowsErr != nil {
@@ -95,14 +104,15 @@ func (ip *IntProfiles) BasicList(userId int) ([]IntProfilesBasicList, error) {
 	return intProfiles, nil
 }
 
-func (ip *IntProfiles) ById(intProfileId int, userId int) (IntProfilesByIdData, error) {
+func (ip *IntProfiles) ById(ctx context.Context, intProfileId int, workspaceId int) (IntProfilesByIdData, error) {
 	var intProfile IntProfilesByIdData
 
-	rows, rowsErr := ip.db.Query(
-		`SELECT int_profile_id
+	rows, rowsErr := ip.db.QueryContext(
+		ctx,
+		`SELECT int_profile_id, int_profile_requires_approval, int_profile_version
         FROM integration_profile
-        WHERE deleted_at IS NULL AND user_id = ? AND int_profile_id = ?`,
-		userId, intProfileId,
+        WHERE deleted_at IS NULL AND workspace_id = ? AND int_profile_id = ?`,
+		workspaceId, intProfileId,
 	)
 
 	if rowsErr != nil {
@@ -120,6 +130,8 @@ func (ip *IntProfiles) ById(intProfileId int, userId int) (IntProfilesByIdData,
 	for rows.Next() {
 		exception := rows.Scan(
 			&intProfile.IntProfileId,
+			&intProfile.IntProfileRequiresApproval,
+			&intProfile.IntProfileVersion,
 		)
 
 		if exception != nil {
@@ -130,14 +142,14 @@ func (ip *IntProfiles) ById(intProfileId int, userId int) (IntProfilesByIdData,
 	return intProfile, nil
 }
 
-func (ip *IntProfiles) List(id string, userId int) ([]IntProfileList, error) {
+func (ip *IntProfiles) List(ctx context.Context, id string, workspaceId int) ([]IntProfileList, error) {
 	var intProfiles []IntProfileList
 
 	whereList := []string{}
 	whereValues := []any{}
 
-	whereList = append(whereList, "profile.user_id = ?")
-	whereValues = append(whereValues, userId)
+	whereList = append(whereList, "profile.workspace_id = ?")
+	whereValues = append(whereValues, workspaceId)
 
 	if id != "" {
 		whereList = append(whereList, "profile.int_profile_id = ?")
@@ -150,9 +162,10 @@ func (ip *IntProfiles) List(id string, userId int) ([]IntProfileList, error) {
 		where = " AND " + strings.Join(whereList, " AND ")
 	}
 
-	rows, rowsErr := ip.db.Query(
-		`SELECT profile.int_profile_id, profile.int_profile_name, color.color_id,
-               color.color_name, color.color_hex, profile.created_at
+	rows, rowsErr := ip.db.QueryContext(
+		ctx,
+		`SELECT profile.int_profile_id, profile.int_profile_name, profile.int_profile_requires_approval, color.color_id,
+               color.color_name, color.color_hex, profile.int_profile_version, profile.created_at
         FROM integration_profile profile
         LEFT JOIN color ON color.color_id = profile.color_id
         WHERE profile.deleted_at IS NULL `+where, whereValues...,
@@ -176,9 +189,11 @@ func (ip *IntProfiles) List(id string, userId int) ([]IntProfileList, error) {
 		exception := rows.Scan(
 			&intProfile.IntProfileId,
 			&intProfile.IntProfileName,
+			&intProfile.IntProfileRequiresApproval,
 			&intProfile.ColorId,
 			&intProfile.ColorName,
 			&intProfile.ColorHex,
+			&intProfile.IntProfileVersion,
 			&intProfile.CreatedAt,
 		)
 
@@ -194,91 +209,119 @@ func (ip *IntProfiles) List(id string, userId int) ([]IntProfileList, error) {
 	return intProfiles, nil
 }
 
-func (ip *IntProfiles) Add(intProfile IntProfileAddData, intCredentials []int, userId int) (int, error) {
+func (ip *IntProfiles) Add(ctx context.Context, intProfile IntProfileAddData, intCredentials []int, userId int) (int, error) {
 	var intProfileId int
 
-	insertRes, insertErr := ip.db.ExecContext(
-		context.Background(),
-		`INSERT INTO synk.integration_profile (int_profile_name, color_id, user_id)
-        VALUES (?, ?, ?)`,
-		intProfile.IntProfileName, intProfile.ColorId, userId,
-	)
+	txErr := WithTx(ctx, ip.db, func(tx *sql.Tx) error {
+		insertRes, insertErr := tx.ExecContext(
+			ctx,
+			`INSERT INTO integration_profile (int_profile_name, int_profile_requires_approval, color_id, user_id, workspace_id)
+            VALUES (?, ?, ?, ?, ?)`,
+			intProfile.IntProfileName, intProfile.IntProfileRequiresApproval, intProfile.ColorId, userId, intProfile.WorkspaceId,
+		)
 
-	if insertErr != nil {
-		return intProfileId, fmt.Errorf("models.integration_profiles.add: %s", insertErr.Error())
-	}
+		if insertErr != nil {
+			return insertErr
+		}
 
-	id, exception := insertRes.LastInsertId()
+		id, exception := insertRes.LastInsertId()
 
-	if exception != nil {
-		return intProfileId, fmt.Errorf("models.integration_profiles.add: %s", exception.Error())
-	}
+		if exception != nil {
+			return exception
+		}
 
-	intProfileId = int(id)
+		intProfileId = int(id)
 
-	for _, credentialId := range intCredentials {
-		ip.db.ExecContext(
-			context.Background(),
-			`INSERT INTO synk.integration_group (int_profile_id, int_credential_id)
-            VALUES (?, ?)`,
-			intProfileId, credentialId,
-		)
+		return linkCredentials(ctx, tx, intProfileId, intCredentials)
+	})
+
+	if txErr != nil {
+		return 0, fmt.Errorf("models.integration_profiles.add: %s", txErr.Error())
 	}
 
 	return intProfileId, nil
 }
 
-func (ip *IntProfiles) Update(intProfile IntProfileUpdateData, intCredentials []int, userId int) (int, error) {
+// Update bumps the profile version. An IntProfileVersion other than 0 must
+// match the stored one, or no row is affected.
+func (ip *IntProfiles) Update(ctx context.Context, intProfile IntProfileUpdateData, intCredentials []int, workspaceId int) (int, error) {
 	var rowsAffected int64
 
-	updateRes, updateErr := ip.db.ExecContext(
-		context.Background(),
-		`UPDATE integration_profile
-        SET int_profile_name = ?,
-            color_id = ?,
-            updated_at = CURRENT_TIMESTAMP
-        WHERE deleted_at IS NULL AND user_id = ? AND int_profile_id = ?`,
-		intProfile.IntProfileName, intProfile.ColorId, userId, intProfile.IntProfileId,
-	)
+	whereValues := []any{intProfile.IntProfileName, intProfile.IntProfileRequiresApproval, intProfile.ColorId, workspaceId, intProfile.IntProfileId}
+	versionWhere := ""
 
-	if updateErr != nil {
-		return int(rowsAffected), fmt.Errorf("models.integration_profiles.update: %s", updateErr.Error())
+	if intProfile.IntProfileVersion != 0 {
+		versionWhere = " AND int_profile_version = ?"
+		whereValues = append(whereValues, intProfile.IntProfileVersion)
 	}
 
-	rowsAffectedVal, exception := updateRes.RowsAffected()
+	txErr := WithTx(ctx, ip.db, func(tx *sql.Tx) error {
+		updateRes, updateErr := tx.ExecContext(
+			ctx,
+			`UPDATE integration_profile
+            SET int_profile_name = ?,
+                int_profile_requires_approval = ?,
+                color_id = ?,
+                int_profile_version = int_profile_version + 1,
+                updated_at = CURRENT_TIMESTAMP
+            WHERE deleted_at IS NULL AND workspace_id = ? AND int_profile_id = ?`+versionWhere,
+			whereValues...,
+		)
 
-	if exception != nil {
-		return int(rowsAffected), fmt.Errorf("models.integration_profiles.update: %s", exception.Error())
-	}
+		if updateErr != nil {
+			return updateErr
+		}
 
-	ip.db.ExecContext(
-		context.Background(),
-		`DELETE FROM integration_group WHERE int_profile_id = ?`, intProfile.IntProfileId,
-	)
+		rowsAffectedVal, exception := updateRes.RowsAffected()
 
-	for _, credentialId := range intCredentials {
-		ip.db.ExecContext(
-			context.Background(),
-			`INSERT INTO synk.integration_group (int_profile_id, int_credential_id)
-            VALUES (?, ?)`,
-			intProfile.IntProfileId, credentialId,
+		if exception != nil {
+			return exception
+		}
+
+		rowsAffected = rowsAffectedVal
+
+		if rowsAffected == 0 {
+			return nil
+		}
+
+		_, unlinkErr := tx.ExecContext(
+			ctx,
+			`DELETE FROM integration_group WHERE int_profile_id = ?`, intProfile.IntProfileId,
 		)
-	}
 
-	rowsAffected = rowsAffectedVal
+		if unlinkErr != nil {
+			return unlinkErr
+		}
+
+		return linkCredentials(ctx, tx, intProfile.IntProfileId, intCredentials)
+	})
+
+	if txErr != nil {
+		return 0, fmt.Errorf("models.integration_profiles.update: %s", txErr.Error())
+	}
 
 	return int(rowsAffected), nil
 }
 
-func (ip *IntProfiles) Delete(intProfileId int, userId int) (int, error) {
+// Delete soft deletes the integration profile. A version other than 0 must match the
+// stored one, or no row is affected.
+func (ip *IntProfiles) Delete(ctx context.Context, intProfileId int, intProfileVersion int, workspaceId int) (int, error) {
 	var rowsAffected int64
 
+	whereValues := []any{workspaceId, intProfileId}
+	versionWhere := ""
+
+	if intProfileVersion != 0 {
+		versionWhere = " AND int_profile_version = ?"
+		whereValues = append(whereValues, intProfileVersion)
+	}
+
 	insertRes, insertErr := ip.db.ExecContext(
-		context.Background(),
+		ctx,
 		`UPDATE integration_profile
         SET deleted_at = CURRENT_TIMESTAMP
-        WHERE deleted_at IS NULL AND user_id = ? AND int_profile_id = ?`,
-		userId, intProfileId,
+        WHERE deleted_at IS NULL AND workspace_id = ? AND int_profile_id = ?`+versionWhere,
+		whereValues...,
 	)
 
 	if insertErr != nil {
@@ -295,3 +338,74 @@ func (ip *IntProfiles) Delete(intProfileId int, userId int) (int, error) {
 
 	return int(rowsAffected), nil
 }
+
+func (ip *IntProfiles) Clone(ctx context.Context, intProfileId int, workspaceId int) (int, error) {
+	var clonedIntProfileId int
+
+	txErr := WithTx(ctx, ip.db, func(tx *sql.Tx) error {
+		insertRes, insertErr := tx.ExecContext(
+			ctx,
+			`INSERT INTO integration_profile (int_profile_name, int_profile_requires_approval, color_id, user_id, workspace_id)
+            SELECT CONCAT(int_profile_name, ?), int_profile_requires_approval, color_id, user_id, workspace_id
+            FROM integration_profile
+            WHERE deleted_at IS NULL AND workspace_id = ? AND int_profile_id = ?`,
+			CLONE_NAME_SUFFIX, workspaceId, intProfileId,
+		)
+
+		if insertErr != nil {
+			return insertErr
+		}
+
+		rowsAffected, rowsErr := insertRes.RowsAffected()
+
+		if rowsErr != nil {
+			return rowsErr
+		}
+
+		if rowsAffected == 0 {
+			return fmt.Errorf("integration profile with id %d not found", intProfileId)
+		}
+
+		id, exception := insertRes.LastInsertId()
+
+		if exception != nil {
+			return exception
+		}
+
+		clonedIntProfileId = int(id)
+
+		_, groupErr := tx.ExecContext(
+			ctx,
+			`INSERT INTO integration_group (int_profile_id, int_credential_id)
+            SELECT ?, int_group.int_credential_id
+            FROM integration_group int_group
+            WHERE int_group.int_profile_id = ?`,
+			clonedIntProfileId, intProfileId,
+		)
+
+		return groupErr
+	})
+
+	if txErr != nil {
+		return 0, fmt.Errorf("models.integration_profiles.clone: %s", txErr.Error())
+	}
+
+	return clonedIntProfileId, nil
+}
+
+func linkCredentials(ctx context.Context, tx *sql.Tx, intProfileId int, intCredentials []int) error {
+	for _, credentialId := range intCredentials {
+		_, linkErr := tx.ExecContext(
+			ctx,
+			`INSERT INTO integration_group (int_profile_id, int_credential_id)
+            VALUES (?, ?)`,
+			intProfileId, credentialId,
+		)
+
+		if linkErr != nil {
+			return fmt.Errorf("link credential %d: %s", credentialId, linkErr.Error())
+		}
+	}
+
+	return nil
+}
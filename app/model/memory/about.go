@@ -0,0 +1,9 @@
+package memory
+
+import "context"
+
+type About struct{}
+
+func (a *About) Ping(ctx context.Context) bool {
+	return true
+}
@@ -0,0 +1,102 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"synk/gateway/app/model"
+	"synk/gateway/app/util"
+)
+
+type ApiKeys struct {
+	store *Store
+}
+
+func (a *ApiKeys) List(ctx context.Context, userId int) ([]model.ApiKeysList, error) {
+	a.store.mu.Lock()
+	defer a.store.mu.Unlock()
+
+	var apiKeys []model.ApiKeysList
+
+	for _, item := range a.store.apiKeys {
+		if item.revoked || item.userId != userId {
+			continue
+		}
+
+		apiKeys = append(apiKeys, model.ApiKeysList{
+			ApiKeyId:     item.id,
+			ApiKeyName:   item.name,
+			ApiKeyPrefix: item.prefix,
+			ApiKeyScopes: item.scopes,
+			LastUsedAt:   util.ToTimeBR(item.lastUsedAt),
+			CreatedAt:    util.ToTimeBR(item.createdAt),
+		})
+	}
+
+	sort.Slice(apiKeys, func(i, j int) bool {
+		return apiKeys[i].ApiKeyId < apiKeys[j].ApiKeyId
+	})
+
+	return apiKeys, nil
+}
+
+func (a *ApiKeys) Add(ctx context.Context, apiKey model.ApiKeyAddData, userId int) (int, error) {
+	a.store.mu.Lock()
+	defer a.store.mu.Unlock()
+
+	apiKeyId := a.store.nextId()
+
+	a.store.apiKeys[apiKeyId] = &apiKeyRecord{
+		id:        apiKeyId,
+		name:      apiKey.ApiKeyName,
+		prefix:    apiKey.ApiKeyPrefix,
+		hash:      apiKey.ApiKeyHash,
+		scopes:    apiKey.ApiKeyScopes,
+		userId:    userId,
+		createdAt: now(),
+	}
+
+	return apiKeyId, nil
+}
+
+func (a *ApiKeys) Revoke(ctx context.Context, apiKeyId int, userId int) (int, error) {
+	a.store.mu.Lock()
+	defer a.store.mu.Unlock()
+
+	item, ok := a.store.apiKeys[apiKeyId]
+
+	if !ok || item.revoked || item.userId != userId {
+		return 0, nil
+	}
+
+	item.revoked = true
+
+	return 1, nil
+}
+
+func (a *ApiKeys) ByHash(ctx context.Context, apiKeyHash string) (model.ApiKeyOwnerData, error) {
+	a.store.mu.Lock()
+	defer a.store.mu.Unlock()
+
+	var apiKey model.ApiKeyOwnerData
+
+	for _, item := range a.store.apiKeys {
+		if !item.revoked && item.hash == apiKeyHash {
+			apiKey.ApiKeyId = item.id
+			apiKey.UserId = item.userId
+			apiKey.ApiKeyScopes = item.scopes
+		}
+	}
+
+	return apiKey, nil
+}
+
+func (a *ApiKeys) Touch(ctx context.Context, apiKeyId int) error {
+	a.store.mu.Lock()
+	defer a.store.mu.Unlock()
+
+	if item, ok := a.store.apiKeys[apiKeyId]; ok {
+		item.lastUsedAt = now()
+	}
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package memory
+
+import (
+	"context"
+	"synk/gateway/app/model"
+	"synk/gateway/app/util"
+)
+
+type AuditLogs struct {
+	store *Store
+}
+
+func (al *AuditLogs) List(ctx context.Context, filter model.AuditLogFilter, workspaceId int, userId int) ([]model.AuditLogsList, error) {
+	al.store.mu.Lock()
+	defer al.store.mu.Unlock()
+
+	var auditLogs []model.AuditLogsList
+
+	limit := model.AuditLimit(filter.Limit)
+
+	for i := len(al.store.auditLogs) - 1; i >= 0 && len(auditLogs) < limit; i-- {
+		item := al.store.auditLogs[i]
+
+		visible := item.data.WorkspaceId == workspaceId ||
+			(item.data.WorkspaceId == 0 && item.data.UserId == userId)
+
+		if !visible || !matchesAuditFilter(filter, item) {
+			continue
+		}
+
+		auditLogs = append(auditLogs, model.AuditLogsList{
+			AuditLogId:      item.id,
+			UserId:          item.data.UserId,
+			WorkspaceId:     item.data.WorkspaceId,
+			AuditAction:     item.data.AuditAction,
+			AuditEntityType: item.data.AuditEntityType,
+			AuditEntityId:   item.data.AuditEntityId,
+			AuditBefore:     model.AuditSnapshot(item.data.AuditBefore),
+			AuditAfter:      model.AuditSnapshot(item.data.AuditAfter),
+			RequestId:       item.data.RequestId,
+			CreatedAt:       util.ToTimeBR(item.createdAt),
+		})
+	}
+
+	return auditLogs, nil
+}
+
+func (al *AuditLogs) Add(ctx context.Context, auditLog model.AuditLogAddData) (int, error) {
+	al.store.mu.Lock()
+	defer al.store.mu.Unlock()
+
+	auditLogId := al.store.nextId()
+
+	al.store.auditLogs = append(al.store.auditLogs, auditLogRecord{
+		data:      auditLog,
+		id:        auditLogId,
+		createdAt: now(),
+	})
+
+	return auditLogId, nil
+}
+
+func matchesAuditFilter(filter model.AuditLogFilter, item auditLogRecord) bool {
+	switch {
+	case filter.UserId != 0 && item.data.UserId != filter.UserId:
+		return false
+	case filter.AuditAction != "" && item.data.AuditAction != filter.AuditAction:
+		return false
+	case filter.AuditEntityType != "" && item.data.AuditEntityType != filter.AuditEntityType:
+		return false
+	case filter.AuditEntityId != 0 && item.data.AuditEntityId != filter.AuditEntityId:
+		return false
+	case filter.Since != "" && item.createdAt < filter.Since:
+		return false
+	case filter.Until != "" && item.createdAt > filter.Until:
+		return false
+	}
+
+	return true
+}
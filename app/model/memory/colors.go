@@ -0,0 +1,37 @@
+package memory
+
+import (
+	"context"
+	"synk/gateway/app/model"
+)
+
+type Colors struct {
+	store *Store
+}
+
+func (c *Colors) List(ctx context.Context, id int) ([]model.ColorsList, error) {
+	c.store.mu.Lock()
+	defer c.store.mu.Unlock()
+
+	var colors []model.ColorsList
+
+	for _, color := range c.store.colors {
+		if id != 0 && color.ColorId != id {
+			continue
+		}
+
+		colors = append(colors, color)
+	}
+
+	return colors, nil
+}
+
+func (s *Store) color(colorId int) model.ColorsList {
+	for _, color := range s.colors {
+		if color.ColorId == colorId {
+			return color
+		}
+	}
+
+	return model.ColorsList{}
+}
@@ -0,0 +1,61 @@
+package memory
+
+import (
+	"context"
+	"strconv"
+	"synk/gateway/app/model"
+	"time"
+)
+
+type IdempotencyKeys struct {
+	store *Store
+}
+
+func (ik *IdempotencyKeys) Reserve(ctx context.Context, key model.IdempotencyKeyData, ttl time.Duration) (model.IdempotencyKeyData, bool, error) {
+	ik.store.mu.Lock()
+	defer ik.store.mu.Unlock()
+
+	for recordKey, item := range ik.store.idempotencyKeys {
+		if !time.Now().Before(item.expiresAt) {
+			delete(ik.store.idempotencyKeys, recordKey)
+		}
+	}
+
+	recordKey := idempotencyRecordKey(key.UserId, key.IdempotencyKey)
+
+	if item, ok := ik.store.idempotencyKeys[recordKey]; ok {
+		return item.data, false, nil
+	}
+
+	ik.store.idempotencyKeys[recordKey] = &idempotencyKeyRecord{
+		data:      key,
+		expiresAt: time.Now().Add(ttl),
+	}
+
+	return key, true, nil
+}
+
+func (ik *IdempotencyKeys) Complete(ctx context.Context, key model.IdempotencyKeyData) error {
+	ik.store.mu.Lock()
+	defer ik.store.mu.Unlock()
+
+	if item, ok := ik.store.idempotencyKeys[idempotencyRecordKey(key.UserId, key.IdempotencyKey)]; ok {
+		item.data.ResponseStatus = key.ResponseStatus
+		item.data.ResponseBody = key.ResponseBody
+	}
+
+	return nil
+}
+
+func (ik *IdempotencyKeys) Release(ctx context.Context, userId int, idempotencyKey string) error {
+	ik.store.mu.Lock()
+	defer ik.store.mu.Unlock()
+
+	delete(ik.store.idempotencyKeys, idempotencyRecordKey(userId, idempotencyKey))
+
+	return nil
+}
+
+func idempotencyRecordKey(userId int, idempotencyKey string) string {
+	return strconv.Itoa(userId) + ":" + idempotencyKey
+}
@@ -0,0 +1,170 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"synk/gateway/app/model"
+	"synk/gateway/app/util"
+)
+
+type IntCredentials struct {
+	store *Store
+}
+
+func (ic *IntCredentials) BasicList(ctx context.Context, workspaceId int) ([]model.IntCredentialsBasicList, error) {
+	ic.store.mu.Lock()
+	defer ic.store.mu.Unlock()
+
+	var intCredentials []model.IntCredentialsBasicList
+
+	for _, item := range ic.store.intCredentials {
+		if item.deleted || item.workspaceId != workspaceId {
+			continue
+		}
+
+		intCredentials = append(intCredentials, item.basic())
+	}
+
+	sort.Slice(intCredentials, func(i, j int) bool {
+		return intCredentials[i].IntCredentialName < intCredentials[j].IntCredentialName
+	})
+
+	return intCredentials, nil
+}
+
+func (ic *IntCredentials) BasicListByProfile(ctx context.Context, profileId int, workspaceId int) ([]model.IntCredentialsBasicList, error) {
+	ic.store.mu.Lock()
+	defer ic.store.mu.Unlock()
+
+	var intCredentials []model.IntCredentialsBasicList
+
+	profile, ok := ic.store.intProfiles[profileId]
+
+	if !ok {
+		return intCredentials, nil
+	}
+
+	for _, credentialId := range profile.credentials {
+		item := ic.store.activeIntCredential(credentialId, workspaceId)
+
+		if item == nil {
+			continue
+		}
+
+		intCredentials = append(intCredentials, item.basic())
+	}
+
+	sort.Slice(intCredentials, func(i, j int) bool {
+		if intCredentials[i].IntCredentialType != intCredentials[j].IntCredentialType {
+			return intCredentials[i].IntCredentialType < intCredentials[j].IntCredentialType
+		}
+
+		return intCredentials[i].IntCredentialName < intCredentials[j].IntCredentialName
+	})
+
+	return intCredentials, nil
+}
+
+func (ic *IntCredentials) List(ctx context.Context, id string, includeConfig bool, workspaceId int) ([]model.IntCredentialList, error) {
+	ic.store.mu.Lock()
+	defer ic.store.mu.Unlock()
+
+	var intCredentials []model.IntCredentialList
+
+	for _, item := range ic.store.intCredentials {
+		if item.deleted || item.workspaceId != workspaceId || !matchesId(id, item.id) {
+			continue
+		}
+
+		intCredential := model.IntCredentialList{
+			IntCredentialId:      item.id,
+			IntCredentialName:    item.name,
+			IntCredentialType:    item.credType,
+			IntCredentialVersion: item.version,
+			CreatedAt:            util.ToTimeBR(item.createdAt),
+		}
+
+		if includeConfig {
+			intCredential.IntCredentialConfig = item.config
+		}
+
+		intCredentials = append(intCredentials, intCredential)
+	}
+
+	sort.Slice(intCredentials, func(i, j int) bool {
+		return intCredentials[i].IntCredentialId < intCredentials[j].IntCredentialId
+	})
+
+	return intCredentials, nil
+}
+
+func (ic *IntCredentials) Add(ctx context.Context, intCredential model.IntCredentialAddData, userId int) (int, error) {
+	ic.store.mu.Lock()
+	defer ic.store.mu.Unlock()
+
+	intCredentialId := ic.store.nextId()
+
+	ic.store.intCredentials[intCredentialId] = &intCredentialRecord{
+		id:          intCredentialId,
+		name:        intCredential.IntCredentialName,
+		credType:    string(intCredential.IntCredentialType),
+		config:      intCredential.IntCredentialConfig,
+		version:     1,
+		userId:      userId,
+		workspaceId: intCredential.WorkspaceId,
+		createdAt:   now(),
+	}
+
+	return intCredentialId, nil
+}
+
+func (ic *IntCredentials) Update(ctx context.Context, intCredential model.IntCredentialUpdateData, workspaceId int) (int, error) {
+	ic.store.mu.Lock()
+	defer ic.store.mu.Unlock()
+
+	item := ic.store.activeIntCredential(intCredential.IntCredentialId, workspaceId)
+
+	if item == nil || (intCredential.IntCredentialVersion != 0 && intCredential.IntCredentialVersion != item.version) {
+		return 0, nil
+	}
+
+	item.name = intCredential.IntCredentialName
+	item.credType = string(intCredential.IntCredentialType)
+	item.config = intCredential.IntCredentialConfig
+	item.version++
+
+	return 1, nil
+}
+
+func (ic *IntCredentials) Delete(ctx context.Context, intCredentialId int, intCredentialVersion int, workspaceId int) (int, error) {
+	ic.store.mu.Lock()
+	defer ic.store.mu.Unlock()
+
+	item := ic.store.activeIntCredential(intCredentialId, workspaceId)
+
+	if item == nil || (intCredentialVersion != 0 && intCredentialVersion != item.version) {
+		return 0, nil
+	}
+
+	item.deleted = true
+
+	return 1, nil
+}
+
+func (item *intCredentialRecord) basic() model.IntCredentialsBasicList {
+	return model.IntCredentialsBasicList{
+		IntCredentialId:   item.id,
+		IntCredentialName: item.name,
+		IntCredentialType: item.credType,
+	}
+}
+
+func (s *Store) activeIntCredential(intCredentialId int, workspaceId int) *intCredentialRecord {
+	item, ok := s.intCredentials[intCredentialId]
+
+	if !ok || item.deleted || item.workspaceId != workspaceId {
+		return nil
+	}
+
+	return item
+}
@@ -0,0 +1,180 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"synk/gateway/app/model"
+	"synk/gateway/app/util"
+)
+
+type IntProfiles struct {
+	store *Store
+}
+
+func (ip *IntProfiles) BasicList(ctx context.Context, workspaceId int) ([]model.IntProfilesBasicList, error) {
+	ip.store.mu.Lock()
+	defer ip.store.mu.Unlock()
+
+	var intProfiles []model.IntProfilesBasicList
+
+	for _, item := range ip.store.intProfiles {
+		if item.deleted || item.workspaceId != workspaceId {
+			continue
+		}
+
+		color := ip.store.color(item.colorId)
+
+		intProfiles = append(intProfiles, model.IntProfilesBasicList{
+			IntProfileId:   item.id,
+			IntProfileName: item.name,
+			ColorName:      color.ColorName,
+			ColorHex:       color.ColorHex,
+		})
+	}
+
+	sort.Slice(intProfiles, func(i, j int) bool {
+		return intProfiles[i].IntProfileName < intProfiles[j].IntProfileName
+	})
+
+	return intProfiles, nil
+}
+
+func (ip *IntProfiles) List(ctx context.Context, id string, workspaceId int) ([]model.IntProfileList, error) {
+	ip.store.mu.Lock()
+	defer ip.store.mu.Unlock()
+
+	var intProfiles []model.IntProfileList
+
+	for _, item := range ip.store.intProfiles {
+		if item.deleted || item.workspaceId != workspaceId || !matchesId(id, item.id) {
+			continue
+		}
+
+		color := ip.store.color(item.colorId)
+
+		intProfiles = append(intProfiles, model.IntProfileList{
+			IntProfileId:               item.id,
+			IntProfileName:             item.name,
+			IntProfileRequiresApproval: item.requiresApproval,
+			ColorId:                    color.ColorId,
+			ColorName:                  color.ColorName,
+			ColorHex:                   color.ColorHex,
+			IntProfileVersion:          item.version,
+			CreatedAt:                  util.ToTimeBR(item.createdAt),
+		})
+	}
+
+	sort.Slice(intProfiles, func(i, j int) bool {
+		return intProfiles[i].IntProfileId < intProfiles[j].IntProfileId
+	})
+
+	return intProfiles, nil
+}
+
+func (ip *IntProfiles) ById(ctx context.Context, intProfileId int, workspaceId int) (model.IntProfilesByIdData, error) {
+	ip.store.mu.Lock()
+	defer ip.store.mu.Unlock()
+
+	var intProfile model.IntProfilesByIdData
+
+	if item := ip.store.activeIntProfile(intProfileId, workspaceId); item != nil {
+		intProfile.IntProfileId = item.id
+		intProfile.IntProfileRequiresApproval = item.requiresApproval
+		intProfile.IntProfileVersion = item.version
+	}
+
+	return intProfile, nil
+}
+
+func (ip *IntProfiles) Add(ctx context.Context, intProfile model.IntProfileAddData, intCredentials []int, userId int) (int, error) {
+	ip.store.mu.Lock()
+	defer ip.store.mu.Unlock()
+
+	intProfileId := ip.store.nextId()
+
+	ip.store.intProfiles[intProfileId] = &intProfileRecord{
+		id:               intProfileId,
+		name:             intProfile.IntProfileName,
+		requiresApproval: intProfile.IntProfileRequiresApproval,
+		colorId:          intProfile.ColorId,
+		version:          1,
+		userId:           userId,
+		workspaceId:      intProfile.WorkspaceId,
+		createdAt:        now(),
+		credentials:      append([]int{}, intCredentials...),
+	}
+
+	return intProfileId, nil
+}
+
+func (ip *IntProfiles) Update(ctx context.Context, intProfile model.IntProfileUpdateData, intCredentials []int, workspaceId int) (int, error) {
+	ip.store.mu.Lock()
+	defer ip.store.mu.Unlock()
+
+	item := ip.store.activeIntProfile(intProfile.IntProfileId, workspaceId)
+
+	if item == nil || (intProfile.IntProfileVersion != 0 && intProfile.IntProfileVersion != item.version) {
+		return 0, nil
+	}
+
+	item.name = intProfile.IntProfileName
+	item.requiresApproval = intProfile.IntProfileRequiresApproval
+	item.colorId = intProfile.ColorId
+	item.credentials = append([]int{}, intCredentials...)
+	item.version++
+
+	return 1, nil
+}
+
+func (ip *IntProfiles) Delete(ctx context.Context, intProfileId int, intProfileVersion int, workspaceId int) (int, error) {
+	ip.store.mu.Lock()
+	defer ip.store.mu.Unlock()
+
+	item := ip.store.activeIntProfile(intProfileId, workspaceId)
+
+	if item == nil || (intProfileVersion != 0 && intProfileVersion != item.version) {
+		return 0, nil
+	}
+
+	item.deleted = true
+
+	return 1, nil
+}
+
+func (ip *IntProfiles) Clone(ctx context.Context, intProfileId int, workspaceId int) (int, error) {
+	ip.store.mu.Lock()
+	defer ip.store.mu.Unlock()
+
+	item := ip.store.activeIntProfile(intProfileId, workspaceId)
+
+	if item == nil {
+		return 0, fmt.Errorf("memory.integration_profiles.clone: integration profile with id %d not found", intProfileId)
+	}
+
+	clonedIntProfileId := ip.store.nextId()
+
+	ip.store.intProfiles[clonedIntProfileId] = &intProfileRecord{
+		id:               clonedIntProfileId,
+		name:             item.name + model.CLONE_NAME_SUFFIX,
+		requiresApproval: item.requiresApproval,
+		colorId:          item.colorId,
+		version:          1,
+		userId:           item.userId,
+		workspaceId:      item.workspaceId,
+		createdAt:        now(),
+		credentials:      append([]int{}, item.credentials...),
+	}
+
+	return clonedIntProfileId, nil
+}
+
+func (s *Store) activeIntProfile(intProfileId int, workspaceId int) *intProfileRecord {
+	item, ok := s.intProfiles[intProfileId]
+
+	if !ok || item.deleted || item.workspaceId != workspaceId {
+		return nil
+	}
+
+	return item
+}
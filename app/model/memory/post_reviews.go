@@ -0,0 +1,83 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"synk/gateway/app/model"
+	"synk/gateway/app/util"
+)
+
+type PostReviews struct {
+	store *Store
+}
+
+func (pr *PostReviews) List(ctx context.Context, postId int, workspaceId int) ([]model.PostReviewsList, error) {
+	pr.store.mu.Lock()
+	defer pr.store.mu.Unlock()
+
+	var postReviews []model.PostReviewsList
+
+	if pr.store.activePost(postId, workspaceId) == nil {
+		return postReviews, nil
+	}
+
+	for _, item := range pr.store.postReviews {
+		if item.postId != postId {
+			continue
+		}
+
+		postReviews = append(postReviews, model.PostReviewsList{
+			PostReviewId:      item.id,
+			UserId:            item.userId,
+			PostReviewStatus:  item.status,
+			PostReviewComment: item.comment,
+			CreatedAt:         util.ToTimeBR(item.createdAt),
+		})
+	}
+
+	return postReviews, nil
+}
+
+func (pr *PostReviews) Submit(ctx context.Context, review model.PostReviewData, userId int, workspaceId int) (int, error) {
+	return pr.transition(review, model.PostReviewStatusPending, []model.PostReviewStatus{
+		model.PostReviewStatusDraft,
+		model.PostReviewStatusRejected,
+	}, userId, workspaceId), nil
+}
+
+func (pr *PostReviews) Decide(ctx context.Context, review model.PostReviewData, userId int, workspaceId int) (int, error) {
+	return pr.transition(review, review.PostReviewStatus, []model.PostReviewStatus{
+		model.PostReviewStatusPending,
+	}, userId, workspaceId), nil
+}
+
+func (pr *PostReviews) transition(
+	review model.PostReviewData,
+	status model.PostReviewStatus,
+	from []model.PostReviewStatus,
+	userId int,
+	workspaceId int,
+) int {
+	pr.store.mu.Lock()
+	defer pr.store.mu.Unlock()
+
+	item := pr.store.activePost(review.PostId, workspaceId)
+
+	if item == nil || !slices.Contains(from, item.reviewStatus) {
+		return 0
+	}
+
+	item.reviewStatus = status
+	item.version++
+
+	pr.store.postReviews = append(pr.store.postReviews, postReviewRecord{
+		id:        pr.store.nextId(),
+		postId:    review.PostId,
+		userId:    userId,
+		status:    status,
+		comment:   review.PostReviewComment,
+		createdAt: now(),
+	})
+
+	return 1
+}
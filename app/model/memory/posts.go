@@ -0,0 +1,166 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"synk/gateway/app/model"
+	"synk/gateway/app/util"
+)
+
+type Posts struct {
+	store *Store
+}
+
+func (p *Posts) List(ctx context.Context, id string, includeContent bool, workspaceId int) ([]model.PostsList, error) {
+	p.store.mu.Lock()
+	defer p.store.mu.Unlock()
+
+	var posts []model.PostsList
+
+	for _, item := range p.store.posts {
+		if item.deleted || item.workspaceId != workspaceId || !matchesId(id, item.id) {
+			continue
+		}
+
+		post := model.PostsList{
+			PostId:           item.id,
+			PostName:         item.name,
+			TemplateId:       item.templateId,
+			IntProfileId:     item.intProfileId,
+			CreatedAt:        util.ToTimeBR(item.createdAt),
+			Status:           model.StatusFromCount(p.store.countPublications(item.id)),
+			PostReviewStatus: item.reviewStatus,
+			PostVersion:      item.version,
+		}
+
+		if includeContent {
+			post.PostContent = item.content
+		}
+		if tpl, ok := p.store.templates[item.templateId]; ok {
+			post.TemplateName = tpl.name
+		}
+		if profile, ok := p.store.intProfiles[item.intProfileId]; ok {
+			post.IntProfileName = profile.name
+		}
+
+		posts = append(posts, post)
+	}
+
+	sort.Slice(posts, func(i, j int) bool {
+		return posts[i].PostId > posts[j].PostId
+	})
+
+	return posts, nil
+}
+
+func (p *Posts) ById(ctx context.Context, postId int, workspaceId int) (model.PostByIdData, error) {
+	p.store.mu.Lock()
+	defer p.store.mu.Unlock()
+
+	var post model.PostByIdData
+
+	if item := p.store.activePost(postId, workspaceId); item != nil {
+		post.PostId = item.id
+		post.IntProfileId = item.intProfileId
+		post.PostReviewStatus = item.reviewStatus
+		post.PostVersion = item.version
+	}
+
+	return post, nil
+}
+
+func (p *Posts) Add(ctx context.Context, post model.PostAddData, userId int) (int, error) {
+	p.store.mu.Lock()
+	defer p.store.mu.Unlock()
+
+	postId := p.store.nextId()
+
+	p.store.posts[postId] = &postRecord{
+		id:           postId,
+		name:         post.PostName,
+		content:      post.PostContent,
+		templateId:   post.TemplateId,
+		intProfileId: post.IntProfileId,
+		reviewStatus: model.PostReviewStatusDraft,
+		version:      1,
+		userId:       userId,
+		workspaceId:  post.WorkspaceId,
+		createdAt:    now(),
+	}
+
+	return postId, nil
+}
+
+func (p *Posts) Update(ctx context.Context, post model.PostUpdateData, workspaceId int) (int, error) {
+	p.store.mu.Lock()
+	defer p.store.mu.Unlock()
+
+	item := p.store.activePost(post.PostId, workspaceId)
+
+	if item == nil || (post.PostVersion != 0 && post.PostVersion != item.version) {
+		return 0, nil
+	}
+
+	item.name = post.PostName
+	item.content = post.PostContent
+	item.templateId = post.TemplateId
+	item.intProfileId = post.IntProfileId
+	item.reviewStatus = model.PostReviewStatusDraft
+	item.version++
+
+	return 1, nil
+}
+
+func (p *Posts) Delete(ctx context.Context, postId int, postVersion int, workspaceId int) (int, error) {
+	p.store.mu.Lock()
+	defer p.store.mu.Unlock()
+
+	item := p.store.activePost(postId, workspaceId)
+
+	if item == nil || (postVersion != 0 && postVersion != item.version) {
+		return 0, nil
+	}
+
+	item.deleted = true
+
+	return 1, nil
+}
+
+func (p *Posts) Clone(ctx context.Context, postId int, workspaceId int) (int, error) {
+	p.store.mu.Lock()
+	defer p.store.mu.Unlock()
+
+	item := p.store.activePost(postId, workspaceId)
+
+	if item == nil {
+		return 0, fmt.Errorf("memory.posts.clone: post with id %d not found", postId)
+	}
+
+	clonedPostId := p.store.nextId()
+
+	p.store.posts[clonedPostId] = &postRecord{
+		id:           clonedPostId,
+		name:         item.name + model.CLONE_NAME_SUFFIX,
+		content:      item.content,
+		templateId:   item.templateId,
+		intProfileId: item.intProfileId,
+		reviewStatus: model.PostReviewStatusDraft,
+		version:      1,
+		userId:       item.userId,
+		workspaceId:  item.workspaceId,
+		createdAt:    now(),
+	}
+
+	return clonedPostId, nil
+}
+
+func (s *Store) activePost(postId int, workspaceId int) *postRecord {
+	item, ok := s.posts[postId]
+
+	if !ok || item.deleted || item.workspaceId != workspaceId {
+		return nil
+	}
+
+	return item
+}
@@ -0,0 +1,44 @@
+package memory
+
+import (
+	"context"
+	"synk/gateway/app/model"
+)
+
+type Publication struct {
+	store *Store
+}
+
+func (p *Publication) CountByPost(ctx context.Context, postId int) (map[model.PublicationStatus]int, error) {
+	p.store.mu.Lock()
+	defer p.store.mu.Unlock()
+
+	return p.store.countPublications(postId), nil
+}
+
+func (p *Publication) CountByStatus(ctx context.Context) (map[model.PublicationStatus]int, error) {
+	p.store.mu.Lock()
+	defer p.store.mu.Unlock()
+
+	posts := map[model.PublicationStatus]int{}
+
+	for postId, item := range p.store.posts {
+		if !item.deleted {
+			posts[model.StatusFromCount(p.store.countPublications(postId))]++
+		}
+	}
+
+	return posts, nil
+}
+
+func (s *Store) countPublications(postId int) map[model.PublicationStatus]int {
+	posts := map[model.PublicationStatus]int{}
+
+	for _, item := range s.publications {
+		if item.postId == postId {
+			posts[item.status]++
+		}
+	}
+
+	return posts
+}
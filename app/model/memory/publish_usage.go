@@ -0,0 +1,88 @@
+package memory
+
+import (
+	"context"
+	"synk/gateway/app/model"
+)
+
+type PublishUsage struct {
+	store *Store
+}
+
+func (pu *PublishUsage) Count(ctx context.Context, userId int, intProfileId int, since string) (model.PublishUsageCount, error) {
+	pu.store.mu.Lock()
+	defer pu.store.mu.Unlock()
+
+	return pu.count(userId, intProfileId, since), nil
+}
+
+func (pu *PublishUsage) count(userId int, intProfileId int, since string) model.PublishUsageCount {
+	var count model.PublishUsageCount
+
+	for _, item := range pu.store.publishUsage {
+		if item.createdAt < since {
+			continue
+		}
+
+		if item.data.UserId == userId {
+			count.UserCount++
+		}
+
+		if item.data.IntProfileId == intProfileId {
+			count.IntProfileCount++
+		}
+	}
+
+	return count
+}
+
+func (pu *PublishUsage) Add(ctx context.Context, usage model.PublishUsageAddData) (int, error) {
+	pu.store.mu.Lock()
+	defer pu.store.mu.Unlock()
+
+	publishUsageId := pu.store.nextId()
+
+	pu.store.publishUsage = append(pu.store.publishUsage, publishUsageRecord{
+		data:      usage,
+		id:        publishUsageId,
+		createdAt: now(),
+	})
+
+	return publishUsageId, nil
+}
+
+func (pu *PublishUsage) Reserve(ctx context.Context, usage model.PublishUsageAddData, since string, userLimit int, intProfileLimit int) (int, model.PublishUsageCount, error) {
+	pu.store.mu.Lock()
+	defer pu.store.mu.Unlock()
+
+	count := pu.count(usage.UserId, usage.IntProfileId, since)
+
+	if (userLimit > 0 && count.UserCount >= userLimit) || (intProfileLimit > 0 && count.IntProfileCount >= intProfileLimit) {
+		return 0, count, nil
+	}
+
+	publishUsageId := pu.store.nextId()
+
+	pu.store.publishUsage = append(pu.store.publishUsage, publishUsageRecord{
+		data:      usage,
+		id:        publishUsageId,
+		createdAt: now(),
+	})
+
+	return publishUsageId, count, nil
+}
+
+func (pu *PublishUsage) Release(ctx context.Context, publishUsageId int) error {
+	pu.store.mu.Lock()
+	defer pu.store.mu.Unlock()
+
+	for i, item := range pu.store.publishUsage {
+		if item.id == publishUsageId {
+			pu.store.publishUsage = append(pu.store.publishUsage[:i], pu.store.publishUsage[i+1:]...)
+
+			break
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,215 @@
+package memory
+
+import (
+	"strconv"
+	"sync"
+	"synk/gateway/app/model"
+	"time"
+)
+
+const DB_TIME_LAYOUT = "2006-01-02 15:04:05"
+
+type postRecord struct {
+	id           int
+	name         string
+	content      string
+	templateId   int
+	intProfileId int
+	reviewStatus model.PostReviewStatus
+	version      int
+	userId       int
+	workspaceId  int
+	createdAt    string
+	deleted      bool
+}
+
+type postReviewRecord struct {
+	id        int
+	postId    int
+	userId    int
+	status    model.PostReviewStatus
+	comment   string
+	createdAt string
+}
+
+type templateRecord struct {
+	id          int
+	name        string
+	content     string
+	urlImport   string
+	version     int
+	userId      int
+	workspaceId int
+	createdAt   string
+	deleted     bool
+}
+
+type intProfileRecord struct {
+	id               int
+	name             string
+	requiresApproval bool
+	colorId          int
+	version          int
+	userId           int
+	workspaceId      int
+	createdAt        string
+	deleted          bool
+	credentials      []int
+}
+
+type intCredentialRecord struct {
+	id          int
+	name        string
+	credType    string
+	config      string
+	version     int
+	userId      int
+	workspaceId int
+	createdAt   string
+	deleted     bool
+}
+
+type apiKeyRecord struct {
+	id         int
+	name       string
+	prefix     string
+	hash       string
+	scopes     []string
+	userId     int
+	lastUsedAt string
+	createdAt  string
+	revoked    bool
+}
+
+type workspaceMemberRecord struct {
+	role      model.WorkspaceRole
+	createdAt string
+}
+
+type workspaceRecord struct {
+	id             int
+	name           string
+	personalUserId int
+	createdAt      string
+	members        map[int]*workspaceMemberRecord
+}
+
+type auditLogRecord struct {
+	data      model.AuditLogAddData
+	id        int
+	createdAt string
+}
+
+type publishUsageRecord struct {
+	data      model.PublishUsageAddData
+	id        int
+	createdAt string
+}
+
+type idempotencyKeyRecord struct {
+	data      model.IdempotencyKeyData
+	expiresAt time.Time
+}
+
+type publicationRecord struct {
+	postId          int
+	intCredentialId int
+	status          model.PublicationStatus
+}
+
+type Store struct {
+	mu              sync.Mutex
+	lastId          int
+	colors          []model.ColorsList
+	posts           map[int]*postRecord
+	templates       map[int]*templateRecord
+	intProfiles     map[int]*intProfileRecord
+	intCredentials  map[int]*intCredentialRecord
+	apiKeys         map[int]*apiKeyRecord
+	workspaces      map[int]*workspaceRecord
+	publications    []publicationRecord
+	postReviews     []postReviewRecord
+	auditLogs       []auditLogRecord
+	publishUsage    []publishUsageRecord
+	idempotencyKeys map[string]*idempotencyKeyRecord
+}
+
+func NewStore() *Store {
+	store := Store{
+		posts:           map[int]*postRecord{},
+		templates:       map[int]*templateRecord{},
+		intProfiles:     map[int]*intProfileRecord{},
+		intCredentials:  map[int]*intCredentialRecord{},
+		apiKeys:         map[int]*apiKeyRecord{},
+		workspaces:      map[int]*workspaceRecord{},
+		idempotencyKeys: map[string]*idempotencyKeyRecord{},
+	}
+
+	return &store
+}
+
+func (s *Store) Repositories() *model.Repositories {
+	repositories := model.Repositories{
+		About:           &About{},
+		Colors:          &Colors{store: s},
+		Publication:     &Publication{store: s},
+		Posts:           &Posts{store: s},
+		PostReviews:     &PostReviews{store: s},
+		Templates:       &Templates{store: s},
+		IntProfiles:     &IntProfiles{store: s},
+		IntCredentials:  &IntCredentials{store: s},
+		ApiKeys:         &ApiKeys{store: s},
+		Workspaces:      &Workspaces{store: s},
+		AuditLogs:       &AuditLogs{store: s},
+		PublishUsage:    &PublishUsage{store: s},
+		IdempotencyKeys: &IdempotencyKeys{store: s},
+	}
+
+	return &repositories
+}
+
+func (s *Store) AddColor(colorName string, colorHex string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	colorId := s.nextId()
+
+	s.colors = append(s.colors, model.ColorsList{
+		ColorId:   colorId,
+		ColorName: colorName,
+		ColorHex:  colorHex,
+	})
+
+	return colorId
+}
+
+func (s *Store) AddPublication(postId int, intCredentialId int, status model.PublicationStatus) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.publications = append(s.publications, publicationRecord{
+		postId:          postId,
+		intCredentialId: intCredentialId,
+		status:          status,
+	})
+}
+
+func (s *Store) nextId() int {
+	s.lastId++
+
+	return s.lastId
+}
+
+func now() string {
+	return time.Now().Format(DB_TIME_LAYOUT)
+}
+
+func matchesId(filter string, id int) bool {
+	if filter == "" {
+		return true
+	}
+
+	filterId, filterErr := strconv.Atoi(filter)
+
+	return filterErr == nil && filterId == id
+}
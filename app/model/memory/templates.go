@@ -0,0 +1,173 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"synk/gateway/app/model"
+	"synk/gateway/app/util"
+)
+
+type Templates struct {
+	store *Store
+}
+
+func (t *Templates) BasicList(ctx context.Context, workspaceId int) ([]model.TemplatesBasicList, error) {
+	t.store.mu.Lock()
+	defer t.store.mu.Unlock()
+
+	var templates []model.TemplatesBasicList
+
+	for _, item := range t.store.templates {
+		if item.deleted || item.workspaceId != workspaceId {
+			continue
+		}
+
+		templates = append(templates, model.TemplatesBasicList{
+			TemplateId:   item.id,
+			TemplateName: item.name,
+		})
+	}
+
+	sort.Slice(templates, func(i, j int) bool {
+		return templates[i].TemplateName < templates[j].TemplateName
+	})
+
+	return templates, nil
+}
+
+func (t *Templates) List(ctx context.Context, id string, includeContent bool, workspaceId int) ([]model.TemplatesList, error) {
+	t.store.mu.Lock()
+	defer t.store.mu.Unlock()
+
+	var templates []model.TemplatesList
+
+	for _, item := range t.store.templates {
+		if item.deleted || item.workspaceId != workspaceId || !matchesId(id, item.id) {
+			continue
+		}
+
+		template := model.TemplatesList{
+			TemplateId:        item.id,
+			TemplateName:      item.name,
+			TemplateUrlImport: item.urlImport,
+			TemplateVersion:   item.version,
+			CreatedAt:         util.ToTimeBR(item.createdAt),
+		}
+
+		if includeContent {
+			template.TemplateContent = item.content
+		}
+
+		templates = append(templates, template)
+	}
+
+	sort.Slice(templates, func(i, j int) bool {
+		return templates[i].TemplateId < templates[j].TemplateId
+	})
+
+	return templates, nil
+}
+
+func (t *Templates) ById(ctx context.Context, templateId int, workspaceId int) (model.TemplatesByIdData, error) {
+	t.store.mu.Lock()
+	defer t.store.mu.Unlock()
+
+	var template model.TemplatesByIdData
+
+	if item := t.store.activeTemplate(templateId, workspaceId); item != nil {
+		template.TemplateId = item.id
+		template.TemplateVersion = item.version
+	}
+
+	return template, nil
+}
+
+func (t *Templates) Add(ctx context.Context, template model.TemplateAddData) (int, error) {
+	t.store.mu.Lock()
+	defer t.store.mu.Unlock()
+
+	templateId := t.store.nextId()
+
+	t.store.templates[templateId] = &templateRecord{
+		id:          templateId,
+		name:        template.TemplateName,
+		content:     template.TemplateContent,
+		urlImport:   template.TemplateUrlImport,
+		version:     1,
+		userId:      template.UserId,
+		workspaceId: template.WorkspaceId,
+		createdAt:   now(),
+	}
+
+	return templateId, nil
+}
+
+func (t *Templates) Update(ctx context.Context, template model.TemplateUpdateData, workspaceId int) (int, error) {
+	t.store.mu.Lock()
+	defer t.store.mu.Unlock()
+
+	item := t.store.activeTemplate(template.TemplateId, workspaceId)
+
+	if item == nil || (template.TemplateVersion != 0 && template.TemplateVersion != item.version) {
+		return 0, nil
+	}
+
+	item.name = template.TemplateName
+	item.content = template.TemplateContent
+	item.urlImport = template.TemplateUrlImport
+	item.version++
+
+	return 1, nil
+}
+
+func (t *Templates) Delete(ctx context.Context, templateId int, templateVersion int, workspaceId int) (int, error) {
+	t.store.mu.Lock()
+	defer t.store.mu.Unlock()
+
+	item := t.store.activeTemplate(templateId, workspaceId)
+
+	if item == nil || (templateVersion != 0 && templateVersion != item.version) {
+		return 0, nil
+	}
+
+	item.deleted = true
+
+	return 1, nil
+}
+
+func (t *Templates) Clone(ctx context.Context, templateId int, workspaceId int) (int, error) {
+	t.store.mu.Lock()
+	defer t.store.mu.Unlock()
+
+	item := t.store.activeTemplate(templateId, workspaceId)
+
+	if item == nil {
+		return 0, fmt.Errorf("memory.templates.clone: template with id %d not found", templateId)
+	}
+
+	clonedTemplateId := t.store.nextId()
+
+	t.store.templates[clonedTemplateId] = &templateRecord{
+		id:          clonedTemplateId,
+		name:        item.name + model.CLONE_NAME_SUFFIX,
+		content:     item.content,
+		urlImport:   item.urlImport,
+		version:     1,
+		userId:      item.userId,
+		workspaceId: item.workspaceId,
+		createdAt:   now(),
+	}
+
+	return clonedTemplateId, nil
+}
+
+func (s *Store) activeTemplate(templateId int, workspaceId int) *templateRecord {
+	item, ok := s.templates[templateId]
+
+	if !ok || item.deleted || item.workspaceId != workspaceId {
+		return nil
+	}
+
+	return item
+}
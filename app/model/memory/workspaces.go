@@ -0,0 +1,189 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"synk/gateway/app/model"
+	"synk/gateway/app/util"
+)
+
+const PERSONAL_WORKSPACE_NAME = "Personal"
+
+type Workspaces struct {
+	store *Store
+}
+
+func (ws *Workspaces) List(ctx context.Context, userId int) ([]model.WorkspacesList, error) {
+	ws.store.mu.Lock()
+	defer ws.store.mu.Unlock()
+
+	var workspaces []model.WorkspacesList
+
+	for _, item := range ws.store.workspaces {
+		member, ok := item.members[userId]
+
+		if !ok {
+			continue
+		}
+
+		workspaces = append(workspaces, model.WorkspacesList{
+			WorkspaceId:   item.id,
+			WorkspaceName: item.name,
+			WorkspaceRole: member.role,
+			Personal:      item.personalUserId != 0,
+			CreatedAt:     util.ToTimeBR(item.createdAt),
+		})
+	}
+
+	sort.Slice(workspaces, func(i, j int) bool {
+		if workspaces[i].Personal != workspaces[j].Personal {
+			return workspaces[i].Personal
+		}
+
+		return workspaces[i].WorkspaceName < workspaces[j].WorkspaceName
+	})
+
+	return workspaces, nil
+}
+
+func (ws *Workspaces) Add(ctx context.Context, workspace model.WorkspaceAddData, userId int) (int, error) {
+	ws.store.mu.Lock()
+	defer ws.store.mu.Unlock()
+
+	return ws.store.addWorkspace(workspace.WorkspaceName, 0, userId), nil
+}
+
+func (ws *Workspaces) Personal(ctx context.Context, userId int) (int, error) {
+	ws.store.mu.Lock()
+	defer ws.store.mu.Unlock()
+
+	for _, item := range ws.store.workspaces {
+		if item.personalUserId == userId {
+			return item.id, nil
+		}
+	}
+
+	return ws.store.addWorkspace(PERSONAL_WORKSPACE_NAME, userId, userId), nil
+}
+
+func (ws *Workspaces) Role(ctx context.Context, workspaceId int, userId int) (model.WorkspaceRole, error) {
+	ws.store.mu.Lock()
+	defer ws.store.mu.Unlock()
+
+	item, ok := ws.store.workspaces[workspaceId]
+
+	if !ok {
+		return "", nil
+	}
+
+	member, ok := item.members[userId]
+
+	if !ok {
+		return "", nil
+	}
+
+	return member.role, nil
+}
+
+func (ws *Workspaces) Members(ctx context.Context, workspaceId int) ([]model.WorkspaceMembersList, error) {
+	ws.store.mu.Lock()
+	defer ws.store.mu.Unlock()
+
+	var members []model.WorkspaceMembersList
+
+	item, ok := ws.store.workspaces[workspaceId]
+
+	if !ok {
+		return members, nil
+	}
+
+	for userId, member := range item.members {
+		members = append(members, model.WorkspaceMembersList{
+			UserId:        userId,
+			WorkspaceRole: member.role,
+			CreatedAt:     util.ToTimeBR(member.createdAt),
+		})
+	}
+
+	sort.Slice(members, func(i, j int) bool {
+		return members[i].UserId < members[j].UserId
+	})
+
+	return members, nil
+}
+
+func (ws *Workspaces) AddMember(ctx context.Context, workspaceId int, userId int, role model.WorkspaceRole) (int, error) {
+	ws.store.mu.Lock()
+	defer ws.store.mu.Unlock()
+
+	item, ok := ws.store.workspaces[workspaceId]
+
+	if !ok {
+		return 0, fmt.Errorf("memory.workspaces.add_member: workspace with id %d not found", workspaceId)
+	}
+
+	if _, exists := item.members[userId]; exists {
+		return 0, fmt.Errorf("memory.workspaces.add_member: %w", model.ErrMemberExists)
+	}
+
+	item.members[userId] = &workspaceMemberRecord{role: role, createdAt: now()}
+
+	return 1, nil
+}
+
+func (ws *Workspaces) UpdateMember(ctx context.Context, workspaceId int, userId int, role model.WorkspaceRole) (int, error) {
+	ws.store.mu.Lock()
+	defer ws.store.mu.Unlock()
+
+	item, ok := ws.store.workspaces[workspaceId]
+
+	if !ok {
+		return 0, nil
+	}
+
+	member, ok := item.members[userId]
+
+	if !ok {
+		return 0, nil
+	}
+
+	member.role = role
+
+	return 1, nil
+}
+
+func (ws *Workspaces) RemoveMember(ctx context.Context, workspaceId int, userId int) (int, error) {
+	ws.store.mu.Lock()
+	defer ws.store.mu.Unlock()
+
+	item, ok := ws.store.workspaces[workspaceId]
+
+	if !ok {
+		return 0, nil
+	}
+
+	if _, exists := item.members[userId]; !exists {
+		return 0, nil
+	}
+
+	delete(item.members, userId)
+
+	return 1, nil
+}
+
+func (s *Store) addWorkspace(name string, personalUserId int, ownerId int) int {
+	workspaceId := s.nextId()
+
+	s.workspaces[workspaceId] = &workspaceRecord{
+		id:             workspaceId,
+		name:           name,
+		personalUserId: personalUserId,
+		createdAt:      now(),
+		members: map[int]*workspaceMemberRecord{
+			ownerId: {role: model.WorkspaceRoleOwner, createdAt: now()},
+		},
+	}
+
+	return workspaceId
+}
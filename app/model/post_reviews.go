@@ -0,0 +1,185 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"synk/gateway/app/util"
+)
+
+type PostReviewStatus string
+
+const (
+	PostReviewStatusDraft    PostReviewStatus = "draft"
+	PostReviewStatusPending  PostReviewStatus = "pending"
+	PostReviewStatusApproved PostReviewStatus = "approved"
+	PostReviewStatusRejected PostReviewStatus = "rejected"
+)
+
+// IsDecision reports whether a reviewer can set the status on a pending post.
+func (prs PostReviewStatus) IsDecision() bool {
+	return prs == PostReviewStatusApproved || prs == PostReviewStatusRejected
+}
+
+type PostReviews struct {
+	db *sql.DB
+}
+
+type PostReviewsList struct {
+	PostReviewId      int              `json:"post_review_id"`
+	UserId            int              `json:"user_id"`
+	UserName          string           `json:"user_name"`
+	PostReviewStatus  PostReviewStatus `json:"post_review_status"`
+	PostReviewComment string           `json:"post_review_comment"`
+	CreatedAt         string           `json:"created_at"`
+}
+
+type PostReviewData struct {
+	PostId            int              `json:"post_id"`
+	PostReviewStatus  PostReviewStatus `json:"post_review_status"`
+	PostReviewComment string           `json:"post_review_comment"`
+}
+
+func NewPostReviews(db *sql.DB) *PostReviews {
+	postReviews := PostReviews{db: db}
+
+	return &postReviews
+}
+
+func (pr *PostReviews) List(ctx context.Context, postId int, workspaceId int) ([]PostReviewsList, error) {
+	var postReviews []PostReviewsList
+
+	rows, rowsErr := pr.db.QueryContext(
+		ctx,
+		`SELECT review.post_review_id, review.user_id, COALESCE(user.user_name, ''),
+            review.post_review_status, review.post_review_comment, review.created_at
+        FROM post_review review
+        INNER JOIN post ON post.post_id = review.post_id
+        LEFT JOIN user ON user.user_id = review.user_id
+        WHERE post.deleted_at IS NULL AND post.workspace_id = ? AND review.post_id = ?
+        ORDER BY review.post_review_id`, workspaceId, postId,
+	)
+
+	if rowsErr != nil {
+		return nil, fmt.Errorf("models.post_reviews.list: %s", rowsErr.Error())
+	}
+
+	defer rows.Close()
+
+	rowsErr = rows.Err()
+
+	if rowsErr != nil {
+		return nil, fmt.Errorf("models.post_reviews.list: %s", rowsErr.Error())
+	}
+
+	for rows.Next() {
+		var postReview PostReviewsList
+
+		exception := rows.Scan(
+			&postReview.PostReviewId,
+			&postReview.UserId,
+			&postReview.UserName,
+			&postReview.PostReviewStatus,
+			&postReview.PostReviewComment,
+			&postReview.CreatedAt,
+		)
+
+		if exception != nil {
+			return nil, fmt.Errorf("models.post_reviews.list: %s", exception.Error())
+		}
+
+		postReview.CreatedAt = util.ToTimeBR(postReview.CreatedAt)
+
+		postReviews = append(postReviews, postReview)
+	}
+
+	return postReviews, nil
+}
+
+// Submit moves a draft or rejected post to pending. It returns 0 rows when
+// the post is missing or already pending/approved.
+func (pr *PostReviews) Submit(ctx context.Context, review PostReviewData, userId int, workspaceId int) (int, error) {
+	rowsAffected, txErr := pr.transition(ctx, review, PostReviewStatusPending, []PostReviewStatus{
+		PostReviewStatusDraft,
+		PostReviewStatusRejected,
+	}, userId, workspaceId)
+
+	if txErr != nil {
+		return 0, fmt.Errorf("models.post_reviews.submit: %s", txErr.Error())
+	}
+
+	return rowsAffected, nil
+}
+
+// Decide approves or rejects a pending post. It returns 0 rows when the post
+// is missing or not pending.
+func (pr *PostReviews) Decide(ctx context.Context, review PostReviewData, userId int, workspaceId int) (int, error) {
+	rowsAffected, txErr := pr.transition(ctx, review, review.PostReviewStatus, []PostReviewStatus{
+		PostReviewStatusPending,
+	}, userId, workspaceId)
+
+	if txErr != nil {
+		return 0, fmt.Errorf("models.post_reviews.decide: %s", txErr.Error())
+	}
+
+	return rowsAffected, nil
+}
+
+func (pr *PostReviews) transition(
+	ctx context.Context,
+	review PostReviewData,
+	status PostReviewStatus,
+	from []PostReviewStatus,
+	userId int,
+	workspaceId int,
+) (int, error) {
+	var rowsAffected int64
+
+	updateValues := []any{status, workspaceId, review.PostId}
+
+	for _, fromStatus := range from {
+		updateValues = append(updateValues, fromStatus)
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(from)), ", ")
+
+	txErr := WithTx(ctx, pr.db, func(tx *sql.Tx) error {
+		updateRes, updateErr := tx.ExecContext(
+			ctx,
+			`UPDATE post
+            SET post_review_status = ?,
+                post_version = post_version + 1
+            WHERE deleted_at IS NULL AND workspace_id = ? AND post_id = ?
+                AND post_review_status IN (`+placeholders+`)`,
+			updateValues...,
+		)
+
+		if updateErr != nil {
+			return updateErr
+		}
+
+		rowsAffectedVal, exception := updateRes.RowsAffected()
+
+		if exception != nil {
+			return exception
+		}
+
+		rowsAffected = rowsAffectedVal
+
+		if rowsAffected == 0 {
+			return nil
+		}
+
+		_, insertErr := tx.ExecContext(
+			ctx,
+			`INSERT INTO post_review (post_id, user_id, post_review_status, post_review_comment)
+            VALUES (?, ?, ?, ?)`,
+			review.PostId, userId, status, review.PostReviewComment,
+		)
+
+		return insertErr
+	})
+
+	return int(rowsAffected), txErr
+}
@@ -8,20 +8,24 @@ import (
 	"synk/gateway/app/util"
 )
 
+const CLONE_NAME_SUFFIX = " (copy)"
+
 type Posts struct {
 	db *sql.DB
 }
 
 type PostsList struct {
-	PostId         int               `json:"post_id"`
-	PostName       string            `json:"post_name"`
-	TemplateName   string            `json:"template_name"`
-	IntProfileName string            `json:"int_profile_name"`
-	CreatedAt      string            `json:"created_at"`
-	Status         PublicationStatus `json:"status"`
-	PostContent    string            `json:"post_content"`
-	TemplateId     int               `json:"template_id"`
-	IntProfileId   int               `json:"int_profile_id"`
+	PostId           int               `json:"post_id"`
+	PostName         string            `json:"post_name"`
+	TemplateName     string            `json:"template_name"`
+	IntProfileName   string            `json:"int_profile_name"`
+	CreatedAt        string            `json:"created_at"`
+	Status           PublicationStatus `json:"status"`
+	PostReviewStatus PostReviewStatus  `json:"post_review_status"`
+	PostContent      string            `json:"post_content"`
+	TemplateId       int               `json:"template_id"`
+	IntProfileId     int               `json:"int_profile_id"`
+	PostVersion      int               `json:"post_version"`
 }
 
 type PostAddData struct {
@@ -29,6 +33,7 @@ type PostAddData struct {
 	PostContent  string `json:"post_content"`
 	TemplateId   int    `json:"template_id"`
 	IntProfileId int    `json:"int_profile_id"`
+	WorkspaceId  int    `json:"workspace_id"`
 	CreatedAt    string `json:"created_at"`
 }
 
@@ -38,11 +43,15 @@ type PostUpdateData struct {
 	PostContent  string `json:"post_content"`
 	TemplateId   int    `json:"template_id"`
 	IntProfileId int    `json:"int_profile_id"`
+	PostVersion  int    `json:"post_version"`
 	UpdatedAt    string `json:"updated_at"`
 }
 
 type PostByIdData struct {
-	PostId int `json:"post_id"`
+	PostId           int              `json:"post_id"`
+	IntProfileId     int              `json:"int_profile_id"`
+	PostReviewStatus PostReviewStatus `json:"post_review_status"`
+	PostVersion      int              `json:"post_version"`
 }
 
 func NewPosts(db *sql.DB) *Posts {
@@ -51,15 +60,15 @@ func NewPosts(db *sql.DB) *Posts {
 	return &posts
 }
 
-func (p *Posts) List(id string, includeContent bool, userId int) ([]PostsList, error) {
+func (p *Posts) List(ctx context.Context, id string, includeContent bool, workspaceId int) ([]PostsList, error) {
 	var posts []PostsList
 
 	whereList := []string{}
 	whereValues := []any{}
 	columnsList := []string{}
 
-	whereList = append(whereList, "post.user_id = ?")
-	whereValues = append(whereValues, userId)
+	whereList = append(whereList, "post.workspace_id = ?")
+	whereValues = append(whereValues, workspaceId)
 
 	if id != "" {
 		whereList = append(whereList, "post_id = ?")
@@ -81,10 +90,11 @@ func (p *Posts) List(id string, includeContent bool, userId int) ([]PostsList, e
 		columns = ", " + strings.Join(columnsList, ", ")
 	}
 
-	rows, rowsErr := p.db.Query(
+	rows, rowsErr := p.db.QueryContext(
+		ctx,
 		`SELECT post.post_id, post.post_name, post.template_id, template.template_name,
                 post.int_profile_id, int_profile.int_profile_name, post.created_at,
-                "" status `+columns+`
+                "" status, post.post_review_status, post.post_version `+columns+`
         FROM post
         LEFT JOIN template ON template.template_id = post.template_id
         LEFT JOIN integration_profile int_profile ON int_profile.int_profile_id = post.int_profile_id
@@ -118,6 +128,8 @@ func (p *Posts) List(id string, includeContent bool, userId int) ([]PostsList, e
 			&post.IntProfileName,
 			&post.CreatedAt,
 			&post.Status,
+			&post.PostReviewStatus,
+			&post.PostVersion,
 			&post.PostContent,
 		)
 
@@ -127,19 +139,13 @@ func (p *Posts) List(id string, includeContent bool, userId int) ([]PostsList, e
 			return nil, fmt.Errorf("models.posts.list: %s", exception.Error())
 		}
 
-		statusCount, statusCountErr := publicationModel.CountByPost(post.PostId)
+		statusCount, statusCountErr := publicationModel.CountByPost(ctx, post.PostId)
 
 		if statusCountErr != nil {
 			return nil, fmt.Errorf("models.posts.list: %s", statusCountErr.Error())
 		}
 
-		if statusCount[PublicationStatusFailed] > 0 {
-			post.Status = PublicationStatusFailed
-		} else if statusCount[PublicationStatusPending] > 0 {
-			post.Status = PublicationStatusPending
-		} else {
-			post.Status = PublicationStatusPublished
-		}
+		post.Status = StatusFromCount(statusCount)
 
 		posts = append(posts, post)
 	}
@@ -147,14 +153,14 @@ func (p *Posts) List(id string, includeContent bool, userId int) ([]PostsList, e
 	return posts, nil
 }
 
-func (p *Posts) Add(post PostAddData, userId int) (int, error) {
+func (p *Posts) Add(ctx context.Context, post PostAddData, userId int) (int, error) {
 	var postId int
 
 	insertRes, insertErr := p.db.ExecContext(
-		context.Background(),
-		`INSERT INTO synk.post (post_name, post_content, template_id, int_profile_id, user_id)
-        VALUES (?, ?, ?, ?, ?)`,
-		post.PostName, post.PostContent, post.TemplateId, post.IntProfileId, userId,
+		ctx,
+		`INSERT INTO post (post_name, post_content, template_id, int_profile_id, user_id, workspace_id)
+        VALUES (?, ?, ?, ?, ?, ?)`,
+		post.PostName, post.PostContent, post.TemplateId, post.IntProfileId, userId, post.WorkspaceId,
 	)
 
 	if insertErr != nil {
@@ -172,19 +178,31 @@ func (p *Posts) Add(post PostAddData, userId int) (int, error) {
 	return postId, nil
 }
 
-func (p *Posts) Update(post PostUpdateData, userId int) (int, error) {
+// Update bumps the post version. A PostVersion other than 0 must match the
+// stored one, or no row is affected.
+func (p *Posts) Update(ctx context.Context, post PostUpdateData, workspaceId int) (int, error) {
 	var rowsAffected int64
 
+	whereValues := []any{post.PostName, post.PostContent, post.TemplateId, post.IntProfileId, PostReviewStatusDraft, workspaceId, post.PostId}
+	versionWhere := ""
+
+	if post.PostVersion != 0 {
+		versionWhere = " AND post_version = ?"
+		whereValues = append(whereValues, post.PostVersion)
+	}
+
 	insertRes, insertErr := p.db.ExecContext(
-		context.Background(),
+		ctx,
 		`UPDATE post
         SET post_name = ?,
             post_content = ?,
             template_id = ?,
             int_profile_id = ?,
+            post_review_status = ?,
+            post_version = post_version + 1,
             updated_at = CURRENT_TIMESTAMP
-        WHERE deleted_at IS NULL AND user_id = ? AND post_id = ?`,
-		post.PostName, post.PostContent, post.TemplateId, post.IntProfileId, userId, post.PostId,
+        WHERE deleted_at IS NULL AND workspace_id = ? AND post_id = ?`+versionWhere,
+		whereValues...,
 	)
 
 	if insertErr != nil {
@@ -202,15 +220,25 @@ func (p *Posts) Update(post PostUpdateData, userId int) (int, error) {
 	return int(rowsAffected), nil
 }
 
-func (p *Posts) Delete(postId int, userId int) (int, error) {
+// Delete soft deletes the post. A version other than 0 must match the
+// stored one, or no row is affected.
+func (p *Posts) Delete(ctx context.Context, postId int, postVersion int, workspaceId int) (int, error) {
 	var rowsAffected int64
 
+	whereValues := []any{workspaceId, postId}
+	versionWhere := ""
+
+	if postVersion != 0 {
+		versionWhere = " AND post_version = ?"
+		whereValues = append(whereValues, postVersion)
+	}
+
 	insertRes, insertErr := p.db.ExecContext(
-		context.Background(),
+		ctx,
 		`UPDATE post
         SET deleted_at = CURRENT_TIMESTAMP
-        WHERE deleted_at IS NULL AND user_id = ? AND post_id = ?`,
-		userId, postId,
+        WHERE deleted_at IS NULL AND workspace_id = ? AND post_id = ?`+versionWhere,
+		whereValues...,
 	)
 
 	if insertErr != nil {
@@ -228,14 +256,52 @@ func (p *Posts) Delete(postId int, userId int) (int, error) {
 	return int(rowsAffected), nil
 }
 
-func (p *Posts) ById(postId int, userId int) (PostByIdData, error) {
+func (p *Posts) Clone(ctx context.Context, postId int, workspaceId int) (int, error) {
+	var clonedPostId int
+
+	insertRes, insertErr := p.db.ExecContext(
+		ctx,
+		`INSERT INTO post (post_name, post_content, template_id, int_profile_id, user_id, workspace_id)
+        SELECT CONCAT(post_name, ?), post_content, template_id, int_profile_id, user_id, workspace_id
+        FROM post
+        WHERE deleted_at IS NULL AND workspace_id = ? AND post_id = ?`,
+		CLONE_NAME_SUFFIX, workspaceId, postId,
+	)
+
+	if insertErr != nil {
+		return clonedPostId, fmt.Errorf("models.posts.clone: %s", insertErr.Error())
+	}
+
+	rowsAffected, rowsErr := insertRes.RowsAffected()
+
+	if rowsErr != nil {
+		return clonedPostId, fmt.Errorf("models.posts.clone: %s", rowsErr.Error())
+	}
+
+	if rowsAffected == 0 {
+		return clonedPostId, fmt.Errorf("models.posts.clone: post with id %d not found", postId)
+	}
+
+	id, exception := insertRes.LastInsertId()
+
+	if exception != nil {
+		return clonedPostId, fmt.Errorf("models.posts.clone: %s", exception.Error())
+	}
+
+	clonedPostId = int(id)
+
+	return clonedPostId, nil
+}
+
+func (p *Posts) ById(ctx context.Context, postId int, workspaceId int) (PostByIdData, error) {
 	var post PostByIdData
 
-	rows, rowsErr := p.db.Query(
-		`SELECT post_id
+	rows, rowsErr := p.db.QueryContext(
+		ctx,
+		`SELECT post_id, int_profile_id, post_review_status, post_version
         FROM post
-        WHERE deleted_at IS NULL AND user_id = ? AND post_id = ?`,
-		userId, postId,
+        WHERE deleted_at IS NULL AND workspace_id = ? AND post_id = ?`,
+		workspaceId, postId,
 	)
 
 	if rowsErr != nil {
@@ -253,6 +319,9 @@ func (p *Posts) ById(postId int, userId int) (PostByIdData, error) {
 	for rows.Next() {
 		exception := rows.Scan(
 			&post.PostId,
+			&post.IntProfileId,
+			&post.PostReviewStatus,
+			&post.PostVersion,
 		)
 
 		if exception != nil {
@@ -1,6 +1,7 @@
 package model
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 )
@@ -13,6 +14,16 @@ const (
 	PublicationStatusPublished PublicationStatus = "published"
 )
 
+func StatusFromCount(statusCount map[PublicationStatus]int) PublicationStatus {
+	if statusCount[PublicationStatusFailed] > 0 {
+		return PublicationStatusFailed
+	} else if statusCount[PublicationStatusPending] > 0 {
+		return PublicationStatusPending
+	}
+
+	return PublicationStatusPublished
+}
+
 type PublicationStatusCount struct {
 	Total  int
 	Status PublicationStatus
@@ -28,10 +39,11 @@ func NewPublication(db *sql.DB) *Publication {
 	return &publication
 }
 
-func (p *Publication) CountByPost(postId int) (map[PublicationStatus]int, error) {
+func (p *Publication) CountByPost(ctx context.Context, postId int) (map[PublicationStatus]int, error) {
 	posts := map[PublicationStatus]int{}
 
-	rows, rowsErr := p.db.Query(
+	rows, rowsErr := p.db.QueryContext(
+		ctx,
 		`SELECT COUNT(*) total, publication.publication_status status
         FROM publication
         WHERE publication.post_id = ?
@@ -67,3 +79,59 @@ func (p *Publication) CountByPost(postId int) (map[PublicationStatus]int, error)
 
 	return posts, nil
 }
+
+// CountByStatus counts the posts of all workspaces by their publication
+// status, as given by StatusFromCount.
+func (p *Publication) CountByStatus(ctx context.Context) (map[PublicationStatus]int, error) {
+	posts := map[PublicationStatus]int{}
+
+	rows, rowsErr := p.db.QueryContext(
+		ctx,
+		`SELECT post.post_id, COUNT(publication.post_id) total, COALESCE(publication.publication_status, '') status
+        FROM post
+        LEFT JOIN publication ON publication.post_id = post.post_id
+        WHERE post.deleted_at IS NULL
+        GROUP BY post.post_id, publication.publication_status`,
+	)
+
+	if rowsErr != nil {
+		return nil, fmt.Errorf("models.publication.countByStatus: %s", rowsErr.Error())
+	}
+
+	defer rows.Close()
+
+	statusCountByPost := map[int]map[PublicationStatus]int{}
+
+	for rows.Next() {
+		var postId int
+		var post PublicationStatusCount
+
+		exception := rows.Scan(
+			&postId,
+			&post.Total,
+			&post.Status,
+		)
+
+		if exception != nil {
+			return nil, fmt.Errorf("models.publication.countByStatus: %s", exception.Error())
+		}
+
+		if statusCountByPost[postId] == nil {
+			statusCountByPost[postId] = map[PublicationStatus]int{}
+		}
+
+		statusCountByPost[postId][post.Status] = post.Total
+	}
+
+	rowsErr = rows.Err()
+
+	if rowsErr != nil {
+		return nil, fmt.Errorf("models.publication.countByStatus: %s", rowsErr.Error())
+	}
+
+	for _, statusCount := range statusCountByPost {
+		posts[StatusFromCount(statusCount)]++
+	}
+
+	return posts, nil
+}
@@ -0,0 +1,160 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+type PublishUsage struct {
+	db *sql.DB
+}
+
+type PublishUsageCount struct {
+	UserCount       int `json:"user_count"`
+	IntProfileCount int `json:"int_profile_count"`
+}
+
+type PublishUsageAddData struct {
+	UserId       int `json:"user_id"`
+	IntProfileId int `json:"int_profile_id"`
+	PostId       int `json:"post_id"`
+}
+
+func NewPublishUsage(db *sql.DB) *PublishUsage {
+	publishUsage := PublishUsage{db: db}
+
+	return &publishUsage
+}
+
+// Count returns how many publishes the user and the integration profile had
+// since the given `YYYY-MM-DD HH:MM:SS` time.
+func (pu *PublishUsage) Count(ctx context.Context, userId int, intProfileId int, since string) (PublishUsageCount, error) {
+	var count PublishUsageCount
+
+	exception := pu.db.QueryRowContext(
+		ctx,
+		`SELECT
+            COALESCE(SUM(user_id = ?), 0) user_count,
+            COALESCE(SUM(int_profile_id = ?), 0) int_profile_count
+        FROM publish_usage
+        WHERE (user_id = ? OR int_profile_id = ?) AND created_at >= ?`,
+		userId, intProfileId, userId, intProfileId, since,
+	).Scan(&count.UserCount, &count.IntProfileCount)
+
+	if exception != nil {
+		return count, fmt.Errorf("models.publish_usage.count: %s", exception.Error())
+	}
+
+	return count, nil
+}
+
+func (pu *PublishUsage) Add(ctx context.Context, usage PublishUsageAddData) (int, error) {
+	var publishUsageId int
+
+	insertRes, insertErr := pu.db.ExecContext(
+		ctx,
+		`INSERT INTO publish_usage (user_id, int_profile_id, post_id) VALUES (?, ?, ?)`,
+		usage.UserId, usage.IntProfileId, usage.PostId,
+	)
+
+	if insertErr != nil {
+		return publishUsageId, fmt.Errorf("models.publish_usage.add: %s", insertErr.Error())
+	}
+
+	id, exception := insertRes.LastInsertId()
+
+	if exception != nil {
+		return publishUsageId, fmt.Errorf("models.publish_usage.add: %s", exception.Error())
+	}
+
+	publishUsageId = int(id)
+
+	return publishUsageId, nil
+}
+
+// Reserve records the publish when the user and the integration profile are
+// under their limits since the given time, 0 being unlimited. The rows of both
+// are locked first, so concurrent publishes are counted one after the other.
+// When a limit is reached, it returns 0 with the current count.
+func (pu *PublishUsage) Reserve(ctx context.Context, usage PublishUsageAddData, since string, userLimit int, intProfileLimit int) (int, PublishUsageCount, error) {
+	var publishUsageId int
+	var count PublishUsageCount
+
+	txErr := WithTx(ctx, pu.db, func(tx *sql.Tx) error {
+		for _, lock := range []struct {
+			query string
+			id    int
+		}{
+			{`SELECT user_id FROM user WHERE user_id = ? FOR UPDATE`, usage.UserId},
+			{`SELECT int_profile_id FROM integration_profile WHERE int_profile_id = ? FOR UPDATE`, usage.IntProfileId},
+		} {
+			var lockedId int
+
+			lockErr := tx.QueryRowContext(ctx, lock.query, lock.id).Scan(&lockedId)
+
+			if lockErr != nil && lockErr != sql.ErrNoRows {
+				return lockErr
+			}
+		}
+
+		countErr := tx.QueryRowContext(
+			ctx,
+			`SELECT
+                COALESCE(SUM(user_id = ?), 0) user_count,
+                COALESCE(SUM(int_profile_id = ?), 0) int_profile_count
+            FROM publish_usage
+            WHERE (user_id = ? OR int_profile_id = ?) AND created_at >= ?`,
+			usage.UserId, usage.IntProfileId, usage.UserId, usage.IntProfileId, since,
+		).Scan(&count.UserCount, &count.IntProfileCount)
+
+		if countErr != nil {
+			return countErr
+		}
+
+		if (userLimit > 0 && count.UserCount >= userLimit) || (intProfileLimit > 0 && count.IntProfileCount >= intProfileLimit) {
+			return nil
+		}
+
+		insertRes, insertErr := tx.ExecContext(
+			ctx,
+			`INSERT INTO publish_usage (user_id, int_profile_id, post_id) VALUES (?, ?, ?)`,
+			usage.UserId, usage.IntProfileId, usage.PostId,
+		)
+
+		if insertErr != nil {
+			return insertErr
+		}
+
+		id, idErr := insertRes.LastInsertId()
+
+		if idErr != nil {
+			return idErr
+		}
+
+		publishUsageId = int(id)
+
+		return nil
+	})
+
+	if txErr != nil {
+		return 0, count, fmt.Errorf("models.publish_usage.reserve: %s", txErr.Error())
+	}
+
+	return publishUsageId, count, nil
+}
+
+// Release removes a reserved publish that did not happen.
+func (pu *PublishUsage) Release(ctx context.Context, publishUsageId int) error {
+	_, deleteErr := pu.db.ExecContext(
+		ctx,
+		`DELETE FROM publish_usage WHERE publish_usage_id = ?`,
+		publishUsageId,
+	)
+
+	if deleteErr != nil {
+		return fmt.Errorf("models.publish_usage.release: %s", deleteErr.Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,137 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+type AboutRepository interface {
+	Ping(ctx context.Context) bool
+}
+
+type ColorsRepository interface {
+	List(ctx context.Context, id int) ([]ColorsList, error)
+}
+
+type PublicationRepository interface {
+	CountByPost(ctx context.Context, postId int) (map[PublicationStatus]int, error)
+	CountByStatus(ctx context.Context) (map[PublicationStatus]int, error)
+}
+
+type PostsRepository interface {
+	List(ctx context.Context, id string, includeContent bool, workspaceId int) ([]PostsList, error)
+	ById(ctx context.Context, postId int, workspaceId int) (PostByIdData, error)
+	Add(ctx context.Context, post PostAddData, userId int) (int, error)
+	Update(ctx context.Context, post PostUpdateData, workspaceId int) (int, error)
+	Delete(ctx context.Context, postId int, postVersion int, workspaceId int) (int, error)
+	Clone(ctx context.Context, postId int, workspaceId int) (int, error)
+}
+
+type PostReviewsRepository interface {
+	List(ctx context.Context, postId int, workspaceId int) ([]PostReviewsList, error)
+	Submit(ctx context.Context, review PostReviewData, userId int, workspaceId int) (int, error)
+	Decide(ctx context.Context, review PostReviewData, userId int, workspaceId int) (int, error)
+}
+
+type TemplatesRepository interface {
+	BasicList(ctx context.Context, workspaceId int) ([]TemplatesBasicList, error)
+	List(ctx context.Context, id string, includeContent bool, workspaceId int) ([]TemplatesList, error)
+	ById(ctx context.Context, templateId int, workspaceId int) (TemplatesByIdData, error)
+	Add(ctx context.Context, template TemplateAddData) (int, error)
+	Update(ctx context.Context, template TemplateUpdateData, workspaceId int) (int, error)
+	Delete(ctx context.Context, templateId int, templateVersion int, workspaceId int) (int, error)
+	Clone(ctx context.Context, templateId int, workspaceId int) (int, error)
+}
+
+type IntProfilesRepository interface {
+	BasicList(ctx context.Context, workspaceId int) ([]IntProfilesBasicList, error)
+	List(ctx context.Context, id string, workspaceId int) ([]IntProfileList, error)
+	ById(ctx context.Context, intProfileId int, workspaceId int) (IntProfilesByIdData, error)
+	Add(ctx context.Context, intProfile IntProfileAddData, intCredentials []int, userId int) (int, error)
+	Update(ctx context.Context, intProfile IntProfileUpdateData, intCredentials []int, workspaceId int) (int, error)
+	Delete(ctx context.Context, intProfileId int, intProfileVersion int, workspaceId int) (int, error)
+	Clone(ctx context.Context, intProfileId int, workspaceId int) (int, error)
+}
+
+type IntCredentialsRepository interface {
+	BasicList(ctx context.Context, workspaceId int) ([]IntCredentialsBasicList, error)
+	BasicListByProfile(ctx context.Context, profileId int, workspaceId int) ([]IntCredentialsBasicList, error)
+	List(ctx context.Context, id string, includeConfig bool, workspaceId int) ([]IntCredentialList, error)
+	Add(ctx context.Context, intCredential IntCredentialAddData, userId int) (int, error)
+	Update(ctx context.Context, intCredential IntCredentialUpdateData, workspaceId int) (int, error)
+	Delete(ctx context.Context, intCredentialId int, intCredentialVersion int, workspaceId int) (int, error)
+}
+
+type ApiKeysRepository interface {
+	List(ctx context.Context, userId int) ([]ApiKeysList, error)
+	Add(ctx context.Context, apiKey ApiKeyAddData, userId int) (int, error)
+	Revoke(ctx context.Context, apiKeyId int, userId int) (int, error)
+	ByHash(ctx context.Context, apiKeyHash string) (ApiKeyOwnerData, error)
+	Touch(ctx context.Context, apiKeyId int) error
+}
+
+type WorkspacesRepository interface {
+	List(ctx context.Context, userId int) ([]WorkspacesList, error)
+	Add(ctx context.Context, workspace WorkspaceAddData, userId int) (int, error)
+	Personal(ctx context.Context, userId int) (int, error)
+	Role(ctx context.Context, workspaceId int, userId int) (WorkspaceRole, error)
+	Members(ctx context.Context, workspaceId int) ([]WorkspaceMembersList, error)
+	AddMember(ctx context.Context, workspaceId int, userId int, role WorkspaceRole) (int, error)
+	UpdateMember(ctx context.Context, workspaceId int, userId int, role WorkspaceRole) (int, error)
+	RemoveMember(ctx context.Context, workspaceId int, userId int) (int, error)
+}
+
+type AuditLogsRepository interface {
+	List(ctx context.Context, filter AuditLogFilter, workspaceId int, userId int) ([]AuditLogsList, error)
+	Add(ctx context.Context, auditLog AuditLogAddData) (int, error)
+}
+
+type PublishUsageRepository interface {
+	Count(ctx context.Context, userId int, intProfileId int, since string) (PublishUsageCount, error)
+	Add(ctx context.Context, usage PublishUsageAddData) (int, error)
+	Reserve(ctx context.Context, usage PublishUsageAddData, since string, userLimit int, intProfileLimit int) (int, PublishUsageCount, error)
+	Release(ctx context.Context, publishUsageId int) error
+}
+
+type IdempotencyKeysRepository interface {
+	Reserve(ctx context.Context, key IdempotencyKeyData, ttl time.Duration) (IdempotencyKeyData, bool, error)
+	Complete(ctx context.Context, key IdempotencyKeyData) error
+	Release(ctx context.Context, userId int, idempotencyKey string) error
+}
+
+type Repositories struct {
+	About           AboutRepository
+	Colors          ColorsRepository
+	Publication     PublicationRepository
+	Posts           PostsRepository
+	PostReviews     PostReviewsRepository
+	Templates       TemplatesRepository
+	IntProfiles     IntProfilesRepository
+	IntCredentials  IntCredentialsRepository
+	ApiKeys         ApiKeysRepository
+	Workspaces      WorkspacesRepository
+	AuditLogs       AuditLogsRepository
+	PublishUsage    PublishUsageRepository
+	IdempotencyKeys IdempotencyKeysRepository
+}
+
+func NewRepositories(db *sql.DB) *Repositories {
+	repositories := Repositories{
+		About:           NewAbout(db),
+		Colors:          NewColors(db),
+		Publication:     NewPublication(db),
+		Posts:           NewPosts(db),
+		PostReviews:     NewPostReviews(db),
+		Templates:       NewTemplates(db),
+		IntProfiles:     NewIntProfiles(db),
+		IntCredentials:  NewIntCredentials(db),
+		ApiKeys:         NewApiKeys(db),
+		Workspaces:      NewWorkspaces(db),
+		AuditLogs:       NewAuditLogs(db),
+		PublishUsage:    NewPublishUsage(db),
+		IdempotencyKeys: NewIdempotencyKeys(db),
+	}
+
+	return &repositories
+}
@@ -18,7 +18,8 @@ type TemplatesBasicList struct {
 }
 
 type TemplatesByIdData struct {
-	TemplateId int `json:"template_id"`
+	TemplateId      int `json:"template_id"`
+	TemplateVersion int `json:"template_version"`
 }
 
 type TemplatesList struct {
@@ -26,6 +27,7 @@ type TemplatesList struct {
 	TemplateName      string `json:"template_name"`
 	TemplateContent   string `json:"template_content"`
 	TemplateUrlImport string `json:"template_url_import"`
+	TemplateVersion   int    `json:"template_version"`
 	CreatedAt         string `json:"created_at"`
 }
 
@@ -34,6 +36,7 @@ type TemplateAddData struct {
 	TemplateContent   string `json:"template_content"`
 	TemplateUrlImport string `json:"template_url_import"`
 	UserId            int    `json:"user_id"`
+	WorkspaceId       int    `json:"workspace_id"`
 	CreatedAt         string `json:"created_at"`
 }
 
@@ -46,6 +49,7 @@ type TemplateUpdateData struct {
 	TemplateName      string `json:"template_name"`
 	TemplateContent   string `json:"template_content"`
 	TemplateUrlImport string `json:"template_url_import"`
+	TemplateVersion   int    `json:"template_version"`
 	UpdatedAt         string `json:"updated_at"`
 }
 
@@ -55,14 +59,15 @@ func NewTemplates(db *sql.DB) *Templates {
 	return &templates
 }
 
-func (t *Templates) BasicList(userId int) ([]TemplatesBasicList, error) {
+func (t *Templates) BasicList(ctx context.Context, workspaceId int) ([]TemplatesBasicList, error) {
 	var templates []TemplatesBasicList
 
-	rows, rowsErr := t.db.Query(
+	rows, rowsErr := t.db.QueryContext(
+		ctx,
 		`SELECT template_id, template_name
         FROM template
-        WHERE deleted_at IS NULL AND user_id = ?
-        ORDER BY template_name`, userId,
+        WHERE deleted_at IS NULL AND workspace_id = ?
+        ORDER BY template_name`, workspaceId,
 	)
 
 	if rowsErr != nil {
@@ -96,14 +101,15 @@ func (t *Templates) BasicList(userId int) ([]TemplatesBasicList, error) {
 	return templates, nil
 }
 
-func (t *Templates) ById(templateId int, userId int) (TemplatesByIdData, error) {
+func (t *Templates) ById(ctx context.Context, templateId int, workspaceId int) (TemplatesByIdData, error) {
 	var template TemplatesByIdData
 
-	rows, rowsErr := t.db.Query(
-		`SELECT template_id
+	rows, rowsErr := t.db.QueryContext(
+		ctx,
+		`SELECT template_id, template_version
         FROM template
-        WHERE deleted_at IS NULL AND user_id = ? AND template_id = ?`,
-		userId, templateId,
+        WHERE deleted_at IS NULL AND workspace_id = ? AND template_id = ?`,
+		workspaceId, templateId,
 	)
 
 	if rowsErr != nil {
@@ -121,6 +127,7 @@ func (t *Templates) ById(templateId int, userId int) (TemplatesByIdData, error)
 	for rows.Next() {
 		exception := rows.Scan(
 			&template.TemplateId,
+			&template.TemplateVersion,
 		)
 
 		if exception != nil {
@@ -131,15 +138,15 @@ func (t *Templates) ById(templateId int, userId int) (TemplatesByIdData, error)
 	return template, nil
 }
 
-func (t *Templates) List(id string, includeContent bool, userId int) ([]TemplatesList, error) {
+func (t *Templates) List(ctx context.Context, id string, includeContent bool, workspaceId int) ([]TemplatesList, error) {
 	var templates []TemplatesList
 
 	whereList := []string{}
 	whereValues := []any{}
 	columnsList := []string{}
 
-	whereList = append(whereList, "user_id = ?")
-	whereValues = append(whereValues, userId)
+	whereList = append(whereList, "workspace_id = ?")
+	whereValues = append(whereValues, workspaceId)
 
 	if id != "" {
 		whereList = append(whereList, "template_id = ?")
@@ -161,9 +168,10 @@ func (t *Templates) List(id string, includeContent bool, userId int) ([]Template
 		columns = ", " + strings.Join(columnsList, ", ")
 	}
 
-	rows, rowsErr := t.db.Query(
+	rows, rowsErr := t.db.QueryContext(
+		ctx,
 		`SELECT template_id, template_name,
-            template_url_import, created_at `+columns+`
+            template_url_import, template_version, created_at `+columns+`
         FROM template
         WHERE deleted_at IS NULL `+where, whereValues...,
 	)
@@ -188,6 +196,7 @@ func (t *Templates) List(id string, includeContent bool, userId int) ([]Template
 			&template.TemplateId,
 			&template.TemplateName,
 			&templateUrlImport,
+			&template.TemplateVersion,
 			&template.CreatedAt,
 			&template.TemplateContent,
 		)
@@ -205,14 +214,14 @@ func (t *Templates) List(id string, includeContent bool, userId int) ([]Template
 	return templates, nil
 }
 
-func (t *Templates) Add(template TemplateAddData) (int, error) {
+func (t *Templates) Add(ctx context.Context, template TemplateAddData) (int, error) {
 	var templateId int
 
 	insertRes, insertErr := t.db.ExecContext(
-		context.Background(),
-		`INSERT INTO synk.template (template_name, template_content, template_url_import, user_id)
-        VALUES (?, ?, ?, ?)`,
-		template.TemplateName, template.TemplateContent, template.TemplateUrlImport, template.UserId,
+		ctx,
+		`INSERT INTO template (template_name, template_content, template_url_import, user_id, workspace_id)
+        VALUES (?, ?, ?, ?, ?)`,
+		template.TemplateName, template.TemplateContent, template.TemplateUrlImport, template.UserId, template.WorkspaceId,
 	)
 
 	if insertErr != nil {
@@ -230,20 +239,31 @@ func (t *Templates) Add(template TemplateAddData) (int, error) {
 	return templateId, nil
 }
 
-func (t *Templates) Update(template TemplateUpdateData, userId int) (int, error) {
+// Update bumps the template version. A TemplateVersion other than 0 must
+// match the stored one, or no row is affected.
+func (t *Templates) Update(ctx context.Context, template TemplateUpdateData, workspaceId int) (int, error) {
 	var rowsAffected int64
 
+	whereValues := []any{template.TemplateName, template.TemplateContent, template.TemplateUrlImport, workspaceId, template.TemplateId}
+	versionWhere := ""
+
+	if template.TemplateVersion != 0 {
+		versionWhere = " AND template_version = ?"
+		whereValues = append(whereValues, template.TemplateVersion)
+	}
+
 	updateRes, updateErr := t.db.ExecContext(
-		context.Background(),
+		ctx,
 		`UPDATE template
         SET template_name = ?,
             template_content = ?,
             template_url_import = ?,
+            template_version = template_version + 1,
             updated_at = CURRENT_TIMESTAMP
         WHERE deleted_at IS NULL AND
-            user_id = ? AND
-            template_id = ?`,
-		template.TemplateName, template.TemplateContent, template.TemplateUrlImport, userId, template.TemplateId,
+            workspace_id = ? AND
+            template_id = ?`+versionWhere,
+		whereValues...,
 	)
 
 	if updateErr != nil {
@@ -261,16 +281,27 @@ func (t *Templates) Update(template TemplateUpdateData, userId int) (int, error)
 	return int(rowsAffected), nil
 }
 
-func (t *Templates) Delete(templateId int, userId int) (int, error) {
+// Delete soft deletes the template. A version other than 0 must match the
+// stored one, or no row is affected.
+func (t *Templates) Delete(ctx context.Context, templateId int, templateVersion int, workspaceId int) (int, error) {
 	var rowsAffected int64
 
+	whereValues := []any{workspaceId, templateId}
+	versionWhere := ""
+
+	if templateVersion != 0 {
+		versionWhere = " AND template_version = ?"
+		whereValues = append(whereValues, templateVersion)
+	}
+
 	insertRes, insertErr := t.db.ExecContext(
-		context.Background(),
+		ctx,
 		`UPDATE template
         SET deleted_at = CURRENT_TIMESTAMP
         WHERE deleted_at IS NULL AND
-            user_id = ? AND
-            template_id = ?`, userId, templateId,
+            workspace_id = ? AND
+            template_id = ?`+versionWhere,
+		whereValues...,
 	)
 
 	if insertErr != nil {
@@ -287,3 +318,42 @@ func (t *Templates) Delete(templateId int, userId int) (int, error) {
 
 	return int(rowsAffected), nil
 }
+
+func (t *Templates) Clone(ctx context.Context, templateId int, workspaceId int) (int, error) {
+	var clonedTemplateId int
+
+	insertRes, insertErr := t.db.ExecContext(
+		ctx,
+		`INSERT INTO template (template_name, template_content, template_url_import, user_id, workspace_id)
+        SELECT CONCAT(template_name, ?), template_content, template_url_import, user_id, workspace_id
+        FROM template
+        WHERE deleted_at IS NULL AND
+            workspace_id = ? AND
+            template_id = ?`,
+		CLONE_NAME_SUFFIX, workspaceId, templateId,
+	)
+
+	if insertErr != nil {
+		return clonedTemplateId, fmt.Errorf("models.templates.clone: %s", insertErr.Error())
+	}
+
+	rowsAffected, rowsErr := insertRes.RowsAffected()
+
+	if rowsErr != nil {
+		return clonedTemplateId, fmt.Errorf("models.templates.clone: %s", rowsErr.Error())
+	}
+
+	if rowsAffected == 0 {
+		return clonedTemplateId, fmt.Errorf("models.templates.clone: template with id %d not found", templateId)
+	}
+
+	id, exception := insertRes.LastInsertId()
+
+	if exception != nil {
+		return clonedTemplateId, fmt.Errorf("models.templates.clone: %s", exception.Error())
+	}
+
+	clonedTemplateId = int(id)
+
+	return clonedTemplateId, nil
+}
@@ -0,0 +1,43 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+func WithTx(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, beginErr := db.BeginTx(ctx, nil)
+
+	if beginErr != nil {
+		return fmt.Errorf("models.tx.begin: %s", beginErr.Error())
+	}
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			tx.Rollback()
+
+			panic(recovered)
+		}
+	}()
+
+	fnErr := fn(tx)
+
+	if fnErr != nil {
+		rollbackErr := tx.Rollback()
+
+		if rollbackErr != nil {
+			return fmt.Errorf("%s (models.tx.rollback: %s)", fnErr.Error(), rollbackErr.Error())
+		}
+
+		return fnErr
+	}
+
+	commitErr := tx.Commit()
+
+	if commitErr != nil {
+		return fmt.Errorf("models.tx.commit: %s", commitErr.Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,372 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"synk/gateway/app/util"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+const MYSQL_ERR_DUPLICATE_ENTRY = 1062
+const MYSQL_ERR_NO_REFERENCED_ROW = 1452
+
+// AddMember wraps these for a user already in the workspace and for a user
+// that doesn't exist.
+var ErrMemberExists = errors.New("user is already a member of the workspace")
+var ErrUserNotFound = errors.New("user not found")
+
+type WorkspaceRole string
+
+const (
+	WorkspaceRoleOwner  WorkspaceRole = "owner"
+	WorkspaceRoleEditor WorkspaceRole = "editor"
+	WorkspaceRoleViewer WorkspaceRole = "viewer"
+)
+
+var workspaceRoleRank = map[WorkspaceRole]int{
+	WorkspaceRoleViewer: 1,
+	WorkspaceRoleEditor: 2,
+	WorkspaceRoleOwner:  3,
+}
+
+func (wr WorkspaceRole) IsValid() bool {
+	_, ok := workspaceRoleRank[wr]
+
+	return ok
+}
+
+// Allows reports whether the role grants at least the required one, as
+// owner > editor > viewer.
+func (wr WorkspaceRole) Allows(required WorkspaceRole) bool {
+	return wr.IsValid() && workspaceRoleRank[wr] >= workspaceRoleRank[required]
+}
+
+type Workspaces struct {
+	db *sql.DB
+}
+
+type WorkspacesList struct {
+	WorkspaceId   int           `json:"workspace_id"`
+	WorkspaceName string        `json:"workspace_name"`
+	WorkspaceRole WorkspaceRole `json:"workspace_role"`
+	Personal      bool          `json:"personal"`
+	CreatedAt     string        `json:"created_at"`
+}
+
+type WorkspaceAddData struct {
+	WorkspaceName string `json:"workspace_name"`
+}
+
+type WorkspaceMembersList struct {
+	UserId        int           `json:"user_id"`
+	UserName      string        `json:"user_name"`
+	WorkspaceRole WorkspaceRole `json:"workspace_role"`
+	CreatedAt     string        `json:"created_at"`
+}
+
+func NewWorkspaces(db *sql.DB) *Workspaces {
+	workspaces := Workspaces{db: db}
+
+	return &workspaces
+}
+
+func (ws *Workspaces) List(ctx context.Context, userId int) ([]WorkspacesList, error) {
+	var workspaces []WorkspacesList
+
+	rows, rowsErr := ws.db.QueryContext(
+		ctx,
+		`SELECT workspace.workspace_id, workspace.workspace_name, member.workspace_role,
+            workspace.personal_user_id IS NOT NULL personal, workspace.created_at
+        FROM workspace
+        JOIN workspace_member member ON member.workspace_id = workspace.workspace_id
+        WHERE workspace.deleted_at IS NULL AND member.user_id = ?
+        ORDER BY personal DESC, workspace.workspace_name`, userId,
+	)
+
+	if rowsErr != nil {
+		return nil, fmt.Errorf("models.workspaces.list: %s", rowsErr.Error())
+	}
+
+	defer rows.Close()
+
+	rowsErr = rows.Err()
+
+	if rowsErr != nil {
+		return nil, fmt.Errorf("models.workspaces.list: %s", rowsErr.Error())
+	}
+
+	for rows.Next() {
+		var workspace WorkspacesList
+
+		exception := rows.Scan(
+			&workspace.WorkspaceId,
+			&workspace.WorkspaceName,
+			&workspace.WorkspaceRole,
+			&workspace.Personal,
+			&workspace.CreatedAt,
+		)
+
+		if exception != nil {
+			return nil, fmt.Errorf("models.workspaces.list: %s", exception.Error())
+		}
+
+		workspace.CreatedAt = util.ToTimeBR(workspace.CreatedAt)
+
+		workspaces = append(workspaces, workspace)
+	}
+
+	return workspaces, nil
+}
+
+func (ws *Workspaces) Add(ctx context.Context, workspace WorkspaceAddData, userId int) (int, error) {
+	var workspaceId int
+
+	txErr := WithTx(ctx, ws.db, func(tx *sql.Tx) error {
+		insertRes, insertErr := tx.ExecContext(
+			ctx,
+			`INSERT INTO workspace (workspace_name) VALUES (?)`, workspace.WorkspaceName,
+		)
+
+		if insertErr != nil {
+			return insertErr
+		}
+
+		id, exception := insertRes.LastInsertId()
+
+		if exception != nil {
+			return exception
+		}
+
+		workspaceId = int(id)
+
+		_, memberErr := tx.ExecContext(
+			ctx,
+			`INSERT INTO workspace_member (workspace_id, user_id, workspace_role) VALUES (?, ?, ?)`,
+			workspaceId, userId, WorkspaceRoleOwner,
+		)
+
+		return memberErr
+	})
+
+	if txErr != nil {
+		return 0, fmt.Errorf("models.workspaces.add: %s", txErr.Error())
+	}
+
+	return workspaceId, nil
+}
+
+// Personal returns the personal workspace of the user, creating it on first
+// use for users registered after the workspace migration.
+func (ws *Workspaces) Personal(ctx context.Context, userId int) (int, error) {
+	var workspaceId int
+
+	selectErr := ws.db.QueryRowContext(
+		ctx,
+		`SELECT workspace.workspace_id
+        FROM workspace
+        JOIN workspace_member member ON member.workspace_id = workspace.workspace_id AND member.user_id = workspace.personal_user_id
+        WHERE workspace.personal_user_id = ?`, userId,
+	).Scan(&workspaceId)
+
+	if selectErr == nil {
+		return workspaceId, nil
+	}
+
+	if selectErr != sql.ErrNoRows {
+		return 0, fmt.Errorf("models.workspaces.personal: %s", selectErr.Error())
+	}
+
+	txErr := WithTx(ctx, ws.db, func(tx *sql.Tx) error {
+		_, insertErr := tx.ExecContext(
+			ctx,
+			`INSERT IGNORE INTO workspace (workspace_name, personal_user_id)
+            SELECT user_name, user_id FROM user WHERE user_id = ?`, userId,
+		)
+
+		if insertErr != nil {
+			return insertErr
+		}
+
+		selectErr := tx.QueryRowContext(
+			ctx,
+			`SELECT workspace_id FROM workspace WHERE personal_user_id = ?`, userId,
+		).Scan(&workspaceId)
+
+		if selectErr != nil {
+			return selectErr
+		}
+
+		_, memberErr := tx.ExecContext(
+			ctx,
+			`INSERT IGNORE INTO workspace_member (workspace_id, user_id, workspace_role) VALUES (?, ?, ?)`,
+			workspaceId, userId, WorkspaceRoleOwner,
+		)
+
+		return memberErr
+	})
+
+	if txErr != nil {
+		return 0, fmt.Errorf("models.workspaces.personal: %s", txErr.Error())
+	}
+
+	return workspaceId, nil
+}
+
+func (ws *Workspaces) Role(ctx context.Context, workspaceId int, userId int) (WorkspaceRole, error) {
+	var role WorkspaceRole
+
+	exception := ws.db.QueryRowContext(
+		ctx,
+		`SELECT member.workspace_role
+        FROM workspace_member member
+        JOIN workspace ON workspace.workspace_id = member.workspace_id
+        WHERE workspace.deleted_at IS NULL AND member.workspace_id = ? AND member.user_id = ?`,
+		workspaceId, userId,
+	).Scan(&role)
+
+	if exception == sql.ErrNoRows {
+		return role, nil
+	}
+
+	if exception != nil {
+		return role, fmt.Errorf("models.workspaces.role: %s", exception.Error())
+	}
+
+	return role, nil
+}
+
+func (ws *Workspaces) Members(ctx context.Context, workspaceId int) ([]WorkspaceMembersList, error) {
+	var members []WorkspaceMembersList
+
+	rows, rowsErr := ws.db.QueryContext(
+		ctx,
+		`SELECT member.user_id, user.user_name, member.workspace_role, member.created_at
+        FROM workspace_member member
+        LEFT JOIN user ON user.user_id = member.user_id
+        WHERE member.workspace_id = ?
+        ORDER BY member.created_at`, workspaceId,
+	)
+
+	if rowsErr != nil {
+		return nil, fmt.Errorf("models.workspaces.members: %s", rowsErr.Error())
+	}
+
+	defer rows.Close()
+
+	rowsErr = rows.Err()
+
+	if rowsErr != nil {
+		return nil, fmt.Errorf("models.workspaces.members: %s", rowsErr.Error())
+	}
+
+	for rows.Next() {
+		var member WorkspaceMembersList
+		var userName sql.NullString
+
+		exception := rows.Scan(
+			&member.UserId,
+			&userName,
+			&member.WorkspaceRole,
+			&member.CreatedAt,
+		)
+
+		if exception != nil {
+			return nil, fmt.Errorf("models.workspaces.members: %s", exception.Error())
+		}
+
+		member.UserName = userName.String
+		member.CreatedAt = util.ToTimeBR(member.CreatedAt)
+
+		members = append(members, member)
+	}
+
+	return members, nil
+}
+
+func (ws *Workspaces) AddMember(ctx context.Context, workspaceId int, userId int, role WorkspaceRole) (int, error) {
+	var rowsAffected int64
+
+	insertRes, insertErr := ws.db.ExecContext(
+		ctx,
+		`INSERT INTO workspace_member (workspace_id, user_id, workspace_role) VALUES (?, ?, ?)`,
+		workspaceId, userId, role,
+	)
+
+	var mysqlErr *mysql.MySQLError
+
+	if errors.As(insertErr, &mysqlErr) && mysqlErr.Number == MYSQL_ERR_DUPLICATE_ENTRY {
+		return int(rowsAffected), fmt.Errorf("models.workspaces.add_member: %w", ErrMemberExists)
+	}
+
+	if errors.As(insertErr, &mysqlErr) && mysqlErr.Number == MYSQL_ERR_NO_REFERENCED_ROW {
+		return int(rowsAffected), fmt.Errorf("models.workspaces.add_member: %w", ErrUserNotFound)
+	}
+
+	if insertErr != nil {
+		return int(rowsAffected), fmt.Errorf("models.workspaces.add_member: %s", insertErr.Error())
+	}
+
+	rowsAffectedVal, exception := insertRes.RowsAffected()
+
+	if exception != nil {
+		return int(rowsAffected), fmt.Errorf("models.workspaces.add_member: %s", exception.Error())
+	}
+
+	rowsAffected = rowsAffectedVal
+
+	return int(rowsAffected), nil
+}
+
+func (ws *Workspaces) UpdateMember(ctx context.Context, workspaceId int, userId int, role WorkspaceRole) (int, error) {
+	var rowsAffected int64
+
+	updateRes, updateErr := ws.db.ExecContext(
+		ctx,
+		`UPDATE workspace_member
+        SET workspace_role = ?,
+            updated_at = CURRENT_TIMESTAMP
+        WHERE workspace_id = ? AND user_id = ?`,
+		role, workspaceId, userId,
+	)
+
+	if updateErr != nil {
+		return int(rowsAffected), fmt.Errorf("models.workspaces.update_member: %s", updateErr.Error())
+	}
+
+	rowsAffectedVal, exception := updateRes.RowsAffected()
+
+	if exception != nil {
+		return int(rowsAffected), fmt.Errorf("models.workspaces.update_member: %s", exception.Error())
+	}
+
+	rowsAffected = rowsAffectedVal
+
+	return int(rowsAffected), nil
+}
+
+func (ws *Workspaces) RemoveMember(ctx context.Context, workspaceId int, userId int) (int, error) {
+	var rowsAffected int64
+
+	deleteRes, deleteErr := ws.db.ExecContext(
+		ctx,
+		`DELETE FROM workspace_member WHERE workspace_id = ? AND user_id = ?`,
+		workspaceId, userId,
+	)
+
+	if deleteErr != nil {
+		return int(rowsAffected), fmt.Errorf("models.workspaces.remove_member: %s", deleteErr.Error())
+	}
+
+	rowsAffectedVal, exception := deleteRes.RowsAffected()
+
+	if exception != nil {
+		return int(rowsAffected), fmt.Errorf("models.workspaces.remove_member: %s", exception.Error())
+	}
+
+	rowsAffected = rowsAffectedVal
+
+	return int(rowsAffected), nil
+}
@@ -1,61 +1,123 @@
 package app
 
 import (
+	"errors"
 	"net/http"
-	"os"
+	"synk/gateway/app/auth"
 	"synk/gateway/app/controller"
-	"synk/gateway/app/util"
+	"synk/gateway/app/model"
+
+	"github.com/prometheus/client_golang/prometheus/collectors"
 )
 
-func Router(service *Service) {
-	aboutController := controller.NewAbout(service.DB)
-	postController := controller.NewPosts(service.DB)
-	templateController := controller.NewTemplates(service.DB)
-	intProfileController := controller.NewIntProfiles(service.DB)
-	intCredentialController := controller.NewIntCredentials(service.DB)
-
-	http.HandleFunc("GET /about", aboutController.HandleAbout)
-	http.HandleFunc("GET /post", postController.HandleList)
-	http.HandleFunc("POST /post", postController.HandleCreate)
-	http.HandleFunc("PUT /post", postController.HandleUpdate)
-	http.HandleFunc("DELETE /post", postController.HandleDelete)
-	http.HandleFunc("POST /post/publish", postController.HandlePublish)
-	http.HandleFunc("GET /templates/basic", templateController.HandleBasicList)
-	http.HandleFunc("GET /templates", templateController.HandleList)
-	http.HandleFunc("POST /templates", templateController.HandleCreate)
-	http.HandleFunc("PUT /templates", templateController.HandleUpdate)
-	http.HandleFunc("DELETE /templates", templateController.HandleDelete)
-	http.HandleFunc("GET /int_profiles/basic", intProfileController.HandleBasicList)
-	http.HandleFunc("GET /int_profiles", intProfileController.HandleList)
-	http.HandleFunc("POST /int_profiles", intProfileController.HandleCreate)
-	http.HandleFunc("PUT /int_profiles", intProfileController.HandleUpdate)
-	http.HandleFunc("DELETE /int_profiles", intProfileController.HandleDelete)
-	http.HandleFunc("GET /int_credentials/basic", intCredentialController.HandleBasicList)
-	http.HandleFunc("GET /int_credentials", intCredentialController.HandleList)
-	http.HandleFunc("POST /int_credentials", intCredentialController.HandleCreate)
-	http.HandleFunc("PUT /int_credentials", intCredentialController.HandleUpdate)
-	http.HandleFunc("DELETE /int_credentials", intCredentialController.HandleDelete)
-
-	port := os.Getenv("PORT")
-	util.Log("app running on port " + port)
-
-	var err error
-	env := os.Getenv("ENV")
-
-	if env == "production" {
-		util.Log("Running in PRODUCTION mode (HTTP)")
-		err = http.ListenAndServe(":"+port, controller.Cors(http.DefaultServeMux))
-	} else {
-		util.Log("Running in DEV mode (HTTPS)")
-		err = http.ListenAndServeTLS(
-			":"+port,
-			"/cert/cert.pem",
-			"/cert/key.pem",
-			controller.Cors(http.DefaultServeMux),
+// Router registers the routes and gives the handler serving them.
+func Router(service *Service) (http.Handler, error) {
+	repositories := model.NewRepositories(service.DB)
+
+	aboutController := controller.NewAbout(repositories, service.Config)
+	postController := controller.NewPosts(repositories, service.Client, service.Config)
+	postReviewController := controller.NewPostReviews(repositories)
+	templateController := controller.NewTemplates(repositories)
+	intProfileController := controller.NewIntProfiles(repositories)
+	intCredentialController := controller.NewIntCredentials(repositories)
+	apiKeyController := controller.NewApiKeys(repositories)
+	workspaceController := controller.NewWorkspaces(repositories)
+	auditController := controller.NewAuditLogs(repositories)
+	healthController := controller.NewHealth(repositories, service.Client, service.Config)
+
+	authenticator := auth.NewAuthenticatorFromConfig(service.Config.Auth, service.Client)
+	rateLimits := controller.NewRateLimits(service.Config.RateLimits)
+	authenticated := func(scope string, handler http.HandlerFunc) http.Handler {
+		return controller.Chain(
+			handler,
+			controller.Auth(authenticator, repositories.ApiKeys),
+			controller.RateLimit(rateLimits),
+			controller.RequireScope(scope),
+		)
+	}
+	workspaced := func(scope string, role model.WorkspaceRole, handler http.HandlerFunc) http.Handler {
+		return controller.Chain(
+			handler,
+			controller.Auth(authenticator, repositories.ApiKeys),
+			controller.RateLimit(rateLimits),
+			controller.RequireScope(scope),
+			controller.Workspace(repositories.Workspaces, role),
 		)
 	}
 
-	if err != nil {
-		util.Log("app failed on running on port " + port + ": " + err.Error())
+	idempotency := controller.Idempotency(repositories.IdempotencyKeys, service.Config.IdempotencyTtl)
+	idempotent := func(handler http.HandlerFunc) http.HandlerFunc {
+		return idempotency(handler).ServeHTTP
 	}
+
+	metricsRegistry, metricsErr := controller.NewMetricsRegistry(
+		collectors.NewDBStatsCollector(service.DB, controller.METRICS_NAMESPACE),
+		controller.NewPostStatusCollector(repositories.Publication),
+	)
+
+	if metricsErr != nil {
+		return nil, errors.New("metrics setup failed: " + metricsErr.Error())
+	}
+
+	// A mux of its own, so the router can be built again, such as in tests.
+	mux := http.NewServeMux()
+
+	viewer := model.WorkspaceRoleViewer
+	editor := model.WorkspaceRoleEditor
+	owner := model.WorkspaceRoleOwner
+
+	mux.HandleFunc("GET /about", aboutController.HandleAbout)
+	mux.Handle("GET /metrics", controller.HandleMetrics(metricsRegistry))
+	mux.HandleFunc("GET /healthz", healthController.HandleLive)
+	mux.HandleFunc("GET /readyz", healthController.HandleReady)
+	mux.Handle("GET /post", workspaced(auth.SCOPE_POSTS_READ, viewer, postController.HandleList))
+	mux.Handle("POST /post", workspaced(auth.SCOPE_POSTS_WRITE, editor, idempotent(postController.HandleCreate)))
+	mux.Handle("PUT /post", workspaced(auth.SCOPE_POSTS_WRITE, editor, postController.HandleUpdate))
+	mux.Handle("DELETE /post", workspaced(auth.SCOPE_POSTS_WRITE, editor, postController.HandleDelete))
+	mux.Handle("POST /post/publish", workspaced(auth.SCOPE_POSTS_PUBLISH, editor, idempotent(postController.HandlePublish)))
+	mux.Handle("POST /post/clone", workspaced(auth.SCOPE_POSTS_WRITE, editor, postController.HandleClone))
+	mux.Handle("GET /post/reviews", workspaced(auth.SCOPE_POSTS_READ, viewer, postReviewController.HandleList))
+	mux.Handle("POST /post/submit", workspaced(auth.SCOPE_POSTS_WRITE, editor, postReviewController.HandleSubmit))
+	mux.Handle("POST /post/review", workspaced(auth.SCOPE_POSTS_REVIEW, owner, postReviewController.HandleDecide))
+	mux.Handle("GET /templates/basic", workspaced(auth.SCOPE_TEMPLATES_READ, viewer, templateController.HandleBasicList))
+	mux.Handle("GET /templates", workspaced(auth.SCOPE_TEMPLATES_READ, viewer, templateController.HandleList))
+	mux.Handle("POST /templates", workspaced(auth.SCOPE_TEMPLATES_WRITE, editor, templateController.HandleCreate))
+	mux.Handle("PUT /templates", workspaced(auth.SCOPE_TEMPLATES_WRITE, editor, templateController.HandleUpdate))
+	mux.Handle("DELETE /templates", workspaced(auth.SCOPE_TEMPLATES_WRITE, editor, templateController.HandleDelete))
+	mux.Handle("POST /templates/clone", workspaced(auth.SCOPE_TEMPLATES_WRITE, editor, templateController.HandleClone))
+	mux.Handle("GET /int_profiles/basic", workspaced(auth.SCOPE_PROFILES_READ, viewer, intProfileController.HandleBasicList))
+	mux.Handle("GET /int_profiles", workspaced(auth.SCOPE_PROFILES_READ, viewer, intProfileController.HandleList))
+	mux.Handle("POST /int_profiles", workspaced(auth.SCOPE_PROFILES_WRITE, editor, intProfileController.HandleCreate))
+	mux.Handle("PUT /int_profiles", workspaced(auth.SCOPE_PROFILES_WRITE, editor, intProfileController.HandleUpdate))
+	mux.Handle("DELETE /int_profiles", workspaced(auth.SCOPE_PROFILES_WRITE, editor, intProfileController.HandleDelete))
+	mux.Handle("POST /int_profiles/clone", workspaced(auth.SCOPE_PROFILES_WRITE, editor, intProfileController.HandleClone))
+	mux.Handle("GET /int_credentials/basic", workspaced(auth.SCOPE_CREDENTIALS_READ, viewer, intCredentialController.HandleBasicList))
+	mux.Handle("GET /int_credentials", workspaced(auth.SCOPE_CREDENTIALS_READ, viewer, intCredentialController.HandleList))
+	mux.Handle("POST /int_credentials", workspaced(auth.SCOPE_CREDENTIALS_WRITE, editor, intCredentialController.HandleCreate))
+	mux.Handle("PUT /int_credentials", workspaced(auth.SCOPE_CREDENTIALS_WRITE, editor, intCredentialController.HandleUpdate))
+	mux.Handle("DELETE /int_credentials", workspaced(auth.SCOPE_CREDENTIALS_WRITE, editor, intCredentialController.HandleDelete))
+	mux.Handle("GET /api_keys", authenticated(auth.SCOPE_API_KEYS_READ, apiKeyController.HandleList))
+	mux.Handle("POST /api_keys", authenticated(auth.SCOPE_API_KEYS_WRITE, apiKeyController.HandleCreate))
+	mux.Handle("DELETE /api_keys", authenticated(auth.SCOPE_API_KEYS_WRITE, apiKeyController.HandleRevoke))
+	mux.Handle("GET /workspaces", authenticated(auth.SCOPE_WORKSPACES_READ, workspaceController.HandleList))
+	mux.Handle("POST /workspaces", authenticated(auth.SCOPE_WORKSPACES_WRITE, workspaceController.HandleCreate))
+	mux.Handle("GET /workspaces/members", workspaced(auth.SCOPE_WORKSPACES_READ, viewer, workspaceController.HandleMemberList))
+	mux.Handle("POST /workspaces/members", workspaced(auth.SCOPE_WORKSPACES_WRITE, owner, workspaceController.HandleMemberAdd))
+	mux.Handle("PUT /workspaces/members", workspaced(auth.SCOPE_WORKSPACES_WRITE, owner, workspaceController.HandleMemberUpdate))
+	mux.Handle("DELETE /workspaces/members", workspaced(auth.SCOPE_WORKSPACES_WRITE, owner, workspaceController.HandleMemberRemove))
+	mux.Handle("GET /audit", workspaced(auth.SCOPE_AUDIT_READ, owner, auditController.HandleList))
+
+	handler := controller.Chain(
+		mux,
+		controller.SentryHub,
+		controller.RequestId,
+		controller.Tracing,
+		controller.Logging,
+		controller.Recovery,
+		controller.Cors(service.Config.Cors),
+		controller.WorkspacePath,
+		controller.Metrics,
+	)
+
+	return handler, nil
 }
@@ -0,0 +1,82 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"synk/gateway/app/config"
+	"synk/gateway/app/util"
+)
+
+// ServerCerts gives the key pair served over TLS, or nil without TLS.
+func ServerCerts(config config.Server) (*util.CertReloader, error) {
+	if !config.TLS {
+		return nil, nil
+	}
+
+	return util.NewCertReloader(config.CertFilePath, config.KeyFilePath, "")
+}
+
+// ServiceCerts gives the root CA and the client key pair used to call the
+// auth server and the queuer, or nil to use the system roots without a client
+// certificate.
+func ServiceCerts(config config.Server) (*util.CertReloader, error) {
+	if config.RootCertFilePath == "" && config.ClientCertFilePath == "" {
+		return nil, nil
+	}
+
+	return util.NewCertReloader(config.ClientCertFilePath, config.ClientKeyFilePath, config.RootCertFilePath)
+}
+
+// NewServer serves over TLS with the key pair of certs, unless it is nil.
+func NewServer(config config.Server, handler http.Handler, certs *util.CertReloader) *http.Server {
+	server := http.Server{
+		Addr:              ":" + config.Port,
+		Handler:           handler,
+		ReadHeaderTimeout: config.ReadHeaderTimeout,
+		ReadTimeout:       config.ReadTimeout,
+		WriteTimeout:      config.WriteTimeout,
+		IdleTimeout:       config.IdleTimeout,
+	}
+
+	if certs != nil {
+		server.TLSConfig = certs.ServerTLSConfig()
+	}
+
+	return &server
+}
+
+// Serve runs server on listener until ctx is done, such as on SIGTERM, then
+// stops accepting connections and waits up to the shutdown timeout of config for in-flight
+// requests, such as publishes, to finish before closing the rest.
+func Serve(ctx context.Context, server *http.Server, listener net.Listener, config config.Server) error {
+	serveErr := make(chan error, 1)
+
+	go func() {
+		if server.TLSConfig != nil {
+			serveErr <- server.ServeTLS(listener, "", "")
+		} else {
+			serveErr <- server.Serve(listener)
+		}
+	}()
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	util.Log("shutting down, waiting for in-flight requests")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), config.ShutdownTimeout)
+	defer cancel()
+
+	if shutdownErr := server.Shutdown(shutdownCtx); shutdownErr != nil {
+		server.Close()
+
+		return errors.New("in-flight requests did not finish within SHUTDOWN_TIMEOUT: " + shutdownErr.Error())
+	}
+
+	return nil
+}
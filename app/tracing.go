@@ -0,0 +1,70 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"synk/gateway/app/config"
+	"synk/gateway/app/util"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.43.0"
+)
+
+const DEFAULT_SERVICE_NAME = "synk-gateway"
+const TRACING_SHUTDOWN_TIMEOUT = time.Second * 5
+
+// InitTracing sets the tracer provider with the exporter kind: `otlp`,
+// `stdout` or `none`. Spans are still
+// created without an exporter, so calls to the auth server and the queuer
+// carry a traceparent. The returned func flushes and stops the exporter.
+func InitTracing(kind string) (func(context.Context) error, error) {
+	options := []sdktrace.TracerProviderOption{}
+
+	switch kind {
+	case config.TRACES_EXPORTER_OTLP:
+		exporter, exporterErr := otlptracehttp.New(context.Background())
+
+		if exporterErr != nil {
+			return nil, errors.New("otlp exporter setup failed: " + exporterErr.Error())
+		}
+
+		options = append(options, sdktrace.WithBatcher(exporter))
+	case config.TRACES_EXPORTER_STDOUT:
+		exporter, exporterErr := stdouttrace.New()
+
+		if exporterErr != nil {
+			return nil, errors.New("stdout exporter setup failed: " + exporterErr.Error())
+		}
+
+		options = append(options, sdktrace.WithSyncer(exporter))
+	case config.TRACES_EXPORTER_NONE:
+	default:
+		return nil, errors.New("traces exporter must be otlp, stdout or none, got " + strconv.Quote(kind))
+	}
+
+	serviceResource, resourceErr := resource.New(
+		context.Background(),
+		resource.WithAttributes(semconv.ServiceName(DEFAULT_SERVICE_NAME)),
+		resource.WithFromEnv(),
+	)
+
+	if resourceErr != nil {
+		return nil, errors.New("tracing resource setup failed: " + resourceErr.Error())
+	}
+
+	provider := sdktrace.NewTracerProvider(append(options, sdktrace.WithResource(serviceResource))...)
+
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	util.Log("exporting traces to " + kind)
+
+	return provider.Shutdown, nil
+}
@@ -0,0 +1,26 @@
+package util
+
+import "runtime/debug"
+
+// Version and Commit are set when building, such as with
+// `-ldflags "-X synk/gateway/app/util.Version=1.4.0 -X synk/gateway/app/util.Commit=$(git rev-parse HEAD)"`.
+var Version = "dev"
+var Commit = ""
+
+// BuildCommit gives Commit, or the VCS revision stamped by `go build`, or
+// `unknown` when neither is available, as with `go run`.
+func BuildCommit() string {
+	if Commit != "" {
+		return Commit
+	}
+
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range info.Settings {
+			if setting.Key == "vcs.revision" {
+				return setting.Value
+			}
+		}
+	}
+
+	return "unknown"
+}
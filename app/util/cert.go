@@ -0,0 +1,197 @@
+package util
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+	"time"
+)
+
+// CertReloader keeps a key pair and a root CA pool read from files, any of
+// them optional, and reads them again when the files change so renewed
+// certificates apply to new connections without restarting.
+type CertReloader struct {
+	certPath string
+	keyPath  string
+	caPath   string
+	mu       sync.RWMutex
+	cert     *tls.Certificate
+	roots    *x509.CertPool
+	modTimes []time.Time
+}
+
+func NewCertReloader(certPath string, keyPath string, caPath string) (*CertReloader, error) {
+	reloader := CertReloader{
+		certPath: certPath,
+		keyPath:  keyPath,
+		caPath:   caPath,
+	}
+
+	if _, reloadErr := reloader.Reload(); reloadErr != nil {
+		return nil, reloadErr
+	}
+
+	return &reloader, nil
+}
+
+// Reload reads the files again when one of them changed since the last read,
+// and tells whether it did. On error, the previous certificates are kept.
+func (c *CertReloader) Reload() (bool, error) {
+	modTimes := []time.Time{}
+
+	for _, path := range []string{c.certPath, c.keyPath, c.caPath} {
+		if path == "" {
+			continue
+		}
+
+		info, statErr := os.Stat(path)
+
+		if statErr != nil {
+			return false, fmt.Errorf("util.cert_reloader.reload: %s", statErr.Error())
+		}
+
+		modTimes = append(modTimes, info.ModTime())
+	}
+
+	c.mu.RLock()
+	changed := !slices.EqualFunc(modTimes, c.modTimes, time.Time.Equal)
+	c.mu.RUnlock()
+
+	if !changed {
+		return false, nil
+	}
+
+	var cert *tls.Certificate
+
+	if c.certPath != "" {
+		pair, pairErr := tls.LoadX509KeyPair(c.certPath, c.keyPath)
+
+		if pairErr != nil {
+			return false, fmt.Errorf("util.cert_reloader.reload: %s", pairErr.Error())
+		}
+
+		cert = &pair
+	}
+
+	var roots *x509.CertPool
+
+	if c.caPath != "" {
+		caPem, readErr := os.ReadFile(c.caPath)
+
+		if readErr != nil {
+			return false, fmt.Errorf("util.cert_reloader.reload: %s", readErr.Error())
+		}
+
+		roots = x509.NewCertPool()
+
+		if !roots.AppendCertsFromPEM(caPem) {
+			return false, fmt.Errorf("util.cert_reloader.reload: no certificate found in %s", c.caPath)
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.cert = cert
+	c.roots = roots
+	c.modTimes = modTimes
+
+	return true, nil
+}
+
+// Watch reloads the files every interval until ctx is done. Files that can't
+// be read, such as while they are being replaced, are tried again on the next
+// tick.
+func (c *CertReloader) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		reloaded, reloadErr := c.Reload()
+
+		if reloadErr != nil {
+			LogWarn("certificates not reloaded: " + reloadErr.Error())
+		} else if reloaded {
+			Log("certificates reloaded")
+		}
+	}
+}
+
+func (c *CertReloader) Certificate() *tls.Certificate {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.cert
+}
+
+// RootCAs is nil without a CA file, meaning the system roots.
+func (c *CertReloader) RootCAs() *x509.CertPool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.roots
+}
+
+// ServerTLSConfig serves the current key pair.
+func (c *CertReloader) ServerTLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetCertificate: func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+			if cert := c.Certificate(); cert != nil {
+				return cert, nil
+			}
+
+			return nil, errors.New("no server certificate loaded")
+		},
+	}
+}
+
+// ClientTLSConfig verifies servers against the current root CAs and presents
+// the current key pair, if any, to servers asking for a client certificate.
+func (c *CertReloader) ClientTLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		// The default verification is bound to a fixed RootCAs, so it is done
+		// by VerifyConnection instead, against the reloaded one.
+		InsecureSkipVerify: true,
+		VerifyConnection:   c.verifyServer,
+		GetClientCertificate: func(info *tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			if cert := c.Certificate(); cert != nil {
+				return cert, nil
+			}
+
+			return &tls.Certificate{}, nil
+		},
+	}
+}
+
+func (c *CertReloader) verifyServer(state tls.ConnectionState) error {
+	if len(state.PeerCertificates) == 0 {
+		return errors.New("server sent no certificate")
+	}
+
+	intermediates := x509.NewCertPool()
+
+	for _, cert := range state.PeerCertificates[1:] {
+		intermediates.AddCert(cert)
+	}
+
+	_, verifyErr := state.PeerCertificates[0].Verify(x509.VerifyOptions{
+		Roots:         c.RootCAs(),
+		DNSName:       state.ServerName,
+		Intermediates: intermediates,
+	})
+
+	return verifyErr
+}
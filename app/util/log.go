@@ -1,11 +1,92 @@
 package util
 
-import "fmt"
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+	"synk/gateway/app/config"
+)
+
+const LOG_FORMAT_JSON = config.LOG_FORMAT_JSON
+const LOG_FORMAT_TEXT = config.LOG_FORMAT_TEXT
+
+type logContextKey struct{}
+
+// requestLog is shared by everything handling a request, so fields added by
+// inner middlewares, such as the user, also reach the access log.
+type requestLog struct {
+	mu     sync.Mutex
+	logger *slog.Logger
+}
+
+// stdout looks up os.Stdout on each write, so logs follow it when replaced.
+type stdout struct{}
+
+func (stdout) Write(content []byte) (int, error) {
+	return os.Stdout.Write(content)
+}
+
+func init() {
+	slog.SetDefault(NewLogger(stdout{}, LOG_FORMAT_JSON, slog.LevelInfo))
+}
+
+func NewLogger(w io.Writer, format string, level slog.Leveler) *slog.Logger {
+	options := &slog.HandlerOptions{Level: level}
+
+	if format == LOG_FORMAT_TEXT {
+		return slog.New(slog.NewTextHandler(w, options))
+	}
+
+	return slog.New(slog.NewJSONHandler(w, options))
+}
+
+// InitLogger sets the default logger with the format and level of config.
+func InitLogger(config config.Log) {
+	slog.SetDefault(NewLogger(stdout{}, config.Format, config.Level))
+}
 
 func Log(message string) {
-	fmt.Println("[" + Now() + "] > " + message)
+	slog.Info(message)
+}
+
+func LogWarn(message string) {
+	slog.Warn(message)
+}
+
+func LogError(message string) {
+	slog.Error(message)
 }
 
 func LogRoute(route string, message string) {
-	fmt.Println("[" + Now() + "] " + route + " > " + message)
+	slog.Info(message, "route", route)
+}
+
+// WithLogger starts the request-scoped logger of ctx.
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, logContextKey{}, &requestLog{logger: logger})
+}
+
+// LoggerFrom gives the request-scoped logger of ctx, or the default one.
+func LoggerFrom(ctx context.Context) *slog.Logger {
+	if log, ok := ctx.Value(logContextKey{}).(*requestLog); ok {
+		log.mu.Lock()
+		defer log.mu.Unlock()
+
+		return log.logger
+	}
+
+	return slog.Default()
+}
+
+// AddLogAttrs adds fields to the request-scoped logger of ctx, for every
+// later log of the request. Without one, it does nothing.
+func AddLogAttrs(ctx context.Context, args ...any) {
+	if log, ok := ctx.Value(logContextKey{}).(*requestLog); ok {
+		log.mu.Lock()
+		defer log.mu.Unlock()
+
+		log.logger = log.logger.With(args...)
+	}
 }
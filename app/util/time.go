@@ -24,3 +24,26 @@ func ToTimeBR(date string) string {
 
 	return formattedDateStr
 }
+
+// ParseTimeBound reads a `YYYY-MM-DD` date or an RFC 3339 time and returns it
+// in the `YYYY-MM-DD HH:MM:SS` database layout, in UTC. A bare date is the
+// start of the day, or its last second when endOfDay is set.
+func ParseTimeBound(value string, endOfDay bool) (string, error) {
+	dbLayout := "2006-01-02 15:04:05"
+
+	if day, dayErr := time.Parse(time.DateOnly, value); dayErr == nil {
+		if endOfDay {
+			day = day.Add(24*time.Hour - time.Second)
+		}
+
+		return day.Format(dbLayout), nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+
+	if err != nil {
+		return "", err
+	}
+
+	return t.UTC().Format(dbLayout), nil
+}
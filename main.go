@@ -1,9 +1,25 @@
 package main
 
 import (
+	"log"
+	"os"
 	"synk/gateway/app"
 )
 
 func main() {
-	app.Run()
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		migrateErr := app.Migrate(os.Args[2:])
+
+		if migrateErr != nil {
+			log.Fatal(migrateErr)
+		}
+
+		return
+	}
+
+	runErr := app.Run()
+
+	if runErr != nil {
+		log.Fatal(runErr)
+	}
 }
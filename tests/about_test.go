@@ -1,6 +1,7 @@
 package tests
 
 import (
+	"context"
 	"synk/gateway/app"
 	"synk/gateway/app/model"
 	"testing"
@@ -15,7 +16,7 @@ func TestAboutModelPing(t *testing.T) {
 
 	aboutModel := model.NewAbout(db)
 
-	if !aboutModel.Ping() {
+	if !aboutModel.Ping(context.Background()) {
 		t.Errorf("about: ping operation failed")
 	}
 
@@ -0,0 +1,75 @@
+package tests
+
+import (
+	"context"
+	"synk/gateway/app"
+	"synk/gateway/app/auth"
+	"synk/gateway/app/model"
+	"testing"
+)
+
+func TestApiKeys_Lifecycle(t *testing.T) {
+	db, err := app.InitDB(true)
+	if err != nil {
+		t.Fatalf("db connection failed: %v", err)
+	}
+	defer db.Close()
+
+	apiKeysModel := model.NewApiKeys(db)
+	userId := 1
+
+	generated, err := auth.GenerateApiKey()
+	if err != nil {
+		t.Fatalf("GenerateApiKey failed: %v", err)
+	}
+
+	id, err := apiKeysModel.Add(context.Background(), model.ApiKeyAddData{
+		ApiKeyName:   "Lifecycle Key",
+		ApiKeyPrefix: generated.Prefix,
+		ApiKeyHash:   generated.Hash,
+		ApiKeyScopes: []string{auth.SCOPE_POSTS_READ, auth.SCOPE_POSTS_PUBLISH},
+	}, userId)
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	defer db.Exec("DELETE FROM api_key WHERE api_key_id = ?", id)
+
+	owner, err := apiKeysModel.ByHash(context.Background(), auth.HashApiKey(generated.Key))
+	if err != nil {
+		t.Fatalf("ByHash failed: %v", err)
+	}
+	if owner.ApiKeyId != id || owner.UserId != userId {
+		t.Errorf("ByHash returned wrong owner %+v", owner)
+	}
+	if len(owner.ApiKeyScopes) != 2 {
+		t.Errorf("ByHash returned wrong scopes %v", owner.ApiKeyScopes)
+	}
+
+	if err := apiKeysModel.Touch(context.Background(), id); err != nil {
+		t.Fatalf("Touch failed: %v", err)
+	}
+
+	list, _ := apiKeysModel.List(context.Background(), userId)
+	found := false
+	for _, item := range list {
+		if item.ApiKeyId == id {
+			found = true
+			if item.LastUsedAt == "" {
+				t.Error("Expected last_used_at to be set")
+			}
+		}
+	}
+	if !found {
+		t.Error("Created key not found in List")
+	}
+
+	rows, err := apiKeysModel.Revoke(context.Background(), id, userId)
+	if err != nil || rows != 1 {
+		t.Fatalf("Revoke failed: rows %d err %v", rows, err)
+	}
+
+	owner, _ = apiKeysModel.ByHash(context.Background(), generated.Hash)
+	if owner.UserId != 0 {
+		t.Error("Revoked key still resolves to a user")
+	}
+}
@@ -0,0 +1,66 @@
+package tests
+
+import (
+	"context"
+	"synk/gateway/app"
+	"synk/gateway/app/model"
+	"testing"
+)
+
+func TestAuditLogs_Lifecycle(t *testing.T) {
+	db, err := app.InitDB(true)
+	if err != nil {
+		t.Fatalf("db connection failed: %v", err)
+	}
+	defer db.Close()
+
+	auditModel := model.NewAuditLogs(db)
+	userId := 1
+
+	workspaceEntryId, err := auditModel.Add(context.Background(), model.AuditLogAddData{
+		UserId:          userId,
+		WorkspaceId:     SEED_WORKSPACE_ID,
+		AuditAction:     model.AuditActionUpdate,
+		AuditEntityType: model.AuditEntityTemplate,
+		AuditEntityId:   42,
+		AuditBefore:     `{"template_name":"Before"}`,
+		AuditAfter:      `{"template_name":"After"}`,
+		RequestId:       "audit-lifecycle",
+	})
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	defer db.Exec("DELETE FROM audit_log WHERE audit_log_id = ?", workspaceEntryId)
+
+	userEntryId, err := auditModel.Add(context.Background(), model.AuditLogAddData{
+		UserId:          userId,
+		AuditAction:     model.AuditActionCreate,
+		AuditEntityType: model.AuditEntityApiKey,
+		AuditEntityId:   7,
+		AuditAfter:      `{"api_key_name":"CI"}`,
+	})
+	if err != nil {
+		t.Fatalf("Add without workspace failed: %v", err)
+	}
+	defer db.Exec("DELETE FROM audit_log WHERE audit_log_id = ?", userEntryId)
+
+	list, err := auditModel.List(context.Background(), model.AuditLogFilter{AuditEntityId: 42}, SEED_WORKSPACE_ID, userId)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(list) != 1 || list[0].RequestId != "audit-lifecycle" || string(list[0].AuditAfter) != `{"template_name":"After"}` {
+		t.Fatalf("unexpected entries %+v", list)
+	}
+
+	list, _ = auditModel.List(context.Background(), model.AuditLogFilter{AuditEntityType: model.AuditEntityApiKey}, SEED_WORKSPACE_ID, userId)
+	if len(list) == 0 || string(list[0].AuditBefore) != "null" {
+		t.Errorf("expected own entry without workspace, got %+v", list)
+	}
+
+	list, _ = auditModel.List(context.Background(), model.AuditLogFilter{AuditEntityType: model.AuditEntityApiKey}, SEED_WORKSPACE_ID, userId+1)
+	for _, entry := range list {
+		if entry.AuditLogId == userEntryId {
+			t.Error("expected entry without workspace to be hidden from other users")
+		}
+	}
+}
@@ -0,0 +1,304 @@
+package tests
+
+import (
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"synk/gateway/app/auth"
+	"testing"
+	"time"
+)
+
+const AUTH_TEST_SECRET = "test-secret"
+
+func encodeTokenSegment(t *testing.T, value any) string {
+	t.Helper()
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		t.Fatalf("could not encode token segment: %v", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func signHS256Token(t *testing.T, secret string, claims map[string]any) string {
+	t.Helper()
+
+	signed := encodeTokenSegment(t, map[string]string{"alg": "HS256", "typ": "JWT"}) + "." + encodeTokenSegment(t, claims)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(signed))
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func signRS256Token(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]any) string {
+	t.Helper()
+
+	signed := encodeTokenSegment(t, map[string]string{"alg": "RS256", "kid": kid}) + "." + encodeTokenSegment(t, claims)
+	digest := sha256.Sum256([]byte(signed))
+
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatalf("could not sign token: %v", err)
+	}
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func newRemoteAuthServer(t *testing.T, status int, userId int, hits *int32) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(hits, 1)
+
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]any{
+			"resource": map[string]any{"ok": status == http.StatusOK, "error": "invalid token"},
+			"user":     map[string]any{"user_id": userId},
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestAuthenticator_LocalHS256(t *testing.T) {
+	var hits int32
+	server := newRemoteAuthServer(t, http.StatusOK, 99, &hits)
+
+	verifier := auth.NewVerifier(AUTH_TEST_SECRET, nil, auth.DEFAULT_USER_CLAIM, "")
+	authenticator := auth.NewAuthenticator(verifier, auth.NewCache(time.Minute, time.Minute), server.Client(), server.URL)
+
+	token := signHS256Token(t, AUTH_TEST_SECRET, map[string]any{
+		"user_id": 7,
+		"exp":     time.Now().Add(time.Hour).Unix(),
+	})
+
+	result := authenticator.Authenticate(context.Background(), "Bearer "+token)
+
+	if !result.Ok() || result.UserId != 7 {
+		t.Errorf("expected user 7, got %+v", result)
+	}
+	if hits != 0 {
+		t.Errorf("expected no remote calls, got %d", hits)
+	}
+}
+
+func TestAuthenticator_RejectsTamperedAndCachesNegative(t *testing.T) {
+	var hits int32
+	server := newRemoteAuthServer(t, http.StatusOK, 99, &hits)
+
+	verifier := auth.NewVerifier(AUTH_TEST_SECRET, nil, auth.DEFAULT_USER_CLAIM, "")
+	authenticator := auth.NewAuthenticator(verifier, auth.NewCache(time.Minute, time.Minute), server.Client(), server.URL)
+
+	token := signHS256Token(t, "other-secret", map[string]any{"user_id": 7})
+
+	for i := 0; i < 2; i++ {
+		result := authenticator.Authenticate(context.Background(), "Bearer "+token)
+
+		if result.Status != http.StatusUnauthorized {
+			t.Errorf("expected 401, got %+v", result)
+		}
+	}
+	if hits != 0 {
+		t.Errorf("expected no remote calls, got %d", hits)
+	}
+}
+
+func TestAuthenticator_RejectsExpired(t *testing.T) {
+	verifier := auth.NewVerifier(AUTH_TEST_SECRET, nil, auth.DEFAULT_USER_CLAIM, "")
+	authenticator := auth.NewAuthenticator(verifier, auth.NewCache(time.Minute, time.Minute), http.DefaultClient, "")
+
+	token := signHS256Token(t, AUTH_TEST_SECRET, map[string]any{
+		"user_id": 7,
+		"exp":     time.Now().Add(-time.Hour).Unix(),
+	})
+
+	result := authenticator.Authenticate(context.Background(), "Bearer "+token)
+
+	if result.Status != http.StatusUnauthorized {
+		t.Errorf("expected 401, got %+v", result)
+	}
+}
+
+func TestAuthenticator_LocalRS256WithJWKS(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("could not generate key: %v", err)
+	}
+
+	var jwksHits int32
+	jwksServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&jwksHits, 1)
+
+		json.NewEncoder(w).Encode(map[string]any{
+			"keys": []map[string]string{{
+				"kid": "main",
+				"kty": "RSA",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	}))
+	defer jwksServer.Close()
+
+	jwks := auth.NewJWKS(jwksServer.URL, jwksServer.Client(), time.Minute)
+	verifier := auth.NewVerifier("", jwks, auth.DEFAULT_USER_CLAIM, "")
+	authenticator := auth.NewAuthenticator(verifier, auth.NewCache(0, 0), http.DefaultClient, "")
+
+	for _, userId := range []int{3, 4} {
+		token := signRS256Token(t, key, "main", map[string]any{"user_id": userId})
+		result := authenticator.Authenticate(context.Background(), "Bearer "+token)
+
+		if !result.Ok() || result.UserId != userId {
+			t.Errorf("expected user %d, got %+v", userId, result)
+		}
+	}
+	if jwksHits != 1 {
+		t.Errorf("expected JWKS to be fetched once, got %d", jwksHits)
+	}
+}
+
+func TestAuthenticator_OpaqueTokenWithoutAuthServer(t *testing.T) {
+	verifier := auth.NewVerifier(AUTH_TEST_SECRET, nil, auth.DEFAULT_USER_CLAIM, "")
+	authenticator := auth.NewAuthenticator(verifier, auth.NewCache(time.Minute, time.Minute), http.DefaultClient, "")
+
+	for _, header := range []string{"Bearer opaque-token", "Bearer a.b"} {
+		result := authenticator.Authenticate(context.Background(), header)
+
+		if result.Status != http.StatusUnauthorized {
+			t.Errorf("expected 401 for %q, got %+v", header, result)
+		}
+	}
+}
+
+func TestAuthenticator_JWKSDownIsNotRefetchedPerRequest(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("could not generate key: %v", err)
+	}
+
+	var jwksHits int32
+	jwksServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&jwksHits, 1)
+
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer jwksServer.Close()
+
+	jwks := auth.NewJWKS(jwksServer.URL, jwksServer.Client(), time.Minute)
+	verifier := auth.NewVerifier("", jwks, auth.DEFAULT_USER_CLAIM, "")
+	authenticator := auth.NewAuthenticator(verifier, auth.NewCache(0, 0), http.DefaultClient, "")
+
+	var wg sync.WaitGroup
+
+	for userId := 1; userId <= 5; userId++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			token := signRS256Token(t, key, "main", map[string]any{"user_id": userId})
+			result := authenticator.Authenticate(context.Background(), "Bearer "+token)
+
+			if result.Status != http.StatusUnauthorized {
+				t.Errorf("expected 401 while the JWKS endpoint is down, got %+v", result)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if jwksHits != 1 {
+		t.Errorf("expected JWKS to be fetched once while down, got %d", jwksHits)
+	}
+}
+
+func TestAuthenticator_RemoteFallbackIsCached(t *testing.T) {
+	var hits int32
+	server := newRemoteAuthServer(t, http.StatusOK, 12, &hits)
+
+	authenticator := auth.NewAuthenticator(nil, auth.NewCache(time.Minute, time.Minute), server.Client(), server.URL)
+
+	for i := 0; i < 3; i++ {
+		result := authenticator.Authenticate(context.Background(), "Bearer opaque-token")
+
+		if !result.Ok() || result.UserId != 12 {
+			t.Errorf("expected user 12, got %+v", result)
+		}
+	}
+	if hits != 1 {
+		t.Errorf("expected one remote call, got %d", hits)
+	}
+}
+
+func TestAuthenticator_RemoteRejectionIsCached(t *testing.T) {
+	var hits int32
+	server := newRemoteAuthServer(t, http.StatusUnauthorized, 0, &hits)
+
+	authenticator := auth.NewAuthenticator(nil, auth.NewCache(time.Minute, time.Minute), server.Client(), server.URL)
+
+	for i := 0; i < 2; i++ {
+		result := authenticator.Authenticate(context.Background(), "Bearer bad-token")
+
+		if result.Status != http.StatusUnauthorized || result.Error != "invalid token" {
+			t.Errorf("expected remote 401, got %+v", result)
+		}
+	}
+	if hits != 1 {
+		t.Errorf("expected one remote call, got %d", hits)
+	}
+}
+
+func TestHasScope(t *testing.T) {
+	cases := []struct {
+		granted  []string
+		required string
+		want     bool
+	}{
+		{[]string{auth.SCOPE_ALL}, auth.SCOPE_POSTS_PUBLISH, true},
+		{[]string{"posts:*"}, auth.SCOPE_POSTS_PUBLISH, true},
+		{[]string{auth.SCOPE_POSTS_READ}, auth.SCOPE_POSTS_READ, true},
+		{[]string{auth.SCOPE_POSTS_READ}, auth.SCOPE_POSTS_PUBLISH, false},
+		{[]string{"templates:*"}, auth.SCOPE_POSTS_READ, false},
+		{nil, auth.SCOPE_POSTS_READ, false},
+	}
+
+	for _, c := range cases {
+		if got := auth.HasScope(c.granted, c.required); got != c.want {
+			t.Errorf("HasScope(%v, %s) = %v, want %v", c.granted, c.required, got, c.want)
+		}
+	}
+}
+
+func TestAuthenticator_TokenScopes(t *testing.T) {
+	verifier := auth.NewVerifier(AUTH_TEST_SECRET, nil, auth.DEFAULT_USER_CLAIM, "")
+	authenticator := auth.NewAuthenticator(verifier, auth.NewCache(0, 0), http.DefaultClient, "")
+
+	scoped := authenticator.Authenticate(context.Background(), "Bearer "+signHS256Token(t, AUTH_TEST_SECRET, map[string]any{
+		"user_id": 7,
+		"scope":   "posts:read posts:publish",
+	}))
+
+	if len(scoped.Scopes) != 2 || !auth.HasScope(scoped.Scopes, auth.SCOPE_POSTS_PUBLISH) || auth.HasScope(scoped.Scopes, auth.SCOPE_POSTS_WRITE) {
+		t.Errorf("unexpected scopes %v", scoped.Scopes)
+	}
+
+	session := authenticator.Authenticate(context.Background(), "Bearer "+signHS256Token(t, AUTH_TEST_SECRET, map[string]any{"user_id": 7}))
+
+	if !auth.HasScope(session.Scopes, auth.SCOPE_CREDENTIALS_WRITE) {
+		t.Errorf("expected token without scopes to keep full access, got %v", session.Scopes)
+	}
+}
@@ -0,0 +1,229 @@
+package tests
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"synk/gateway/app"
+	"synk/gateway/app/config"
+	"synk/gateway/app/controller"
+	"synk/gateway/app/util"
+	"testing"
+	"time"
+)
+
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+// issueTestCert signs a certificate for 127.0.0.1 with parent, or self-signs
+// a CA when parent is nil.
+func issueTestCert(t *testing.T, parent *testCert, serial int64) testCert {
+	t.Helper()
+
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "synk test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+
+	signer := &testCert{cert: template, key: key}
+
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature
+	} else {
+		signer = parent
+	}
+
+	der, certErr := x509.CreateCertificate(rand.Reader, template, signer.cert, &key.PublicKey, signer.key)
+	if certErr != nil {
+		t.Fatalf("failed to create certificate: %v", certErr)
+	}
+
+	cert, _ := x509.ParseCertificate(der)
+
+	return testCert{cert: cert, key: key}
+}
+
+// writeTestCert writes the certificate and key PEM files, with a modification
+// time of at, so rewrites are seen even within the filesystem time precision.
+func writeTestCert(t *testing.T, cert testCert, certPath string, keyPath string, at time.Time) {
+	t.Helper()
+
+	keyDer, _ := x509.MarshalECPrivateKey(cert.key)
+
+	os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.cert.Raw}), 0600)
+	os.Chtimes(certPath, at, at)
+
+	if keyPath != "" {
+		os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600)
+		os.Chtimes(keyPath, at, at)
+	}
+}
+
+func servedSerial(t *testing.T, certs *util.CertReloader) int64 {
+	t.Helper()
+
+	cert, certErr := certs.ServerTLSConfig().GetCertificate(&tls.ClientHelloInfo{})
+	if certErr != nil {
+		t.Fatalf("expected a certificate, got %v", certErr)
+	}
+
+	leaf, _ := x509.ParseCertificate(cert.Certificate[0])
+
+	return leaf.SerialNumber.Int64()
+}
+
+func TestCertReloader_Reload(t *testing.T) {
+	dir := t.TempDir()
+	certPath := filepath.Join(dir, "cert.pem")
+	keyPath := filepath.Join(dir, "key.pem")
+	ca := issueTestCert(t, nil, 1)
+	now := time.Now()
+
+	writeTestCert(t, issueTestCert(t, &ca, 2), certPath, keyPath, now)
+
+	certs, certsErr := util.NewCertReloader(certPath, keyPath, "")
+	if certsErr != nil {
+		t.Fatalf("NewCertReloader failed: %v", certsErr)
+	}
+
+	if reloaded, _ := certs.Reload(); reloaded {
+		t.Error("expected no reload without changes")
+	}
+
+	writeTestCert(t, issueTestCert(t, &ca, 3), certPath, keyPath, now.Add(time.Minute))
+
+	if reloaded, reloadErr := certs.Reload(); !reloaded || reloadErr != nil {
+		t.Fatalf("expected a reload after the files changed, got %v %v", reloaded, reloadErr)
+	}
+	if serial := servedSerial(t, certs); serial != 3 {
+		t.Errorf("expected the renewed certificate, got serial %d", serial)
+	}
+
+	writeTestCert(t, issueTestCert(t, &ca, 4), certPath, "", now.Add(time.Minute*2))
+
+	if _, reloadErr := certs.Reload(); reloadErr == nil {
+		t.Error("expected an error while the key doesn't match the certificate")
+	}
+	if serial := servedSerial(t, certs); serial != 3 {
+		t.Errorf("expected the previous certificate kept, got serial %d", serial)
+	}
+
+	if _, missingErr := util.NewCertReloader(filepath.Join(dir, "missing.pem"), keyPath, ""); missingErr == nil {
+		t.Error("expected an error for a missing certificate")
+	}
+}
+
+func TestServiceClient_MutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	caPath := filepath.Join(dir, "rootCA.pem")
+	clientCertPath := filepath.Join(dir, "client.pem")
+	clientKeyPath := filepath.Join(dir, "client-key.pem")
+
+	ca := issueTestCert(t, nil, 1)
+	serverCert := issueTestCert(t, &ca, 2)
+
+	writeTestCert(t, ca, caPath, "", time.Now())
+	writeTestCert(t, issueTestCert(t, &ca, 3), clientCertPath, clientKeyPath, time.Now())
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(ca.cert)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{serverCert.cert.Raw}, PrivateKey: serverCert.key}},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCAs,
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	cfg := config.Default()
+	cfg.Server.RootCertFilePath = caPath
+	cfg.Server.ClientCertFilePath = clientCertPath
+	cfg.Server.ClientKeyFilePath = clientKeyPath
+
+	certs, certsErr := app.ServiceCerts(cfg.Server)
+	if certsErr != nil {
+		t.Fatalf("ServiceCerts failed: %v", certsErr)
+	}
+
+	resp, respErr := controller.NewServiceClient(certs).Get(server.URL)
+	if respErr != nil {
+		t.Fatalf("expected the call to pass mTLS, got %v", respErr)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusAccepted {
+		t.Errorf("expected 202, got %d", resp.StatusCode)
+	}
+
+	if _, untrustedErr := controller.NewServiceClient(nil).Get(server.URL); untrustedErr == nil {
+		t.Error("expected the test CA to be untrusted without ROOT_CERTIFICATE_FILE_PATH")
+	}
+
+	anonymous, _ := util.NewCertReloader("", "", caPath)
+
+	if _, anonymousErr := controller.NewServiceClient(anonymous).Get(server.URL); anonymousErr == nil {
+		t.Error("expected the server to refuse a client without certificate")
+	}
+}
+
+func TestServe_TLS(t *testing.T) {
+	dir := t.TempDir()
+	certPath := filepath.Join(dir, "cert.pem")
+	keyPath := filepath.Join(dir, "key.pem")
+	caPath := filepath.Join(dir, "rootCA.pem")
+
+	ca := issueTestCert(t, nil, 1)
+
+	writeTestCert(t, ca, caPath, "", time.Now())
+	writeTestCert(t, issueTestCert(t, &ca, 2), certPath, keyPath, time.Now())
+
+	cfg := config.Default()
+	cfg.Server.CertFilePath = certPath
+	cfg.Server.KeyFilePath = keyPath
+
+	serverCerts, certsErr := app.ServerCerts(cfg.Server)
+	if certsErr != nil || serverCerts == nil {
+		t.Fatalf("expected TLS with TLS_ENABLED, got %v", certsErr)
+	}
+
+	listener, _ := net.Listen("tcp", "127.0.0.1:0")
+	server := app.NewServer(cfg.Server, http.NotFoundHandler(), serverCerts)
+
+	go app.Serve(t.Context(), server, listener, cfg.Server)
+
+	clientCerts, _ := util.NewCertReloader("", "", caPath)
+
+	resp, respErr := controller.NewServiceClient(clientCerts).Get("https://" + listener.Addr().String())
+	if respErr != nil {
+		t.Fatalf("expected an HTTPS response, got %v", respErr)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", resp.StatusCode)
+	}
+}
@@ -1,6 +1,7 @@
 package tests
 
 import (
+	"context"
 	"synk/gateway/app"
 	"synk/gateway/app/model"
 	"testing"
@@ -15,7 +16,7 @@ func TestColorsList(t *testing.T) {
 
 	colorsModel := model.NewColors(db)
 
-	allColors, err := colorsModel.List(0)
+	allColors, err := colorsModel.List(context.Background(), 0)
 	if err != nil {
 		t.Errorf("colors: list all operation failed: %v", err)
 	}
@@ -23,7 +24,7 @@ func TestColorsList(t *testing.T) {
 	if len(allColors) > 0 {
 		targetColor := allColors[0]
 
-		specificList, err := colorsModel.List(targetColor.ColorId)
+		specificList, err := colorsModel.List(context.Background(), targetColor.ColorId)
 
 		if err != nil {
 			t.Errorf("colors: list specific id failed: %v", err)
@@ -55,7 +56,7 @@ func TestColorsListNotFound(t *testing.T) {
 
 	colorsModel := model.NewColors(db)
 
-	list, err := colorsModel.List(-1)
+	list, err := colorsModel.List(context.Background(), -1)
 
 	if err != nil {
 		t.Errorf("colors: list should not error on non-existent ID, got: %v", err)
@@ -0,0 +1,31 @@
+package tests
+
+import (
+	"synk/gateway/app/controller"
+	"testing"
+)
+
+func TestParseIfMatch(t *testing.T) {
+	cases := []struct {
+		value   string
+		version int
+		ok      bool
+	}{
+		{`"3"`, 3, true},
+		{`W/"3"`, 3, true},
+		{`3`, 3, true},
+		{` "12" `, 12, true},
+		{`*`, 0, true},
+		{`"0"`, 0, false},
+		{`"abc"`, 0, false},
+		{``, 0, false},
+	}
+
+	for _, c := range cases {
+		version, ok := controller.ParseIfMatch(c.value)
+
+		if version != c.version || ok != c.ok {
+			t.Errorf("ParseIfMatch(%q) = %d, %v; want %d, %v", c.value, version, ok, c.version, c.ok)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"synk/gateway/app/config"
+	"testing"
+	"time"
+)
+
+var configTestKeys = []string{
+	"CONFIG_FILE", "ENV", "PORT", "DB_HOST", "DB_PORT", "DB_USER", "DB_NAME",
+	"QUEUER_ENDPOINT", "AUTH_ENDPOINT", "AUTH_JWT_SECRET", "AUTH_JWKS_URL", "WEB_ENDPOINT",
+	"TLS_ENABLED", "TLS_CERT_FILE_PATH", "TLS_KEY_FILE_PATH", "ROOT_CERTIFICATE_FILE_PATH",
+	"TLS_CLIENT_CERT_FILE_PATH", "TLS_CLIENT_KEY_FILE_PATH", "SERVER_WRITE_TIMEOUT",
+	"SERVER_IDLE_TIMEOUT", "SHUTDOWN_TIMEOUT", "RATE_LIMIT_READ", "RATE_LIMIT_WRITE",
+	"RATE_LIMIT_PUBLISH", "PUBLISH_DAILY_QUOTA_USER", "IDEMPOTENCY_TTL", "READINESS_TIMEOUT",
+	"LOG_FORMAT", "LOG_LEVEL", "OTEL_TRACES_EXPORTER", "ERROR_REPORTER", "SENTRY_DSN",
+	"SENTRY_SAMPLE_RATE",
+}
+
+// setupConfigEnv clears the settings read by the tests, then sets the
+// required ones.
+func setupConfigEnv(t *testing.T) {
+	t.Helper()
+
+	for _, key := range configTestKeys {
+		t.Setenv(key, "")
+	}
+
+	t.Setenv("PORT", "8083")
+	t.Setenv("DB_HOST", "db")
+	t.Setenv("DB_PORT", "3306")
+	t.Setenv("DB_USER", "synk")
+	t.Setenv("QUEUER_ENDPOINT", "http://queuer:8082/")
+	t.Setenv("AUTH_ENDPOINT", "http://auth:8081")
+}
+
+func TestConfigLoad(t *testing.T) {
+	setupConfigEnv(t)
+	t.Setenv("SERVER_WRITE_TIMEOUT", "45s")
+	t.Setenv("SHUTDOWN_TIMEOUT", "10")
+	t.Setenv("RATE_LIMIT_WRITE", "off")
+	t.Setenv("WEB_ENDPOINT", "https://app.synk.dev, https://admin.synk.dev")
+	t.Setenv("LOG_LEVEL", "warn")
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if cfg.Server.Port != "8083" || !cfg.Server.TLS || cfg.Server.RootCertFilePath != config.DEFAULT_ROOT_CERTIFICATE_FILE_PATH {
+		t.Errorf("expected TLS and the default root CA outside production, got %+v", cfg.Server)
+	}
+	if cfg.Server.WriteTimeout != 45*time.Second || cfg.Server.ShutdownTimeout != 10*time.Second {
+		t.Errorf("expected timeouts from env, got %+v", cfg.Server)
+	}
+	if cfg.Server.ReadHeaderTimeout != 5*time.Second || cfg.IdempotencyTtl != 24*time.Hour {
+		t.Errorf("expected defaults for unset durations, got %s %s", cfg.Server.ReadHeaderTimeout, cfg.IdempotencyTtl)
+	}
+	if cfg.QueuerEndpoint != "http://queuer:8082" {
+		t.Errorf("expected the trailing slash trimmed, got %s", cfg.QueuerEndpoint)
+	}
+	if cfg.RateLimits.Write.Requests != 0 || cfg.RateLimits.Read.Requests != 300 {
+		t.Errorf("expected the write limit off and the default read limit, got %+v", cfg.RateLimits)
+	}
+	if len(cfg.Cors.AllowedOrigins) != 2 || cfg.Cors.AllowedMethods != config.DEFAULT_CORS_ALLOWED_METHODS || cfg.Cors.ExposedHeaders != config.DEFAULT_CORS_EXPOSED_HEADERS {
+		t.Errorf("unexpected cors config %+v", cfg.Cors)
+	}
+	if cfg.Log.Level.String() != "WARN" || cfg.Reporter.Kind != config.ERROR_REPORTER_STDOUT {
+		t.Errorf("unexpected log or reporter config %+v %+v", cfg.Log, cfg.Reporter)
+	}
+
+	t.Setenv("ENV", "production")
+
+	cfg, err = config.Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if cfg.Server.TLS || cfg.Server.RootCertFilePath != "" {
+		t.Errorf("expected plain HTTP and the system roots in production, got %+v", cfg.Server)
+	}
+}
+
+func TestConfigLoad_ReportsAllProblems(t *testing.T) {
+	setupConfigEnv(t)
+	t.Setenv("PORT", "http")
+	t.Setenv("DB_HOST", "")
+	t.Setenv("AUTH_ENDPOINT", "")
+	t.Setenv("SERVER_IDLE_TIMEOUT", "forever")
+	t.Setenv("RATE_LIMIT_READ", "60")
+	t.Setenv("LOG_FORMAT", "xml")
+	t.Setenv("TLS_CLIENT_CERT_FILE_PATH", "/certs/client.pem")
+
+	_, err := config.Load()
+	if err == nil {
+		t.Fatal("expected Load to fail")
+	}
+
+	for _, key := range []string{"PORT", "DB_HOST", "AUTH_ENDPOINT", "SERVER_IDLE_TIMEOUT", "RATE_LIMIT_READ", "LOG_FORMAT", "TLS_CLIENT_KEY_FILE_PATH"} {
+		if !strings.Contains(err.Error(), key) {
+			t.Errorf("expected %s in the report, got %v", key, err)
+		}
+	}
+}
+
+func TestConfigLoad_File(t *testing.T) {
+	setupConfigEnv(t)
+
+	path := filepath.Join(t.TempDir(), "gateway.env")
+	content := "# gateway\nexport PORT=9000\nDB_NAME=\"synk_file\"\nLOG_FORMAT=text # for local runs\nREADINESS_TIMEOUT=\n"
+
+	if writeErr := os.WriteFile(path, []byte(content), 0600); writeErr != nil {
+		t.Fatalf("failed to write config file: %v", writeErr)
+	}
+
+	t.Setenv("CONFIG_FILE", path)
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if cfg.Server.Port != "8083" {
+		t.Errorf("expected env to win over the file, got %s", cfg.Server.Port)
+	}
+	if cfg.DB.Name != "synk_file" || cfg.Log.Format != config.LOG_FORMAT_TEXT || cfg.ReadinessTimeout != 2*time.Second {
+		t.Errorf("expected settings from the file, got %s %s %s", cfg.DB.Name, cfg.Log.Format, cfg.ReadinessTimeout)
+	}
+
+	t.Setenv("CONFIG_FILE", filepath.Join(t.TempDir(), "missing.env"))
+
+	if _, err := config.Load(); err == nil {
+		t.Error("expected an error for a missing CONFIG_FILE")
+	}
+}
+
+func TestConfigLoad_Reporter(t *testing.T) {
+	cases := []struct {
+		kind     string
+		dsn      string
+		expected string
+		ok       bool
+	}{
+		{"", "https://key@sentry.example/1", config.ERROR_REPORTER_SENTRY, true},
+		{"", "", config.ERROR_REPORTER_STDOUT, true},
+		{"none", "https://key@sentry.example/1", config.ERROR_REPORTER_NONE, true},
+		{"stdout", "", config.ERROR_REPORTER_STDOUT, true},
+		{"sentry", "", "", false},
+		{"datadog", "", "", false},
+	}
+
+	for _, c := range cases {
+		setupConfigEnv(t)
+		t.Setenv("ERROR_REPORTER", c.kind)
+		t.Setenv("SENTRY_DSN", c.dsn)
+
+		cfg, err := config.Load()
+
+		if (err == nil) != c.ok || (err == nil && cfg.Reporter.Kind != c.expected) {
+			t.Errorf("ERROR_REPORTER %q with SENTRY_DSN %q gave %+v, %v; want %q, ok %v", c.kind, c.dsn, cfg, err, c.expected, c.ok)
+		}
+	}
+}
+
+func TestParseRateLimit(t *testing.T) {
+	if limit, err := config.ParseRateLimit("60/1m"); err != nil || limit.Requests != 60 || limit.Period != time.Minute {
+		t.Errorf("expected valid limit, got %+v %v", limit, err)
+	}
+	if limit, err := config.ParseRateLimit("off"); err != nil || limit.Requests != 0 {
+		t.Errorf("expected disabled limit, got %+v %v", limit, err)
+	}
+
+	for _, value := range []string{"60", "0/1m", "ten/1m", "60/soon"} {
+		if _, err := config.ParseRateLimit(value); err == nil {
+			t.Errorf("expected %q to be rejected", value)
+		}
+	}
+}
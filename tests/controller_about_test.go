@@ -4,25 +4,18 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
-	"os"
-	"synk/gateway/app"
+	"synk/gateway/app/config"
 	"synk/gateway/app/controller"
 	"testing"
 )
 
 func TestAbout_HandleAbout(t *testing.T) {
+	store, _ := setupControllerStore(t)
 
-	db, err := app.InitDB(true)
-	if err != nil {
-		t.Fatalf("db connection failed: %v", err)
-	}
-	defer db.Close()
+	cfg := config.Default()
+	cfg.Server.Port = "9999"
 
-	aboutController := controller.NewAbout(db)
-
-	originalPort := os.Getenv("PORT")
-	os.Setenv("PORT", "9999")
-	defer os.Setenv("PORT", originalPort)
+	aboutController := controller.NewAbout(store.Repositories(), cfg)
 
 	req, _ := http.NewRequest("GET", "/about", nil)
 	rr := httptest.NewRecorder()
@@ -37,6 +30,8 @@ func TestAbout_HandleAbout(t *testing.T) {
 		ServerPort string `json:"server_port"`
 		AppPort    string `json:"app_port"`
 		DbWorking  bool   `json:"db_working"`
+		Version    string `json:"version"`
+		Commit     string `json:"commit"`
 	}
 	type AboutResponse struct {
 		Ok   bool      `json:"ok"`
@@ -44,7 +39,7 @@ func TestAbout_HandleAbout(t *testing.T) {
 	}
 
 	var response AboutResponse
-	err = json.Unmarshal(rr.Body.Bytes(), &response)
+	err := json.Unmarshal(rr.Body.Bytes(), &response)
 	if err != nil {
 		t.Fatalf("failed to decode response body: %v", err)
 	}
@@ -54,10 +49,18 @@ func TestAbout_HandleAbout(t *testing.T) {
 	}
 
 	if !response.Info.DbWorking {
-		t.Error("expected 'db_working' to be true (since we connected to a real DB)")
+		t.Error("expected 'db_working' to be true (since the repository is reachable)")
 	}
 
 	if response.Info.AppPort != "9999" {
 		t.Errorf("expected 'app_port' to be '9999', got '%s'", response.Info.AppPort)
 	}
+
+	if response.Info.ServerPort != "9999" {
+		t.Errorf("expected 'server_port' to be '9999', got '%s'", response.Info.ServerPort)
+	}
+
+	if response.Info.Version != "dev" || response.Info.Commit == "" {
+		t.Errorf("expected build version and commit, got '%s' '%s'", response.Info.Version, response.Info.Commit)
+	}
 }
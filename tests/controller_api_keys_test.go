@@ -0,0 +1,185 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"synk/gateway/app/auth"
+	"synk/gateway/app/controller"
+	"synk/gateway/app/model/memory"
+	"testing"
+	"time"
+)
+
+func injectScopesContext(r *http.Request, scopes ...string) *http.Request {
+	ctx := context.WithValue(r.Context(), controller.CONTEXT_SCOPES_KEY, scopes)
+	return r.WithContext(ctx)
+}
+
+func requestApiKeyCreation(store *memory.Store, userId int, callerScopes []string, reqBody controller.HandleApiKeyCreateRequest) *httptest.ResponseRecorder {
+	apiKeysController := controller.NewApiKeys(store.Repositories())
+
+	jsonBody, _ := json.Marshal(reqBody)
+
+	req, _ := http.NewRequest("POST", "/api_keys", bytes.NewBuffer(jsonBody))
+	req = injectUserContext(req, userId)
+	req = injectScopesContext(req, callerScopes...)
+	rr := httptest.NewRecorder()
+
+	apiKeysController.HandleCreate(rr, req)
+
+	return rr
+}
+
+func createApiKeyThroughController(t *testing.T, store *memory.Store, userId int, scopes ...string) controller.CreateApiKeyCreateDataResponse {
+	t.Helper()
+
+	rr := requestApiKeyCreation(store, userId, []string{auth.SCOPE_ALL}, controller.HandleApiKeyCreateRequest{
+		ApiKeyName:   "CI",
+		ApiKeyScopes: scopes,
+	})
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("wrong status code: got %v want %v. Body: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+
+	var response controller.HandleApiKeyCreateResponse
+	json.Unmarshal(rr.Body.Bytes(), &response)
+
+	return response.Data
+}
+
+func TestApiKeys_HandleCreateAndList(t *testing.T) {
+	store, userId := setupControllerStore(t)
+
+	created := createApiKeyThroughController(t, store, userId, auth.SCOPE_POSTS_READ)
+
+	if created.ApiKeyId == 0 || !strings.HasPrefix(created.ApiKey, auth.API_KEY_PREFIX) {
+		t.Fatalf("unexpected created key %+v", created)
+	}
+	if !strings.HasPrefix(created.ApiKey, created.ApiKeyPrefix) {
+		t.Errorf("prefix %s does not match key", created.ApiKeyPrefix)
+	}
+
+	apiKeysController := controller.NewApiKeys(store.Repositories())
+
+	req, _ := http.NewRequest("GET", "/api_keys", nil)
+	req = injectUserContext(req, userId)
+	rr := httptest.NewRecorder()
+
+	apiKeysController.HandleList(rr, req)
+
+	if strings.Contains(rr.Body.String(), created.ApiKey) {
+		t.Error("list must not expose the plain key")
+	}
+
+	var response controller.HandleApiKeyListResponse
+	json.Unmarshal(rr.Body.Bytes(), &response)
+
+	if len(response.Data) != 1 || response.Data[0].ApiKeyPrefix != created.ApiKeyPrefix {
+		t.Fatalf("expected created key to be listed, got %+v", response.Data)
+	}
+	if len(response.Data[0].ApiKeyScopes) != 1 || response.Data[0].ApiKeyScopes[0] != auth.SCOPE_POSTS_READ {
+		t.Errorf("unexpected scopes %v", response.Data[0].ApiKeyScopes)
+	}
+}
+
+func TestApiKeys_HandleCreateInvalid(t *testing.T) {
+	store, userId := setupControllerStore(t)
+
+	cases := []controller.HandleApiKeyCreateRequest{
+		{ApiKeyName: " ", ApiKeyScopes: []string{auth.SCOPE_POSTS_READ}},
+		{ApiKeyName: "No Scopes"},
+		{ApiKeyName: "Unknown Scope", ApiKeyScopes: []string{"posts:destroy"}},
+	}
+
+	for _, reqBody := range cases {
+		rr := requestApiKeyCreation(store, userId, []string{auth.SCOPE_ALL}, reqBody)
+
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("%+v: wrong status code: got %v want %v", reqBody, rr.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestApiKeys_HandleCreateCannotEscalate(t *testing.T) {
+	store, userId := setupControllerStore(t)
+
+	rr := requestApiKeyCreation(store, userId, []string{auth.SCOPE_API_KEYS_WRITE, auth.SCOPE_POSTS_READ}, controller.HandleApiKeyCreateRequest{
+		ApiKeyName:   "Escalated",
+		ApiKeyScopes: []string{auth.SCOPE_POSTS_READ, auth.SCOPE_POSTS_PUBLISH},
+	})
+
+	if rr.Code != http.StatusForbidden {
+		t.Errorf("wrong status code: got %v want %v", rr.Code, http.StatusForbidden)
+	}
+	if !strings.Contains(rr.Body.String(), auth.SCOPE_POSTS_PUBLISH) {
+		t.Errorf("expected missing scope to be named, got %s", rr.Body.String())
+	}
+}
+
+func TestApiKeys_AuthMiddlewareAndRevoke(t *testing.T) {
+	store, userId := setupControllerStore(t)
+
+	created := createApiKeyThroughController(t, store, userId, auth.SCOPE_POSTS_READ)
+
+	authenticator := auth.NewAuthenticator(nil, auth.NewCache(time.Minute, time.Minute), http.DefaultClient, "")
+
+	var ctxUserId int
+	handler := controller.Chain(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctxUserId = r.Context().Value(controller.CONTEXT_USER_ID_KEY).(int)
+		}),
+		controller.Auth(authenticator, store.Repositories().ApiKeys),
+		controller.RequireScope(auth.SCOPE_POSTS_READ),
+	)
+
+	req, _ := http.NewRequest("GET", "/post", nil)
+	req.Header.Set(controller.API_KEY_HEADER, created.ApiKey)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK || ctxUserId != userId {
+		t.Fatalf("expected key to resolve to user %d, got status %d user %d", userId, rr.Code, ctxUserId)
+	}
+
+	publishHandler := controller.Chain(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+		controller.Auth(authenticator, store.Repositories().ApiKeys),
+		controller.RequireScope(auth.SCOPE_POSTS_PUBLISH),
+	)
+	publishRr := httptest.NewRecorder()
+	publishHandler.ServeHTTP(publishRr, req)
+
+	if publishRr.Code != http.StatusForbidden {
+		t.Errorf("expected key without posts:publish to be forbidden, got %d", publishRr.Code)
+	}
+
+	list, _ := store.Repositories().ApiKeys.List(context.Background(), userId)
+	if len(list) != 1 || list[0].LastUsedAt == "" {
+		t.Errorf("expected last used to be recorded, got %+v", list)
+	}
+
+	apiKeysController := controller.NewApiKeys(store.Repositories())
+
+	jsonBody, _ := json.Marshal(controller.HandleApiKeyRevokeRequest{ApiKeyId: created.ApiKeyId})
+	revokeReq, _ := http.NewRequest("DELETE", "/api_keys", bytes.NewBuffer(jsonBody))
+	revokeReq = injectUserContext(revokeReq, userId)
+	revokeRr := httptest.NewRecorder()
+
+	apiKeysController.HandleRevoke(revokeRr, revokeReq)
+
+	if revokeRr.Code != http.StatusOK {
+		t.Fatalf("wrong status code: got %v want %v", revokeRr.Code, http.StatusOK)
+	}
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusUnauthorized {
+		t.Errorf("expected revoked key to be refused, got %d", rr.Code)
+	}
+}
@@ -0,0 +1,208 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"synk/gateway/app/controller"
+	"synk/gateway/app/model"
+	"synk/gateway/app/model/memory"
+	"testing"
+)
+
+func requestAuditList(store *memory.Store, userId int, query string) *httptest.ResponseRecorder {
+	auditController := controller.NewAuditLogs(store.Repositories())
+
+	req, _ := http.NewRequest("GET", "/audit?"+query, nil)
+	req = injectUserContext(req, userId)
+	rr := httptest.NewRecorder()
+
+	auditController.HandleList(rr, req)
+
+	return rr
+}
+
+func TestAuditLogs_HandleRecordsChanges(t *testing.T) {
+	store, userId := setupControllerStore(t)
+
+	credsController := controller.NewIntCredentials(store.Repositories())
+	credId := createStoreCredential(t, store, "Audited", model.Telegram, `{"token": "old"}`, userId)
+
+	jsonBody, _ := json.Marshal(controller.HandleIntCredentialUpdateRequest{
+		IntCredentialId:     credId,
+		IntCredentialName:   "Audited Renamed",
+		IntCredentialType:   model.Telegram,
+		IntCredentialConfig: `{"token": "new"}`,
+	})
+
+	req, _ := http.NewRequest("PUT", "/int_credentials", bytes.NewBuffer(jsonBody))
+	req.Header.Set(controller.IF_MATCH_HEADER, controller.ETag(1))
+	req = injectUserContext(req, userId)
+	req = req.WithContext(context.WithValue(req.Context(), controller.CONTEXT_REQUEST_ID_KEY, "req-audit-1"))
+	rr := httptest.NewRecorder()
+
+	credsController.HandleUpdate(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("wrong status code: got %v want %v. Body: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+
+	jsonBody, _ = json.Marshal(controller.HandleIntCredentialDeleteRequest{IntCredentialId: credId})
+
+	req, _ = http.NewRequest("DELETE", "/int_credentials", bytes.NewBuffer(jsonBody))
+	req.Header.Set(controller.IF_MATCH_HEADER, controller.ETag(2))
+	req = injectUserContext(req, userId)
+	rr = httptest.NewRecorder()
+
+	credsController.HandleDelete(rr, req)
+
+	rr = requestAuditList(store, userId, "audit_entity_type=int_credential")
+
+	var response controller.HandleAuditLogListResponse
+	json.Unmarshal(rr.Body.Bytes(), &response)
+
+	if len(response.Data) != 2 {
+		t.Fatalf("expected update and delete entries, got %+v", response.Data)
+	}
+
+	deleted, updated := response.Data[0], response.Data[1]
+
+	if deleted.AuditAction != model.AuditActionDelete || string(deleted.AuditAfter) != "null" {
+		t.Errorf("expected delete entry without after snapshot, got %+v", deleted)
+	}
+	if updated.AuditAction != model.AuditActionUpdate || updated.AuditEntityId != credId || updated.RequestId != "req-audit-1" {
+		t.Errorf("unexpected update entry %+v", updated)
+	}
+	if strings.Contains(string(updated.AuditBefore), "old") || strings.Contains(string(updated.AuditAfter), "new") {
+		t.Errorf("expected credential config to be redacted, got %s / %s", updated.AuditBefore, updated.AuditAfter)
+	}
+	if !strings.Contains(string(updated.AuditAfter), "Audited Renamed") {
+		t.Errorf("expected after snapshot to hold the new name, got %s", updated.AuditAfter)
+	}
+
+	rr = requestAuditList(store, userId, "audit_action=update")
+	json.Unmarshal(rr.Body.Bytes(), &response)
+
+	if len(response.Data) != 1 {
+		t.Errorf("expected action filter to keep only the update, got %+v", response.Data)
+	}
+
+	rr = requestAuditList(store, userId+1, "")
+	json.Unmarshal(rr.Body.Bytes(), &response)
+
+	if len(response.Data) != 0 {
+		t.Errorf("expected entries to be hidden from another workspace, got %+v", response.Data)
+	}
+}
+
+func TestAuditLogs_HandleRecordsFullSnapshots(t *testing.T) {
+	store, userId := setupControllerStore(t)
+
+	templatesController := controller.NewTemplates(store.Repositories())
+	tplId := createStoreTemplate(t, store, "Audited Tpl", "Old content", userId)
+
+	jsonBody, _ := json.Marshal(controller.HandleTemplateUpdateRequest{
+		TemplateId:        tplId,
+		TemplateName:      "Audited Tpl",
+		TemplateContent:   "New content",
+		TemplateUrlImport: "https://synk.dev/template",
+	})
+
+	req, _ := http.NewRequest("PUT", "/templates", bytes.NewBuffer(jsonBody))
+	req.Header.Set(controller.IF_MATCH_HEADER, controller.ETag(1))
+	req = injectUserContext(req, userId)
+	rr := httptest.NewRecorder()
+
+	templatesController.HandleUpdate(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("wrong status code: got %v want %v. Body: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+
+	rr = requestAuditList(store, userId, "audit_entity_type=template&audit_action=update")
+
+	var response controller.HandleAuditLogListResponse
+	json.Unmarshal(rr.Body.Bytes(), &response)
+
+	if len(response.Data) != 1 {
+		t.Fatalf("expected the update entry, got %+v", response.Data)
+	}
+
+	var before, after model.TemplatesList
+
+	json.Unmarshal(response.Data[0].AuditBefore, &before)
+	json.Unmarshal(response.Data[0].AuditAfter, &after)
+
+	if before.TemplateContent != "Old content" || after.TemplateContent != "New content" {
+		t.Errorf("expected the content change in the snapshots, got %s / %s", response.Data[0].AuditBefore, response.Data[0].AuditAfter)
+	}
+	if before.TemplateVersion != 1 || after.TemplateVersion != 2 {
+		t.Errorf("expected the version change in the snapshots, got %d / %d", before.TemplateVersion, after.TemplateVersion)
+	}
+}
+
+// contextAuditLogs fails like the SQL repository does once the context is cancelled.
+type contextAuditLogs struct {
+	model.AuditLogsRepository
+}
+
+func (ca contextAuditLogs) Add(ctx context.Context, auditLog model.AuditLogAddData) (int, error) {
+	if ctx.Err() != nil {
+		return 0, ctx.Err()
+	}
+
+	return ca.AuditLogsRepository.Add(ctx, auditLog)
+}
+
+func TestAuditLogs_RecordAfterClientGone(t *testing.T) {
+	store, userId := setupControllerStore(t)
+
+	repositories := store.Repositories()
+	repositories.AuditLogs = contextAuditLogs{repositories.AuditLogs}
+
+	req, _ := http.NewRequest("DELETE", "/posts", nil)
+	req = injectUserContext(req, userId)
+
+	ctx, cancel := context.WithCancel(req.Context())
+	cancel()
+
+	controller.NewAuditor(repositories).Record(req.WithContext(ctx), model.AuditActionDelete, model.AuditEntityPost, 1, map[string]any{"post_id": 1}, nil)
+
+	var response controller.HandleAuditLogListResponse
+	json.Unmarshal(requestAuditList(store, userId, "").Body.Bytes(), &response)
+
+	if len(response.Data) != 1 {
+		t.Errorf("expected the audit to be recorded after the client went away, got %d entries", len(response.Data))
+	}
+}
+
+func TestAuditLogs_HandleListInvalidFilter(t *testing.T) {
+	store, userId := setupControllerStore(t)
+
+	for _, query := range []string{"since=yesterday", "limit=-1", "audit_entity_id=abc"} {
+		if rr := requestAuditList(store, userId, query); rr.Code != http.StatusBadRequest {
+			t.Errorf("expected 400 for %s, got %d", query, rr.Code)
+		}
+	}
+}
+
+func TestAuditLogs_RedactSnapshot(t *testing.T) {
+	redacted := controller.RedactSnapshot(map[string]any{
+		"api_key":  "sk_live_secret",
+		"name":     "kept",
+		"settings": map[string]any{"refresh_token": "abc", "chat_id": 1},
+	})
+
+	if strings.Contains(redacted, "sk_live_secret") || strings.Contains(redacted, "abc") {
+		t.Errorf("expected secrets to be redacted, got %s", redacted)
+	}
+	if !strings.Contains(redacted, `"name":"kept"`) || !strings.Contains(redacted, `"chat_id":1`) {
+		t.Errorf("expected other fields to be kept, got %s", redacted)
+	}
+	if controller.RedactSnapshot(nil) != "" {
+		t.Error("expected nil snapshot to be empty")
+	}
+}
@@ -0,0 +1,136 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"synk/gateway/app/config"
+	"synk/gateway/app/controller"
+	"testing"
+	"time"
+)
+
+type downAbout struct{}
+
+func (downAbout) Ping(ctx context.Context) bool {
+	return false
+}
+
+func requestReadiness(t *testing.T, health *controller.Health) (int, controller.HandleReadinessResponse) {
+	t.Helper()
+
+	req, _ := http.NewRequest("GET", "/readyz", nil)
+	rr := httptest.NewRecorder()
+
+	health.HandleReady(rr, req)
+
+	var response controller.HandleReadinessResponse
+
+	if decodeErr := json.Unmarshal(rr.Body.Bytes(), &response); decodeErr != nil {
+		t.Fatalf("failed to decode response body: %v", decodeErr)
+	}
+
+	return rr.Code, response
+}
+
+func TestHealth_HandleLive(t *testing.T) {
+	store, _ := setupControllerStore(t)
+
+	health := controller.NewHealth(store.Repositories(), http.DefaultClient, config.Default())
+
+	req, _ := http.NewRequest("GET", "/healthz", nil)
+	rr := httptest.NewRecorder()
+
+	health.HandleLive(rr, req)
+
+	var response controller.HandleHealthResponse
+	json.Unmarshal(rr.Body.Bytes(), &response)
+
+	if rr.Code != http.StatusOK || !response.Resource.Ok || response.Status != controller.HEALTH_STATUS_UP {
+		t.Errorf("expected 200 and up, got %d %+v", rr.Code, response)
+	}
+}
+
+func TestHealth_HandleReady(t *testing.T) {
+	store, _ := setupControllerStore(t)
+
+	authServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer authServer.Close()
+
+	queuerStatus := http.StatusOK
+	queuerServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(queuerStatus)
+	}))
+	defer queuerServer.Close()
+
+	cfg := config.Default()
+	cfg.Auth.Endpoint = authServer.URL
+	cfg.QueuerEndpoint = queuerServer.URL
+
+	health := controller.NewHealth(store.Repositories(), http.DefaultClient, cfg)
+
+	status, response := requestReadiness(t, health)
+
+	if status != http.StatusOK || !response.Resource.Ok {
+		t.Fatalf("expected ready, got %d %+v", status, response)
+	}
+
+	for _, name := range []string{"database", "auth", "queuer"} {
+		if response.Checks[name].Status != controller.HEALTH_STATUS_UP {
+			t.Errorf("expected %s up, got %+v", name, response.Checks[name])
+		}
+	}
+
+	queuerStatus = http.StatusServiceUnavailable
+
+	status, response = requestReadiness(t, health)
+
+	if status != http.StatusServiceUnavailable || response.Resource.Error != "not ready, down: queuer" {
+		t.Errorf("expected 503 for the queuer, got %d %+v", status, response.Resource)
+	}
+	if check := response.Checks["queuer"]; check.Status != controller.HEALTH_STATUS_DOWN || check.Error != "answered 503" {
+		t.Errorf("expected the queuer down, got %+v", check)
+	}
+	if response.Checks["auth"].Status != controller.HEALTH_STATUS_UP {
+		t.Errorf("expected auth still up, got %+v", response.Checks["auth"])
+	}
+}
+
+func TestHealth_HandleReadyTimeoutAndSkipped(t *testing.T) {
+	store, _ := setupControllerStore(t)
+	repositories := store.Repositories()
+	repositories.About = downAbout{}
+
+	queuerServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer queuerServer.Close()
+
+	cfg := config.Default()
+	cfg.QueuerEndpoint = queuerServer.URL
+	cfg.ReadinessTimeout = time.Millisecond * 50
+
+	health := controller.NewHealth(repositories, http.DefaultClient, cfg)
+
+	start := time.Now()
+	status, response := requestReadiness(t, health)
+
+	if time.Since(start) > time.Second/2 {
+		t.Errorf("expected checks to stop at the readiness timeout, took %s", time.Since(start))
+	}
+	if status != http.StatusServiceUnavailable || response.Resource.Error != "not ready, down: database, queuer" {
+		t.Errorf("expected database and queuer down, got %d %+v", status, response.Resource)
+	}
+	if response.Checks["auth"].Status != controller.HEALTH_STATUS_SKIPPED {
+		t.Errorf("expected auth skipped without endpoint, got %+v", response.Checks["auth"])
+	}
+	if check := response.Checks["queuer"]; check.Status != controller.HEALTH_STATUS_DOWN || check.LatencyMs < 50 {
+		t.Errorf("expected the queuer to time out, got %+v", check)
+	}
+}
@@ -3,93 +3,44 @@ package tests
 import (
 	"bytes"
 	"context"
-	"database/sql"
 	"encoding/json"
-	"fmt"
 	"net/http"
 	"net/http/httptest"
-	"synk/gateway/app"
+	"strconv"
 	"synk/gateway/app/controller"
+	"synk/gateway/app/model"
+	"synk/gateway/app/model/memory"
 	"testing"
 )
 
-func setupProfileControllerDB(t *testing.T) (*sql.DB, int) {
-	db, err := app.InitDB(true)
-	if err != nil {
-		t.Fatalf("db connection failed: %v", err)
-	}
-
-	userId, err := createTestUserForProfiles(db)
-	if err != nil {
-		t.Fatalf("failed to create test user: %v", err)
-	}
-
-	return db, userId
-}
-
-func createTestUserForProfiles(db *sql.DB) (int, error) {
-	var userId int
-
-	userName := "Profile Controller User"
-	userEmail := "profile_controller@synk.com"
-	userPass := "123456"
-
-	insertRes, insertErr := db.ExecContext(
-		context.Background(),
-		`INSERT INTO synk.user (user_name, user_email, user_pass) VALUES (?, ?, ?)`,
-		userName, userEmail, userPass,
-	)
-
-	if insertErr != nil {
-
-		var existingId int
-		err := db.QueryRow("SELECT user_id FROM user WHERE user_email = ?", userEmail).Scan(&existingId)
-		if err == nil {
-			return existingId, nil
-		}
-		return 0, fmt.Errorf("failed to create user: %s", insertErr.Error())
-	}
-
-	id, _ := insertRes.LastInsertId()
-	userId = int(id)
-	return userId, nil
-}
-
 func injectProfileUserContext(r *http.Request, userId int) *http.Request {
 	ctx := context.WithValue(r.Context(), controller.CONTEXT_USER_ID_KEY, userId)
+	ctx = context.WithValue(ctx, controller.CONTEXT_WORKSPACE_ID_KEY, userId)
 	return r.WithContext(ctx)
 }
 
-func getValidColorForProfile(t *testing.T, db *sql.DB) int {
-	var colorId int
-	err := db.QueryRow("SELECT color_id FROM color LIMIT 1").Scan(&colorId)
-	if err != nil {
-		t.Fatalf("Setup failed: No color found in DB. %v", err)
-	}
-	return colorId
-}
+func createStoreProfile(t *testing.T, store *memory.Store, name string, colorId int, credentials []int, userId int) int {
+	t.Helper()
 
-func createDummyCredentialForProfile(t *testing.T, db *sql.DB, userId int) int {
-	res, err := db.ExecContext(context.Background(),
-		`INSERT INTO integration_credential (int_credential_name, int_credential_type, int_credential_config, user_id)
-		 VALUES ('Profile Test Cred', 'discord', '{}', ?)`, userId)
+	id, err := store.Repositories().IntProfiles.Add(context.Background(), model.IntProfileAddData{
+		IntProfileName: name,
+		ColorId:        colorId,
+		WorkspaceId:    userId,
+	}, credentials, userId)
 	if err != nil {
-		t.Fatalf("Setup failed: Could not create dummy credential: %v", err)
+		t.Fatalf("Setup failed: Could not create profile: %v", err)
 	}
-	id, _ := res.LastInsertId()
-	return int(id)
+
+	return id
 }
 
 func TestIntProfiles_HandleCreate(t *testing.T) {
-	db, userId := setupProfileControllerDB(t)
-	defer db.Close()
-	defer db.Exec("DELETE FROM user WHERE user_id = ?", userId)
+	store, userId := setupControllerStore(t)
 
-	colorId := getValidColorForProfile(t, db)
-	credId := createDummyCredentialForProfile(t, db, userId)
-	defer db.Exec("DELETE FROM integration_credential WHERE int_credential_id = ?", credId)
+	colorId := store.AddColor("Primary Blue", "007BFF")
+	credId := createStoreCredential(t, store, "Profile Test Cred", model.Discord, "{}", userId)
 
-	pController := controller.NewIntProfiles(db)
+	pController := controller.NewIntProfiles(store.Repositories())
 
 	reqBody := controller.HandleIntProfileCreateRequest{
 		IntProfileName:  "Controller Create Profile",
@@ -117,22 +68,41 @@ func TestIntProfiles_HandleCreate(t *testing.T) {
 	if response.Data.IntProfileId == 0 {
 		t.Error("Expected valid IntProfileId")
 	}
+}
+
+func TestIntProfiles_HandleCreateUnknownCredential(t *testing.T) {
+	store, userId := setupControllerStore(t)
+
+	colorId := store.AddColor("Primary Blue", "007BFF")
+	otherCredId := createStoreCredential(t, store, "Other User Cred", model.Discord, "{}", userId+1)
+
+	pController := controller.NewIntProfiles(store.Repositories())
+
+	reqBody := controller.HandleIntProfileCreateRequest{
+		IntProfileName:  "Invalid Credentials",
+		ColorId:         colorId,
+		CredentialsList: []int{otherCredId},
+	}
+	jsonBody, _ := json.Marshal(reqBody)
+
+	req, _ := http.NewRequest("POST", "/int_profiles", bytes.NewBuffer(jsonBody))
+	req = injectProfileUserContext(req, userId)
+	rr := httptest.NewRecorder()
+
+	pController.HandleCreate(rr, req)
 
-	db.Exec("DELETE FROM integration_profile WHERE int_profile_id = ?", response.Data.IntProfileId)
-	db.Exec("DELETE FROM integration_group WHERE int_profile_id = ?", response.Data.IntProfileId)
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("wrong status code: got %v want %v", rr.Code, http.StatusBadRequest)
+	}
 }
 
 func TestIntProfiles_HandleBasicList(t *testing.T) {
-	db, userId := setupProfileControllerDB(t)
-	defer db.Close()
-	defer db.Exec("DELETE FROM user WHERE user_id = ?", userId)
+	store, userId := setupControllerStore(t)
 
-	pController := controller.NewIntProfiles(db)
-	colorId := getValidColorForProfile(t, db)
+	pController := controller.NewIntProfiles(store.Repositories())
+	colorId := store.AddColor("Primary Blue", "007BFF")
 
-	res, _ := db.Exec("INSERT INTO integration_profile (int_profile_name, color_id, user_id) VALUES ('BasicList Item', ?, ?)", colorId, userId)
-	id, _ := res.LastInsertId()
-	defer db.Exec("DELETE FROM integration_profile WHERE int_profile_id = ?", id)
+	createStoreProfile(t, store, "BasicList Item", colorId, []int{}, userId)
 
 	req, _ := http.NewRequest("GET", "/int_profiles/basic", nil)
 	req = injectProfileUserContext(req, userId)
@@ -153,22 +123,13 @@ func TestIntProfiles_HandleBasicList(t *testing.T) {
 }
 
 func TestIntProfiles_HandleList(t *testing.T) {
-	db, userId := setupProfileControllerDB(t)
-	defer db.Close()
-	defer db.Exec("DELETE FROM user WHERE user_id = ?", userId)
-
-	pController := controller.NewIntProfiles(db)
-
-	colorId := getValidColorForProfile(t, db)
-	credId := createDummyCredentialForProfile(t, db, userId)
-	defer db.Exec("DELETE FROM integration_credential WHERE int_credential_id = ?", credId)
+	store, userId := setupControllerStore(t)
 
-	res, _ := db.Exec("INSERT INTO integration_profile (int_profile_name, color_id, user_id) VALUES ('DetailedList Item', ?, ?)", colorId, userId)
-	profId, _ := res.LastInsertId()
-	defer db.Exec("DELETE FROM integration_profile WHERE int_profile_id = ?", profId)
+	pController := controller.NewIntProfiles(store.Repositories())
 
-	db.Exec("INSERT INTO integration_group (int_profile_id, int_credential_id) VALUES (?, ?)", profId, credId)
-	defer db.Exec("DELETE FROM integration_group WHERE int_profile_id = ?", profId)
+	colorId := store.AddColor("Primary Blue", "007BFF")
+	credId := createStoreCredential(t, store, "Profile Test Cred", model.Discord, "{}", userId)
+	profId := createStoreProfile(t, store, "DetailedList Item", colorId, []int{credId}, userId)
 
 	req, _ := http.NewRequest("GET", "/int_profiles", nil)
 	req = injectProfileUserContext(req, userId)
@@ -185,7 +146,7 @@ func TestIntProfiles_HandleList(t *testing.T) {
 
 	found := false
 	for _, p := range response.Data {
-		if p.IntProfileId == int(profId) {
+		if p.IntProfileId == profId {
 			found = true
 
 			if len(p.Credentials) == 0 {
@@ -199,22 +160,16 @@ func TestIntProfiles_HandleList(t *testing.T) {
 }
 
 func TestIntProfiles_HandleUpdate(t *testing.T) {
-	db, userId := setupProfileControllerDB(t)
-	defer db.Close()
-	defer db.Exec("DELETE FROM user WHERE user_id = ?", userId)
+	store, userId := setupControllerStore(t)
 
-	pController := controller.NewIntProfiles(db)
+	pController := controller.NewIntProfiles(store.Repositories())
 
-	colorId := getValidColorForProfile(t, db)
-	credId := createDummyCredentialForProfile(t, db, userId)
-	defer db.Exec("DELETE FROM integration_credential WHERE int_credential_id = ?", credId)
-
-	res, _ := db.Exec("INSERT INTO integration_profile (int_profile_name, color_id, user_id) VALUES ('Old Name', ?, ?)", colorId, userId)
-	profId, _ := res.LastInsertId()
-	defer db.Exec("DELETE FROM integration_profile WHERE int_profile_id = ?", profId)
+	colorId := store.AddColor("Primary Blue", "007BFF")
+	credId := createStoreCredential(t, store, "Profile Test Cred", model.Discord, "{}", userId)
+	profId := createStoreProfile(t, store, "Old Name", colorId, []int{}, userId)
 
 	reqBody := controller.HandleIntProfileUpdateRequest{
-		IntProfileId:    int(profId),
+		IntProfileId:    profId,
 		IntProfileName:  "Updated Name",
 		ColorId:         colorId,
 		CredentialsList: []int{credId},
@@ -222,6 +177,7 @@ func TestIntProfiles_HandleUpdate(t *testing.T) {
 	jsonBody, _ := json.Marshal(reqBody)
 
 	req, _ := http.NewRequest("PUT", "/int_profiles", bytes.NewBuffer(jsonBody))
+	req.Header.Set(controller.IF_MATCH_HEADER, controller.ETag(1))
 	req = injectProfileUserContext(req, userId)
 	rr := httptest.NewRecorder()
 
@@ -237,27 +193,28 @@ func TestIntProfiles_HandleUpdate(t *testing.T) {
 	if response.Data.RowsAffected != 1 {
 		t.Errorf("expected 1 row affected, got %d", response.Data.RowsAffected)
 	}
+
+	credentials, _ := store.Repositories().IntCredentials.BasicListByProfile(context.Background(), profId, userId)
+	if len(credentials) != 1 || credentials[0].IntCredentialId != credId {
+		t.Errorf("expected credential links to be replaced, got %v", credentials)
+	}
 }
 
 func TestIntProfiles_HandleDelete(t *testing.T) {
-	db, userId := setupProfileControllerDB(t)
-	defer db.Close()
-	defer db.Exec("DELETE FROM user WHERE user_id = ?", userId)
-
-	pController := controller.NewIntProfiles(db)
-	colorId := getValidColorForProfile(t, db)
+	store, userId := setupControllerStore(t)
 
-	res, _ := db.Exec("INSERT INTO integration_profile (int_profile_name, color_id, user_id) VALUES ('To Delete', ?, ?)", colorId, userId)
-	profId, _ := res.LastInsertId()
+	pController := controller.NewIntProfiles(store.Repositories())
+	colorId := store.AddColor("Primary Blue", "007BFF")
 
-	defer db.Exec("DELETE FROM integration_profile WHERE int_profile_id = ?", profId)
+	profId := createStoreProfile(t, store, "To Delete", colorId, []int{}, userId)
 
 	reqBody := controller.HandleIntProfileDeleteRequest{
-		IntProfileId: int(profId),
+		IntProfileId: profId,
 	}
 	jsonBody, _ := json.Marshal(reqBody)
 
 	req, _ := http.NewRequest("DELETE", "/int_profiles", bytes.NewBuffer(jsonBody))
+	req.Header.Set(controller.IF_MATCH_HEADER, controller.ETag(1))
 	req = injectProfileUserContext(req, userId)
 	rr := httptest.NewRecorder()
 
@@ -274,3 +231,103 @@ func TestIntProfiles_HandleDelete(t *testing.T) {
 		t.Errorf("expected 1 row affected (deleted), got %d", response.Data.RowsAffected)
 	}
 }
+
+func TestIntProfiles_HandleClone(t *testing.T) {
+	store, userId := setupControllerStore(t)
+
+	pController := controller.NewIntProfiles(store.Repositories())
+
+	colorId := store.AddColor("Primary Blue", "007BFF")
+	credId := createStoreCredential(t, store, "Profile Test Cred", model.Discord, "{}", userId)
+	profId := createStoreProfile(t, store, "To Clone", colorId, []int{credId}, userId)
+
+	reqBody := controller.HandleIntProfileCloneRequest{
+		IntProfileId: profId,
+	}
+	jsonBody, _ := json.Marshal(reqBody)
+
+	req, _ := http.NewRequest("POST", "/int_profiles/clone", bytes.NewBuffer(jsonBody))
+	req = injectProfileUserContext(req, userId)
+	rr := httptest.NewRecorder()
+
+	pController.HandleClone(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Errorf("wrong status code: got %v want %v. Body: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+
+	var response controller.HandleIntProfileCreateResponse
+	json.Unmarshal(rr.Body.Bytes(), &response)
+
+	if response.Data.IntProfileId == 0 || response.Data.IntProfileId == profId {
+		t.Fatalf("Expected a new IntProfileId, got %d", response.Data.IntProfileId)
+	}
+
+	list, _ := store.Repositories().IntProfiles.List(context.Background(), strconv.Itoa(response.Data.IntProfileId), userId)
+	if len(list) != 1 || list[0].IntProfileName != "To Clone"+model.CLONE_NAME_SUFFIX {
+		t.Errorf("Expected cloned profile to be stored, got %v", list)
+	}
+
+	credentials, _ := store.Repositories().IntCredentials.BasicListByProfile(context.Background(), response.Data.IntProfileId, userId)
+	if len(credentials) != 1 || credentials[0].IntCredentialId != credId {
+		t.Errorf("Expected credential links to be cloned, got %v", credentials)
+	}
+}
+
+func TestIntProfiles_HandleUpdateApprovalRequiresOwner(t *testing.T) {
+	store, userId := setupControllerStore(t)
+
+	pController := controller.NewIntProfiles(store.Repositories())
+
+	colorId := store.AddColor("Primary Blue", "007BFF")
+	credId := createStoreCredential(t, store, "Profile Test Cred", model.Discord, "{}", userId)
+	profId, _ := store.Repositories().IntProfiles.Add(context.Background(), model.IntProfileAddData{
+		IntProfileName:             "Reviewed",
+		IntProfileRequiresApproval: true,
+		ColorId:                    colorId,
+		WorkspaceId:                userId,
+	}, []int{credId}, userId)
+
+	update := func(role model.WorkspaceRole, requiresApproval *bool) int {
+		jsonBody, _ := json.Marshal(controller.HandleIntProfileUpdateRequest{
+			IntProfileId:               profId,
+			IntProfileName:             "Reviewed",
+			IntProfileRequiresApproval: requiresApproval,
+			ColorId:                    colorId,
+			CredentialsList:            []int{credId},
+		})
+
+		req, _ := http.NewRequest("PUT", "/int_profiles", bytes.NewBuffer(jsonBody))
+		req.Header.Set(controller.IF_MATCH_HEADER, "*")
+		req = injectProfileUserContext(req, userId)
+		req = req.WithContext(context.WithValue(req.Context(), controller.CONTEXT_WORKSPACE_ROLE_KEY, role))
+		rr := httptest.NewRecorder()
+
+		pController.HandleUpdate(rr, req)
+
+		return rr.Code
+	}
+
+	requiresApproval := func() bool {
+		profile, _ := store.Repositories().IntProfiles.ById(context.Background(), profId, userId)
+
+		return profile.IntProfileRequiresApproval
+	}
+
+	off := false
+
+	if code := update(model.WorkspaceRoleEditor, &off); code != http.StatusForbidden {
+		t.Errorf("expected an editor turning approval off to get 403, got %d", code)
+	}
+	if !requiresApproval() {
+		t.Error("expected approval to stay on after the editor request")
+	}
+
+	if code := update(model.WorkspaceRoleEditor, nil); code != http.StatusOK || !requiresApproval() {
+		t.Errorf("expected an update without the flag to keep approval on, got %d", code)
+	}
+
+	if code := update(model.WorkspaceRoleOwner, &off); code != http.StatusOK || requiresApproval() {
+		t.Errorf("expected the owner to turn approval off, got %d", code)
+	}
+}
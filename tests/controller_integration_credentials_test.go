@@ -3,72 +3,42 @@ package tests
 import (
 	"bytes"
 	"context"
-	"database/sql"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
-	"synk/gateway/app"
 	"synk/gateway/app/controller"
 	"synk/gateway/app/model"
+	"synk/gateway/app/model/memory"
 	"testing"
 )
 
-func setupCredControllerDB(t *testing.T) (*sql.DB, int) {
-	db, err := app.InitDB(true)
-	if err != nil {
-		t.Fatalf("db connection failed: %v", err)
-	}
-
-	userId, err := createTestUserForCreds(db)
-	if err != nil {
-		t.Fatalf("failed to create test user: %v", err)
-	}
-
-	return db, userId
+func injectCredUserContext(r *http.Request, userId int) *http.Request {
+	ctx := context.WithValue(r.Context(), controller.CONTEXT_USER_ID_KEY, userId)
+	ctx = context.WithValue(ctx, controller.CONTEXT_WORKSPACE_ID_KEY, userId)
+	return r.WithContext(ctx)
 }
 
-func createTestUserForCreds(db *sql.DB) (int, error) {
-	var userId int
-	userName := "Creds Controller Test User"
-	userEmail := "creds_controller@synk.com"
-	userPass := "123456"
+func createStoreCredential(t *testing.T, store *memory.Store, name string, credType model.SocialPlatform, config string, userId int) int {
+	t.Helper()
 
-	insertRes, insertErr := db.ExecContext(
-		context.Background(),
-		`INSERT INTO synk.user (user_name, user_email, user_pass) VALUES (?, ?, ?)`,
-		userName, userEmail, userPass,
-	)
-
-	if insertErr != nil {
-
-		var existingId int
-		err := db.QueryRow("SELECT user_id FROM user WHERE user_email = ?", userEmail).Scan(&existingId)
-		if err == nil {
-			return existingId, nil
-		}
-		return 0, fmt.Errorf("failed to create user: %s", insertErr.Error())
+	id, err := store.Repositories().IntCredentials.Add(context.Background(), model.IntCredentialAddData{
+		IntCredentialName:   name,
+		IntCredentialType:   credType,
+		IntCredentialConfig: config,
+		WorkspaceId:         userId,
+	}, userId)
+	if err != nil {
+		t.Fatalf("Setup failed: Could not create credential: %v", err)
 	}
 
-	id, _ := insertRes.LastInsertId()
-	userId = int(id)
-	return userId, nil
-}
-
-func injectCredUserContext(r *http.Request, userId int) *http.Request {
-	ctx := context.WithValue(r.Context(), controller.CONTEXT_USER_ID_KEY, userId)
-	return r.WithContext(ctx)
+	return id
 }
 
 func TestIntCredentials_HandleCreate(t *testing.T) {
-	db, userId := setupCredControllerDB(t)
-	defer db.Close()
+	store, userId := setupControllerStore(t)
 
-	defer db.Exec("DELETE FROM user WHERE user_id = ?", userId)
-
-	credsController := controller.NewIntCredentials(db)
-
-	//
+	credsController := controller.NewIntCredentials(store.Repositories())
 
 	reqBody := controller.HandleIntCredentialCreateRequest{
 		IntCredentialName:   "Controller Create Test",
@@ -96,20 +66,37 @@ func TestIntCredentials_HandleCreate(t *testing.T) {
 	if response.Data.IntCredentialId == 0 {
 		t.Error("Expected valid IntCredentialId, got 0")
 	}
+}
+
+func TestIntCredentials_HandleCreateInvalidType(t *testing.T) {
+	store, userId := setupControllerStore(t)
+
+	credsController := controller.NewIntCredentials(store.Repositories())
+
+	reqBody := controller.HandleIntCredentialCreateRequest{
+		IntCredentialName:   "Invalid Type",
+		IntCredentialType:   model.SocialPlatform("myspace"),
+		IntCredentialConfig: `{}`,
+	}
+	jsonBody, _ := json.Marshal(reqBody)
 
-	db.Exec("DELETE FROM integration_credential WHERE int_credential_id = ?", response.Data.IntCredentialId)
+	req, _ := http.NewRequest("POST", "/int_credentials", bytes.NewBuffer(jsonBody))
+	req = injectCredUserContext(req, userId)
+	rr := httptest.NewRecorder()
+
+	credsController.HandleCreate(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("wrong status code: got %v want %v", rr.Code, http.StatusBadRequest)
+	}
 }
 
 func TestIntCredentials_HandleBasicList(t *testing.T) {
-	db, userId := setupCredControllerDB(t)
-	defer db.Close()
-	defer db.Exec("DELETE FROM user WHERE user_id = ?", userId)
+	store, userId := setupControllerStore(t)
 
-	credsController := controller.NewIntCredentials(db)
+	credsController := controller.NewIntCredentials(store.Repositories())
 
-	res, _ := db.Exec("INSERT INTO integration_credential (int_credential_name, int_credential_type, int_credential_config, user_id) VALUES ('BasicList Item', 'discord', '{}', ?)", userId)
-	id, _ := res.LastInsertId()
-	defer db.Exec("DELETE FROM integration_credential WHERE int_credential_id = ?", id)
+	createStoreCredential(t, store, "BasicList Item", model.Discord, "{}", userId)
 
 	req, _ := http.NewRequest("GET", "/int_credentials/basic", nil)
 	req = injectCredUserContext(req, userId)
@@ -130,15 +117,11 @@ func TestIntCredentials_HandleBasicList(t *testing.T) {
 }
 
 func TestIntCredentials_HandleList(t *testing.T) {
-	db, userId := setupCredControllerDB(t)
-	defer db.Close()
-	defer db.Exec("DELETE FROM user WHERE user_id = ?", userId)
+	store, userId := setupControllerStore(t)
 
-	credsController := controller.NewIntCredentials(db)
+	credsController := controller.NewIntCredentials(store.Repositories())
 
-	res, _ := db.Exec("INSERT INTO integration_credential (int_credential_name, int_credential_type, int_credential_config, user_id) VALUES ('DetailedList Item', 'telegram', '{\"secret\":1}', ?)", userId)
-	id, _ := res.LastInsertId()
-	defer db.Exec("DELETE FROM integration_credential WHERE int_credential_id = ?", id)
+	id := createStoreCredential(t, store, "DetailedList Item", model.Telegram, `{"secret":1}`, userId)
 
 	url := fmt.Sprintf("/int_credentials?int_credential_id=%d&include_config=1", id)
 	req, _ := http.NewRequest("GET", url, nil)
@@ -164,18 +147,14 @@ func TestIntCredentials_HandleList(t *testing.T) {
 }
 
 func TestIntCredentials_HandleUpdate(t *testing.T) {
-	db, userId := setupCredControllerDB(t)
-	defer db.Close()
-	defer db.Exec("DELETE FROM user WHERE user_id = ?", userId)
+	store, userId := setupControllerStore(t)
 
-	credsController := controller.NewIntCredentials(db)
+	credsController := controller.NewIntCredentials(store.Repositories())
 
-	res, _ := db.Exec("INSERT INTO integration_credential (int_credential_name, int_credential_type, int_credential_config, user_id) VALUES ('Old Name', 'discord', '{}', ?)", userId)
-	id, _ := res.LastInsertId()
-	defer db.Exec("DELETE FROM integration_credential WHERE int_credential_id = ?", id)
+	id := createStoreCredential(t, store, "Old Name", model.Discord, "{}", userId)
 
 	reqBody := controller.HandleIntCredentialUpdateRequest{
-		IntCredentialId:     int(id),
+		IntCredentialId:     id,
 		IntCredentialName:   "Updated Name",
 		IntCredentialType:   model.Telegram,
 		IntCredentialConfig: `{"new":1}`,
@@ -183,6 +162,7 @@ func TestIntCredentials_HandleUpdate(t *testing.T) {
 	jsonBody, _ := json.Marshal(reqBody)
 
 	req, _ := http.NewRequest("PUT", "/int_credentials", bytes.NewBuffer(jsonBody))
+	req.Header.Set(controller.IF_MATCH_HEADER, controller.ETag(1))
 	req = injectCredUserContext(req, userId)
 	rr := httptest.NewRecorder()
 
@@ -201,23 +181,19 @@ func TestIntCredentials_HandleUpdate(t *testing.T) {
 }
 
 func TestIntCredentials_HandleDelete(t *testing.T) {
-	db, userId := setupCredControllerDB(t)
-	defer db.Close()
-	defer db.Exec("DELETE FROM user WHERE user_id = ?", userId)
-
-	credsController := controller.NewIntCredentials(db)
+	store, userId := setupControllerStore(t)
 
-	res, _ := db.Exec("INSERT INTO integration_credential (int_credential_name, int_credential_type, int_credential_config, user_id) VALUES ('To Delete', 'discord', '{}', ?)", userId)
-	id, _ := res.LastInsertId()
+	credsController := controller.NewIntCredentials(store.Repositories())
 
-	defer db.Exec("DELETE FROM integration_credential WHERE int_credential_id = ?", id)
+	id := createStoreCredential(t, store, "To Delete", model.Discord, "{}", userId)
 
 	reqBody := controller.HandleIntCredentialDeleteRequest{
-		IntCredentialId: int(id),
+		IntCredentialId: id,
 	}
 	jsonBody, _ := json.Marshal(reqBody)
 
 	req, _ := http.NewRequest("DELETE", "/int_credentials", bytes.NewBuffer(jsonBody))
+	req.Header.Set(controller.IF_MATCH_HEADER, controller.ETag(1))
 	req = injectCredUserContext(req, userId)
 	rr := httptest.NewRecorder()
 
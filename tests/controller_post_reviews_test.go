@@ -0,0 +1,190 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"synk/gateway/app/config"
+	"synk/gateway/app/controller"
+	"synk/gateway/app/model"
+	"synk/gateway/app/model/memory"
+	"testing"
+)
+
+func createApprovalPost(t *testing.T, store *memory.Store, userId int) int {
+	t.Helper()
+
+	tplId := createStoreTemplate(t, store, "Review Tpl", "x", userId)
+	colorId := store.AddColor("Primary Blue", "007BFF")
+
+	profId, err := store.Repositories().IntProfiles.Add(context.Background(), model.IntProfileAddData{
+		IntProfileName:             "Public Channel",
+		IntProfileRequiresApproval: true,
+		ColorId:                    colorId,
+		WorkspaceId:                userId,
+	}, []int{}, userId)
+	if err != nil {
+		t.Fatalf("Setup failed: Could not create profile: %v", err)
+	}
+
+	return createStorePost(t, store, "Needs Review", "x", tplId, profId, userId)
+}
+
+func requestPostReview(store *memory.Store, route string, userId int, reqBody controller.HandlePostReviewRequest) *httptest.ResponseRecorder {
+	reviewController := controller.NewPostReviews(store.Repositories())
+
+	jsonBody, _ := json.Marshal(reqBody)
+
+	req, _ := http.NewRequest("POST", route, bytes.NewBuffer(jsonBody))
+	req = injectPostUserContext(req, userId)
+	rr := httptest.NewRecorder()
+
+	if route == "/post/submit" {
+		reviewController.HandleSubmit(rr, req)
+	} else {
+		reviewController.HandleDecide(rr, req)
+	}
+
+	return rr
+}
+
+func requestPostPublish(store *memory.Store, config *config.Config, userId int, postId int) *httptest.ResponseRecorder {
+	postController := controller.NewPosts(store.Repositories(), controller.NewServiceClient(nil), config)
+
+	jsonBody, _ := json.Marshal(controller.HandlePostDeleteRequest{PostId: postId})
+
+	req, _ := http.NewRequest("POST", "/post/publish", bytes.NewBuffer(jsonBody))
+	req = injectPostUserContext(req, userId)
+	rr := httptest.NewRecorder()
+
+	postController.HandlePublish(rr, req)
+
+	return rr
+}
+
+func TestPostReviews_HandlePublishRequiresApproval(t *testing.T) {
+	queue := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"resource":{"ok":true,"error":""}}`))
+	}))
+	defer queue.Close()
+
+	cfg := config.Default()
+	cfg.QueuerEndpoint = queue.URL
+
+	store, userId := setupControllerStore(t)
+	postId := createApprovalPost(t, store, userId)
+
+	if rr := requestPostPublish(store, cfg, userId, postId); rr.Code != http.StatusForbidden {
+		t.Fatalf("expected draft post to be refused, got %d", rr.Code)
+	}
+
+	if rr := requestPostReview(store, "/post/submit", userId, controller.HandlePostReviewRequest{PostId: postId}); rr.Code != http.StatusOK {
+		t.Fatalf("wrong status code on submit: got %v want %v. Body: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	if rr := requestPostReview(store, "/post/submit", userId, controller.HandlePostReviewRequest{PostId: postId}); rr.Code != http.StatusConflict {
+		t.Errorf("expected pending post not to be submitted twice, got %d", rr.Code)
+	}
+
+	if rr := requestPostPublish(store, cfg, userId, postId); rr.Code != http.StatusForbidden {
+		t.Errorf("expected pending post to be refused, got %d", rr.Code)
+	}
+
+	if rr := requestPostReview(store, "/post/review", userId, controller.HandlePostReviewRequest{
+		PostId:           postId,
+		PostReviewStatus: model.PostReviewStatusPending,
+	}); rr.Code != http.StatusBadRequest {
+		t.Errorf("expected invalid decision to be refused, got %d", rr.Code)
+	}
+
+	if rr := requestPostReview(store, "/post/review", userId, controller.HandlePostReviewRequest{
+		PostId:            postId,
+		PostReviewStatus:  model.PostReviewStatusApproved,
+		PostReviewComment: "Looks good",
+	}); rr.Code != http.StatusOK {
+		t.Fatalf("wrong status code on review: got %v want %v. Body: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+
+	if rr := requestPostPublish(store, cfg, userId, postId); rr.Code != http.StatusOK {
+		t.Errorf("expected approved post to be published, got %d. Body: %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestPostReviews_HandlePublishWithoutProfile(t *testing.T) {
+	var queued bool
+
+	queue := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queued = true
+		w.Write([]byte(`{"resource":{"ok":true,"error":""}}`))
+	}))
+	defer queue.Close()
+
+	cfg := config.Default()
+	cfg.QueuerEndpoint = queue.URL
+
+	store, userId := setupControllerStore(t)
+	postId := createApprovalPost(t, store, userId)
+
+	post, _ := store.Repositories().Posts.ById(context.Background(), postId, userId)
+	store.Repositories().IntProfiles.Delete(context.Background(), post.IntProfileId, 0, userId)
+
+	if rr := requestPostPublish(store, cfg, userId, postId); rr.Code != http.StatusBadRequest {
+		t.Errorf("expected a post without profile to be refused, got %d", rr.Code)
+	}
+	if queued {
+		t.Error("expected nothing to be sent to the queuer")
+	}
+}
+
+func TestPostReviews_HandleList(t *testing.T) {
+	store, userId := setupControllerStore(t)
+	postId := createApprovalPost(t, store, userId)
+
+	requestPostReview(store, "/post/submit", userId, controller.HandlePostReviewRequest{PostId: postId})
+	requestPostReview(store, "/post/review", userId, controller.HandlePostReviewRequest{
+		PostId:            postId,
+		PostReviewStatus:  model.PostReviewStatusRejected,
+		PostReviewComment: "Fix the link",
+	})
+
+	reviewController := controller.NewPostReviews(store.Repositories())
+
+	req, _ := http.NewRequest("GET", "/post/reviews?post_id="+strconv.Itoa(postId), nil)
+	req = injectPostUserContext(req, userId)
+	rr := httptest.NewRecorder()
+
+	reviewController.HandleList(rr, req)
+
+	var response controller.HandlePostReviewListResponse
+	json.Unmarshal(rr.Body.Bytes(), &response)
+
+	if len(response.Data) != 2 {
+		t.Fatalf("expected submission and decision, got %+v", response.Data)
+	}
+	if response.Data[1].PostReviewStatus != model.PostReviewStatusRejected || response.Data[1].PostReviewComment != "Fix the link" {
+		t.Errorf("unexpected decision %+v", response.Data[1])
+	}
+
+	list, _ := store.Repositories().Posts.List(context.Background(), strconv.Itoa(postId), false, userId)
+	if len(list) != 1 || list[0].PostReviewStatus != model.PostReviewStatusRejected {
+		t.Errorf("expected post to be rejected, got %+v", list)
+	}
+}
+
+func TestPostReviews_UpdateResetsApproval(t *testing.T) {
+	store, userId := setupControllerStore(t)
+	postId := createApprovalPost(t, store, userId)
+
+	repositories := store.Repositories()
+	repositories.PostReviews.Submit(context.Background(), model.PostReviewData{PostId: postId}, userId, userId)
+	repositories.PostReviews.Decide(context.Background(), model.PostReviewData{PostId: postId, PostReviewStatus: model.PostReviewStatusApproved}, userId, userId)
+
+	post, _ := repositories.Posts.ById(context.Background(), postId, userId)
+	repositories.Posts.Update(context.Background(), model.PostUpdateData{PostId: postId, PostName: "Edited", IntProfileId: post.IntProfileId}, userId)
+
+	if post, _ = repositories.Posts.ById(context.Background(), postId, userId); post.PostReviewStatus != model.PostReviewStatusDraft {
+		t.Errorf("expected edited post to go back to draft, got %s", post.PostReviewStatus)
+	}
+}
@@ -3,96 +3,60 @@ package tests
 import (
 	"bytes"
 	"context"
-	"database/sql"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
-	"synk/gateway/app"
+	"strings"
+	"sync"
+	"synk/gateway/app/config"
 	"synk/gateway/app/controller"
+	"synk/gateway/app/model"
+	"synk/gateway/app/model/memory"
+	"synk/gateway/app/util"
 	"testing"
+	"time"
 )
 
-func setupPostsControllerDB(t *testing.T) (*sql.DB, int) {
-	db, err := app.InitDB(true)
-	if err != nil {
-		t.Fatalf("db connection failed: %v", err)
-	}
-
-	userId, err := createTestUserForPosts(db)
-	if err != nil {
-		t.Fatalf("failed to create test user: %v", err)
-	}
-
-	return db, userId
-}
-
-func createTestUserForPosts(db *sql.DB) (int, error) {
-	var userId int
-	userName := "Posts Controller User"
-	userEmail := "posts_controller_test@synk.com"
-	userPass := "123456"
-
-	insertRes, insertErr := db.ExecContext(
-		context.Background(),
-		`INSERT INTO synk.user (user_name, user_email, user_pass) VALUES (?, ?, ?)`,
-		userName, userEmail, userPass,
-	)
-
-	if insertErr != nil {
-
-		var existingId int
-		err := db.QueryRow("SELECT user_id FROM user WHERE user_email = ?", userEmail).Scan(&existingId)
-		if err == nil {
-			return existingId, nil
-		}
-		return 0, fmt.Errorf("failed to create user: %s", insertErr.Error())
-	}
-
-	id, _ := insertRes.LastInsertId()
-	userId = int(id)
-	return userId, nil
-}
-
 func injectPostUserContext(r *http.Request, userId int) *http.Request {
 	ctx := context.WithValue(r.Context(), controller.CONTEXT_USER_ID_KEY, userId)
+	ctx = context.WithValue(ctx, controller.CONTEXT_WORKSPACE_ID_KEY, userId)
 	return r.WithContext(ctx)
 }
 
-func createPostDependencies(t *testing.T, db *sql.DB, userId int) (int, int) {
+func createPostDependencies(t *testing.T, store *memory.Store, userId int) (int, int) {
+	t.Helper()
 
-	res, err := db.Exec("INSERT INTO template (template_name, template_content, template_url_import, user_id) VALUES ('Post Ctrl Tpl', 'x', 'x', ?)", userId)
-	if err != nil {
-		t.Fatalf("Failed to create template: %v", err)
-	}
-	tplId, _ := res.LastInsertId()
+	tplId := createStoreTemplate(t, store, "Post Ctrl Tpl", "x", userId)
+	colorId := store.AddColor("Primary Blue", "007BFF")
+	profId := createStoreProfile(t, store, "Post Ctrl Profile", colorId, []int{}, userId)
 
-	var colorId int
-	err = db.QueryRow("SELECT color_id FROM color LIMIT 1").Scan(&colorId)
-	if err != nil {
-		t.Fatalf("No color found in DB (required for profile): %v", err)
-	}
+	return tplId, profId
+}
+
+func createStorePost(t *testing.T, store *memory.Store, name string, content string, tplId int, profId int, userId int) int {
+	t.Helper()
 
-	res, err = db.Exec("INSERT INTO integration_profile (int_profile_name, color_id, user_id) VALUES ('Post Ctrl Profile', ?, ?)", colorId, userId)
+	id, err := store.Repositories().Posts.Add(context.Background(), model.PostAddData{
+		PostName:     name,
+		PostContent:  content,
+		TemplateId:   tplId,
+		IntProfileId: profId,
+		WorkspaceId:  userId,
+	}, userId)
 	if err != nil {
-		t.Fatalf("Failed to create profile: %v", err)
+		t.Fatalf("Setup failed: Could not create post: %v", err)
 	}
-	profId, _ := res.LastInsertId()
 
-	return int(tplId), int(profId)
+	return id
 }
 
 func TestPosts_HandleCreate(t *testing.T) {
-	db, userId := setupPostsControllerDB(t)
-	defer db.Close()
+	store, userId := setupControllerStore(t)
 
-	defer db.Exec("DELETE FROM user WHERE user_id = ?", userId)
+	tplId, profId := createPostDependencies(t, store, userId)
 
-	tplId, profId := createPostDependencies(t, db, userId)
-	defer db.Exec("DELETE FROM template WHERE template_id = ?", tplId)
-	defer db.Exec("DELETE FROM integration_profile WHERE int_profile_id = ?", profId)
-
-	postController := controller.NewPosts(db)
+	postController := controller.NewPosts(store.Repositories(), controller.NewServiceClient(nil), config.Default())
 
 	reqBody := controller.HandlePostCreateRequest{
 		PostName:     "Controller Create Test",
@@ -121,24 +85,42 @@ func TestPosts_HandleCreate(t *testing.T) {
 	if response.Data.PostId == 0 {
 		t.Error("Expected valid PostId")
 	}
+}
+
+func TestPosts_HandleCreateUnknownTemplate(t *testing.T) {
+	store, userId := setupControllerStore(t)
+
+	_, profId := createPostDependencies(t, store, userId)
+
+	postController := controller.NewPosts(store.Repositories(), controller.NewServiceClient(nil), config.Default())
+
+	reqBody := controller.HandlePostCreateRequest{
+		PostName:     "Unknown Template",
+		PostContent:  "Content",
+		TemplateId:   9999,
+		IntProfileId: profId,
+	}
+	jsonBody, _ := json.Marshal(reqBody)
+
+	req, _ := http.NewRequest("POST", "/posts", bytes.NewBuffer(jsonBody))
+	req = injectPostUserContext(req, userId)
+	rr := httptest.NewRecorder()
 
-	db.Exec("DELETE FROM post WHERE post_id = ?", response.Data.PostId)
+	postController.HandleCreate(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("wrong status code: got %v want %v", rr.Code, http.StatusBadRequest)
+	}
 }
 
 func TestPosts_HandleList(t *testing.T) {
-	db, userId := setupPostsControllerDB(t)
-	defer db.Close()
-	defer db.Exec("DELETE FROM user WHERE user_id = ?", userId)
-
-	tplId, profId := createPostDependencies(t, db, userId)
-	defer db.Exec("DELETE FROM template WHERE template_id = ?", tplId)
-	defer db.Exec("DELETE FROM integration_profile WHERE int_profile_id = ?", profId)
+	store, userId := setupControllerStore(t)
 
-	res, _ := db.Exec("INSERT INTO post (post_name, post_content, template_id, int_profile_id, user_id) VALUES ('List Test', 'Hidden Content', ?, ?, ?)", tplId, profId, userId)
-	postId, _ := res.LastInsertId()
-	defer db.Exec("DELETE FROM post WHERE post_id = ?", postId)
+	tplId, profId := createPostDependencies(t, store, userId)
+	postId := createStorePost(t, store, "List Test", "Hidden Content", tplId, profId, userId)
+	store.AddPublication(postId, 1, model.PublicationStatusFailed)
 
-	postController := controller.NewPosts(db)
+	postController := controller.NewPosts(store.Repositories(), controller.NewServiceClient(nil), config.Default())
 
 	url := fmt.Sprintf("/posts?post_id=%d&include_content=1", postId)
 	req, _ := http.NewRequest("GET", url, nil)
@@ -160,26 +142,25 @@ func TestPosts_HandleList(t *testing.T) {
 		if response.Data[0].PostContent != "Hidden Content" {
 			t.Error("Expected content to be included")
 		}
+		if response.Data[0].Status != model.PublicationStatusFailed {
+			t.Errorf("Expected status 'failed', got %s", response.Data[0].Status)
+		}
+		if response.Data[0].TemplateName != "Post Ctrl Tpl" {
+			t.Errorf("Expected template name to be joined, got %s", response.Data[0].TemplateName)
+		}
 	}
 }
 
 func TestPosts_HandleUpdate(t *testing.T) {
-	db, userId := setupPostsControllerDB(t)
-	defer db.Close()
-	defer db.Exec("DELETE FROM user WHERE user_id = ?", userId)
+	store, userId := setupControllerStore(t)
 
-	tplId, profId := createPostDependencies(t, db, userId)
-	defer db.Exec("DELETE FROM template WHERE template_id = ?", tplId)
-	defer db.Exec("DELETE FROM integration_profile WHERE int_profile_id = ?", profId)
+	tplId, profId := createPostDependencies(t, store, userId)
+	postId := createStorePost(t, store, "Old Name", "Old Content", tplId, profId, userId)
 
-	res, _ := db.Exec("INSERT INTO post (post_name, post_content, template_id, int_profile_id, user_id) VALUES ('Old Name', 'Old Content', ?, ?, ?)", tplId, profId, userId)
-	postId, _ := res.LastInsertId()
-	defer db.Exec("DELETE FROM post WHERE post_id = ?", postId)
-
-	postController := controller.NewPosts(db)
+	postController := controller.NewPosts(store.Repositories(), controller.NewServiceClient(nil), config.Default())
 
 	reqBody := controller.HandlePostUpdateRequest{
-		PostId:       int(postId),
+		PostId:       postId,
 		PostName:     "Updated Name",
 		PostContent:  "Updated Content",
 		TemplateId:   tplId,
@@ -188,6 +169,7 @@ func TestPosts_HandleUpdate(t *testing.T) {
 	jsonBody, _ := json.Marshal(reqBody)
 
 	req, _ := http.NewRequest("PUT", "/posts", bytes.NewBuffer(jsonBody))
+	req.Header.Set(controller.IF_MATCH_HEADER, controller.ETag(1))
 	req = injectPostUserContext(req, userId)
 	rr := httptest.NewRecorder()
 
@@ -206,27 +188,20 @@ func TestPosts_HandleUpdate(t *testing.T) {
 }
 
 func TestPosts_HandleDelete(t *testing.T) {
-	db, userId := setupPostsControllerDB(t)
-	defer db.Close()
-	defer db.Exec("DELETE FROM user WHERE user_id = ?", userId)
-
-	tplId, profId := createPostDependencies(t, db, userId)
-	defer db.Exec("DELETE FROM template WHERE template_id = ?", tplId)
-	defer db.Exec("DELETE FROM integration_profile WHERE int_profile_id = ?", profId)
+	store, userId := setupControllerStore(t)
 
-	res, _ := db.Exec("INSERT INTO post (post_name, post_content, template_id, int_profile_id, user_id) VALUES ('Delete Me', 'x', ?, ?, ?)", tplId, profId, userId)
-	postId, _ := res.LastInsertId()
+	tplId, profId := createPostDependencies(t, store, userId)
+	postId := createStorePost(t, store, "Delete Me", "x", tplId, profId, userId)
 
-	defer db.Exec("DELETE FROM post WHERE post_id = ?", postId)
-
-	postController := controller.NewPosts(db)
+	postController := controller.NewPosts(store.Repositories(), controller.NewServiceClient(nil), config.Default())
 
 	reqBody := controller.HandlePostDeleteRequest{
-		PostId: int(postId),
+		PostId: postId,
 	}
 	jsonBody, _ := json.Marshal(reqBody)
 
 	req, _ := http.NewRequest("DELETE", "/posts", bytes.NewBuffer(jsonBody))
+	req.Header.Set(controller.IF_MATCH_HEADER, controller.ETag(1))
 	req = injectPostUserContext(req, userId)
 	rr := httptest.NewRecorder()
 
@@ -243,3 +218,198 @@ func TestPosts_HandleDelete(t *testing.T) {
 		t.Errorf("Expected 1 row affected, got %d", response.Data.RowsAffected)
 	}
 }
+
+func TestPosts_HandleClone(t *testing.T) {
+	store, userId := setupControllerStore(t)
+
+	tplId, profId := createPostDependencies(t, store, userId)
+	postId := createStorePost(t, store, "Clone Me", "x", tplId, profId, userId)
+
+	postController := controller.NewPosts(store.Repositories(), controller.NewServiceClient(nil), config.Default())
+
+	reqBody := controller.HandlePostCloneRequest{
+		PostId: postId,
+	}
+	jsonBody, _ := json.Marshal(reqBody)
+
+	req, _ := http.NewRequest("POST", "/posts/clone", bytes.NewBuffer(jsonBody))
+	req = injectPostUserContext(req, userId)
+	rr := httptest.NewRecorder()
+
+	postController.HandleClone(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Errorf("wrong status code: got %v want %v. Body: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+
+	var response controller.HandlePostCreateResponse
+	json.Unmarshal(rr.Body.Bytes(), &response)
+
+	if response.Data.PostId == 0 || response.Data.PostId == postId {
+		t.Errorf("Expected a new PostId, got %d", response.Data.PostId)
+	}
+}
+
+func TestPosts_HandlePublishQuota(t *testing.T) {
+	queue := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"resource":{"ok":true,"error":""}}`))
+	}))
+	defer queue.Close()
+
+	cfg := config.Default()
+	cfg.QueuerEndpoint = queue.URL
+	cfg.PublishQuota = config.PublishQuota{User: 0, IntProfile: 2}
+
+	store, userId := setupControllerStore(t)
+	tplId, profId := createPostDependencies(t, store, userId)
+	postId := createStorePost(t, store, "Quota", "x", tplId, profId, userId)
+
+	for i := 0; i < 2; i++ {
+		if rr := requestPostPublish(store, cfg, userId, postId); rr.Code != http.StatusOK {
+			t.Fatalf("expected publish %d to pass, got %d. Body: %s", i+1, rr.Code, rr.Body.String())
+		}
+	}
+
+	rr := requestPostPublish(store, cfg, userId, postId)
+	if rr.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected profile quota to be reached, got %d", rr.Code)
+	}
+	if rr.Header().Get("Retry-After") == "" {
+		t.Error("expected Retry-After until the next day")
+	}
+}
+
+func TestPosts_HandlePublishConcurrentQuota(t *testing.T) {
+	queue := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+		w.Write([]byte(`{"resource":{"ok":true,"error":""}}`))
+	}))
+	defer queue.Close()
+
+	cfg := config.Default()
+	cfg.QueuerEndpoint = queue.URL
+	cfg.PublishQuota = config.PublishQuota{User: 2, IntProfile: 0}
+
+	store, userId := setupControllerStore(t)
+	tplId, profId := createPostDependencies(t, store, userId)
+	postId := createStorePost(t, store, "Concurrent", "x", tplId, profId, userId)
+
+	var wg sync.WaitGroup
+	codes := make(chan int, 6)
+
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			codes <- requestPostPublish(store, cfg, userId, postId).Code
+		}()
+	}
+
+	wg.Wait()
+	close(codes)
+
+	accepted := 0
+
+	for code := range codes {
+		if code == http.StatusOK {
+			accepted++
+		} else if code != http.StatusTooManyRequests {
+			t.Errorf("expected 200 or 429, got %d", code)
+		}
+	}
+
+	if accepted != 2 {
+		t.Errorf("expected the quota to let exactly 2 concurrent publishes through, got %d", accepted)
+	}
+}
+
+func TestPosts_HandlePublishRejectedByQueuer(t *testing.T) {
+	queuerStatus := http.StatusUnprocessableEntity
+
+	queue := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(queuerStatus)
+		w.Write([]byte(`{"resource":{"ok":false,"error":"channel not reachable"}}`))
+	}))
+	defer queue.Close()
+
+	cfg := config.Default()
+	cfg.QueuerEndpoint = queue.URL
+
+	store, userId := setupControllerStore(t)
+	tplId, profId := createPostDependencies(t, store, userId)
+	postId := createStorePost(t, store, "Rejected", "x", tplId, profId, userId)
+
+	rr := requestPostPublish(store, cfg, userId, postId)
+
+	if rr.Code != http.StatusUnprocessableEntity || !strings.Contains(rr.Body.String(), "channel not reachable") {
+		t.Errorf("expected the queuer rejection to be passed on, got %d. Body: %s", rr.Code, rr.Body.String())
+	}
+
+	queuerStatus = http.StatusServiceUnavailable
+
+	if rr := requestPostPublish(store, cfg, userId, postId); rr.Code != http.StatusBadGateway {
+		t.Errorf("expected 502 for a queuer failure, got %d", rr.Code)
+	}
+
+	dayStart, _ := util.ParseTimeBound(time.Now().UTC().Format(time.DateOnly), false)
+	usage, _ := store.Repositories().PublishUsage.Count(context.Background(), userId, profId, dayStart)
+
+	if usage.UserCount != 0 || usage.IntProfileCount != 0 {
+		t.Errorf("expected rejected publishes not to use the quota, got %+v", usage)
+	}
+
+	audits, _ := store.Repositories().AuditLogs.List(context.Background(), model.AuditLogFilter{}, userId, userId)
+
+	for _, audit := range audits {
+		if audit.AuditAction == model.AuditActionPublish {
+			t.Errorf("expected rejected publishes not to be audited, got %+v", audit)
+		}
+	}
+}
+
+type racingPosts struct {
+	model.PostsRepository
+	raced bool
+}
+
+func (rp *racingPosts) ById(ctx context.Context, id int, workspaceId int) (model.PostByIdData, error) {
+	post, err := rp.PostsRepository.ById(ctx, id, workspaceId)
+
+	if !rp.raced && post.PostId != 0 {
+		rp.raced = true
+		rp.PostsRepository.Update(ctx, model.PostUpdateData{PostId: post.PostId, PostName: "Edited Meanwhile", IntProfileId: post.IntProfileId}, workspaceId)
+	}
+
+	return post, err
+}
+
+func TestPosts_HandleDeleteConcurrentUpdate(t *testing.T) {
+	store, userId := setupControllerStore(t)
+
+	tplId, profId := createPostDependencies(t, store, userId)
+	postId := createStorePost(t, store, "Delete Me", "x", tplId, profId, userId)
+
+	repositories := store.Repositories()
+	repositories.Posts = &racingPosts{PostsRepository: repositories.Posts}
+
+	postController := controller.NewPosts(repositories, controller.NewServiceClient(nil), config.Default())
+
+	jsonBody, _ := json.Marshal(controller.HandlePostDeleteRequest{PostId: postId})
+
+	req, _ := http.NewRequest("DELETE", "/posts", bytes.NewBuffer(jsonBody))
+	req.Header.Set(controller.IF_MATCH_HEADER, controller.ETag(1))
+	req = injectPostUserContext(req, userId)
+	rr := httptest.NewRecorder()
+
+	postController.HandleDelete(rr, req)
+
+	if rr.Code != http.StatusPreconditionFailed {
+		t.Errorf("wrong status code: got %v want %v", rr.Code, http.StatusPreconditionFailed)
+	}
+
+	if post, _ := store.Repositories().Posts.ById(context.Background(), postId, userId); post.PostId == 0 {
+		t.Error("expected the post updated in the meantime to be kept")
+	}
+}
@@ -3,75 +3,54 @@ package tests
 import (
 	"bytes"
 	"context"
-	"database/sql"
 	"encoding/json"
-	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strconv"
-	"synk/gateway/app"
 	"synk/gateway/app/controller"
+	"synk/gateway/app/model"
+	"synk/gateway/app/model/memory"
 	"testing"
 )
 
-func setupControllerDB(t *testing.T) (*sql.DB, int) {
-	db, err := app.InitDB(true)
-	if err != nil {
-		t.Fatalf("db connection failed: %v", err)
-	}
+// Controller tests give every user a workspace sharing its id, so a resource
+// created for userId+1 lives outside the workspace of the request.
+const CONTROLLER_TEST_USER_ID = 1
 
-	userId, err := createTestUser(db)
-	if err != nil {
-		t.Fatalf("failed to create test user: %v", err)
-	}
+func setupControllerStore(t *testing.T) (*memory.Store, int) {
+	t.Helper()
 
-	return db, userId
+	return memory.NewStore(), CONTROLLER_TEST_USER_ID
 }
 
-func createTestUser(db *sql.DB) (int, error) {
-	var userId int
-
-	userName := "Controller Test User"
-	userEmail := "test_controller@synk.com"
-	userPass := "123456"
+func injectUserContext(r *http.Request, userId int) *http.Request {
 
-	insertRes, insertErr := db.ExecContext(
-		context.Background(),
-		`INSERT INTO synk.user (user_name, user_email, user_pass) VALUES (?, ?, ?)`,
-		userName, userEmail, userPass,
-	)
+	ctx := context.WithValue(r.Context(), controller.CONTEXT_USER_ID_KEY, userId)
+	ctx = context.WithValue(ctx, controller.CONTEXT_WORKSPACE_ID_KEY, userId)
+	return r.WithContext(ctx)
+}
 
-	if insertErr != nil {
+func createStoreTemplate(t *testing.T, store *memory.Store, name string, content string, userId int) int {
+	t.Helper()
 
-		var existingId int
-		err := db.QueryRow("SELECT user_id FROM user WHERE user_email = ?", userEmail).Scan(&existingId)
-		if err == nil {
-			return existingId, nil
-		}
-		return 0, fmt.Errorf("failed to create user: %s", insertErr.Error())
+	id, err := store.Repositories().Templates.Add(context.Background(), model.TemplateAddData{
+		TemplateName:      name,
+		TemplateContent:   content,
+		TemplateUrlImport: "x",
+		UserId:            userId,
+		WorkspaceId:       userId,
+	})
+	if err != nil {
+		t.Fatalf("Setup failed: Could not create template: %v", err)
 	}
 
-	id, exception := insertRes.LastInsertId()
-	if exception != nil {
-		return 0, fmt.Errorf("failed to get last insert id: %s", exception.Error())
-	}
-	userId = int(id)
-	return userId, nil
-}
-
-func injectUserContext(r *http.Request, userId int) *http.Request {
-
-	ctx := context.WithValue(r.Context(), controller.CONTEXT_USER_ID_KEY, userId)
-	return r.WithContext(ctx)
+	return id
 }
 
 func TestTemplates_HandleCreate(t *testing.T) {
-	db, userId := setupControllerDB(t)
-	defer db.Close()
+	store, userId := setupControllerStore(t)
 
-	defer db.Exec("DELETE FROM user WHERE user_id = ?", userId)
-
-	tmplController := controller.NewTemplates(db)
+	tmplController := controller.NewTemplates(store.Repositories())
 
 	reqBody := controller.HandleTemplateCreateRequest{
 		TemplateName:      "Controller Test",
@@ -100,20 +79,32 @@ func TestTemplates_HandleCreate(t *testing.T) {
 	if response.Data.TemplateId == 0 {
 		t.Error("handler did not return a valid template ID")
 	}
+}
 
-	db.Exec("DELETE FROM template WHERE template_id = ?", response.Data.TemplateId)
+func TestTemplates_HandleCreateMissingFields(t *testing.T) {
+	store, userId := setupControllerStore(t)
+
+	tmplController := controller.NewTemplates(store.Repositories())
+
+	jsonBody, _ := json.Marshal(controller.HandleTemplateCreateRequest{TemplateName: "Only Name"})
+
+	req, _ := http.NewRequest("POST", "/templates", bytes.NewBuffer(jsonBody))
+	req = injectUserContext(req, userId)
+	rr := httptest.NewRecorder()
+
+	tmplController.HandleCreate(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("wrong status code: got %v want %v", rr.Code, http.StatusBadRequest)
+	}
 }
 
 func TestTemplates_HandleBasicList(t *testing.T) {
-	db, userId := setupControllerDB(t)
-	defer db.Close()
-	defer db.Exec("DELETE FROM user WHERE user_id = ?", userId)
+	store, userId := setupControllerStore(t)
 
-	tmplController := controller.NewTemplates(db)
+	tmplController := controller.NewTemplates(store.Repositories())
 
-	res, _ := db.Exec("INSERT INTO template (template_name, template_content, template_url_import, user_id) VALUES ('BasicList Item', 'x', 'x', ?)", userId)
-	tID, _ := res.LastInsertId()
-	defer db.Exec("DELETE FROM template WHERE template_id = ?", tID)
+	createStoreTemplate(t, store, "BasicList Item", "x", userId)
 
 	req, _ := http.NewRequest("GET", "/templates/basic", nil)
 	req = injectUserContext(req, userId)
@@ -134,17 +125,13 @@ func TestTemplates_HandleBasicList(t *testing.T) {
 }
 
 func TestTemplates_HandleList(t *testing.T) {
-	db, userId := setupControllerDB(t)
-	defer db.Close()
-	defer db.Exec("DELETE FROM user WHERE user_id = ?", userId)
+	store, userId := setupControllerStore(t)
 
-	tmplController := controller.NewTemplates(db)
+	tmplController := controller.NewTemplates(store.Repositories())
 
-	res, _ := db.Exec("INSERT INTO template (template_name, template_content, template_url_import, user_id) VALUES ('DetailedList Item', 'SecretContent', 'x', ?)", userId)
-	tID, _ := res.LastInsertId()
-	defer db.Exec("DELETE FROM template WHERE template_id = ?", tID)
+	tID := createStoreTemplate(t, store, "DetailedList Item", "SecretContent", userId)
 
-	req, _ := http.NewRequest("GET", "/templates?include_content=1&template_id="+strconv.Itoa(int(tID)), nil)
+	req, _ := http.NewRequest("GET", "/templates?include_content=1&template_id="+strconv.Itoa(tID), nil)
 	req = injectUserContext(req, userId)
 	rr := httptest.NewRecorder()
 
@@ -167,18 +154,14 @@ func TestTemplates_HandleList(t *testing.T) {
 }
 
 func TestTemplates_HandleUpdate(t *testing.T) {
-	db, userId := setupControllerDB(t)
-	defer db.Close()
-	defer db.Exec("DELETE FROM user WHERE user_id = ?", userId)
+	store, userId := setupControllerStore(t)
 
-	tmplController := controller.NewTemplates(db)
+	tmplController := controller.NewTemplates(store.Repositories())
 
-	res, _ := db.Exec("INSERT INTO template (template_name, template_content, template_url_import, user_id) VALUES ('Old Name', 'Old Content', 'x', ?)", userId)
-	tID, _ := res.LastInsertId()
-	defer db.Exec("DELETE FROM template WHERE template_id = ?", tID)
+	tID := createStoreTemplate(t, store, "Old Name", "Old Content", userId)
 
 	reqBody := controller.HandleTemplateUpdateRequest{
-		TemplateId:        int(tID),
+		TemplateId:        tID,
 		TemplateName:      "New Name",
 		TemplateContent:   "New Content",
 		TemplateUrlImport: "http://new.com",
@@ -186,6 +169,7 @@ func TestTemplates_HandleUpdate(t *testing.T) {
 	jsonBody, _ := json.Marshal(reqBody)
 
 	req, _ := http.NewRequest("PUT", "/templates", bytes.NewBuffer(jsonBody))
+	req.Header.Set(controller.IF_MATCH_HEADER, controller.ETag(1))
 	req = injectUserContext(req, userId)
 	rr := httptest.NewRecorder()
 
@@ -203,24 +187,147 @@ func TestTemplates_HandleUpdate(t *testing.T) {
 	}
 }
 
-func TestTemplates_HandleDelete(t *testing.T) {
-	db, userId := setupControllerDB(t)
-	defer db.Close()
-	defer db.Exec("DELETE FROM user WHERE user_id = ?", userId)
+func TestTemplates_HandleUpdateOtherUser(t *testing.T) {
+	store, userId := setupControllerStore(t)
 
-	tmplController := controller.NewTemplates(db)
+	tmplController := controller.NewTemplates(store.Repositories())
 
-	res, _ := db.Exec("INSERT INTO template (template_name, template_content, template_url_import, user_id) VALUES ('To Delete', 'x', 'x', ?)", userId)
-	tID, _ := res.LastInsertId()
+	tID := createStoreTemplate(t, store, "Someone Else", "x", userId+1)
 
-	defer db.Exec("DELETE FROM template WHERE template_id = ?", tID)
+	reqBody := controller.HandleTemplateUpdateRequest{
+		TemplateId:        tID,
+		TemplateName:      "Hijacked",
+		TemplateContent:   "x",
+		TemplateUrlImport: "x",
+	}
+	jsonBody, _ := json.Marshal(reqBody)
+
+	req, _ := http.NewRequest("PUT", "/templates", bytes.NewBuffer(jsonBody))
+	req.Header.Set(controller.IF_MATCH_HEADER, controller.ETag(1))
+	req = injectUserContext(req, userId)
+	rr := httptest.NewRecorder()
+
+	tmplController.HandleUpdate(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("wrong status code: got %v want %v", rr.Code, http.StatusBadRequest)
+	}
+}
+
+func requestTemplateUpdate(tmplController *controller.Templates, userId int, templateId int, ifMatch string) *httptest.ResponseRecorder {
+	jsonBody, _ := json.Marshal(controller.HandleTemplateUpdateRequest{
+		TemplateId:        templateId,
+		TemplateName:      "Edited",
+		TemplateContent:   "x",
+		TemplateUrlImport: "x",
+	})
+
+	req, _ := http.NewRequest("PUT", "/templates", bytes.NewBuffer(jsonBody))
+	req = injectUserContext(req, userId)
+
+	if ifMatch != "" {
+		req.Header.Set(controller.IF_MATCH_HEADER, ifMatch)
+	}
+
+	rr := httptest.NewRecorder()
+
+	tmplController.HandleUpdate(rr, req)
+
+	return rr
+}
+
+func TestTemplates_HandleUpdateVersionConflict(t *testing.T) {
+	store, userId := setupControllerStore(t)
+
+	tmplController := controller.NewTemplates(store.Repositories())
+
+	tID := createStoreTemplate(t, store, "Shared", "x", userId)
+
+	rr := requestTemplateUpdate(tmplController, userId, tID, controller.ETag(1))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("wrong status code: got %v want %v. Body: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	if etag := rr.Header().Get(controller.ETAG_HEADER); etag != controller.ETag(2) {
+		t.Errorf("expected ETag %s after update, got %q", controller.ETag(2), etag)
+	}
+
+	rr = requestTemplateUpdate(tmplController, userId, tID, controller.ETag(1))
+
+	if rr.Code != http.StatusPreconditionFailed {
+		t.Fatalf("wrong status code: got %v want %v. Body: %s", rr.Code, http.StatusPreconditionFailed, rr.Body.String())
+	}
+
+	var response controller.HandleVersionConflictResponse
+	json.Unmarshal(rr.Body.Bytes(), &response)
+
+	if response.Data.CurrentVersion != 2 {
+		t.Errorf("expected current version 2 on conflict, got %d", response.Data.CurrentVersion)
+	}
+	if etag := rr.Header().Get(controller.ETAG_HEADER); etag != controller.ETag(2) {
+		t.Errorf("expected ETag %s on conflict, got %q", controller.ETag(2), etag)
+	}
+}
+
+func TestTemplates_HandleUpdateMissingIfMatch(t *testing.T) {
+	store, userId := setupControllerStore(t)
+
+	tmplController := controller.NewTemplates(store.Repositories())
+
+	tID := createStoreTemplate(t, store, "Unguarded", "x", userId)
+
+	rr := requestTemplateUpdate(tmplController, userId, tID, "")
+
+	if rr.Code != http.StatusPreconditionRequired {
+		t.Errorf("wrong status code: got %v want %v", rr.Code, http.StatusPreconditionRequired)
+	}
+
+	templateList, _ := store.Repositories().Templates.List(context.Background(), strconv.Itoa(tID), false, userId)
+
+	if templateList[0].TemplateName != "Unguarded" || templateList[0].TemplateVersion != 1 {
+		t.Errorf("template changed without If-Match: %+v", templateList[0])
+	}
+}
+
+func TestTemplates_HandleListETag(t *testing.T) {
+	store, userId := setupControllerStore(t)
+
+	tmplController := controller.NewTemplates(store.Repositories())
+
+	tID := createStoreTemplate(t, store, "Tagged", "x", userId)
+
+	req, _ := http.NewRequest("GET", "/templates?template_id="+strconv.Itoa(tID), nil)
+	req = injectUserContext(req, userId)
+	rr := httptest.NewRecorder()
+
+	tmplController.HandleList(rr, req)
+
+	if etag := rr.Header().Get(controller.ETAG_HEADER); etag != controller.ETag(1) {
+		t.Errorf("expected ETag %s, got %q", controller.ETag(1), etag)
+	}
+
+	var response controller.HandleTemplateListResponse
+	json.Unmarshal(rr.Body.Bytes(), &response)
+
+	if len(response.Data) != 1 || response.Data[0].TemplateVersion != 1 {
+		t.Errorf("expected template_version 1 in list, got %+v", response.Data)
+	}
+}
+
+func TestTemplates_HandleDelete(t *testing.T) {
+	store, userId := setupControllerStore(t)
+
+	tmplController := controller.NewTemplates(store.Repositories())
+
+	tID := createStoreTemplate(t, store, "To Delete", "x", userId)
 
 	reqBody := controller.HandleTemplateDeleteRequest{
-		TemplateId: int(tID),
+		TemplateId: tID,
 	}
 	jsonBody, _ := json.Marshal(reqBody)
 
 	req, _ := http.NewRequest("DELETE", "/templates", bytes.NewBuffer(jsonBody))
+	req.Header.Set(controller.IF_MATCH_HEADER, controller.ETag(1))
 	req = injectUserContext(req, userId)
 	rr := httptest.NewRecorder()
 
@@ -237,3 +344,38 @@ func TestTemplates_HandleDelete(t *testing.T) {
 		t.Errorf("expected 1 row affected (deleted), got %d", response.Data.RowsAffected)
 	}
 }
+
+func TestTemplates_HandleClone(t *testing.T) {
+	store, userId := setupControllerStore(t)
+
+	tmplController := controller.NewTemplates(store.Repositories())
+
+	tID := createStoreTemplate(t, store, "To Clone", "x", userId)
+
+	reqBody := controller.HandleTemplateCloneRequest{
+		TemplateId: tID,
+	}
+	jsonBody, _ := json.Marshal(reqBody)
+
+	req, _ := http.NewRequest("POST", "/templates/clone", bytes.NewBuffer(jsonBody))
+	req = injectUserContext(req, userId)
+	rr := httptest.NewRecorder()
+
+	tmplController.HandleClone(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Errorf("wrong status code: got %v want %v. Body: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+
+	var response controller.HandleTemplateCreateResponse
+	json.Unmarshal(rr.Body.Bytes(), &response)
+
+	if response.Data.TemplateId == 0 || response.Data.TemplateId == tID {
+		t.Errorf("expected a new template ID, got %d", response.Data.TemplateId)
+	}
+
+	list, _ := store.Repositories().Templates.List(context.Background(), strconv.Itoa(response.Data.TemplateId), false, userId)
+	if len(list) != 1 || list[0].TemplateName != "To Clone"+model.CLONE_NAME_SUFFIX {
+		t.Errorf("expected cloned template to be stored, got %v", list)
+	}
+}
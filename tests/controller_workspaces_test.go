@@ -0,0 +1,166 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"synk/gateway/app/controller"
+	"synk/gateway/app/model"
+	"synk/gateway/app/model/memory"
+	"testing"
+)
+
+func injectWorkspaceContext(r *http.Request, userId int, workspaceId int) *http.Request {
+	ctx := context.WithValue(r.Context(), controller.CONTEXT_USER_ID_KEY, userId)
+	ctx = context.WithValue(ctx, controller.CONTEXT_WORKSPACE_ID_KEY, workspaceId)
+	return r.WithContext(ctx)
+}
+
+func requestWorkspaceMember(store *memory.Store, method string, workspaceId int, reqBody controller.HandleWorkspaceMemberRequest) *httptest.ResponseRecorder {
+	wsController := controller.NewWorkspaces(store.Repositories())
+
+	jsonBody, _ := json.Marshal(reqBody)
+
+	req, _ := http.NewRequest(method, "/workspaces/members", bytes.NewBuffer(jsonBody))
+	req = injectWorkspaceContext(req, CONTROLLER_TEST_USER_ID, workspaceId)
+	rr := httptest.NewRecorder()
+
+	switch method {
+	case "POST":
+		wsController.HandleMemberAdd(rr, req)
+	case "PUT":
+		wsController.HandleMemberUpdate(rr, req)
+	case "DELETE":
+		wsController.HandleMemberRemove(rr, req)
+	}
+
+	return rr
+}
+
+func TestWorkspaces_HandleCreateAndList(t *testing.T) {
+	store, userId := setupControllerStore(t)
+
+	wsController := controller.NewWorkspaces(store.Repositories())
+
+	jsonBody, _ := json.Marshal(controller.HandleWorkspaceCreateRequest{WorkspaceName: "Marketing"})
+
+	req, _ := http.NewRequest("POST", "/workspaces", bytes.NewBuffer(jsonBody))
+	req = injectUserContext(req, userId)
+	rr := httptest.NewRecorder()
+
+	wsController.HandleCreate(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("wrong status code: got %v want %v. Body: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+
+	req, _ = http.NewRequest("GET", "/workspaces", nil)
+	req = injectUserContext(req, userId)
+	rr = httptest.NewRecorder()
+
+	wsController.HandleList(rr, req)
+
+	var response controller.HandleWorkspaceListResponse
+	json.Unmarshal(rr.Body.Bytes(), &response)
+
+	if len(response.Data) != 2 {
+		t.Fatalf("expected personal and team workspaces, got %+v", response.Data)
+	}
+	if !response.Data[0].Personal || response.Data[1].WorkspaceName != "Marketing" {
+		t.Errorf("unexpected workspaces %+v", response.Data)
+	}
+	if response.Data[1].WorkspaceRole != model.WorkspaceRoleOwner {
+		t.Errorf("expected creator to be owner, got %s", response.Data[1].WorkspaceRole)
+	}
+}
+
+func TestWorkspaces_HandleMembers(t *testing.T) {
+	store, userId := setupControllerStore(t)
+
+	teamId, _ := store.Repositories().Workspaces.Add(context.Background(), model.WorkspaceAddData{WorkspaceName: "Team"}, userId)
+
+	if rr := requestWorkspaceMember(store, "POST", teamId, controller.HandleWorkspaceMemberRequest{
+		UserId:        userId + 1,
+		WorkspaceRole: "admin",
+	}); rr.Code != http.StatusBadRequest {
+		t.Errorf("expected unknown role to be refused, got %d", rr.Code)
+	}
+
+	if rr := requestWorkspaceMember(store, "POST", teamId, controller.HandleWorkspaceMemberRequest{
+		UserId:        userId + 1,
+		WorkspaceRole: model.WorkspaceRoleEditor,
+	}); rr.Code != http.StatusOK {
+		t.Fatalf("wrong status code: got %v want %v. Body: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+
+	if rr := requestWorkspaceMember(store, "POST", teamId, controller.HandleWorkspaceMemberRequest{
+		UserId:        userId + 1,
+		WorkspaceRole: model.WorkspaceRoleViewer,
+	}); rr.Code != http.StatusConflict {
+		t.Errorf("expected an existing member to be refused, got %d", rr.Code)
+	}
+
+	if rr := requestWorkspaceMember(store, "PUT", teamId, controller.HandleWorkspaceMemberRequest{
+		UserId:        userId,
+		WorkspaceRole: model.WorkspaceRoleViewer,
+	}); rr.Code != http.StatusConflict {
+		t.Errorf("expected last owner demotion to be refused, got %d", rr.Code)
+	}
+
+	if rr := requestWorkspaceMember(store, "DELETE", teamId, controller.HandleWorkspaceMemberRequest{
+		UserId: userId,
+	}); rr.Code != http.StatusConflict {
+		t.Errorf("expected last owner removal to be refused, got %d", rr.Code)
+	}
+
+	if rr := requestWorkspaceMember(store, "DELETE", teamId, controller.HandleWorkspaceMemberRequest{
+		UserId: userId + 1,
+	}); rr.Code != http.StatusOK {
+		t.Errorf("wrong status code: got %v want %v", rr.Code, http.StatusOK)
+	}
+
+	wsController := controller.NewWorkspaces(store.Repositories())
+
+	req, _ := http.NewRequest("GET", "/workspaces/members", nil)
+	req = injectWorkspaceContext(req, userId, teamId)
+	rr := httptest.NewRecorder()
+
+	wsController.HandleMemberList(rr, req)
+
+	var response controller.HandleWorkspaceMemberListResponse
+	json.Unmarshal(rr.Body.Bytes(), &response)
+
+	if len(response.Data) != 1 || response.Data[0].UserId != userId {
+		t.Errorf("expected only the owner to remain, got %+v", response.Data)
+	}
+}
+
+func TestWorkspaces_SharedResources(t *testing.T) {
+	store, userId := setupControllerStore(t)
+
+	teamId, _ := store.Repositories().Workspaces.Add(context.Background(), model.WorkspaceAddData{WorkspaceName: "Team"}, userId)
+	store.Repositories().Workspaces.AddMember(context.Background(), teamId, userId+1, model.WorkspaceRoleViewer)
+
+	store.Repositories().Templates.Add(context.Background(), model.TemplateAddData{
+		TemplateName: "Shared",
+		UserId:       userId,
+		WorkspaceId:  teamId,
+	})
+
+	tmplController := controller.NewTemplates(store.Repositories())
+
+	req, _ := http.NewRequest("GET", "/templates/basic", nil)
+	req = injectWorkspaceContext(req, userId+1, teamId)
+	rr := httptest.NewRecorder()
+
+	tmplController.HandleBasicList(rr, req)
+
+	var response controller.HandleTemplateBasicListResponse
+	json.Unmarshal(rr.Body.Bytes(), &response)
+
+	if len(response.Data) != 1 || response.Data[0].TemplateName != "Shared" {
+		t.Errorf("expected member to see the shared template, got %+v", response.Data)
+	}
+}
@@ -0,0 +1,61 @@
+package tests
+
+import (
+	"synk/gateway/app/config"
+	"testing"
+	"time"
+)
+
+func TestLoadDBConfig_TestMode(t *testing.T) {
+	t.Setenv("DB_HOST", "prod_host")
+	t.Setenv("DB_USER", "prod_user")
+	t.Setenv("DB_NAME", "")
+	t.Setenv("DB_HOST_TEST", "test_host")
+	t.Setenv("DB_USER_TEST", "")
+	t.Setenv("DB_NAME_TEST", "")
+	t.Setenv("DB_MAX_OPEN_CONNS", "42")
+	t.Setenv("DB_CONN_MAX_LIFETIME", "90s")
+
+	dbConfig, err := config.LoadDB(true)
+	if err != nil {
+		t.Fatalf("LoadDB failed: %v", err)
+	}
+
+	if dbConfig.Host != "test_host" {
+		t.Errorf("Expected test host, got %s", dbConfig.Host)
+	}
+	if dbConfig.User != "prod_user" {
+		t.Errorf("Expected user to fall back to DB_USER, got %s", dbConfig.User)
+	}
+	if dbConfig.Name != config.DEFAULT_DB_TEST_NAME {
+		t.Errorf("Expected default test database, got %s", dbConfig.Name)
+	}
+	if dbConfig.MaxOpenConns != 42 {
+		t.Errorf("Expected 42 max open conns, got %d", dbConfig.MaxOpenConns)
+	}
+	if dbConfig.ConnMaxLifetime != 90*time.Second {
+		t.Errorf("Expected 90s lifetime, got %s", dbConfig.ConnMaxLifetime)
+	}
+
+	production, _ := config.LoadDB(false)
+	if production.Name != config.DEFAULT_DB_NAME || production.Host != "prod_host" {
+		t.Errorf("Expected production config, got %+v", production)
+	}
+}
+
+func TestLoadDBConfig_RejectsSameDatabase(t *testing.T) {
+	t.Setenv("DB_NAME", "shared")
+	t.Setenv("DB_NAME_TEST", "shared")
+
+	if _, err := config.LoadDB(true); err == nil {
+		t.Error("Expected error when test and production databases are the same")
+	}
+}
+
+func TestLoadDBConfig_InvalidPool(t *testing.T) {
+	t.Setenv("DB_MAX_IDLE_CONNS", "many")
+
+	if _, err := config.LoadDB(false); err == nil {
+		t.Error("Expected error for non-numeric DB_MAX_IDLE_CONNS")
+	}
+}
@@ -0,0 +1,190 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"synk/gateway/app"
+	"synk/gateway/app/controller"
+	"synk/gateway/app/model"
+	"synk/gateway/app/model/memory"
+	"testing"
+	"time"
+)
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	calls := 0
+	status := http.StatusOK
+
+	handler := controller.Idempotency(memory.NewStore().Repositories().IdempotencyKeys, time.Hour)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(status)
+		w.Write([]byte(`{"post":{"post_id":1}}`))
+	}))
+
+	serve := func(key string, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", "/post", bytes.NewBufferString(body))
+		req.Header.Set(controller.IDEMPOTENCY_KEY_HEADER, key)
+		req = injectUserContext(req, CONTROLLER_TEST_USER_ID)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		return rr
+	}
+
+	serve("create-1", `{"post_name":"A"}`)
+	rr := serve("create-1", `{"post_name":"A"}`)
+
+	if calls != 1 {
+		t.Errorf("expected retry not to reach the handler, got %d calls", calls)
+	}
+	if rr.Code != http.StatusOK || rr.Header().Get(controller.IDEMPOTENCY_REPLAYED_HEADER) != "true" || rr.Body.String() != `{"post":{"post_id":1}}` {
+		t.Errorf("expected stored response to be replayed, got %d %s", rr.Code, rr.Body.String())
+	}
+
+	if rr := serve("create-1", `{"post_name":"B"}`); rr.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected reuse with another body to give 422, got %d", rr.Code)
+	}
+
+	status = http.StatusInternalServerError
+	serve("create-2", `{"post_name":"C"}`)
+	status = http.StatusOK
+	serve("create-2", `{"post_name":"C"}`)
+
+	if calls != 3 {
+		t.Errorf("expected failed request to release its key, got %d calls", calls)
+	}
+
+	req, _ := http.NewRequest("POST", "/post", bytes.NewBufferString(`{}`))
+	req = req.WithContext(context.WithValue(req.Context(), controller.CONTEXT_USER_ID_KEY, CONTROLLER_TEST_USER_ID))
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if calls != 4 {
+		t.Errorf("expected requests without key to pass through, got %d calls", calls)
+	}
+}
+
+// contextIdempotencyKeys fails like the SQL repository does once the context is cancelled.
+type contextIdempotencyKeys struct {
+	model.IdempotencyKeysRepository
+}
+
+func (ck contextIdempotencyKeys) Complete(ctx context.Context, key model.IdempotencyKeyData) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	return ck.IdempotencyKeysRepository.Complete(ctx, key)
+}
+
+func (ck contextIdempotencyKeys) Release(ctx context.Context, userId int, idempotencyKey string) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	return ck.IdempotencyKeysRepository.Release(ctx, userId, idempotencyKey)
+}
+
+func TestIdempotencyMiddleware_ClientGone(t *testing.T) {
+	calls := 0
+	status := http.StatusOK
+	keys := contextIdempotencyKeys{memory.NewStore().Repositories().IdempotencyKeys}
+
+	handler := controller.Idempotency(keys, time.Hour)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(status)
+		w.Write([]byte(`{"post":{"post_id":1}}`))
+	}))
+
+	serve := func(key string, disconnect bool) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", "/post", bytes.NewBufferString(`{"post_name":"A"}`))
+		req.Header.Set(controller.IDEMPOTENCY_KEY_HEADER, key)
+		req = injectUserContext(req, CONTROLLER_TEST_USER_ID)
+
+		ctx, cancel := context.WithCancel(req.Context())
+		defer cancel()
+
+		if disconnect {
+			cancel()
+		}
+
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req.WithContext(ctx))
+
+		return rr
+	}
+
+	serve("timeout-1", true)
+
+	if rr := serve("timeout-1", false); rr.Code != http.StatusOK || rr.Header().Get(controller.IDEMPOTENCY_REPLAYED_HEADER) != "true" {
+		t.Errorf("expected the retry to replay the completed response, got %d %s", rr.Code, rr.Body.String())
+	}
+
+	status = http.StatusInternalServerError
+	serve("timeout-2", true)
+	status = http.StatusOK
+
+	if rr := serve("timeout-2", false); rr.Code != http.StatusOK || calls != 3 {
+		t.Errorf("expected the failed request to release its key, got %d after %d calls", rr.Code, calls)
+	}
+}
+
+func TestIdempotencyKeys_Reserve(t *testing.T) {
+	db, err := app.InitDB(true)
+	if err != nil {
+		t.Fatalf("db connection failed: %v", err)
+	}
+	defer db.Close()
+
+	keysModel := model.NewIdempotencyKeys(db)
+	key := model.IdempotencyKeyData{UserId: 1, IdempotencyKey: "reserve-test", RequestHash: "hash-a"}
+	defer db.Exec("DELETE FROM idempotency_key WHERE idempotency_key = ?", key.IdempotencyKey)
+
+	if _, reserved, err := keysModel.Reserve(context.Background(), key, time.Hour); err != nil || !reserved {
+		t.Fatalf("expected first reserve to succeed, got %v %v", reserved, err)
+	}
+
+	key.ResponseStatus = http.StatusOK
+	key.ResponseBody = `{"ok":true}`
+
+	if err := keysModel.Complete(context.Background(), key); err != nil {
+		t.Fatalf("Complete failed: %v", err)
+	}
+
+	stored, reserved, err := keysModel.Reserve(context.Background(), model.IdempotencyKeyData{UserId: 1, IdempotencyKey: "reserve-test", RequestHash: "hash-b"}, time.Hour)
+	if err != nil || reserved {
+		t.Fatalf("expected key to be taken, got %v %v", reserved, err)
+	}
+	if stored.RequestHash != "hash-a" || stored.ResponseStatus != http.StatusOK || stored.ResponseBody != `{"ok":true}` {
+		t.Errorf("unexpected stored key %+v", stored)
+	}
+
+	if err := keysModel.Release(context.Background(), 1, "reserve-test"); err != nil {
+		t.Fatalf("Release failed: %v", err)
+	}
+	if _, reserved, _ := keysModel.Reserve(context.Background(), key, time.Hour); !reserved {
+		t.Error("expected released key to be reserved again")
+	}
+
+	_, err = db.Exec(
+		`INSERT INTO idempotency_key (user_id, idempotency_key, request_hash, expires_at)
+        VALUES (1, 'expired-test', 'hash-c', DATE_SUB(CURRENT_TIMESTAMP, INTERVAL 1 HOUR))`,
+	)
+	if err != nil {
+		t.Fatalf("Setup failed: Could not insert expired key: %v", err)
+	}
+	defer db.Exec("DELETE FROM idempotency_key WHERE idempotency_key = 'expired-test'")
+
+	if _, _, err := keysModel.Reserve(context.Background(), model.IdempotencyKeyData{UserId: 1, IdempotencyKey: "purge-test", RequestHash: "hash-d"}, time.Hour); err != nil {
+		t.Fatalf("Reserve failed: %v", err)
+	}
+	defer db.Exec("DELETE FROM idempotency_key WHERE idempotency_key = 'purge-test'")
+
+	var expired int
+	db.QueryRow("SELECT COUNT(*) FROM idempotency_key WHERE idempotency_key = 'expired-test'").Scan(&expired)
+
+	if expired != 0 {
+		t.Error("expected expired keys of other requests to be purged on reserve")
+	}
+}
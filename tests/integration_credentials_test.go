@@ -54,9 +54,10 @@ func TestIntCredentials_Add(t *testing.T) {
 		IntCredentialName:   "Test Add Bot",
 		IntCredentialType:   model.Discord,
 		IntCredentialConfig: "{}",
+		WorkspaceId:         SEED_WORKSPACE_ID,
 	}
 
-	id, err := credsModel.Add(input, userId)
+	id, err := credsModel.Add(context.Background(), input, userId)
 	if err != nil {
 		t.Fatalf("Add failed: %v", err)
 	}
@@ -76,8 +77,9 @@ func TestIntCredentials_Update(t *testing.T) {
 		IntCredentialName:   "Pre-Update Name",
 		IntCredentialType:   model.Discord,
 		IntCredentialConfig: "{}",
+		WorkspaceId:         SEED_WORKSPACE_ID,
 	}
-	id, err := credsModel.Add(input, userId)
+	id, err := credsModel.Add(context.Background(), input, userId)
 	if err != nil {
 		t.Fatalf("Setup for Update failed (Add): %v", err)
 	}
@@ -90,7 +92,7 @@ func TestIntCredentials_Update(t *testing.T) {
 		IntCredentialConfig: `{"new": "val"}`,
 	}
 
-	rows, err := credsModel.Update(updateData, userId)
+	rows, err := credsModel.Update(context.Background(), updateData, SEED_WORKSPACE_ID)
 	if err != nil {
 		t.Fatalf("Update failed: %v", err)
 	}
@@ -98,7 +100,7 @@ func TestIntCredentials_Update(t *testing.T) {
 		t.Errorf("Expected 1 row updated, got %d", rows)
 	}
 
-	list, _ := credsModel.List(strconv.Itoa(id), true, userId)
+	list, _ := credsModel.List(context.Background(), strconv.Itoa(id), true, SEED_WORKSPACE_ID)
 	if len(list) > 0 {
 		if list[0].IntCredentialName != "Post-Update Name" {
 			t.Error("Update name not persisted")
@@ -117,9 +119,10 @@ func TestIntCredentials_Delete(t *testing.T) {
 		IntCredentialName:   "To Delete",
 		IntCredentialType:   model.Discord,
 		IntCredentialConfig: "{}",
+		WorkspaceId:         SEED_WORKSPACE_ID,
 	}
 
-	id, err := credsModel.Add(input, userId)
+	id, err := credsModel.Add(context.Background(), input, userId)
 	if err != nil {
 		t.Fatalf("Setup for Delete failed (Add): %v", err)
 	}
@@ -129,7 +132,7 @@ func TestIntCredentials_Delete(t *testing.T) {
 
 	defer db.Exec("DELETE FROM integration_credential WHERE int_credential_id = ?", id)
 
-	rows, err := credsModel.Delete(id, userId)
+	rows, err := credsModel.Delete(context.Background(), id, 0, SEED_WORKSPACE_ID)
 	if err != nil {
 		t.Fatalf("Delete failed: %v", err)
 	}
@@ -137,7 +140,7 @@ func TestIntCredentials_Delete(t *testing.T) {
 		t.Errorf("Expected 1 row deleted, got %d. (Did the ID exist?)", rows)
 	}
 
-	list, _ := credsModel.List(strconv.Itoa(id), false, userId)
+	list, _ := credsModel.List(context.Background(), strconv.Itoa(id), false, SEED_WORKSPACE_ID)
 	if len(list) != 0 {
 		t.Error("Item still returned after delete")
 	}
@@ -152,14 +155,15 @@ func TestIntCredentials_List(t *testing.T) {
 		IntCredentialName:   "List Test",
 		IntCredentialType:   model.Discord,
 		IntCredentialConfig: `{"token":"123"}`,
+		WorkspaceId:         SEED_WORKSPACE_ID,
 	}
-	id, err := credsModel.Add(input, userId)
+	id, err := credsModel.Add(context.Background(), input, userId)
 	if err != nil {
 		t.Fatalf("Setup for List failed: %v", err)
 	}
 	defer db.Exec("DELETE FROM integration_credential WHERE int_credential_id = ?", id)
 
-	listWithConfig, err := credsModel.List(strconv.Itoa(id), true, userId)
+	listWithConfig, err := credsModel.List(context.Background(), strconv.Itoa(id), true, SEED_WORKSPACE_ID)
 	if err != nil {
 		t.Errorf("List failed: %v", err)
 	}
@@ -167,12 +171,12 @@ func TestIntCredentials_List(t *testing.T) {
 		t.Error("Expected config to be present")
 	}
 
-	listNoConfig, _ := credsModel.List(strconv.Itoa(id), false, userId)
+	listNoConfig, _ := credsModel.List(context.Background(), strconv.Itoa(id), false, SEED_WORKSPACE_ID)
 	if len(listNoConfig) > 0 && listNoConfig[0].IntCredentialConfig != "" {
 		t.Error("Expected config to be empty string")
 	}
 
-	listAll, _ := credsModel.List("", false, userId)
+	listAll, _ := credsModel.List(context.Background(), "", false, SEED_WORKSPACE_ID)
 	if len(listAll) == 0 {
 		t.Error("Expected list all to return items")
 	}
@@ -187,14 +191,15 @@ func TestIntCredentials_BasicList(t *testing.T) {
 		IntCredentialName:   "Basic List Test",
 		IntCredentialType:   model.Discord,
 		IntCredentialConfig: "{}",
+		WorkspaceId:         SEED_WORKSPACE_ID,
 	}
-	id, err := credsModel.Add(input, userId)
+	id, err := credsModel.Add(context.Background(), input, userId)
 	if err != nil {
 		t.Fatalf("Setup for BasicList failed (Add): %v", err)
 	}
 	defer db.Exec("DELETE FROM integration_credential WHERE int_credential_id = ?", id)
 
-	list, err := credsModel.BasicList(userId)
+	list, err := credsModel.BasicList(context.Background(), SEED_WORKSPACE_ID)
 	if err != nil {
 		t.Fatalf("BasicList failed: %v", err)
 	}
@@ -223,8 +228,9 @@ func TestIntCredentials_BasicListByProfile(t *testing.T) {
 		IntCredentialName:   "Linked Cred",
 		IntCredentialType:   model.Discord,
 		IntCredentialConfig: "{}",
+		WorkspaceId:         SEED_WORKSPACE_ID,
 	}
-	credId, err := credsModel.Add(credInput, userId)
+	credId, err := credsModel.Add(context.Background(), credInput, userId)
 	if err != nil {
 		t.Fatalf("Setup failed: Could not create credential: %v", err)
 	}
@@ -237,8 +243,8 @@ func TestIntCredentials_BasicListByProfile(t *testing.T) {
 	}
 
 	res, err := db.ExecContext(context.Background(),
-		"INSERT INTO integration_profile (int_profile_name, color_id, user_id) VALUES ('Test Profile', ?, ?)",
-		colorId, userId)
+		"INSERT INTO integration_profile (int_profile_name, color_id, user_id, workspace_id) VALUES ('Test Profile', ?, ?, ?)",
+		colorId, userId, SEED_WORKSPACE_ID)
 	if err != nil {
 		t.Fatalf("Failed to create test profile: %v", err)
 	}
@@ -252,7 +258,7 @@ func TestIntCredentials_BasicListByProfile(t *testing.T) {
 	}
 	defer db.Exec("DELETE FROM integration_group WHERE int_profile_id = ?", profileId)
 
-	list, err := credsModel.BasicListByProfile(profileId, userId)
+	list, err := credsModel.BasicListByProfile(context.Background(), profileId, SEED_WORKSPACE_ID)
 	if err != nil {
 		t.Fatalf("BasicListByProfile failed: %v", err)
 	}
@@ -282,9 +288,10 @@ func TestIntCredentialsLifecycle(t *testing.T) {
 		IntCredentialName:   "Test Integration Bot",
 		IntCredentialType:   model.Discord,
 		IntCredentialConfig: `{"token": "123-test-token"}`,
+		WorkspaceId:         SEED_WORKSPACE_ID,
 	}
 
-	createdId, err := credsModel.Add(newCred, testUserId)
+	createdId, err := credsModel.Add(context.Background(), newCred, testUserId)
 	if err != nil {
 		t.Fatalf("credentials: add failed: %v", err)
 	}
@@ -295,7 +302,7 @@ func TestIntCredentialsLifecycle(t *testing.T) {
 
 	t.Logf("Created Credential ID: %d", createdId)
 
-	list, err := credsModel.List(strconv.Itoa(createdId), true, testUserId)
+	list, err := credsModel.List(context.Background(), strconv.Itoa(createdId), true, SEED_WORKSPACE_ID)
 	if err != nil {
 		t.Errorf("credentials: list failed: %v", err)
 	}
@@ -320,7 +327,7 @@ func TestIntCredentialsLifecycle(t *testing.T) {
 		IntCredentialConfig: `{"token": "456-new-token"}`,
 	}
 
-	rowsAffected, err := credsModel.Update(updateData, testUserId)
+	rowsAffected, err := credsModel.Update(context.Background(), updateData, SEED_WORKSPACE_ID)
 	if err != nil {
 		t.Errorf("credentials: update failed: %v", err)
 	}
@@ -328,7 +335,7 @@ func TestIntCredentialsLifecycle(t *testing.T) {
 		t.Errorf("credentials: expected 1 row updated, got %d", rowsAffected)
 	}
 
-	updatedList, _ := credsModel.List(strconv.Itoa(createdId), false, testUserId)
+	updatedList, _ := credsModel.List(context.Background(), strconv.Itoa(createdId), false, SEED_WORKSPACE_ID)
 	if len(updatedList) > 0 {
 		if updatedList[0].IntCredentialName != "Updated Bot Name" {
 			t.Errorf("credentials: update name not persisted")
@@ -342,7 +349,7 @@ func TestIntCredentialsLifecycle(t *testing.T) {
 		}
 	}
 
-	delRows, err := credsModel.Delete(createdId, testUserId)
+	delRows, err := credsModel.Delete(context.Background(), createdId, 0, SEED_WORKSPACE_ID)
 	if err != nil {
 		t.Errorf("credentials: delete failed: %v", err)
 	}
@@ -350,7 +357,7 @@ func TestIntCredentialsLifecycle(t *testing.T) {
 		t.Errorf("credentials: expected 1 row deleted, got %d", delRows)
 	}
 
-	finalList, _ := credsModel.List(strconv.Itoa(createdId), false, testUserId)
+	finalList, _ := credsModel.List(context.Background(), strconv.Itoa(createdId), false, SEED_WORKSPACE_ID)
 	if len(finalList) != 0 {
 		t.Errorf("credentials: item should be deleted but was returned in list")
 	}
@@ -365,7 +372,7 @@ func TestIntCredentialsBasicList(t *testing.T) {
 
 	credsModel := model.NewIntCredentials(db)
 
-	list, err := credsModel.BasicList(1)
+	list, err := credsModel.BasicList(context.Background(), 1)
 	if err != nil {
 		t.Errorf("credentials: basic list failed: %v", err)
 	}
@@ -28,8 +28,8 @@ func getValidColorId(t *testing.T, db *sql.DB) int {
 
 func createDummyCredential(t *testing.T, db *sql.DB, userId int) int {
 	res, err := db.ExecContext(context.Background(),
-		`INSERT INTO integration_credential (int_credential_name, int_credential_type, int_credential_config, user_id)
-		 VALUES ('Profile Test Cred', 'discord', '{}', ?)`, userId)
+		`INSERT INTO integration_credential (int_credential_name, int_credential_type, int_credential_config, user_id, workspace_id)
+		 VALUES ('Profile Test Cred', 'discord', '{}', ?, ?)`, userId, SEED_WORKSPACE_ID)
 	if err != nil {
 		t.Fatalf("Setup failed: Could not create dummy credential: %v", err)
 	}
@@ -59,10 +59,11 @@ func TestIntProfiles_Add(t *testing.T) {
 	input := model.IntProfileAddData{
 		IntProfileName: "Test Add Profile",
 		ColorId:        colorId,
+		WorkspaceId:    SEED_WORKSPACE_ID,
 	}
 	credentialsToLink := []int{credId}
 
-	id, err := profileModel.Add(input, credentialsToLink, userId)
+	id, err := profileModel.Add(context.Background(), input, credentialsToLink, userId)
 	if err != nil {
 		t.Fatalf("Add failed: %v", err)
 	}
@@ -83,8 +84,8 @@ func TestIntProfiles_Update(t *testing.T) {
 	credId := createDummyCredential(t, db, userId)
 	defer db.Exec("DELETE FROM integration_credential WHERE int_credential_id = ?", credId)
 
-	createInput := model.IntProfileAddData{IntProfileName: "Pre-Update", ColorId: colorId}
-	id, _ := profileModel.Add(createInput, []int{}, userId)
+	createInput := model.IntProfileAddData{IntProfileName: "Pre-Update", ColorId: colorId, WorkspaceId: SEED_WORKSPACE_ID}
+	id, _ := profileModel.Add(context.Background(), createInput, []int{}, userId)
 	defer db.Exec("DELETE FROM integration_profile WHERE int_profile_id = ?", id)
 
 	updateInput := model.IntProfileUpdateData{
@@ -94,7 +95,7 @@ func TestIntProfiles_Update(t *testing.T) {
 	}
 	credentialsToLink := []int{credId}
 
-	rows, err := profileModel.Update(updateInput, credentialsToLink, userId)
+	rows, err := profileModel.Update(context.Background(), updateInput, credentialsToLink, SEED_WORKSPACE_ID)
 	if err != nil {
 		t.Fatalf("Update failed: %v", err)
 	}
@@ -102,7 +103,7 @@ func TestIntProfiles_Update(t *testing.T) {
 		t.Errorf("Expected 1 row updated, got %d", rows)
 	}
 
-	list, _ := profileModel.List(strconv.Itoa(id), userId)
+	list, _ := profileModel.List(context.Background(), strconv.Itoa(id), SEED_WORKSPACE_ID)
 	if list[0].IntProfileName != "Post-Update" {
 		t.Errorf("Update name not persisted. Got %s", list[0].IntProfileName)
 	}
@@ -114,16 +115,16 @@ func TestIntProfiles_Delete(t *testing.T) {
 	profileModel := model.NewIntProfiles(db)
 
 	colorId := getValidColorId(t, db)
-	input := model.IntProfileAddData{IntProfileName: "To Delete", ColorId: colorId}
+	input := model.IntProfileAddData{IntProfileName: "To Delete", ColorId: colorId, WorkspaceId: SEED_WORKSPACE_ID}
 
-	id, err := profileModel.Add(input, []int{}, userId)
+	id, err := profileModel.Add(context.Background(), input, []int{}, userId)
 	if err != nil {
 		t.Fatalf("Setup for Delete failed: %v", err)
 	}
 
 	defer db.Exec("DELETE FROM integration_profile WHERE int_profile_id = ?", id)
 
-	rows, err := profileModel.Delete(id, userId)
+	rows, err := profileModel.Delete(context.Background(), id, 0, SEED_WORKSPACE_ID)
 	if err != nil {
 		t.Fatalf("Delete failed: %v", err)
 	}
@@ -131,7 +132,7 @@ func TestIntProfiles_Delete(t *testing.T) {
 		t.Errorf("Expected 1 row deleted, got %d", rows)
 	}
 
-	list, _ := profileModel.List(strconv.Itoa(id), userId)
+	list, _ := profileModel.List(context.Background(), strconv.Itoa(id), SEED_WORKSPACE_ID)
 	if len(list) != 0 {
 		t.Error("Profile returned after soft delete")
 	}
@@ -143,12 +144,12 @@ func TestIntProfiles_List(t *testing.T) {
 	profileModel := model.NewIntProfiles(db)
 
 	colorId := getValidColorId(t, db)
-	input := model.IntProfileAddData{IntProfileName: "List Test", ColorId: colorId}
+	input := model.IntProfileAddData{IntProfileName: "List Test", ColorId: colorId, WorkspaceId: SEED_WORKSPACE_ID}
 
-	id, _ := profileModel.Add(input, []int{}, userId)
+	id, _ := profileModel.Add(context.Background(), input, []int{}, userId)
 	defer db.Exec("DELETE FROM integration_profile WHERE int_profile_id = ?", id)
 
-	list, err := profileModel.List(strconv.Itoa(id), userId)
+	list, err := profileModel.List(context.Background(), strconv.Itoa(id), SEED_WORKSPACE_ID)
 	if err != nil {
 		t.Fatalf("List failed: %v", err)
 	}
@@ -172,11 +173,11 @@ func TestIntProfiles_BasicList(t *testing.T) {
 	profileModel := model.NewIntProfiles(db)
 
 	colorId := getValidColorId(t, db)
-	input := model.IntProfileAddData{IntProfileName: "Basic List Test", ColorId: colorId}
-	id, _ := profileModel.Add(input, []int{}, userId)
+	input := model.IntProfileAddData{IntProfileName: "Basic List Test", ColorId: colorId, WorkspaceId: SEED_WORKSPACE_ID}
+	id, _ := profileModel.Add(context.Background(), input, []int{}, userId)
 	defer db.Exec("DELETE FROM integration_profile WHERE int_profile_id = ?", id)
 
-	list, err := profileModel.BasicList(userId)
+	list, err := profileModel.BasicList(context.Background(), SEED_WORKSPACE_ID)
 	if err != nil {
 		t.Fatalf("BasicList failed: %v", err)
 	}
@@ -202,11 +203,11 @@ func TestIntProfiles_ById(t *testing.T) {
 	profileModel := model.NewIntProfiles(db)
 
 	colorId := getValidColorId(t, db)
-	input := model.IntProfileAddData{IntProfileName: "ById Test", ColorId: colorId}
-	id, _ := profileModel.Add(input, []int{}, userId)
+	input := model.IntProfileAddData{IntProfileName: "ById Test", ColorId: colorId, WorkspaceId: SEED_WORKSPACE_ID}
+	id, _ := profileModel.Add(context.Background(), input, []int{}, userId)
 	defer db.Exec("DELETE FROM integration_profile WHERE int_profile_id = ?", id)
 
-	data, err := profileModel.ById(id, userId)
+	data, err := profileModel.ById(context.Background(), id, SEED_WORKSPACE_ID)
 	if err != nil {
 		t.Fatalf("ById failed: %v", err)
 	}
@@ -216,6 +217,45 @@ func TestIntProfiles_ById(t *testing.T) {
 	}
 }
 
+func TestIntProfiles_Clone(t *testing.T) {
+	db, userId := setupProfilesDB(t)
+	defer db.Close()
+	profileModel := model.NewIntProfiles(db)
+	credentialModel := model.NewIntCredentials(db)
+
+	colorId := getValidColorId(t, db)
+	credId := createDummyCredential(t, db, userId)
+	defer db.Exec("DELETE FROM integration_credential WHERE int_credential_id = ?", credId)
+
+	input := model.IntProfileAddData{IntProfileName: "Clone Source", ColorId: colorId, WorkspaceId: SEED_WORKSPACE_ID}
+	id, _ := profileModel.Add(context.Background(), input, []int{credId}, userId)
+	defer db.Exec("DELETE FROM integration_profile WHERE int_profile_id = ?", id)
+	defer db.Exec("DELETE FROM integration_group WHERE int_profile_id = ?", id)
+
+	clonedId, err := profileModel.Clone(context.Background(), id, SEED_WORKSPACE_ID)
+	if err != nil {
+		t.Fatalf("Clone failed: %v", err)
+	}
+	defer db.Exec("DELETE FROM integration_profile WHERE int_profile_id = ?", clonedId)
+	defer db.Exec("DELETE FROM integration_group WHERE int_profile_id = ?", clonedId)
+
+	list, _ := profileModel.List(context.Background(), strconv.Itoa(clonedId), SEED_WORKSPACE_ID)
+	if len(list) != 1 {
+		t.Fatalf("Expected cloned profile to be listed, got %d items", len(list))
+	}
+	if list[0].IntProfileName != "Clone Source"+model.CLONE_NAME_SUFFIX {
+		t.Errorf("Unexpected cloned name. Got %s", list[0].IntProfileName)
+	}
+	if list[0].ColorId != colorId {
+		t.Errorf("Color not cloned. Got %d", list[0].ColorId)
+	}
+
+	credentials, _ := credentialModel.BasicListByProfile(context.Background(), clonedId, SEED_WORKSPACE_ID)
+	if len(credentials) != 1 || credentials[0].IntCredentialId != credId {
+		t.Errorf("Credential links not cloned. Got %v", credentials)
+	}
+}
+
 func TestIntProfileLifecycle(t *testing.T) {
 	db, userId := setupProfilesDB(t)
 	defer db.Close()
@@ -225,13 +265,14 @@ func TestIntProfileLifecycle(t *testing.T) {
 	newProfile := model.IntProfileAddData{
 		IntProfileName: "Lifecycle Test",
 		ColorId:        colorId,
+		WorkspaceId:    SEED_WORKSPACE_ID,
 	}
-	createdId, err := profileModel.Add(newProfile, []int{}, userId)
+	createdId, err := profileModel.Add(context.Background(), newProfile, []int{}, userId)
 	if err != nil {
 		t.Fatalf("lifecycle: add failed: %v", err)
 	}
 
-	list, _ := profileModel.List(strconv.Itoa(createdId), userId)
+	list, _ := profileModel.List(context.Background(), strconv.Itoa(createdId), SEED_WORKSPACE_ID)
 	if len(list) != 1 {
 		t.Errorf("lifecycle: list count mismatch")
 	}
@@ -241,14 +282,40 @@ func TestIntProfileLifecycle(t *testing.T) {
 		IntProfileName: "Lifecycle Updated",
 		ColorId:        colorId,
 	}
-	profileModel.Update(updateData, []int{}, userId)
+	profileModel.Update(context.Background(), updateData, []int{}, SEED_WORKSPACE_ID)
 
-	profileModel.Delete(createdId, userId)
+	profileModel.Delete(context.Background(), createdId, 0, SEED_WORKSPACE_ID)
 
-	finalList, _ := profileModel.List(strconv.Itoa(createdId), userId)
+	finalList, _ := profileModel.List(context.Background(), strconv.Itoa(createdId), SEED_WORKSPACE_ID)
 	if len(finalList) != 0 {
 		t.Error("lifecycle: soft delete failed")
 	}
 
 	db.Exec("DELETE FROM integration_profile WHERE int_profile_id = ?", createdId)
 }
+
+func TestIntProfiles_AddRollsBackOnLinkError(t *testing.T) {
+	db, userId := setupProfilesDB(t)
+	defer db.Close()
+	profileModel := model.NewIntProfiles(db)
+
+	colorId := getValidColorId(t, db)
+	credId := createDummyCredential(t, db, userId)
+	defer db.Exec("DELETE FROM integration_credential WHERE int_credential_id = ?", credId)
+
+	input := model.IntProfileAddData{IntProfileName: "Tx Rollback Profile", ColorId: colorId, WorkspaceId: SEED_WORKSPACE_ID}
+
+	id, err := profileModel.Add(context.Background(), input, []int{credId, credId}, userId)
+	if err == nil {
+		defer db.Exec("DELETE FROM integration_profile WHERE int_profile_id = ?", id)
+		defer db.Exec("DELETE FROM integration_group WHERE int_profile_id = ?", id)
+
+		t.Skip("integration_group accepts duplicated links, nothing to roll back")
+	}
+
+	var count int
+	db.QueryRow("SELECT COUNT(*) FROM integration_profile WHERE int_profile_name = 'Tx Rollback Profile' AND user_id = ?", userId).Scan(&count)
+	if count != 0 {
+		t.Errorf("Expected profile insert to be rolled back, got %d rows", count)
+	}
+}
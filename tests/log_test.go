@@ -2,13 +2,53 @@ package tests
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"strings"
+	"synk/gateway/app/config"
+	"synk/gateway/app/controller"
 	"synk/gateway/app/util"
 	"testing"
 )
 
+func captureLogs(t *testing.T) *bytes.Buffer {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	previous := slog.Default()
+	slog.SetDefault(util.NewLogger(&buf, util.LOG_FORMAT_JSON, slog.LevelDebug))
+
+	t.Cleanup(func() {
+		slog.SetDefault(previous)
+	})
+
+	return &buf
+}
+
+func decodeLogLines(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+
+	lines := []map[string]any{}
+
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		entry := map[string]any{}
+
+		if decodeErr := json.Unmarshal([]byte(line), &entry); decodeErr != nil {
+			t.Fatalf("expected JSON log line, got %q", line)
+		}
+
+		lines = append(lines, entry)
+	}
+
+	return lines
+}
+
 func TestLog(t *testing.T) {
 	originalStdout := os.Stdout
 	r, w, _ := os.Pipe()
@@ -53,3 +93,78 @@ func TestLogRoute(t *testing.T) {
 		t.Errorf("util.LogRoute() print empty content")
 	}
 }
+
+func TestInitLogger(t *testing.T) {
+	previous := slog.Default()
+	defer slog.SetDefault(previous)
+
+	util.InitLogger(config.Log{Format: config.LOG_FORMAT_TEXT, Level: slog.LevelWarn})
+
+	if slog.Default().Enabled(context.Background(), slog.LevelInfo) {
+		t.Error("expected info logs to be disabled at LOG_LEVEL warn")
+	}
+}
+
+func TestLoggingMiddleware(t *testing.T) {
+	buf := captureLogs(t)
+
+	handler := controller.Chain(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			util.AddLogAttrs(r.Context(), "user_id", 7)
+
+			controller.WriteErrorResponse(w, r, controller.ErrorResponse{}, "/posts", "post with id 1 not found", http.StatusBadRequest)
+		}),
+		controller.RequestId,
+		controller.Logging,
+	)
+
+	req, _ := http.NewRequest("GET", "/post?id=1", nil)
+	req.Header.Set(controller.REQUEST_ID_HEADER, "req-42")
+
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	lines := decodeLogLines(t, buf)
+
+	if len(lines) != 2 {
+		t.Fatalf("expected handler and access logs, got %d: %s", len(lines), buf.String())
+	}
+
+	handlerLog, accessLog := lines[0], lines[1]
+
+	if handlerLog["msg"] != "post with id 1 not found" || handlerLog["route"] != "/posts" || handlerLog["level"] != "WARN" {
+		t.Errorf("unexpected handler log: %v", handlerLog)
+	}
+	if handlerLog["request_id"] != "req-42" || handlerLog["user_id"] != float64(7) {
+		t.Errorf("expected request fields on handler log, got %v", handlerLog)
+	}
+
+	if accessLog["msg"] != "request" || accessLog["status"] != float64(http.StatusBadRequest) || accessLog["level"] != "WARN" {
+		t.Errorf("unexpected access log: %v", accessLog)
+	}
+	if accessLog["request_id"] != "req-42" || accessLog["user_id"] != float64(7) || accessLog["method"] != "GET" || accessLog["path"] != "/post" {
+		t.Errorf("expected request fields on access log, got %v", accessLog)
+	}
+	if _, ok := accessLog["latency_ms"].(float64); !ok {
+		t.Errorf("expected latency_ms on access log, got %v", accessLog)
+	}
+}
+
+func TestLoggingMiddlewareRoute(t *testing.T) {
+	buf := captureLogs(t)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /post/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	handler := controller.Chain(mux, controller.RequestId, controller.Logging, controller.Metrics)
+
+	req, _ := http.NewRequest("GET", "/post/1", nil)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	lines := decodeLogLines(t, buf)
+
+	if len(lines) != 1 || lines[0]["route"] != "GET /post/{id}" || lines[0]["path"] != "/post/1" {
+		t.Errorf("expected the route pattern on the access log, got %s", buf.String())
+	}
+}
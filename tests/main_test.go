@@ -0,0 +1,67 @@
+package tests
+
+import (
+	"fmt"
+	"os"
+	"synk/gateway/app"
+	"testing"
+)
+
+// SEED_WORKSPACE_ID is the personal workspace of the seed user.
+const SEED_WORKSPACE_ID = 1
+
+func TestMain(m *testing.M) {
+	if os.Getenv("DB_TEST_THROWAWAY") != "true" {
+		os.Exit(m.Run())
+	}
+
+	schemaName, schemaErr := app.CreateTestSchema()
+
+	if schemaErr == nil {
+		os.Setenv("DB_NAME_TEST", schemaName)
+
+		schemaErr = seedTestSchema()
+	}
+
+	if schemaErr != nil {
+		fmt.Println("failed to prepare throwaway test schema: " + schemaErr.Error())
+
+		if schemaName != "" {
+			app.DropTestSchema(schemaName)
+		}
+
+		os.Exit(1)
+	}
+
+	code := m.Run()
+
+	if dropErr := app.DropTestSchema(schemaName); dropErr != nil {
+		fmt.Println("failed to drop throwaway test schema: " + dropErr.Error())
+	}
+
+	os.Exit(code)
+}
+
+func seedTestSchema() error {
+	db, err := app.InitDB(true)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	seeds := []string{
+		`INSERT INTO user (user_id, user_name, user_email, user_pass) VALUES (1, 'Seed User', 'seed@synk.com', '123456')`,
+		`INSERT INTO workspace (workspace_id, workspace_name, personal_user_id) VALUES (1, 'Personal', 1)`,
+		`INSERT INTO workspace_member (workspace_id, user_id, workspace_role) VALUES (1, 1, 'owner')`,
+		`INSERT INTO integration_credential (int_credential_name, int_credential_type, int_credential_config, user_id, workspace_id) VALUES ('Seed Credential', 'discord', '{}', 1, 1)`,
+		`INSERT INTO integration_profile (int_profile_name, color_id, user_id, workspace_id) SELECT 'Seed Profile', color_id, 1, 1 FROM color LIMIT 1`,
+	}
+
+	for _, seed := range seeds {
+		if _, seedErr := db.Exec(seed); seedErr != nil {
+			return seedErr
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,125 @@
+package tests
+
+import (
+	"context"
+	"strconv"
+	"synk/gateway/app/model"
+	"synk/gateway/app/model/memory"
+	"testing"
+)
+
+func TestMemoryStore_ScopesByWorkspace(t *testing.T) {
+	repositories := memory.NewStore().Repositories()
+
+	id, _ := repositories.Templates.Add(context.Background(), model.TemplateAddData{TemplateName: "Owned", UserId: 1, WorkspaceId: 1})
+
+	if data, _ := repositories.Templates.ById(context.Background(), id, 2); data.TemplateId != 0 {
+		t.Error("Expected template to be hidden from another workspace")
+	}
+	if rows, _ := repositories.Templates.Delete(context.Background(), id, 0, 2); rows != 0 {
+		t.Errorf("Expected no rows deleted for another workspace, got %d", rows)
+	}
+	if data, _ := repositories.Templates.ById(context.Background(), id, 1); data.TemplateId != id {
+		t.Errorf("Expected template to be found in its workspace, got %d", data.TemplateId)
+	}
+}
+
+func TestMemoryStore_SoftDelete(t *testing.T) {
+	repositories := memory.NewStore().Repositories()
+
+	id, _ := repositories.IntCredentials.Add(context.Background(), model.IntCredentialAddData{
+		IntCredentialName: "To Delete",
+		IntCredentialType: model.Telegram,
+		WorkspaceId:       1,
+	}, 1)
+
+	if rows, _ := repositories.IntCredentials.Delete(context.Background(), id, 2, 1); rows != 0 {
+		t.Errorf("Expected a stale version to delete nothing, got %d", rows)
+	}
+
+	rows, _ := repositories.IntCredentials.Delete(context.Background(), id, 1, 1)
+	if rows != 1 {
+		t.Errorf("Expected 1 row deleted, got %d", rows)
+	}
+
+	list, _ := repositories.IntCredentials.List(context.Background(), strconv.Itoa(id), false, 1)
+	if len(list) != 0 {
+		t.Error("Credential returned after soft delete")
+	}
+
+	rows, _ = repositories.IntCredentials.Delete(context.Background(), id, 0, 1)
+	if rows != 0 {
+		t.Errorf("Expected deleting twice to affect 0 rows, got %d", rows)
+	}
+}
+
+func TestMemoryStore_PublicationStatus(t *testing.T) {
+	store := memory.NewStore()
+	repositories := store.Repositories()
+
+	postId, _ := repositories.Posts.Add(context.Background(), model.PostAddData{PostName: "Status", WorkspaceId: 1}, 1)
+
+	list, _ := repositories.Posts.List(context.Background(), strconv.Itoa(postId), false, 1)
+	if len(list) != 1 || list[0].Status != model.PublicationStatusPublished {
+		t.Fatalf("Expected default status 'published', got %v", list)
+	}
+
+	store.AddPublication(postId, 1, model.PublicationStatusPending)
+
+	list, _ = repositories.Posts.List(context.Background(), strconv.Itoa(postId), false, 1)
+	if list[0].Status != model.PublicationStatusPending {
+		t.Errorf("Expected status 'pending', got %s", list[0].Status)
+	}
+}
+
+func TestMemoryStore_Workspaces(t *testing.T) {
+	repositories := memory.NewStore().Repositories()
+
+	personalId, _ := repositories.Workspaces.Personal(context.Background(), 1)
+	if again, _ := repositories.Workspaces.Personal(context.Background(), 1); again != personalId {
+		t.Errorf("Expected personal workspace to be reused, got %d and %d", personalId, again)
+	}
+
+	teamId, _ := repositories.Workspaces.Add(context.Background(), model.WorkspaceAddData{WorkspaceName: "Team"}, 1)
+
+	if role, _ := repositories.Workspaces.Role(context.Background(), teamId, 1); role != model.WorkspaceRoleOwner {
+		t.Errorf("Expected creator to be owner, got '%s'", role)
+	}
+	if role, _ := repositories.Workspaces.Role(context.Background(), teamId, 2); role != "" {
+		t.Errorf("Expected non member to have no role, got '%s'", role)
+	}
+
+	if rows, _ := repositories.Workspaces.AddMember(context.Background(), teamId, 2, model.WorkspaceRoleViewer); rows != 1 {
+		t.Errorf("Expected member to be added, got %d", rows)
+	}
+	if rows, _ := repositories.Workspaces.UpdateMember(context.Background(), teamId, 2, model.WorkspaceRoleEditor); rows != 1 {
+		t.Errorf("Expected member to be updated, got %d", rows)
+	}
+	if role, _ := repositories.Workspaces.Role(context.Background(), teamId, 2); role != model.WorkspaceRoleEditor {
+		t.Errorf("Expected member to be editor, got '%s'", role)
+	}
+
+	list, _ := repositories.Workspaces.List(context.Background(), 2)
+	if len(list) != 1 || list[0].WorkspaceId != teamId {
+		t.Errorf("Expected member to list only the team workspace, got %v", list)
+	}
+
+	if rows, _ := repositories.Workspaces.RemoveMember(context.Background(), teamId, 2); rows != 1 {
+		t.Errorf("Expected member to be removed, got %d", rows)
+	}
+	if role, _ := repositories.Workspaces.Role(context.Background(), teamId, 2); role != "" {
+		t.Errorf("Expected removed member to have no role, got '%s'", role)
+	}
+}
+
+func TestWorkspaceRole_Allows(t *testing.T) {
+	if !model.WorkspaceRoleOwner.Allows(model.WorkspaceRoleEditor) {
+		t.Error("Expected owner to satisfy editor")
+	}
+	if model.WorkspaceRoleViewer.Allows(model.WorkspaceRoleEditor) {
+		t.Error("Expected viewer not to satisfy editor")
+	}
+	if model.WorkspaceRole("admin").IsValid() {
+		t.Error("Expected unknown role to be invalid")
+	}
+}
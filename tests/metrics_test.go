@@ -0,0 +1,120 @@
+package tests
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"synk/gateway/app/auth"
+	"synk/gateway/app/controller"
+	"synk/gateway/app/model"
+	"synk/gateway/app/model/memory"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func scrapeMetrics(t *testing.T) string {
+	t.Helper()
+
+	req, _ := http.NewRequest("GET", "/metrics", nil)
+	rr := httptest.NewRecorder()
+
+	registry, registryErr := controller.NewMetricsRegistry()
+
+	if registryErr != nil {
+		t.Fatalf("metrics registry failed: %v", registryErr)
+	}
+
+	controller.HandleMetrics(registry).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200 on /metrics, got %d", rr.Code)
+	}
+
+	body, _ := io.ReadAll(rr.Body)
+
+	return string(body)
+}
+
+func TestMetrics_RequestsByRoute(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /metrics-test/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	handler := controller.Metrics(mux)
+
+	for _, path := range []string{"/metrics-test/1", "/metrics-test/2", "/metrics-test-missing"} {
+		req, _ := http.NewRequest("GET", path, nil)
+
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	metrics := scrapeMetrics(t)
+
+	if !strings.Contains(metrics, `synk_http_requests_total{route="GET /metrics-test/{id}",status="201"} 2`) {
+		t.Errorf("expected both requests counted under the route pattern, got:\n%s", metrics)
+	}
+	if !strings.Contains(metrics, `synk_http_request_duration_seconds_count{route="GET /metrics-test/{id}",status="201"} 2`) {
+		t.Error("expected both requests timed under the route pattern")
+	}
+	if !strings.Contains(metrics, `synk_http_requests_total{route="unmatched",status="404"}`) {
+		t.Error("expected the unmatched request counted without its path")
+	}
+	if !strings.Contains(metrics, "go_goroutines") {
+		t.Error("expected runtime metrics")
+	}
+}
+
+func TestMetrics_AuthCheckFailures(t *testing.T) {
+	verifier := auth.NewVerifier(AUTH_TEST_SECRET, nil, auth.DEFAULT_USER_CLAIM, "")
+	authenticator := auth.NewAuthenticator(verifier, auth.NewCache(time.Minute, time.Minute), http.DefaultClient, "")
+
+	handler := controller.Auth(authenticator, memory.NewStore().Repositories().ApiKeys)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	req, _ := http.NewRequest("GET", "/post", nil)
+	req.Header.Set("Authorization", "Bearer "+signHS256Token(t, "another-secret", map[string]any{"user_id": 5}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	metrics := scrapeMetrics(t)
+
+	if !strings.Contains(metrics, `synk_auth_check_failures_total{status="401"}`) {
+		t.Errorf("expected the refused token counted, got:\n%s", metrics)
+	}
+	if !strings.Contains(metrics, `synk_auth_check_duration_seconds_count{result="failed"}`) {
+		t.Error("expected the refused token timed")
+	}
+}
+
+func TestMetrics_PostStatusCollector(t *testing.T) {
+	store := memory.NewStore()
+	repositories := store.Repositories()
+
+	pendingId, _ := repositories.Posts.Add(context.Background(), model.PostAddData{PostName: "Pending", WorkspaceId: 1}, 1)
+	failedId, _ := repositories.Posts.Add(context.Background(), model.PostAddData{PostName: "Failed", WorkspaceId: 1}, 1)
+	repositories.Posts.Add(context.Background(), model.PostAddData{PostName: "Published", WorkspaceId: 2}, 2)
+	deletedId, _ := repositories.Posts.Add(context.Background(), model.PostAddData{PostName: "Deleted", WorkspaceId: 1}, 1)
+
+	store.AddPublication(pendingId, 1, model.PublicationStatusPending)
+	store.AddPublication(failedId, 1, model.PublicationStatusPublished)
+	store.AddPublication(failedId, 2, model.PublicationStatusFailed)
+	repositories.Posts.Delete(context.Background(), deletedId, 0, 1)
+
+	expected := `
+# HELP synk_posts Posts by publication status.
+# TYPE synk_posts gauge
+synk_posts{status="failed"} 1
+synk_posts{status="pending"} 1
+synk_posts{status="published"} 1
+`
+
+	collector := controller.NewPostStatusCollector(repositories.Publication)
+
+	if compareErr := testutil.CollectAndCompare(collector, strings.NewReader(expected), "synk_posts"); compareErr != nil {
+		t.Error(compareErr)
+	}
+}
@@ -0,0 +1,224 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"synk/gateway/app/auth"
+	"synk/gateway/app/config"
+	"synk/gateway/app/controller"
+	"synk/gateway/app/model"
+	"synk/gateway/app/model/memory"
+	"testing"
+	"time"
+)
+
+func TestCors_AllowsConfiguredOrigins(t *testing.T) {
+	cfg := config.Default()
+	cfg.Cors.AllowedOrigins = []string{"https://app.synk.dev/", "https://admin.synk.dev"}
+	cfg.Cors.AllowedHeaders = "Authorization, X-Custom"
+
+	handler := controller.Cors(cfg.Cors)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+
+	for _, origin := range []string{"https://app.synk.dev", "https://admin.synk.dev"} {
+		req, _ := http.NewRequest("GET", "/about", nil)
+		req.Header.Set("Origin", origin)
+		rr := httptest.NewRecorder()
+
+		handler.ServeHTTP(rr, req)
+
+		if rr.Header().Get("Access-Control-Allow-Origin") != origin {
+			t.Errorf("expected origin %s to be allowed, got %q", origin, rr.Header().Get("Access-Control-Allow-Origin"))
+		}
+		if rr.Code != http.StatusTeapot {
+			t.Errorf("expected request to reach handler, got %d", rr.Code)
+		}
+		for _, header := range []string{"ETag", "Retry-After", "X-Request-ID", "Idempotent-Replayed"} {
+			if !strings.Contains(rr.Header().Get("Access-Control-Expose-Headers"), header) {
+				t.Errorf("expected %s to be exposed, got %q", header, rr.Header().Get("Access-Control-Expose-Headers"))
+			}
+		}
+	}
+
+	req, _ := http.NewRequest("OPTIONS", "/post", nil)
+	req.Header.Set("Origin", "https://evil.dev")
+	rr := httptest.NewRecorder()
+
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusNoContent {
+		t.Errorf("expected preflight to return 204, got %d", rr.Code)
+	}
+	if rr.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Error("expected unknown origin to be refused")
+	}
+	if rr.Header().Get("Access-Control-Allow-Headers") != "Authorization, X-Custom" {
+		t.Errorf("unexpected allowed headers %q", rr.Header().Get("Access-Control-Allow-Headers"))
+	}
+	if rr.Header().Get("Access-Control-Allow-Methods") != config.DEFAULT_CORS_ALLOWED_METHODS {
+		t.Errorf("unexpected allowed methods %q", rr.Header().Get("Access-Control-Allow-Methods"))
+	}
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	verifier := auth.NewVerifier(AUTH_TEST_SECRET, nil, auth.DEFAULT_USER_CLAIM, "")
+	authenticator := auth.NewAuthenticator(verifier, auth.NewCache(time.Minute, time.Minute), http.DefaultClient, "")
+
+	var userId int
+	handler := controller.Auth(authenticator, memory.NewStore().Repositories().ApiKeys)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userId = r.Context().Value(controller.CONTEXT_USER_ID_KEY).(int)
+	}))
+
+	req, _ := http.NewRequest("GET", "/post", nil)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 without header, got %d", rr.Code)
+	}
+
+	req, _ = http.NewRequest("GET", "/post", nil)
+	req.Header.Set("Authorization", "Bearer "+signHS256Token(t, AUTH_TEST_SECRET, map[string]any{"user_id": 5}))
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK || userId != 5 {
+		t.Errorf("expected user 5 in context, got status %d user %d", rr.Code, userId)
+	}
+}
+
+func TestRecoveryMiddleware(t *testing.T) {
+	handler := controller.Chain(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic("boom")
+		}),
+		controller.Recovery,
+		controller.Logging,
+	)
+
+	req, _ := http.NewRequest("GET", "/post", nil)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500, got %d", rr.Code)
+	}
+}
+
+func TestRequireScopeMiddleware(t *testing.T) {
+	handler := controller.RequireScope(auth.SCOPE_POSTS_PUBLISH)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	req, _ := http.NewRequest("POST", "/post/publish", nil)
+	req = injectScopesContext(req, auth.SCOPE_POSTS_READ)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusForbidden {
+		t.Errorf("expected 403, got %d", rr.Code)
+	}
+	if !strings.Contains(rr.Body.String(), "missing scope "+auth.SCOPE_POSTS_PUBLISH) {
+		t.Errorf("expected missing scope in body, got %s", rr.Body.String())
+	}
+
+	req, _ = http.NewRequest("POST", "/post/publish", nil)
+	req = injectScopesContext(req, "posts:*")
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Errorf("expected wildcard scope to pass, got %d", rr.Code)
+	}
+}
+
+func TestWorkspaceMiddleware(t *testing.T) {
+	repositories := memory.NewStore().Repositories()
+
+	teamId, _ := repositories.Workspaces.Add(context.Background(), model.WorkspaceAddData{WorkspaceName: "Team"}, 1)
+	repositories.Workspaces.AddMember(context.Background(), teamId, 2, model.WorkspaceRoleViewer)
+
+	var workspaceId int
+	handler := controller.Workspace(repositories.Workspaces, model.WorkspaceRoleEditor)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		workspaceId = r.Context().Value(controller.CONTEXT_WORKSPACE_ID_KEY).(int)
+	}))
+
+	serve := func(userId int, header string) int {
+		req, _ := http.NewRequest("POST", "/post", nil)
+		req = injectPostUserContext(req, userId)
+		if header != "" {
+			req.Header.Set(controller.WORKSPACE_HEADER, header)
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		return rr.Code
+	}
+
+	if code := serve(1, strconv.Itoa(teamId)); code != http.StatusOK || workspaceId != teamId {
+		t.Errorf("expected owner to reach workspace %d, got status %d workspace %d", teamId, code, workspaceId)
+	}
+	if code := serve(2, strconv.Itoa(teamId)); code != http.StatusForbidden {
+		t.Errorf("expected viewer to be refused on editor route, got %d", code)
+	}
+	if code := serve(3, strconv.Itoa(teamId)); code != http.StatusForbidden {
+		t.Errorf("expected non member to be refused, got %d", code)
+	}
+	if code := serve(1, "abc"); code != http.StatusBadRequest {
+		t.Errorf("expected invalid workspace id to be refused, got %d", code)
+	}
+
+	personalId, _ := repositories.Workspaces.Personal(context.Background(), 3)
+	if code := serve(3, ""); code != http.StatusOK || workspaceId != personalId {
+		t.Errorf("expected personal workspace %d without header, got status %d workspace %d", personalId, code, workspaceId)
+	}
+}
+
+func TestWorkspacePathMiddleware(t *testing.T) {
+	var path, header string
+	handler := controller.WorkspacePath(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		header = r.Header.Get(controller.WORKSPACE_HEADER)
+	}))
+
+	req, _ := http.NewRequest("GET", "/w/7/templates/basic", nil)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if path != "/templates/basic" || header != "7" {
+		t.Errorf("expected /templates/basic in workspace 7, got %s in workspace %q", path, header)
+	}
+
+	req, _ = http.NewRequest("GET", "/templates", nil)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if path != "/templates" || header != "" {
+		t.Errorf("expected path to be kept without prefix, got %s in workspace %q", path, header)
+	}
+}
+
+func TestRequestIdMiddleware(t *testing.T) {
+	var ctxRequestId string
+	handler := controller.RequestId(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctxRequestId, _ = r.Context().Value(controller.CONTEXT_REQUEST_ID_KEY).(string)
+	}))
+
+	req, _ := http.NewRequest("GET", "/post", nil)
+	req.Header.Set(controller.REQUEST_ID_HEADER, "client-id-1")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if ctxRequestId != "client-id-1" || rr.Header().Get(controller.REQUEST_ID_HEADER) != "client-id-1" {
+		t.Errorf("expected incoming request id to be kept, got %q", ctxRequestId)
+	}
+
+	req, _ = http.NewRequest("GET", "/post", nil)
+	req.Header.Set(controller.REQUEST_ID_HEADER, "bad id\n")
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if len(ctxRequestId) != 32 || rr.Header().Get(controller.REQUEST_ID_HEADER) != ctxRequestId {
+		t.Errorf("expected invalid request id to be replaced, got %q", ctxRequestId)
+	}
+}
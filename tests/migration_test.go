@@ -0,0 +1,109 @@
+package tests
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"synk/gateway/app"
+	"synk/gateway/app/migration"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+func TestMigrationList(t *testing.T) {
+	migrations, err := migration.List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+
+	if len(migrations) == 0 {
+		t.Fatal("Expected embedded migrations")
+	}
+
+	for i, item := range migrations {
+		if item.Up == "" || item.Down == "" {
+			t.Errorf("Migration %s is missing up or down script", item.Version)
+		}
+		if i > 0 && migrations[i-1].Version >= item.Version {
+			t.Errorf("Migrations not sorted: %s before %s", migrations[i-1].Version, item.Version)
+		}
+	}
+
+	if !strings.Contains(migrations[0].Up, "CREATE TABLE IF NOT EXISTS post") {
+		t.Error("Expected first migration to create the post table")
+	}
+}
+
+func TestMigrationBaselineDown(t *testing.T) {
+	migrations, err := migration.List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+
+	if migrations[0].Version != migration.BASELINE_VERSION {
+		t.Fatalf("Expected the first migration to be the baseline, got %s", migrations[0].Version)
+	}
+	if statements := migration.Statements(migrations[0].Down); len(statements) != 0 {
+		t.Errorf("Expected the baseline down to drop nothing, got %v", statements)
+	}
+}
+
+func TestMigrationStatements(t *testing.T) {
+	script := `-- comment
+CREATE TABLE a (
+    id INT
+);
+
+INSERT INTO a VALUES (1);
+DROP TABLE b`
+
+	statements := migration.Statements(script)
+
+	if len(statements) != 3 {
+		t.Fatalf("Expected 3 statements, got %d: %v", len(statements), statements)
+	}
+	if strings.HasSuffix(statements[0], ";") {
+		t.Error("Expected trailing semicolon to be removed")
+	}
+	if statements[2] != "DROP TABLE b" {
+		t.Errorf("Expected last statement without semicolon to be kept, got %q", statements[2])
+	}
+}
+
+func TestMigrationAlreadyApplied(t *testing.T) {
+	if !migration.AlreadyApplied(fmt.Errorf("wrapped: %w", &mysql.MySQLError{Number: migration.MYSQL_ERR_DUPLICATE_COLUMN})) {
+		t.Error("Expected a duplicate column to be skipped on re-run")
+	}
+	if migration.AlreadyApplied(&mysql.MySQLError{Number: 1064}) {
+		t.Error("Expected a syntax error to fail the migration")
+	}
+	if migration.AlreadyApplied(errors.New("connection refused")) {
+		t.Error("Expected other errors to fail the migration")
+	}
+}
+
+func TestMigrator_Status(t *testing.T) {
+	db, err := app.InitDB(true)
+	if err != nil {
+		t.Fatalf("db connection failed: %v", err)
+	}
+	defer db.Close()
+
+	migrator := migration.NewMigrator(db)
+
+	if _, err := migrator.Up(); err != nil {
+		t.Fatalf("Up failed: %v", err)
+	}
+
+	statusList, err := migrator.Status()
+	if err != nil {
+		t.Fatalf("Status failed: %v", err)
+	}
+
+	for _, status := range statusList {
+		if !status.Applied {
+			t.Errorf("Expected migration %s to be applied after Up", status.Version)
+		}
+	}
+}
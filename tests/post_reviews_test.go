@@ -0,0 +1,60 @@
+package tests
+
+import (
+	"context"
+	"synk/gateway/app/model"
+	"testing"
+)
+
+func TestPostReviews_Lifecycle(t *testing.T) {
+	db, userId := setupPostsDB(t)
+	defer db.Close()
+
+	postsModel := model.NewPosts(db)
+	reviewsModel := model.NewPostReviews(db)
+
+	tplId := createDummyTemplate(t, db, userId)
+	defer db.Exec("DELETE FROM template WHERE template_id = ?", tplId)
+	profileId := getValidProfileId(t, db)
+
+	postId, err := postsModel.Add(context.Background(), model.PostAddData{
+		PostName:     "Review Lifecycle",
+		TemplateId:   tplId,
+		IntProfileId: profileId,
+		WorkspaceId:  SEED_WORKSPACE_ID,
+	}, userId)
+	if err != nil {
+		t.Fatalf("Setup failed (Add): %v", err)
+	}
+	defer db.Exec("DELETE FROM post WHERE post_id = ?", postId)
+	defer db.Exec("DELETE FROM post_review WHERE post_id = ?", postId)
+
+	if rows, _ := reviewsModel.Decide(context.Background(), model.PostReviewData{PostId: postId, PostReviewStatus: model.PostReviewStatusApproved}, userId, SEED_WORKSPACE_ID); rows != 0 {
+		t.Errorf("expected draft post not to be decided, got %d rows", rows)
+	}
+
+	if rows, err := reviewsModel.Submit(context.Background(), model.PostReviewData{PostId: postId}, userId, SEED_WORKSPACE_ID); err != nil || rows != 1 {
+		t.Fatalf("Submit failed: rows %d err %v", rows, err)
+	}
+
+	if rows, err := reviewsModel.Decide(context.Background(), model.PostReviewData{
+		PostId:            postId,
+		PostReviewStatus:  model.PostReviewStatusApproved,
+		PostReviewComment: "Ship it",
+	}, userId, SEED_WORKSPACE_ID); err != nil || rows != 1 {
+		t.Fatalf("Decide failed: rows %d err %v", rows, err)
+	}
+
+	post, _ := postsModel.ById(context.Background(), postId, SEED_WORKSPACE_ID)
+	if post.PostReviewStatus != model.PostReviewStatusApproved {
+		t.Errorf("expected post to be approved, got %s", post.PostReviewStatus)
+	}
+
+	reviews, err := reviewsModel.List(context.Background(), postId, SEED_WORKSPACE_ID)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(reviews) != 2 || reviews[1].PostReviewComment != "Ship it" {
+		t.Errorf("unexpected reviews %+v", reviews)
+	}
+}
@@ -19,8 +19,8 @@ func setupPostsDB(t *testing.T) (*sql.DB, int) {
 
 func createDummyTemplate(t *testing.T, db *sql.DB, userId int) int {
 	res, err := db.ExecContext(context.Background(),
-		`INSERT INTO template (template_name, template_content, template_url_import, user_id)
-		 VALUES ('Post Test Template', '<p>Test</p>', '', ?)`, userId)
+		`INSERT INTO template (template_name, template_content, template_url_import, user_id, workspace_id)
+		 VALUES ('Post Test Template', '<p>Test</p>', '', ?, ?)`, userId, SEED_WORKSPACE_ID)
 	if err != nil {
 		t.Fatalf("Setup failed: Could not create dummy template: %v", err)
 	}
@@ -70,9 +70,10 @@ func TestPosts_Add(t *testing.T) {
 		PostContent:  "Content",
 		TemplateId:   tplId,
 		IntProfileId: profileId,
+		WorkspaceId:  SEED_WORKSPACE_ID,
 	}
 
-	id, err := postsModel.Add(input, userId)
+	id, err := postsModel.Add(context.Background(), input, userId)
 	if err != nil {
 		t.Fatalf("Add failed: %v", err)
 	}
@@ -96,8 +97,9 @@ func TestPosts_Update(t *testing.T) {
 		PostName:     "Pre-Update",
 		TemplateId:   tplId,
 		IntProfileId: profileId,
+		WorkspaceId:  SEED_WORKSPACE_ID,
 	}
-	id, err := postsModel.Add(createInput, userId)
+	id, err := postsModel.Add(context.Background(), createInput, userId)
 	if err != nil {
 		t.Fatalf("Setup failed (Add): %v", err)
 	}
@@ -111,7 +113,7 @@ func TestPosts_Update(t *testing.T) {
 		IntProfileId: profileId,
 	}
 
-	rows, err := postsModel.Update(updateInput, userId)
+	rows, err := postsModel.Update(context.Background(), updateInput, SEED_WORKSPACE_ID)
 	if err != nil {
 		t.Fatalf("Update failed: %v", err)
 	}
@@ -119,7 +121,7 @@ func TestPosts_Update(t *testing.T) {
 		t.Errorf("Expected 1 row updated, got %d", rows)
 	}
 
-	list, _ := postsModel.List(strconv.Itoa(id), true, userId)
+	list, _ := postsModel.List(context.Background(), strconv.Itoa(id), true, SEED_WORKSPACE_ID)
 	if len(list) > 0 {
 		if list[0].PostName != "Post-Update" {
 			t.Errorf("Name not updated. Got %s", list[0].PostName)
@@ -139,12 +141,16 @@ func TestPosts_Delete(t *testing.T) {
 	defer db.Exec("DELETE FROM template WHERE template_id = ?", tplId)
 	profileId := getValidProfileId(t, db)
 
-	createInput := model.PostAddData{PostName: "To Delete", TemplateId: tplId, IntProfileId: profileId}
-	id, _ := postsModel.Add(createInput, userId)
+	createInput := model.PostAddData{PostName: "To Delete", TemplateId: tplId, IntProfileId: profileId, WorkspaceId: SEED_WORKSPACE_ID}
+	id, _ := postsModel.Add(context.Background(), createInput, userId)
 
 	defer db.Exec("DELETE FROM post WHERE post_id = ?", id)
 
-	rows, err := postsModel.Delete(id, userId)
+	if rows, _ := postsModel.Delete(context.Background(), id, 2, SEED_WORKSPACE_ID); rows != 0 {
+		t.Errorf("Expected a stale version to delete nothing, got %d", rows)
+	}
+
+	rows, err := postsModel.Delete(context.Background(), id, 1, SEED_WORKSPACE_ID)
 	if err != nil {
 		t.Fatalf("Delete failed: %v", err)
 	}
@@ -152,7 +158,7 @@ func TestPosts_Delete(t *testing.T) {
 		t.Errorf("Expected 1 row deleted, got %d", rows)
 	}
 
-	list, _ := postsModel.List(strconv.Itoa(id), false, userId)
+	list, _ := postsModel.List(context.Background(), strconv.Itoa(id), false, SEED_WORKSPACE_ID)
 	if len(list) != 0 {
 		t.Error("Post returned after soft delete")
 	}
@@ -167,11 +173,11 @@ func TestPosts_ById(t *testing.T) {
 	defer db.Exec("DELETE FROM template WHERE template_id = ?", tplId)
 	profileId := getValidProfileId(t, db)
 
-	createInput := model.PostAddData{PostName: "ById Test", TemplateId: tplId, IntProfileId: profileId}
-	id, _ := postsModel.Add(createInput, userId)
+	createInput := model.PostAddData{PostName: "ById Test", TemplateId: tplId, IntProfileId: profileId, WorkspaceId: SEED_WORKSPACE_ID}
+	id, _ := postsModel.Add(context.Background(), createInput, userId)
 	defer db.Exec("DELETE FROM post WHERE post_id = ?", id)
 
-	data, err := postsModel.ById(id, userId)
+	data, err := postsModel.ById(context.Background(), id, SEED_WORKSPACE_ID)
 	if err != nil {
 		t.Fatalf("ById failed: %v", err)
 	}
@@ -181,6 +187,52 @@ func TestPosts_ById(t *testing.T) {
 	}
 }
 
+func TestPosts_Clone(t *testing.T) {
+	db, userId := setupPostsDB(t)
+	defer db.Close()
+	postsModel := model.NewPosts(db)
+
+	tplId := createDummyTemplate(t, db, userId)
+	defer db.Exec("DELETE FROM template WHERE template_id = ?", tplId)
+	profileId := getValidProfileId(t, db)
+
+	createInput := model.PostAddData{
+		PostName:     "Clone Test",
+		PostContent:  "Cloned Content",
+		TemplateId:   tplId,
+		IntProfileId: profileId,
+		WorkspaceId:  SEED_WORKSPACE_ID,
+	}
+	id, _ := postsModel.Add(context.Background(), createInput, userId)
+	defer db.Exec("DELETE FROM post WHERE post_id = ?", id)
+
+	clonedId, err := postsModel.Clone(context.Background(), id, SEED_WORKSPACE_ID)
+	if err != nil {
+		t.Fatalf("Clone failed: %v", err)
+	}
+	defer db.Exec("DELETE FROM post WHERE post_id = ?", clonedId)
+
+	if clonedId == 0 || clonedId == id {
+		t.Fatalf("Clone returned invalid ID %d", clonedId)
+	}
+
+	list, _ := postsModel.List(context.Background(), strconv.Itoa(clonedId), true, SEED_WORKSPACE_ID)
+	if len(list) != 1 {
+		t.Fatalf("Expected cloned post to be listed, got %d items", len(list))
+	}
+	if list[0].PostName != "Clone Test"+model.CLONE_NAME_SUFFIX {
+		t.Errorf("Unexpected cloned name. Got %s", list[0].PostName)
+	}
+	if list[0].PostContent != "Cloned Content" {
+		t.Errorf("Content not cloned. Got %s", list[0].PostContent)
+	}
+
+	_, err = postsModel.Clone(context.Background(), id, SEED_WORKSPACE_ID+1)
+	if err == nil {
+		t.Error("Expected error when cloning a post from another workspace")
+	}
+}
+
 func TestPosts_List(t *testing.T) {
 	db, userId := setupPostsDB(t)
 	defer db.Close()
@@ -196,13 +248,14 @@ func TestPosts_List(t *testing.T) {
 		PostContent:  "Hidden Content",
 		TemplateId:   tplId,
 		IntProfileId: profileId,
+		WorkspaceId:  SEED_WORKSPACE_ID,
 	}
-	id, _ := postsModel.Add(createInput, userId)
+	id, _ := postsModel.Add(context.Background(), createInput, userId)
 
 	defer db.Exec("DELETE FROM post WHERE post_id = ?", id)
 	defer db.Exec("DELETE FROM publication WHERE post_id = ?", id)
 
-	listFull, err := postsModel.List(strconv.Itoa(id), true, userId)
+	listFull, err := postsModel.List(context.Background(), strconv.Itoa(id), true, SEED_WORKSPACE_ID)
 	if err != nil {
 		t.Fatalf("List (full) failed: %v", err)
 	}
@@ -217,7 +270,7 @@ func TestPosts_List(t *testing.T) {
 		t.Errorf("Expected default status 'published', got %s", listFull[0].Status)
 	}
 
-	listShort, _ := postsModel.List(strconv.Itoa(id), false, userId)
+	listShort, _ := postsModel.List(context.Background(), strconv.Itoa(id), false, SEED_WORKSPACE_ID)
 	if len(listShort) > 0 && listShort[0].PostContent != "" {
 		t.Error("Expected content to be empty")
 	}
@@ -229,7 +282,7 @@ func TestPosts_List(t *testing.T) {
 		t.Fatalf("Failed to inject publication: %v", err)
 	}
 
-	listStatus, _ := postsModel.List(strconv.Itoa(id), false, userId)
+	listStatus, _ := postsModel.List(context.Background(), strconv.Itoa(id), false, SEED_WORKSPACE_ID)
 	if len(listStatus) > 0 {
 		if listStatus[0].Status != model.PublicationStatusFailed {
 			t.Errorf("Expected status 'failed', got %s", listStatus[0].Status)
@@ -257,13 +310,14 @@ func TestPostLifecycleWithDependencies(t *testing.T) {
 		PostContent:  "Content",
 		TemplateId:   tplId,
 		IntProfileId: profileId,
+		WorkspaceId:  SEED_WORKSPACE_ID,
 	}
-	postId, _ := postsModel.Add(newPost, testUserId)
+	postId, _ := postsModel.Add(context.Background(), newPost, testUserId)
 
 	defer db.Exec("DELETE FROM post WHERE post_id = ?", postId)
 	defer db.Exec("DELETE FROM publication WHERE post_id = ?", postId)
 
-	list, _ := postsModel.List(strconv.Itoa(postId), false, testUserId)
+	list, _ := postsModel.List(context.Background(), strconv.Itoa(postId), false, SEED_WORKSPACE_ID)
 	if len(list) > 0 && list[0].Status != model.PublicationStatusPublished {
 		t.Errorf("Expected default published")
 	}
@@ -272,7 +326,7 @@ func TestPostLifecycleWithDependencies(t *testing.T) {
 		"INSERT INTO publication (post_id, int_credential_id, publication_status) VALUES (?, ?, 'pending')",
 		postId, credId)
 
-	listPending, _ := postsModel.List(strconv.Itoa(postId), false, testUserId)
+	listPending, _ := postsModel.List(context.Background(), strconv.Itoa(postId), false, SEED_WORKSPACE_ID)
 	if len(listPending) > 0 && listPending[0].Status != model.PublicationStatusPending {
 		t.Errorf("Expected pending")
 	}
@@ -281,7 +335,7 @@ func TestPostLifecycleWithDependencies(t *testing.T) {
 		"INSERT INTO publication (post_id, int_credential_id, publication_status) VALUES (?, ?, 'failed')",
 		postId, credId)
 
-	listFailed, _ := postsModel.List(strconv.Itoa(postId), false, testUserId)
+	listFailed, _ := postsModel.List(context.Background(), strconv.Itoa(postId), false, SEED_WORKSPACE_ID)
 	if len(listFailed) > 0 && listFailed[0].Status != model.PublicationStatusFailed {
 		t.Errorf("Expected failed")
 	}
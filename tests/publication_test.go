@@ -33,8 +33,8 @@ func TestPublicationCountByPost(t *testing.T) {
 		t.Fatalf("publication: valid 'int_credential_id' required. %v", err)
 	}
 
-	res, err := db.Exec(`INSERT INTO post (post_name, post_content, template_id, int_profile_id, user_id)
-						 VALUES ('Pub Test Post', 'Dummy Content', ?, ?, 1)`, templateId, intProfileId)
+	res, err := db.Exec(`INSERT INTO post (post_name, post_content, template_id, int_profile_id, user_id, workspace_id)
+						 VALUES ('Pub Test Post', 'Dummy Content', ?, ?, 1, 1)`, templateId, intProfileId)
 	if err != nil {
 		t.Fatalf("publication: could not create dummy post for testing: %v", err)
 	}
@@ -58,7 +58,7 @@ func TestPublicationCountByPost(t *testing.T) {
 	}
 	defer db.Exec("DELETE FROM publication WHERE post_id = ?", postId)
 
-	counts, err := pubModel.CountByPost(postId)
+	counts, err := pubModel.CountByPost(context.Background(), postId)
 	if err != nil {
 		t.Fatalf("publication: CountByPost failed: %v", err)
 	}
@@ -73,3 +73,65 @@ func TestPublicationCountByPost(t *testing.T) {
 		t.Errorf("publication: expected 1 published, got %d", counts[model.PublicationStatusPublished])
 	}
 }
+
+func TestPublicationCountByStatus(t *testing.T) {
+	db, err := app.InitDB(true)
+	if err != nil {
+		t.Fatalf("publication: db connection failed [%v]", err.Error())
+	}
+	defer db.Close()
+
+	pubModel := model.NewPublication(db)
+
+	var templateId, intProfileId, intCredentialId int
+
+	err = db.QueryRow("SELECT template_id FROM template WHERE deleted_at IS NULL LIMIT 1").Scan(&templateId)
+	if err != nil {
+		t.Fatalf("publication: valid 'template_id' required. %v", err)
+	}
+
+	err = db.QueryRow("SELECT int_profile_id FROM integration_profile WHERE deleted_at IS NULL LIMIT 1").Scan(&intProfileId)
+	if err != nil {
+		t.Fatalf("publication: valid 'int_profile_id' required. %v", err)
+	}
+
+	err = db.QueryRow("SELECT int_credential_id FROM integration_credential WHERE deleted_at IS NULL LIMIT 1").Scan(&intCredentialId)
+	if err != nil {
+		t.Fatalf("publication: valid 'int_credential_id' required. %v", err)
+	}
+
+	before, err := pubModel.CountByStatus(context.Background())
+	if err != nil {
+		t.Fatalf("publication: CountByStatus failed: %v", err)
+	}
+
+	res, err := db.Exec(`INSERT INTO post (post_name, post_content, template_id, int_profile_id, user_id, workspace_id)
+						 VALUES ('Pub Status Post', 'Dummy Content', ?, ?, 1, 1)`, templateId, intProfileId)
+	if err != nil {
+		t.Fatalf("publication: could not create dummy post for testing: %v", err)
+	}
+	postIdInt64, _ := res.LastInsertId()
+	postId := int(postIdInt64)
+
+	defer db.Exec("DELETE FROM post WHERE post_id = ?", postId)
+
+	for _, status := range []string{"published", "failed"} {
+		_, err := db.Exec("INSERT INTO publication (post_id, int_credential_id, publication_status) VALUES (?, ?, ?)", postId, intCredentialId, status)
+		if err != nil {
+			t.Fatalf("publication: failed to insert test data: %v", err)
+		}
+	}
+	defer db.Exec("DELETE FROM publication WHERE post_id = ?", postId)
+
+	after, err := pubModel.CountByStatus(context.Background())
+	if err != nil {
+		t.Fatalf("publication: CountByStatus failed: %v", err)
+	}
+
+	if after[model.PublicationStatusFailed] != before[model.PublicationStatusFailed]+1 {
+		t.Errorf("publication: expected one more failed post, got %d then %d", before[model.PublicationStatusFailed], after[model.PublicationStatusFailed])
+	}
+	if after[model.PublicationStatusPublished] != before[model.PublicationStatusPublished] {
+		t.Errorf("publication: expected the same published posts, got %d then %d", before[model.PublicationStatusPublished], after[model.PublicationStatusPublished])
+	}
+}
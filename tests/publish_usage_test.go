@@ -0,0 +1,94 @@
+package tests
+
+import (
+	"context"
+	"synk/gateway/app"
+	"synk/gateway/app/model"
+	"testing"
+	"time"
+)
+
+func TestPublishUsage_Count(t *testing.T) {
+	db, err := app.InitDB(true)
+	if err != nil {
+		t.Fatalf("db connection failed: %v", err)
+	}
+	defer db.Close()
+
+	usageModel := model.NewPublishUsage(db)
+	userId := 1
+	since := time.Now().UTC().Add(-time.Hour).Format("2006-01-02 15:04:05")
+
+	before, err := usageModel.Count(context.Background(), userId, 9001, since)
+	if err != nil {
+		t.Fatalf("Count failed: %v", err)
+	}
+
+	usageId, err := usageModel.Add(context.Background(), model.PublishUsageAddData{UserId: userId, IntProfileId: 9001, PostId: 1})
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	defer db.Exec("DELETE FROM publish_usage WHERE publish_usage_id = ?", usageId)
+
+	after, _ := usageModel.Count(context.Background(), userId, 9001, since)
+	if after.UserCount != before.UserCount+1 || after.IntProfileCount != before.IntProfileCount+1 {
+		t.Errorf("expected both counts to grow by one, got %+v then %+v", before, after)
+	}
+
+	other, _ := usageModel.Count(context.Background(), userId+1, 9002, since)
+	if other.UserCount != 0 || other.IntProfileCount != 0 {
+		t.Errorf("expected other user and profile not to be counted, got %+v", other)
+	}
+}
+
+func TestPublishUsage_Reserve(t *testing.T) {
+	db, err := app.InitDB(true)
+	if err != nil {
+		t.Fatalf("db connection failed: %v", err)
+	}
+	defer db.Close()
+
+	usageModel := model.NewPublishUsage(db)
+	usage := model.PublishUsageAddData{UserId: 1, IntProfileId: 9003, PostId: 1}
+	since := time.Now().UTC().Add(-time.Hour).Format("2006-01-02 15:04:05")
+
+	before, _ := usageModel.Count(context.Background(), usage.UserId, usage.IntProfileId, since)
+
+	usageId, count, err := usageModel.Reserve(context.Background(), usage, since, 0, before.IntProfileCount+1)
+	if err != nil || usageId == 0 {
+		t.Fatalf("expected the reservation under the limit to pass, got %d %v", usageId, err)
+	}
+	if count != before {
+		t.Errorf("expected the count before the reservation, got %+v want %+v", count, before)
+	}
+
+	refusedId, _, err := usageModel.Reserve(context.Background(), usage, since, 0, before.IntProfileCount+1)
+	if err != nil || refusedId != 0 {
+		t.Errorf("expected the reservation at the limit to be refused, got %d %v", refusedId, err)
+	}
+
+	if err := usageModel.Release(context.Background(), usageId); err != nil {
+		t.Fatalf("Release failed: %v", err)
+	}
+
+	if after, _ := usageModel.Count(context.Background(), usage.UserId, usage.IntProfileId, since); after != before {
+		t.Errorf("expected the released reservation not to be counted, got %+v want %+v", after, before)
+	}
+}
+
+func TestPublishUsage_SessionTimeZone(t *testing.T) {
+	db, err := app.InitDB(true)
+	if err != nil {
+		t.Fatalf("db connection failed: %v", err)
+	}
+	defer db.Close()
+
+	var timeZone string
+
+	if err := db.QueryRow("SELECT @@session.time_zone").Scan(&timeZone); err != nil {
+		t.Fatalf("time zone query failed: %v", err)
+	}
+	if timeZone != "+00:00" {
+		t.Errorf("expected the session to use UTC for the daily quota window, got %s", timeZone)
+	}
+}
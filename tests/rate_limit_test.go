@@ -0,0 +1,82 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"synk/gateway/app/config"
+	"synk/gateway/app/controller"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter_Allow(t *testing.T) {
+	limiter := controller.NewRateLimiter(2, time.Hour)
+
+	for i := 0; i < 2; i++ {
+		if allowed, _ := limiter.Allow(1); !allowed {
+			t.Fatalf("expected request %d to be allowed", i+1)
+		}
+	}
+
+	allowed, retryAfter := limiter.Allow(1)
+	if allowed {
+		t.Fatal("expected request over the burst to be limited")
+	}
+	if retryAfter <= 0 || retryAfter > 30*time.Minute {
+		t.Errorf("expected retry within half an hour, got %s", retryAfter)
+	}
+
+	if allowed, _ := limiter.Allow(2); !allowed {
+		t.Error("expected another user to keep its own bucket")
+	}
+}
+
+func TestNewRateLimits(t *testing.T) {
+	limits := controller.NewRateLimits(config.RateLimits{
+		Read:  config.RateLimit{Requests: 60, Period: time.Minute},
+		Write: config.RateLimit{},
+	})
+
+	if limits[controller.RATE_LIMIT_CLASS_READ] == nil {
+		t.Error("expected the read limit to be set")
+	}
+	if _, ok := limits[controller.RATE_LIMIT_CLASS_WRITE]; ok {
+		t.Error("expected a limit of 0 requests to be disabled")
+	}
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	limits := controller.RateLimits{
+		controller.RATE_LIMIT_CLASS_PUBLISH: controller.NewRateLimiter(1, time.Minute),
+	}
+
+	handler := controller.RateLimit(limits)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	serve := func(method string, path string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, nil)
+		req = req.WithContext(context.WithValue(req.Context(), controller.CONTEXT_USER_ID_KEY, 1))
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		return rr
+	}
+
+	if rr := serve("POST", "/post/publish"); rr.Code != http.StatusOK {
+		t.Fatalf("expected first publish to pass, got %d", rr.Code)
+	}
+
+	rr := serve("POST", "/post/publish")
+	if rr.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429, got %d", rr.Code)
+	}
+	if retryAfter := rr.Header().Get("Retry-After"); retryAfter == "" || retryAfter == "0" {
+		t.Errorf("expected Retry-After header, got %q", retryAfter)
+	}
+
+	if rr := serve("GET", "/post"); rr.Code != http.StatusOK {
+		t.Errorf("expected class without limiter to pass, got %d", rr.Code)
+	}
+}
@@ -0,0 +1,113 @@
+package tests
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"synk/gateway/app/controller"
+	"testing"
+	"time"
+)
+
+type recordingReporter struct {
+	breadcrumbs []string
+	errors      []string
+	panics      []any
+}
+
+func (rr *recordingReporter) Breadcrumb(r *http.Request, category string, message string) {
+	rr.breadcrumbs = append(rr.breadcrumbs, category+": "+message)
+}
+
+func (rr *recordingReporter) Error(r *http.Request, route string, message string) {
+	rr.errors = append(rr.errors, route+": "+message)
+}
+
+func (rr *recordingReporter) Panic(r *http.Request, recovered any) {
+	rr.panics = append(rr.panics, recovered)
+}
+
+func (rr *recordingReporter) Flush(timeout time.Duration) bool {
+	return true
+}
+
+func setupRecordingReporter(t *testing.T) *recordingReporter {
+	t.Helper()
+
+	recorder := &recordingReporter{}
+	previousReporter := controller.CurrentErrorReporter()
+
+	controller.SetErrorReporter(recorder)
+	t.Cleanup(func() { controller.SetErrorReporter(previousReporter) })
+
+	return recorder
+}
+
+func TestWriteErrorResponseReporter(t *testing.T) {
+	recorder := setupRecordingReporter(t)
+
+	req, _ := http.NewRequest("GET", "/post", nil)
+
+	controller.WriteErrorResponse(httptest.NewRecorder(), req, controller.ErrorResponse{}, "/post", "post with id 1 not found", http.StatusBadRequest)
+	controller.WriteErrorResponse(httptest.NewRecorder(), req, controller.ErrorResponse{}, "/post", "error on post fetch", http.StatusInternalServerError)
+
+	if len(recorder.breadcrumbs) != 1 || recorder.breadcrumbs[0] != "response: 400 post with id 1 not found" {
+		t.Errorf("expected the client error as breadcrumb, got %v", recorder.breadcrumbs)
+	}
+	if len(recorder.errors) != 1 || recorder.errors[0] != "/post: error on post fetch" {
+		t.Errorf("expected the server error reported, got %v", recorder.errors)
+	}
+}
+
+func TestRecoveryReportsPanic(t *testing.T) {
+	recorder := setupRecordingReporter(t)
+
+	handler := controller.Recovery(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+
+	req, _ := http.NewRequest("GET", "/post", nil)
+	rr := httptest.NewRecorder()
+
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusInternalServerError {
+		t.Errorf("wrong status code: got %v want %v", rr.Code, http.StatusInternalServerError)
+	}
+	if len(recorder.panics) != 1 || recorder.panics[0] != "boom" {
+		t.Errorf("expected the panic reported once, got %v", recorder.panics)
+	}
+	if len(recorder.errors) != 0 {
+		t.Errorf("panic reported twice as error: %v", recorder.errors)
+	}
+}
+
+func TestStdoutReporter(t *testing.T) {
+	originalStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	req, _ := http.NewRequest("POST", "/post/publish", nil)
+	req = req.WithContext(context.WithValue(req.Context(), controller.CONTEXT_REQUEST_ID_KEY, "req-42"))
+	req = injectUserContext(req, 7)
+
+	controller.StdoutReporter{}.Error(req, "/posts", "queuer refused Bearer abc.def")
+
+	w.Close()
+
+	output, _ := io.ReadAll(r)
+
+	os.Stdout = originalStdout
+
+	got := string(output)
+
+	if !strings.Contains(got, `"msg":"reported error"`) || !strings.Contains(got, `"error":"queuer refused Bearer [redacted]"`) {
+		t.Errorf("expected scrubbed error on stdout, got %q", got)
+	}
+	if !strings.Contains(got, `"request_id":"req-42","user_id":7,"workspace_id":7`) {
+		t.Errorf("expected request context on stdout, got %q", got)
+	}
+}
@@ -0,0 +1,150 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"synk/gateway/app/controller"
+	"testing"
+
+	"github.com/getsentry/sentry-go"
+)
+
+func setupSentryTransport(t *testing.T) *sentry.MockTransport {
+	t.Helper()
+
+	transport := &sentry.MockTransport{}
+
+	client, clientErr := sentry.NewClient(sentry.ClientOptions{
+		Transport:        transport,
+		BeforeSend:       controller.ScrubSentryEvent,
+		BeforeBreadcrumb: controller.ScrubSentryBreadcrumb,
+	})
+	if clientErr != nil {
+		t.Fatalf("Setup failed: Could not create sentry client: %v", clientErr)
+	}
+
+	previousReporter := controller.CurrentErrorReporter()
+
+	sentry.CurrentHub().BindClient(client)
+	controller.SetErrorReporter(controller.SentryReporter{})
+
+	t.Cleanup(func() {
+		sentry.CurrentHub().BindClient(nil)
+		controller.SetErrorReporter(previousReporter)
+	})
+
+	return transport
+}
+
+func TestSentryReportsServerErrorsOnly(t *testing.T) {
+	transport := setupSentryTransport(t)
+
+	handler := controller.SentryHub(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response := controller.ErrorResponse{}
+
+		controller.WriteErrorResponse(httptest.NewRecorder(), r, response, "/post", "post with id 1 not found", http.StatusBadRequest)
+
+		if len(transport.Events()) != 0 {
+			t.Errorf("expected no event for a client error, got %d", len(transport.Events()))
+		}
+
+		controller.WriteErrorResponse(w, r, response, "/post", "queuer refused Bearer abc.def", http.StatusInternalServerError)
+	}))
+
+	req, _ := http.NewRequest("POST", "/post?access_token=abc&post_id=1", nil)
+	req.Header.Set("Authorization", "Bearer abc.def")
+	req.Header.Set(controller.API_KEY_HEADER, "synk_secret")
+	rr := httptest.NewRecorder()
+
+	handler.ServeHTTP(rr, req)
+
+	events := transport.Events()
+
+	if len(events) != 1 {
+		t.Fatalf("expected 1 event for a server error, got %d", len(events))
+	}
+
+	event := events[0]
+
+	if strings.Contains(event.Message, "abc.def") {
+		t.Errorf("bearer token leaked in message: %s", event.Message)
+	}
+	if event.Tags["route"] != "/post" {
+		t.Errorf("expected route tag /post, got %q", event.Tags["route"])
+	}
+	if len(event.Breadcrumbs) != 1 || !strings.Contains(event.Breadcrumbs[0].Message, "400") {
+		t.Errorf("expected the client error as breadcrumb, got %+v", event.Breadcrumbs)
+	}
+	if event.Request == nil {
+		t.Fatal("expected request data on the event")
+	}
+	if value, ok := event.Request.Headers[controller.API_KEY_HEADER]; ok && value != controller.AUDIT_REDACTED {
+		t.Errorf("api key header leaked: %s", value)
+	}
+	if strings.Contains(event.Request.QueryString, "access_token=abc") || !strings.Contains(event.Request.QueryString, "post_id=1") {
+		t.Errorf("expected only the token param redacted, got %s", event.Request.QueryString)
+	}
+}
+
+func TestSentryHubIsolatesRequests(t *testing.T) {
+	transport := setupSentryTransport(t)
+
+	handler := controller.SentryHub(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := http.StatusBadRequest
+
+		if r.URL.Path == "/fail" {
+			status = http.StatusInternalServerError
+		}
+
+		controller.WriteErrorResponse(w, r, controller.ErrorResponse{}, r.URL.Path, "error on "+r.URL.Path, status)
+	}))
+
+	for _, path := range []string{"/first", "/second", "/fail"} {
+		req, _ := http.NewRequest("GET", path, nil)
+
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	events := transport.Events()
+
+	if len(events) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(events))
+	}
+	if len(events[0].Breadcrumbs) != 0 {
+		t.Errorf("breadcrumbs of other requests leaked: %+v", events[0].Breadcrumbs)
+	}
+}
+
+func TestScrubText(t *testing.T) {
+	cases := map[string]string{
+		`auth failed for Bearer eyJhbGciOi.x-y`:           `auth failed for Bearer [redacted]`,
+		`invalid key synk_AbC-123_x`:                      `invalid key [redacted]`,
+		`{"int_credential_config": "{\"a\":1}", "id": 2}`: `{"int_credential_config": "[redacted]", "id": 2}`,
+		`{"accessToken":"tok","name":"ok"}`:               `{"accessToken": "[redacted]","name":"ok"}`,
+		`GET /send?client_secret=xyz&post_id=3`:           `GET /send?client_secret=[redacted]&post_id=3`,
+		`post with id 3 not found`:                        `post with id 3 not found`,
+	}
+
+	for text, expected := range cases {
+		if scrubbed := controller.ScrubText(text); scrubbed != expected {
+			t.Errorf("ScrubText(%q) = %q; want %q", text, scrubbed, expected)
+		}
+	}
+}
+
+func TestScrubSentryEventUser(t *testing.T) {
+	event := &sentry.Event{
+		User:  sentry.User{ID: "7", Email: "someone@example.com", IPAddress: "10.0.0.1"},
+		Extra: map[string]any{"api_key": "synk_x", "post_id": 3},
+	}
+
+	event = controller.ScrubSentryEvent(event, nil)
+
+	if event.User.ID != "7" || event.User.Email != "" || event.User.IPAddress != "" {
+		t.Errorf("expected only the user ID kept, got %+v", event.User)
+	}
+	if event.Extra["api_key"] != controller.AUDIT_REDACTED || event.Extra["post_id"] != 3 {
+		t.Errorf("unexpected extra after scrub: %+v", event.Extra)
+	}
+}
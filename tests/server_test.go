@@ -0,0 +1,140 @@
+package tests
+
+import (
+	"context"
+	"database/sql"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"synk/gateway/app"
+	"synk/gateway/app/config"
+	"testing"
+	"time"
+
+	_ "github.com/go-sql-driver/mysql"
+)
+
+func TestNewServer(t *testing.T) {
+	cfg := config.Default()
+	cfg.Server.Port = "8083"
+	cfg.Server.WriteTimeout = 45 * time.Second
+
+	server := app.NewServer(cfg.Server, http.NotFoundHandler(), nil)
+
+	if server.Addr != ":8083" || server.WriteTimeout != 45*time.Second || server.IdleTimeout != 60*time.Second {
+		t.Errorf("expected server built from config, got %s %s %s", server.Addr, server.WriteTimeout, server.IdleTimeout)
+	}
+	if server.TLSConfig != nil {
+		t.Error("expected plain HTTP without certificates")
+	}
+}
+
+func TestRouterBuiltTwice(t *testing.T) {
+	db, _ := sql.Open("mysql", "gateway@tcp(127.0.0.1:1)/synk_router_test")
+	defer db.Close()
+
+	service := &app.Service{Config: config.Default(), DB: db, Client: http.DefaultClient}
+
+	for i := 0; i < 2; i++ {
+		handler, routerErr := app.Router(service)
+
+		if routerErr != nil {
+			t.Fatalf("expected router %d to be built, got %v", i+1, routerErr)
+		}
+
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest("GET", "/healthz", nil))
+
+		if rr.Code != http.StatusOK {
+			t.Errorf("expected router %d to serve its routes, got %d", i+1, rr.Code)
+		}
+	}
+}
+
+func serveInBackground(t *testing.T, handler http.Handler, shutdownTimeout time.Duration) (string, context.CancelFunc, chan error) {
+	t.Helper()
+
+	listener, listenErr := net.Listen("tcp", "127.0.0.1:0")
+	if listenErr != nil {
+		t.Fatalf("failed to listen: %v", listenErr)
+	}
+
+	serverConfig := config.Server{ShutdownTimeout: shutdownTimeout}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+
+	go func() {
+		done <- app.Serve(ctx, app.NewServer(serverConfig, handler, nil), listener, serverConfig)
+	}()
+
+	return "http://" + listener.Addr().String(), cancel, done
+}
+
+func TestServe_DrainsInFlightRequests(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	url, cancel, done := serveInBackground(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		w.WriteHeader(http.StatusAccepted)
+	}), time.Second*5)
+
+	status := make(chan int, 1)
+
+	go func() {
+		resp, respErr := http.Get(url + "/post/publish")
+
+		if respErr != nil {
+			status <- 0
+
+			return
+		}
+
+		resp.Body.Close()
+		status <- resp.StatusCode
+	}()
+
+	<-started
+	cancel()
+
+	select {
+	case err := <-done:
+		t.Fatalf("expected Serve to wait for the in-flight request, returned %v", err)
+	case <-time.After(time.Millisecond * 50):
+	}
+
+	close(release)
+
+	if code := <-status; code != http.StatusAccepted {
+		t.Errorf("expected the in-flight request to finish with 202, got %d", code)
+	}
+	if err := <-done; err != nil {
+		t.Errorf("expected a clean shutdown, got %v", err)
+	}
+}
+
+func TestServe_ShutdownTimeout(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+
+	url, cancel, done := serveInBackground(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	}), time.Millisecond*50)
+
+	go http.Get(url)
+
+	<-started
+	cancel()
+
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Error("expected an error when requests outlive SHUTDOWN_TIMEOUT")
+		}
+	case <-time.After(time.Second * 2):
+		t.Fatal("expected Serve to give up after SHUTDOWN_TIMEOUT")
+	}
+}
@@ -37,9 +37,10 @@ func TestTemplates_Add(t *testing.T) {
 		TemplateContent:   "<p>Content</p>",
 		TemplateUrlImport: "http://test.com",
 		UserId:            userId,
+		WorkspaceId:       SEED_WORKSPACE_ID,
 	}
 
-	id, err := tplModel.Add(input)
+	id, err := tplModel.Add(context.Background(), input)
 	if err != nil {
 		t.Fatalf("Add failed: %v", err)
 	}
@@ -59,8 +60,9 @@ func TestTemplates_Update(t *testing.T) {
 		TemplateName:    "Pre-Update",
 		TemplateContent: "Old",
 		UserId:          userId,
+		WorkspaceId:     SEED_WORKSPACE_ID,
 	}
-	id, err := tplModel.Add(createInput)
+	id, err := tplModel.Add(context.Background(), createInput)
 	if err != nil {
 		t.Fatalf("Setup failed: %v", err)
 	}
@@ -73,7 +75,7 @@ func TestTemplates_Update(t *testing.T) {
 		TemplateUrlImport: "http://updated.com",
 	}
 
-	rows, err := tplModel.Update(updateInput, userId)
+	rows, err := tplModel.Update(context.Background(), updateInput, SEED_WORKSPACE_ID)
 	if err != nil {
 		t.Fatalf("Update failed: %v", err)
 	}
@@ -81,7 +83,7 @@ func TestTemplates_Update(t *testing.T) {
 		t.Errorf("Expected 1 row updated, got %d", rows)
 	}
 
-	list, _ := tplModel.List(strconv.Itoa(id), true, userId)
+	list, _ := tplModel.List(context.Background(), strconv.Itoa(id), true, SEED_WORKSPACE_ID)
 	if len(list) > 0 {
 		if list[0].TemplateName != "Post-Update" {
 			t.Errorf("Update name not persisted. Got %s", list[0].TemplateName)
@@ -92,17 +94,51 @@ func TestTemplates_Update(t *testing.T) {
 	}
 }
 
+func TestTemplates_UpdateVersion(t *testing.T) {
+	db, userId := setupTemplatesDB(t)
+	defer db.Close()
+	tplModel := model.NewTemplates(db)
+
+	id, err := tplModel.Add(context.Background(), model.TemplateAddData{TemplateName: "Versioned", UserId: userId, WorkspaceId: SEED_WORKSPACE_ID})
+	if err != nil {
+		t.Fatalf("Setup failed: %v", err)
+	}
+	defer db.Exec("DELETE FROM template WHERE template_id = ?", id)
+
+	byId, _ := tplModel.ById(context.Background(), id, SEED_WORKSPACE_ID)
+	if byId.TemplateVersion != 1 {
+		t.Fatalf("Expected version 1 on creation, got %d", byId.TemplateVersion)
+	}
+
+	updateInput := model.TemplateUpdateData{TemplateId: id, TemplateName: "Versioned v2", TemplateVersion: 1}
+
+	rows, err := tplModel.Update(context.Background(), updateInput, SEED_WORKSPACE_ID)
+	if err != nil || rows != 1 {
+		t.Fatalf("Expected update with current version to pass, got rows %d, err %v", rows, err)
+	}
+
+	rows, err = tplModel.Update(context.Background(), updateInput, SEED_WORKSPACE_ID)
+	if err != nil || rows != 0 {
+		t.Errorf("Expected update with stale version to affect no rows, got rows %d, err %v", rows, err)
+	}
+
+	byId, _ = tplModel.ById(context.Background(), id, SEED_WORKSPACE_ID)
+	if byId.TemplateVersion != 2 {
+		t.Errorf("Expected version 2 after update, got %d", byId.TemplateVersion)
+	}
+}
+
 func TestTemplates_Delete(t *testing.T) {
 	db, userId := setupTemplatesDB(t)
 	defer db.Close()
 	tplModel := model.NewTemplates(db)
 
-	createInput := model.TemplateAddData{TemplateName: "To Delete", UserId: userId}
-	id, _ := tplModel.Add(createInput)
+	createInput := model.TemplateAddData{TemplateName: "To Delete", UserId: userId, WorkspaceId: SEED_WORKSPACE_ID}
+	id, _ := tplModel.Add(context.Background(), createInput)
 
 	defer db.Exec("DELETE FROM template WHERE template_id = ?", id)
 
-	rows, err := tplModel.Delete(id, userId)
+	rows, err := tplModel.Delete(context.Background(), id, 0, SEED_WORKSPACE_ID)
 	if err != nil {
 		t.Fatalf("Delete failed: %v", err)
 	}
@@ -110,7 +146,7 @@ func TestTemplates_Delete(t *testing.T) {
 		t.Errorf("Expected 1 row deleted, got %d", rows)
 	}
 
-	list, _ := tplModel.List(strconv.Itoa(id), false, userId)
+	list, _ := tplModel.List(context.Background(), strconv.Itoa(id), false, SEED_WORKSPACE_ID)
 	if len(list) != 0 {
 		t.Error("Item returned after soft delete")
 	}
@@ -125,11 +161,12 @@ func TestTemplates_List(t *testing.T) {
 		TemplateName:    "List Test",
 		TemplateContent: "Secret Content",
 		UserId:          userId,
+		WorkspaceId:     SEED_WORKSPACE_ID,
 	}
-	id, _ := tplModel.Add(createInput)
+	id, _ := tplModel.Add(context.Background(), createInput)
 	defer db.Exec("DELETE FROM template WHERE template_id = ?", id)
 
-	listFull, err := tplModel.List(strconv.Itoa(id), true, userId)
+	listFull, err := tplModel.List(context.Background(), strconv.Itoa(id), true, SEED_WORKSPACE_ID)
 	if err != nil {
 		t.Fatalf("List (full) failed: %v", err)
 	}
@@ -140,14 +177,14 @@ func TestTemplates_List(t *testing.T) {
 		t.Error("Expected content to be present")
 	}
 
-	listShort, _ := tplModel.List(strconv.Itoa(id), false, userId)
+	listShort, _ := tplModel.List(context.Background(), strconv.Itoa(id), false, SEED_WORKSPACE_ID)
 	if len(listShort) > 0 {
 		if listShort[0].TemplateContent != "" {
 			t.Error("Expected content to be empty string")
 		}
 	}
 
-	listAll, _ := tplModel.List("", false, userId)
+	listAll, _ := tplModel.List(context.Background(), "", false, SEED_WORKSPACE_ID)
 	if len(listAll) == 0 {
 		t.Error("List all returned 0 items")
 	}
@@ -158,11 +195,11 @@ func TestTemplates_BasicList(t *testing.T) {
 	defer db.Close()
 	tplModel := model.NewTemplates(db)
 
-	createInput := model.TemplateAddData{TemplateName: "Basic List Test", UserId: userId}
-	id, _ := tplModel.Add(createInput)
+	createInput := model.TemplateAddData{TemplateName: "Basic List Test", UserId: userId, WorkspaceId: SEED_WORKSPACE_ID}
+	id, _ := tplModel.Add(context.Background(), createInput)
 	defer db.Exec("DELETE FROM template WHERE template_id = ?", id)
 
-	list, err := tplModel.BasicList(userId)
+	list, err := tplModel.BasicList(context.Background(), SEED_WORKSPACE_ID)
 	if err != nil {
 		t.Fatalf("BasicList failed: %v", err)
 	}
@@ -187,11 +224,11 @@ func TestTemplates_ById(t *testing.T) {
 	defer db.Close()
 	tplModel := model.NewTemplates(db)
 
-	createInput := model.TemplateAddData{TemplateName: "ById Test", UserId: userId}
-	id, _ := tplModel.Add(createInput)
+	createInput := model.TemplateAddData{TemplateName: "ById Test", UserId: userId, WorkspaceId: SEED_WORKSPACE_ID}
+	id, _ := tplModel.Add(context.Background(), createInput)
 	defer db.Exec("DELETE FROM template WHERE template_id = ?", id)
 
-	data, err := tplModel.ById(id, userId)
+	data, err := tplModel.ById(context.Background(), id, SEED_WORKSPACE_ID)
 	if err != nil {
 		t.Fatalf("ById failed: %v", err)
 	}
@@ -211,15 +248,16 @@ func TestTemplateLifecycle(t *testing.T) {
 		TemplateContent:   "<html>...</html>",
 		TemplateUrlImport: "http://lifecycle.com",
 		UserId:            userId,
+		WorkspaceId:       SEED_WORKSPACE_ID,
 	}
-	createdId, err := tplModel.Add(newTpl)
+	createdId, err := tplModel.Add(context.Background(), newTpl)
 	if err != nil {
 		t.Fatalf("lifecycle: add failed: %v", err)
 	}
 
 	defer db.ExecContext(context.Background(), "DELETE FROM template WHERE template_id = ?", createdId)
 
-	list, _ := tplModel.List(strconv.Itoa(createdId), true, userId)
+	list, _ := tplModel.List(context.Background(), strconv.Itoa(createdId), true, SEED_WORKSPACE_ID)
 	if len(list) != 1 {
 		t.Error("lifecycle: creation check failed")
 	}
@@ -230,12 +268,48 @@ func TestTemplateLifecycle(t *testing.T) {
 		TemplateContent:   "New",
 		TemplateUrlImport: "",
 	}
-	tplModel.Update(updateData, userId)
+	tplModel.Update(context.Background(), updateData, SEED_WORKSPACE_ID)
 
-	tplModel.Delete(createdId, userId)
+	tplModel.Delete(context.Background(), createdId, 0, SEED_WORKSPACE_ID)
 
-	finalList, _ := tplModel.List(strconv.Itoa(createdId), false, userId)
+	finalList, _ := tplModel.List(context.Background(), strconv.Itoa(createdId), false, SEED_WORKSPACE_ID)
 	if len(finalList) != 0 {
 		t.Error("lifecycle: soft delete check failed")
 	}
 }
+
+func TestTemplates_Clone(t *testing.T) {
+	db, userId := setupTemplatesDB(t)
+	defer db.Close()
+	tplModel := model.NewTemplates(db)
+
+	createInput := model.TemplateAddData{
+		TemplateName:      "Clone Source",
+		TemplateContent:   "Clone Content",
+		TemplateUrlImport: "http://clone.com",
+		UserId:            userId,
+		WorkspaceId:       SEED_WORKSPACE_ID,
+	}
+	id, err := tplModel.Add(context.Background(), createInput)
+	if err != nil {
+		t.Fatalf("Setup failed: %v", err)
+	}
+	defer db.Exec("DELETE FROM template WHERE template_id = ?", id)
+
+	clonedId, err := tplModel.Clone(context.Background(), id, SEED_WORKSPACE_ID)
+	if err != nil {
+		t.Fatalf("Clone failed: %v", err)
+	}
+	defer db.Exec("DELETE FROM template WHERE template_id = ?", clonedId)
+
+	list, _ := tplModel.List(context.Background(), strconv.Itoa(clonedId), true, SEED_WORKSPACE_ID)
+	if len(list) != 1 {
+		t.Fatalf("Expected cloned template to be listed, got %d items", len(list))
+	}
+	if list[0].TemplateName != "Clone Source"+model.CLONE_NAME_SUFFIX {
+		t.Errorf("Unexpected cloned name. Got %s", list[0].TemplateName)
+	}
+	if list[0].TemplateContent != "Clone Content" || list[0].TemplateUrlImport != "http://clone.com" {
+		t.Error("Template fields not cloned")
+	}
+}
@@ -0,0 +1,200 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"synk/gateway/app"
+	"synk/gateway/app/auth"
+	"synk/gateway/app/config"
+	"synk/gateway/app/controller"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const TRACE_TEST_TRACE_ID = "4bf92f3577b34da6a3ce929d0e0e4736"
+const TRACE_TEST_PARENT_ID = "00f067aa0ba902b7"
+const TRACE_TEST_TRACEPARENT = "00-" + TRACE_TEST_TRACE_ID + "-" + TRACE_TEST_PARENT_ID + "-01"
+
+func setupSpanRecorder(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+
+	recorder := tracetest.NewSpanRecorder()
+
+	previousProvider := otel.GetTracerProvider()
+	previousPropagator := otel.GetTextMapPropagator()
+
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+
+	return recorder
+}
+
+func forwardedSpanContext(header http.Header) trace.SpanContext {
+	ctx := propagation.TraceContext{}.Extract(context.Background(), propagation.HeaderCarrier(header))
+
+	return trace.SpanContextFromContext(ctx)
+}
+
+func TestTracePropagation(t *testing.T) {
+	recorder := setupSpanRecorder(t)
+
+	var forwarded http.Header
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		forwarded = r.Header.Clone()
+
+		w.Write([]byte(`{"resource":{"ok":true},"user":{"user_id":7}}`))
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: controller.PropagatingTransport{}, Timeout: time.Second}
+	authenticator := auth.NewAuthenticator(nil, auth.NewCache(0, 0), client, server.URL)
+
+	handler := controller.Chain(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authenticator.Authenticate(r.Context(), "Bearer opaque-token")
+		}),
+		controller.RequestId,
+		controller.Tracing,
+	)
+
+	req, _ := http.NewRequest("GET", "/w/7/post", nil)
+	req.Header.Set(controller.REQUEST_ID_HEADER, "req-42")
+	req.Header.Set(controller.TRACEPARENT_HEADER, TRACE_TEST_TRACEPARENT)
+
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if forwarded.Get(controller.REQUEST_ID_HEADER) != "req-42" {
+		t.Errorf("expected request id forwarded to auth, got %q", forwarded.Get(controller.REQUEST_ID_HEADER))
+	}
+
+	spans := recorder.Ended()
+
+	if len(spans) != 2 {
+		t.Fatalf("expected client and server spans, got %d", len(spans))
+	}
+
+	clientSpan, serverSpan := spans[0], spans[1]
+
+	if serverSpan.Name() != "GET /post" || serverSpan.SpanKind() != trace.SpanKindServer {
+		t.Errorf("unexpected server span %q of kind %v", serverSpan.Name(), serverSpan.SpanKind())
+	}
+	if serverSpan.SpanContext().TraceID().String() != TRACE_TEST_TRACE_ID || serverSpan.Parent().SpanID().String() != TRACE_TEST_PARENT_ID {
+		t.Errorf("expected the incoming trace to be joined, got parent %v", serverSpan.Parent())
+	}
+	if clientSpan.Name() != "GET /users/check" || clientSpan.Parent().SpanID() != serverSpan.SpanContext().SpanID() {
+		t.Errorf("expected auth call as child span, got %q with parent %v", clientSpan.Name(), clientSpan.Parent())
+	}
+
+	forwardedContext := forwardedSpanContext(forwarded)
+
+	if forwardedContext.TraceID().String() != TRACE_TEST_TRACE_ID || forwardedContext.SpanID() != clientSpan.SpanContext().SpanID() {
+		t.Errorf("expected the client span forwarded, got %q", forwarded.Get(controller.TRACEPARENT_HEADER))
+	}
+
+	req, _ = http.NewRequest("GET", "/post", nil)
+
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if newContext := forwardedSpanContext(forwarded); !newContext.IsValid() || newContext.TraceID().String() == TRACE_TEST_TRACE_ID {
+		t.Errorf("expected a new trace started, got %q", forwarded.Get(controller.TRACEPARENT_HEADER))
+	}
+}
+
+func TestTracingMarksServerErrors(t *testing.T) {
+	recorder := setupSpanRecorder(t)
+
+	handler := controller.Tracing(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		controller.WriteErrorResponse(w, r, controller.ErrorResponse{}, "/posts", "queue url not set at config", http.StatusInternalServerError)
+	}))
+
+	req, _ := http.NewRequest("POST", "/post/publish", nil)
+
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := recorder.Ended()
+
+	if len(spans) != 1 || spans[0].Status().Code != codes.Error {
+		t.Fatalf("expected one span marked as error, got %+v", spans)
+	}
+}
+
+func TestInitTracing(t *testing.T) {
+	previousProvider := otel.GetTracerProvider()
+	previousPropagator := otel.GetTextMapPropagator()
+
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+
+	if _, initErr := app.InitTracing("jaeger"); initErr == nil {
+		t.Error("expected an error for OTEL_TRACES_EXPORTER jaeger")
+	}
+
+	shutdown, initErr := app.InitTracing(config.TRACES_EXPORTER_NONE)
+
+	if initErr != nil {
+		t.Fatalf("unexpected error: %v", initErr)
+	}
+
+	defer shutdown(context.Background())
+
+	_, span := otel.Tracer(controller.TRACER_NAME).Start(context.Background(), "check")
+	defer span.End()
+
+	if !span.SpanContext().IsValid() {
+		t.Error("expected spans to be created without an exporter")
+	}
+}
+
+func TestErrorResponseRequestId(t *testing.T) {
+	handler := controller.RequestId(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response := controller.ErrorResponse{
+			Resource: controller.ResponseHeader{
+				Ok:    false,
+				Error: "post with id 1 not found",
+			},
+		}
+
+		controller.WriteErrorResponse(w, r, response, "/posts", response.Resource.Error, http.StatusBadRequest)
+	}))
+
+	req, _ := http.NewRequest("GET", "/post", nil)
+	req.Header.Set(controller.REQUEST_ID_HEADER, "req-42")
+	rr := httptest.NewRecorder()
+
+	handler.ServeHTTP(rr, req)
+
+	var response controller.ErrorResponse
+
+	if decodeErr := json.Unmarshal(rr.Body.Bytes(), &response); decodeErr != nil {
+		t.Fatalf("could not decode response: %v", decodeErr)
+	}
+
+	if response.Resource.RequestId != "req-42" || response.Resource.Error != "post with id 1 not found" {
+		t.Errorf("expected the request id on the error, got %+v", response.Resource)
+	}
+
+	success := httptest.NewRecorder()
+	controller.WriteSuccessResponse(success, controller.ErrorResponse{Resource: controller.ResponseHeader{Ok: true}})
+
+	if strings.Contains(success.Body.String(), "request_id") {
+		t.Errorf("expected no request id on success, got %s", success.Body.String())
+	}
+}
@@ -0,0 +1,77 @@
+package tests
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"synk/gateway/app"
+	"synk/gateway/app/model"
+	"testing"
+)
+
+func TestWithTx_Commit(t *testing.T) {
+	db, err := app.InitDB(true)
+	if err != nil {
+		t.Fatalf("db connection failed: %v", err)
+	}
+	defer db.Close()
+
+	var templateId int64
+
+	txErr := model.WithTx(context.Background(), db, func(tx *sql.Tx) error {
+		res, insertErr := tx.ExecContext(context.Background(),
+			`INSERT INTO template (template_name, template_content, template_url_import, user_id, workspace_id)
+			 VALUES ('Tx Commit Template', 'x', 'x', 1, 1)`)
+		if insertErr != nil {
+			return insertErr
+		}
+
+		templateId, _ = res.LastInsertId()
+
+		return nil
+	})
+	if txErr != nil {
+		t.Fatalf("WithTx failed: %v", txErr)
+	}
+	defer db.Exec("DELETE FROM template WHERE template_id = ?", templateId)
+
+	var count int
+	db.QueryRow("SELECT COUNT(*) FROM template WHERE template_id = ?", templateId).Scan(&count)
+	if count != 1 {
+		t.Errorf("Expected committed row to exist, got %d rows", count)
+	}
+}
+
+func TestWithTx_Rollback(t *testing.T) {
+	db, err := app.InitDB(true)
+	if err != nil {
+		t.Fatalf("db connection failed: %v", err)
+	}
+	defer db.Close()
+
+	var templateId int64
+	fnErr := errors.New("forced failure")
+
+	txErr := model.WithTx(context.Background(), db, func(tx *sql.Tx) error {
+		res, insertErr := tx.ExecContext(context.Background(),
+			`INSERT INTO template (template_name, template_content, template_url_import, user_id, workspace_id)
+			 VALUES ('Tx Rollback Template', 'x', 'x', 1, 1)`)
+		if insertErr != nil {
+			return insertErr
+		}
+
+		templateId, _ = res.LastInsertId()
+
+		return fnErr
+	})
+	if !errors.Is(txErr, fnErr) {
+		t.Fatalf("Expected WithTx to return the callback error, got %v", txErr)
+	}
+	defer db.Exec("DELETE FROM template WHERE template_id = ?", templateId)
+
+	var count int
+	db.QueryRow("SELECT COUNT(*) FROM template WHERE template_id = ?", templateId).Scan(&count)
+	if count != 0 {
+		t.Errorf("Expected row to be rolled back, got %d rows", count)
+	}
+}
@@ -0,0 +1,80 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"synk/gateway/app"
+	"synk/gateway/app/model"
+	"testing"
+)
+
+func TestWorkspaces_Lifecycle(t *testing.T) {
+	db, err := app.InitDB(true)
+	if err != nil {
+		t.Fatalf("db connection failed: %v", err)
+	}
+	defer db.Close()
+
+	workspacesModel := model.NewWorkspaces(db)
+	userId := 1
+
+	personalId, err := workspacesModel.Personal(context.Background(), userId)
+	if err != nil {
+		t.Fatalf("Personal failed: %v", err)
+	}
+	if personalId != SEED_WORKSPACE_ID {
+		t.Errorf("expected seed personal workspace %d, got %d", SEED_WORKSPACE_ID, personalId)
+	}
+
+	res, err := db.Exec(`INSERT INTO user (user_name, user_email, user_pass) VALUES ('Member', 'member@synk.com', '123456')`)
+	if err != nil {
+		t.Fatalf("Setup failed: Could not create member: %v", err)
+	}
+	memberId64, _ := res.LastInsertId()
+	memberId := int(memberId64)
+	defer db.Exec("DELETE FROM user WHERE user_id = ?", memberId)
+
+	teamId, err := workspacesModel.Add(context.Background(), model.WorkspaceAddData{WorkspaceName: "Lifecycle Team"}, userId)
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	defer db.Exec("DELETE FROM workspace WHERE workspace_id = ?", teamId)
+	defer db.Exec("DELETE FROM workspace_member WHERE workspace_id = ?", teamId)
+
+	if role, _ := workspacesModel.Role(context.Background(), teamId, userId); role != model.WorkspaceRoleOwner {
+		t.Errorf("expected creator to be owner, got '%s'", role)
+	}
+
+	if rows, err := workspacesModel.AddMember(context.Background(), teamId, memberId, model.WorkspaceRoleViewer); err != nil || rows != 1 {
+		t.Fatalf("AddMember failed: rows %d err %v", rows, err)
+	}
+	if _, err := workspacesModel.AddMember(context.Background(), teamId, memberId, model.WorkspaceRoleViewer); !errors.Is(err, model.ErrMemberExists) {
+		t.Errorf("expected ErrMemberExists for a duplicate member, got %v", err)
+	}
+	if _, err := workspacesModel.AddMember(context.Background(), teamId, memberId+1000, model.WorkspaceRoleViewer); !errors.Is(err, model.ErrUserNotFound) {
+		t.Errorf("expected ErrUserNotFound for an unknown user, got %v", err)
+	}
+	if rows, _ := workspacesModel.UpdateMember(context.Background(), teamId, memberId, model.WorkspaceRoleEditor); rows != 1 {
+		t.Errorf("expected member to be updated, got %d", rows)
+	}
+
+	members, err := workspacesModel.Members(context.Background(), teamId)
+	if err != nil {
+		t.Fatalf("Members failed: %v", err)
+	}
+	if len(members) != 2 {
+		t.Errorf("expected 2 members, got %+v", members)
+	}
+
+	list, _ := workspacesModel.List(context.Background(), memberId)
+	if len(list) != 1 || list[0].WorkspaceId != teamId || list[0].WorkspaceRole != model.WorkspaceRoleEditor {
+		t.Errorf("expected member to list the team workspace as editor, got %+v", list)
+	}
+
+	if rows, _ := workspacesModel.RemoveMember(context.Background(), teamId, memberId); rows != 1 {
+		t.Errorf("expected member to be removed, got %d", rows)
+	}
+	if role, _ := workspacesModel.Role(context.Background(), teamId, memberId); role != "" {
+		t.Errorf("expected removed member to have no role, got '%s'", role)
+	}
+}